@@ -1,13 +1,41 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
 	"task-manager/Delivery/dto"
 	domain "task-manager/Domain"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
 )
 
+// requesterFromContext builds the caller's claims from the values set by AuthMiddleware.
+func requesterFromContext(c *gin.Context) domain.Claims {
+	role, _ := c.Get("role")
+	r, _ := role.(domain.Role)
+	permissions, _ := c.Get("permissions")
+	p, _ := permissions.([]domain.Permission)
+	return domain.Claims{
+		UserID:      c.GetString("userID"),
+		Username:    c.GetString("username"),
+		Role:        r,
+		TokenID:     c.GetString("tokenID"),
+		ExpiresAt:   c.GetTime("tokenExpiresAt"),
+		Permissions: p,
+	}
+}
+
 // --- USER CONTROLLER ---
 type UserController struct {
 	userUseCase domain.IUserUseCase
@@ -17,53 +45,407 @@ func NewUserController(userUseCase domain.IUserUseCase) *UserController {
 	return &UserController{userUseCase: userUseCase}
 }
 
+func toUserResponse(u *domain.User) dto.UserResponse {
+	return dto.UserResponse{ID: u.ID, Username: u.Username, Role: string(u.Role), Email: u.Email, Disabled: u.Disabled}
+}
+
 func (uc *UserController) Register(c *gin.Context) {
 	var req dto.RegisterUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, invalidInput(err))
 		return
 	}
-	user := domain.User{Username: req.Username, Password: req.Password}
+	user := domain.User{Username: req.Username, Password: req.Password, Email: req.Email}
 
-	registeredUser, err := uc.userUseCase.Register(user)
+	registeredUser, err := uc.userUseCase.Register(c.Request.Context(), user)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
-	res := dto.UserResponse{ID: registeredUser.ID, Username: registeredUser.Username, Role: string(registeredUser.Role)}
-	c.JSON(http.StatusCreated, res)
+	c.JSON(http.StatusCreated, toUserResponse(registeredUser))
 }
 
 func (uc *UserController) Login(c *gin.Context) {
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		respondError(c, invalidInput(err))
+		return
+	}
+	result, err := uc.userUseCase.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toLoginResponse(result))
+}
+
+func (uc *UserController) CompleteMFALogin(c *gin.Context) {
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	result, err := uc.userUseCase.CompleteMFALogin(c.Request.Context(), req.MFAPending, req.Code, c.ClientIP())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toLoginResponse(result))
+}
+
+// StartMFALoginEnrollment lets a user whose role requires two-factor
+// authentication enroll in the middle of logging in.
+func (uc *UserController) StartMFALoginEnrollment(c *gin.Context) {
+	var req dto.MFALoginEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	setup, err := uc.userUseCase.StartMFALoginEnrollment(c.Request.Context(), req.MFAPending)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.MFASetupResponse{Secret: setup.Secret, URI: setup.URI})
+}
+
+func toLoginResponse(result *domain.LoginResult) dto.LoginResponse {
+	if result.Tokens == nil {
+		return dto.LoginResponse{
+			MFAPending:            result.MFAPending,
+			MFAExpiresAt:          &result.MFAExpiresAt,
+			MFAEnrollmentRequired: result.MFAEnrollmentRequired,
+		}
+	}
+	return dto.LoginResponse{
+		Token:         result.Tokens.AccessToken,
+		RefreshToken:  result.Tokens.RefreshToken,
+		RecoveryCodes: result.RecoveryCodes,
+	}
+}
+
+func (uc *UserController) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
 		return
 	}
-	token, err := uc.userUseCase.Login(req.Username, req.Password)
+	tokens, err := uc.userUseCase.Refresh(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
-	res := dto.LoginResponse{Token: token}
+	res := dto.LoginResponse{Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
 	c.JSON(http.StatusOK, res)
 }
 
+func (uc *UserController) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	// The body is optional, without it only the access token is revoked
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, invalidInput(err))
+			return
+		}
+	}
+	err := uc.userUseCase.Logout(c.Request.Context(), requesterFromContext(c), req.RefreshToken)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	tokens, err := uc.userUseCase.ChangePassword(c.Request.Context(), c.GetString("userID"), req.OldPassword, req.NewPassword, c.ClientIP())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.LoginResponse{Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken})
+}
+
+// RequestPasswordReset answers the same whether or not the user exists.
+func (uc *UserController) RequestPasswordReset(c *gin.Context) {
+	var req dto.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	if err := uc.userUseCase.RequestPasswordReset(c.Request.Context(), req.Username); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account has an email address, a reset link has been sent to it"})
+}
+
+func (uc *UserController) ConfirmPasswordReset(c *gin.Context) {
+	var req dto.ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	if err := uc.userUseCase.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+func (uc *UserController) StartMFAEnrollment(c *gin.Context) {
+	setup, err := uc.userUseCase.StartMFAEnrollment(c.Request.Context(), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.MFASetupResponse{Secret: setup.Secret, URI: setup.URI})
+}
+
+func (uc *UserController) ConfirmMFAEnrollment(c *gin.Context) {
+	var req dto.ConfirmMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	codes, err := uc.userUseCase.ConfirmMFAEnrollment(c.Request.Context(), c.GetString("userID"), req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (uc *UserController) DisableMFA(c *gin.Context) {
+	var req dto.DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	if err := uc.userUseCase.DisableMFA(c.Request.Context(), c.GetString("userID"), req.Password); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
 func (uc *UserController) PromoteUser(c *gin.Context) {
 	var req dto.PromoteUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, invalidInput(err))
 		return
 	}
 	promoterID, _ := c.Get("userID")
-	err := uc.userUseCase.PromoteUser(req.Username, promoterID.(string))
+	err := uc.userUseCase.PromoteUser(c.Request.Context(), req.Username, promoterID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User promoted successfully"})
 }
 
+func (uc *UserController) AssignRole(c *gin.Context) {
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	user, err := uc.userUseCase.AssignRole(c.Request.Context(), c.Param("id"), domain.Role(req.Role), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+func (uc *UserController) ListUsers(c *gin.Context) {
+	var req dto.UserListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	query := domain.UserQuery{Role: domain.Role(req.Role), Disabled: req.Disabled, Cursor: req.Cursor, Limit: req.Limit}
+	page, err := uc.userUseCase.ListUsers(c.Request.Context(), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := dto.UserPageResponse{Items: []dto.UserResponse{}, NextCursor: page.NextCursor}
+	for i := range page.Users {
+		res.Items = append(res.Items, toUserResponse(&page.Users[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (uc *UserController) GetUser(c *gin.Context) {
+	user, err := uc.userUseCase.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+func (uc *UserController) DemoteUser(c *gin.Context) {
+	user, err := uc.userUseCase.DemoteUser(c.Request.Context(), c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+func (uc *UserController) DisableUser(c *gin.Context) {
+	uc.setDisabled(c, true)
+}
+
+func (uc *UserController) EnableUser(c *gin.Context) {
+	uc.setDisabled(c, false)
+}
+
+func (uc *UserController) setDisabled(c *gin.Context, disabled bool) {
+	user, err := uc.userUseCase.SetUserDisabled(c.Request.Context(), c.Param("id"), disabled, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+func (uc *UserController) DeleteUser(c *gin.Context) {
+	if err := uc.userUseCase.DeleteUser(c.Request.Context(), c.Param("id"), c.GetString("userID")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+func (uc *UserController) ResetMFA(c *gin.Context) {
+	if err := uc.userUseCase.ResetMFA(c.Request.Context(), c.Param("id"), c.GetString("userID")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+// --- PROJECT CONTROLLER ---
+
+type ProjectController struct {
+	projectUseCase domain.IProjectUseCase
+}
+
+func NewProjectController(projectUseCase domain.IProjectUseCase) *ProjectController {
+	return &ProjectController{projectUseCase: projectUseCase}
+}
+
+func toProjectResponse(p *domain.Project) dto.ProjectResponse {
+	res := dto.ProjectResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		CreatedBy:   p.CreatedBy,
+		Members:     []dto.ProjectMemberResponse{},
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	for _, m := range p.Members {
+		res.Members = append(res.Members, dto.ProjectMemberResponse{UserID: m.UserID, Role: string(m.Role), AddedAt: m.AddedAt})
+	}
+	return res
+}
+
+func (pc *ProjectController) ListProjects(c *gin.Context) {
+	projects, err := pc.projectUseCase.ListProjects(c.Request.Context(), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	res := dto.ProjectListResponse{Items: []dto.ProjectResponse{}}
+	for i := range projects {
+		res.Items = append(res.Items, toProjectResponse(&projects[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (pc *ProjectController) CreateProject(c *gin.Context) {
+	var req dto.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	project, err := pc.projectUseCase.CreateProject(c.Request.Context(), domain.Project{Name: req.Name, Description: req.Description}, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toProjectResponse(project))
+}
+
+func (pc *ProjectController) GetProject(c *gin.Context) {
+	project, err := pc.projectUseCase.GetProject(c.Request.Context(), c.Param("pid"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) UpdateProject(c *gin.Context) {
+	var req dto.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	patch := domain.ProjectPatch{Name: req.Name, Description: req.Description}
+
+	project, err := pc.projectUseCase.UpdateProject(c.Request.Context(), c.Param("pid"), patch, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) DeleteProject(c *gin.Context) {
+	if err := pc.projectUseCase.DeleteProject(c.Request.Context(), c.Param("pid"), c.GetString("userID")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
+
+func (pc *ProjectController) SetMember(c *gin.Context) {
+	var req dto.SetProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	project, err := pc.projectUseCase.SetMember(c.Request.Context(), c.Param("pid"), c.Param("userId"), domain.ProjectRole(req.Role), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) RemoveMember(c *gin.Context) {
+	project, err := pc.projectUseCase.RemoveMember(c.Request.Context(), c.Param("pid"), c.Param("userId"), c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
 // --- TASK CONTROLLER ---
 
 type TaskController struct {
@@ -74,71 +456,1202 @@ func NewTaskController(taskUseCase domain.ITaskUseCase) *TaskController {
 	return &TaskController{taskUseCase: taskUseCase}
 }
 
+func toTaskResponse(t *domain.Task) dto.TaskResponse {
+	res := dto.TaskResponse{
+		ID:          t.ID,
+		ProjectID:   t.ProjectID,
+		Title:       t.Title,
+		Description: t.Description,
+		DueDate:     t.DueDate,
+		Status:      string(t.Status),
+		CreatedBy:   t.CreatedBy,
+		AssigneeID:  t.AssigneeID,
+		ParentID:    t.ParentID,
+		Checklist:   []dto.ChecklistItemResponse{},
+		Labels:      append([]string{}, t.Labels...),
+		Attachments: []dto.AttachmentResponse{},
+		Version:     t.Version,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+	for _, item := range t.Checklist {
+		res.Checklist = append(res.Checklist, dto.ChecklistItemResponse{ID: item.ID, Text: item.Text, Done: item.Done})
+	}
+	for i := range t.Attachments {
+		res.Attachments = append(res.Attachments, toAttachmentResponse(&t.Attachments[i]))
+	}
+	if r := t.Recurrence; r != nil {
+		res.Recurrence = &dto.RecurrenceResponse{Rule: r.Rule, SeriesID: r.SeriesID, Occurrence: r.Index, ScheduledAt: r.ScheduledAt}
+	}
+	if t.Progress != nil {
+		res.Progress = &dto.TaskProgressResponse{Completed: t.Progress.Completed, Total: t.Progress.Total, Percent: t.Progress.Percent}
+	}
+	return res
+}
+
 func (tc *TaskController) CreateTask(c *gin.Context) {
 	var req dto.CreateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, invalidInput(err))
 		return
 	}
-	task := domain.Task{Title: req.Title, Description: req.Description, DueDate: req.DueDate, Status: req.Status}
+	task := domain.Task{Title: req.Title, Description: req.Description, DueDate: req.DueDate, Status: domain.TaskStatus(req.Status), AssigneeID: req.AssigneeID, ParentID: req.ParentID}
+	if req.Recurrence != "" {
+		task.Recurrence = &domain.Recurrence{Rule: req.Recurrence}
+	}
 
-	createdTask, err := tc.taskUseCase.CreateTask(task)
+	createdTask, err := tc.taskUseCase.CreateTask(c.Request.Context(), c.Param("pid"), task, requesterFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		respondError(c, err)
 		return
 	}
-	res := dto.TaskResponse{ID: createdTask.ID, Title: createdTask.Title, Description: createdTask.Description, DueDate: createdTask.DueDate, Status: createdTask.Status, CreatedAt: createdTask.CreatedAt, UpdatedAt: createdTask.UpdatedAt}
-	c.JSON(http.StatusCreated, res)
+	setTaskETag(c, createdTask)
+	c.JSON(http.StatusCreated, toTaskResponse(createdTask))
 }
 
 func (tc *TaskController) GetAllTasks(c *gin.Context) {
-	tasks, err := tc.taskUseCase.GetAllTasks()
+	var req dto.TaskListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	query := domain.TaskQuery{
+		Status:    domain.TaskStatus(req.Status),
+		DueAfter:  req.DueAfter,
+		DueBefore: req.DueBefore,
+		Title:     req.Q,
+		SortBy:    req.Sort,
+		SortDesc:  req.Order == "desc",
+		Cursor:    req.Cursor,
+		Limit:     req.Limit,
+		LabelsAny: splitList(req.LabelsAny),
+		LabelsAll: splitList(req.LabelsAll),
+	}
+
+	page, err := tc.taskUseCase.GetAllTasks(c.Request.Context(), c.Param("pid"), query, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	res := dto.TaskPageResponse{Items: []dto.TaskResponse{}, NextCursor: page.NextCursor, Total: page.Total}
+	for i := range page.Tasks {
+		res.Items = append(res.Items, toTaskResponse(&page.Tasks[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (tc *TaskController) SearchTasks(c *gin.Context) {
+	var req dto.TaskSearchQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	query := domain.TaskSearchQuery{Text: req.Q, Cursor: req.Cursor, Limit: req.Limit}
+
+	page, err := tc.taskUseCase.SearchTasks(c.Request.Context(), c.Param("pid"), query, requesterFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+		respondError(c, err)
 		return
 	}
-	var taskResponses []dto.TaskResponse
-	for _, t := range tasks {
-		taskResponses = append(taskResponses, dto.TaskResponse{ID: t.ID, Title: t.Title, Description: t.Description, DueDate: t.DueDate, Status: t.Status, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt})
+	res := dto.TaskSearchPageResponse{Items: []dto.TaskSearchHitResponse{}, NextCursor: page.NextCursor}
+	for i := range page.Hits {
+		hit := &page.Hits[i]
+		res.Items = append(res.Items, dto.TaskSearchHitResponse{
+			Task:           toTaskResponse(&hit.Task),
+			Score:          hit.Score,
+			TitleHighlight: hit.TitleHighlight,
+			Snippet:        hit.Snippet,
+		})
 	}
-	c.JSON(http.StatusOK, taskResponses)
+	c.JSON(http.StatusOK, res)
 }
 
 func (tc *TaskController) GetTaskByID(c *gin.Context) {
 	taskID := c.Param("id")
-	task, err := tc.taskUseCase.GetTaskByID(taskID)
+	task, err := tc.taskUseCase.GetTaskByID(c.Request.Context(), c.Param("pid"), taskID, requesterFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
-	res := dto.TaskResponse{ID: task.ID, Title: task.Title, Description: task.Description, DueDate: task.DueDate, Status: task.Status, CreatedAt: task.CreatedAt, UpdatedAt: task.UpdatedAt}
-	c.JSON(http.StatusOK, res)
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
 }
 
 func (tc *TaskController) UpdateTask(c *gin.Context) {
 	taskID := c.Param("id")
 	var req dto.UpdateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, invalidInput(err))
+		return
+	}
+	version, _, err := ifMatchVersion(c)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	task := domain.Task{Title: req.Title, Description: req.Description, DueDate: req.DueDate, Status: req.Status}
+	task := domain.Task{Title: req.Title, Description: req.Description, DueDate: req.DueDate, Status: domain.TaskStatus(req.Status), Version: version}
 
-	updatedTask, err := tc.taskUseCase.UpdateTask(taskID, task)
+	updatedTask, err := tc.taskUseCase.UpdateTask(c.Request.Context(), c.Param("pid"), taskID, task, requesterFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
-	res := dto.TaskResponse{ID: updatedTask.ID, Title: updatedTask.Title, Description: updatedTask.Description, DueDate: updatedTask.DueDate, Status: updatedTask.Status, CreatedAt: updatedTask.CreatedAt, UpdatedAt: updatedTask.UpdatedAt}
-	c.JSON(http.StatusOK, res)
+	setTaskETag(c, updatedTask)
+	c.JSON(http.StatusOK, toTaskResponse(updatedTask))
+}
+
+// PatchTask applies a JSON Merge Patch (RFC 7396) to a task. The request must
+// carry the task's current ETag in If-Match.
+func (tc *TaskController) PatchTask(c *gin.Context) {
+	taskID := c.Param("id")
+
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		respondError(c, errUnsupportedMediaType)
+		return
+	}
+
+	version, present, err := ifMatchVersion(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !present {
+		respondError(c, errPreconditionRequired)
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	patch, err := parseTaskMergePatch(body)
+	if err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	task, err := tc.taskUseCase.PatchTask(c.Request.Context(), c.Param("pid"), taskID, patch, version, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+// UpdateSeries edits the whole series of a recurring task, where PUT and
+// PATCH only change the one occurrence.
+func (tc *TaskController) UpdateSeries(c *gin.Context) {
+	taskID := c.Param("id")
+	var req dto.UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	version, _, err := ifMatchVersion(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	patch := domain.SeriesPatch{Title: req.Title, Description: req.Description, DueDate: req.DueDate, Rule: req.Recurrence}
+
+	task, err := tc.taskUseCase.UpdateSeries(c.Request.Context(), c.Param("pid"), taskID, patch, version, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
 }
 
 func (tc *TaskController) DeleteTask(c *gin.Context) {
 	taskID := c.Param("id")
-	err := tc.taskUseCase.DeleteTask(taskID)
+	err := tc.taskUseCase.DeleteTask(c.Request.Context(), c.Param("pid"), taskID, requesterFromContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
+
+func (tc *TaskController) AssignTask(c *gin.Context) {
+	taskID := c.Param("id")
+	var req dto.AssignTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	task, err := tc.taskUseCase.AssignTask(c.Request.Context(), c.Param("pid"), taskID, req.AssigneeID, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+func (tc *TaskController) UnassignTask(c *gin.Context) {
+	taskID := c.Param("id")
+	task, err := tc.taskUseCase.AssignTask(c.Request.Context(), c.Param("pid"), taskID, "", requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+func (tc *TaskController) TransitionTask(c *gin.Context) {
+	taskID := c.Param("id")
+	var req dto.TransitionTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	task, err := tc.taskUseCase.TransitionTask(c.Request.Context(), c.Param("pid"), taskID, domain.TaskStatus(req.Status), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+// CreateSubtask creates a task under the task in the path.
+func (tc *TaskController) CreateSubtask(c *gin.Context) {
+	var req dto.CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	task := domain.Task{Title: req.Title, Description: req.Description, DueDate: req.DueDate, Status: domain.TaskStatus(req.Status), AssigneeID: req.AssigneeID, ParentID: c.Param("id")}
+
+	createdTask, err := tc.taskUseCase.CreateTask(c.Request.Context(), c.Param("pid"), task, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, createdTask)
+	c.JSON(http.StatusCreated, toTaskResponse(createdTask))
+}
+
+func (tc *TaskController) GetSubtasks(c *gin.Context) {
+	tasks, err := tc.taskUseCase.GetSubtasks(c.Request.Context(), c.Param("pid"), c.Param("id"), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	res := dto.TaskListResponse{Items: []dto.TaskResponse{}}
+	for i := range tasks {
+		res.Items = append(res.Items, toTaskResponse(&tasks[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (tc *TaskController) AddChecklistItem(c *gin.Context) {
+	var req dto.AddChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	task, err := tc.taskUseCase.AddChecklistItem(c.Request.Context(), c.Param("pid"), c.Param("id"), req.Text, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusCreated, toTaskResponse(task))
+}
+
+func (tc *TaskController) UpdateChecklistItem(c *gin.Context) {
+	var req dto.UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+	patch := domain.ChecklistItemPatch{Text: req.Text, Done: req.Done}
+
+	task, err := tc.taskUseCase.UpdateChecklistItem(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("itemId"), patch, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+func (tc *TaskController) ReorderChecklist(c *gin.Context) {
+	var req dto.ReorderChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	task, err := tc.taskUseCase.ReorderChecklist(c.Request.Context(), c.Param("pid"), c.Param("id"), req.ItemIDs, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+func (tc *TaskController) RemoveChecklistItem(c *gin.Context) {
+	task, err := tc.taskUseCase.RemoveChecklistItem(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("itemId"), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+func (tc *TaskController) AttachLabel(c *gin.Context) {
+	task, err := tc.taskUseCase.AttachLabel(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("name"), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+func (tc *TaskController) DetachLabel(c *gin.Context) {
+	task, err := tc.taskUseCase.DetachLabel(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("name"), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
+
+// splitList parses a comma-separated query parameter, ignoring empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// setTaskETag exposes the task's version as a strong ETag.
+func setTaskETag(c *gin.Context, task *domain.Task) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(task.Version, 10)))
+}
+
+// ifMatchVersion reads the task version from the If-Match header. present is
+// false when the header is missing; "*" matches any version and yields 0.
+func ifMatchVersion(c *gin.Context) (version int64, present bool, err error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, false, nil
+	}
+	if header == "*" {
+		return 0, true, nil
+	}
+	tag, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err == nil {
+		version, err = strconv.ParseInt(tag, 10, 64)
+	}
+	if err != nil || version <= 0 {
+		return 0, true, errPreconditionFailed
+	}
+	return version, true, nil
+}
+
+// parseTaskMergePatch turns a JSON Merge Patch document into a TaskPatch.
+// Members set to null are removed, which is only meaningful for the
+// description; the other fields are required.
+func parseTaskMergePatch(body []byte) (domain.TaskPatch, error) {
+	var patch domain.TaskPatch
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		return patch, errors.New("merge patch must be a JSON object")
+	}
+
+	for field, raw := range doc {
+		isNull := string(raw) == "null"
+		switch field {
+		case "title":
+			if isNull {
+				return patch, errors.New("title cannot be removed")
+			}
+			if err := json.Unmarshal(raw, &patch.Title); err != nil {
+				return patch, fmt.Errorf("invalid title: %w", err)
+			}
+		case "description":
+			description := ""
+			if !isNull {
+				if err := json.Unmarshal(raw, &description); err != nil {
+					return patch, fmt.Errorf("invalid description: %w", err)
+				}
+			}
+			patch.Description = &description
+		case "due_date":
+			if isNull {
+				return patch, errors.New("due_date cannot be removed")
+			}
+			var dueDate time.Time
+			if err := json.Unmarshal(raw, &dueDate); err != nil {
+				return patch, fmt.Errorf("invalid due_date: %w", err)
+			}
+			patch.DueDate = &dueDate
+		case "status":
+			if isNull {
+				return patch, errors.New("status cannot be removed")
+			}
+			if err := json.Unmarshal(raw, &patch.Status); err != nil {
+				return patch, fmt.Errorf("invalid status: %w", err)
+			}
+		default:
+			return patch, fmt.Errorf("field %q cannot be patched", field)
+		}
+	}
+	return patch, nil
+}
+
+// --- TASK STREAM CONTROLLER ---
+
+// streamKeepAlive is how often an idle stream sends a keep-alive, so proxies
+// do not close it.
+const streamKeepAlive = 15 * time.Second
+
+type TaskStreamController struct {
+	streamUseCase domain.ITaskStreamUseCase
+}
+
+func NewTaskStreamController(streamUseCase domain.ITaskStreamUseCase) *TaskStreamController {
+	return &TaskStreamController{streamUseCase: streamUseCase}
+}
+
+func toTaskStreamEvent(e domain.StreamEvent) dto.TaskStreamEvent {
+	res := dto.TaskStreamEvent{
+		ID:         e.ID,
+		Type:       e.Event.Type,
+		ActorID:    e.Event.ActorID,
+		OccurredAt: e.Event.OccurredAt,
+	}
+	if e.Event.Task != nil {
+		task := toTaskResponse(e.Event.Task)
+		res.Task = &task
+	}
+	return res
+}
+
+// Stream pushes task events as Server-Sent Events, or as WebSocket text
+// messages when the request asks for an upgrade. Clients resume with the
+// Last-Event-ID header, or the last_event_id query parameter where headers
+// cannot be set.
+func (sc *TaskStreamController) Stream(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	events, err := sc.streamUseCase.Subscribe(ctx, c.Param("pid"), requesterFromContext(c), lastEventID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		sc.streamWebSocket(c, events, cancel)
+		return
+	}
+	sc.streamSSE(c, events)
+}
+
+// streamSSE writes events until the subscription ends, which happens when
+// the client goes away and the request context is cancelled.
+func (sc *TaskStreamController) streamSSE(c *gin.Context, events <-chan domain.StreamEvent) {
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(toTaskStreamEvent(e))
+			if err != nil {
+				log.Printf("Failed to encode stream event %s: %v", e.ID, err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Event.Type, data)
+		case <-keepAlive.C:
+			io.WriteString(c.Writer, ": keep-alive\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// streamWebSocket sends events as JSON text messages. A hijacked connection
+// no longer cancels the request context, so a failed read or write cancels
+// the subscription instead.
+func (sc *TaskStreamController) streamWebSocket(c *gin.Context, events <-chan domain.StreamEvent, cancel context.CancelFunc) {
+	server := websocket.Server{
+		// Streams are authenticated by bearer token rather than cookies, so
+		// any origin may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			go func() {
+				// Clients send nothing; reading only notices the close
+				io.Copy(io.Discard, ws)
+				cancel()
+			}()
+
+			keepAlive := time.NewTicker(streamKeepAlive)
+			defer keepAlive.Stop()
+			for {
+				var err error
+				select {
+				case e, ok := <-events:
+					if !ok {
+						return
+					}
+					err = websocket.JSON.Send(ws, toTaskStreamEvent(e))
+				case <-keepAlive.C:
+					ws.PayloadType = websocket.PingFrame
+					_, err = ws.Write(nil)
+				}
+				if err != nil {
+					cancel()
+					return
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// --- LABEL CONTROLLER ---
+
+type LabelController struct {
+	labelUseCase domain.ILabelUseCase
+}
+
+func NewLabelController(labelUseCase domain.ILabelUseCase) *LabelController {
+	return &LabelController{labelUseCase: labelUseCase}
+}
+
+func toLabelResponse(l *domain.Label) dto.LabelResponse {
+	return dto.LabelResponse{Name: l.Name, Color: l.Color, CreatedAt: l.CreatedAt}
+}
+
+func (lc *LabelController) ListLabels(c *gin.Context) {
+	labels, err := lc.labelUseCase.ListLabels(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	res := dto.LabelListResponse{Items: []dto.LabelResponse{}}
+	for i := range labels {
+		res.Items = append(res.Items, toLabelResponse(&labels[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (lc *LabelController) CreateLabel(c *gin.Context) {
+	var req dto.CreateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	label, err := lc.labelUseCase.CreateLabel(c.Request.Context(), domain.Label{Name: req.Name, Color: req.Color}, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toLabelResponse(label))
+}
+
+func (lc *LabelController) UpdateLabel(c *gin.Context) {
+	var req dto.UpdateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	label, err := lc.labelUseCase.UpdateLabel(c.Request.Context(), c.Param("name"), req.Color, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toLabelResponse(label))
+}
+
+func (lc *LabelController) DeleteLabel(c *gin.Context) {
+	if err := lc.labelUseCase.DeleteLabel(c.Request.Context(), c.Param("name"), c.GetString("userID")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
+
+// --- ROLE CONTROLLER ---
+
+type RoleController struct {
+	roleUseCase domain.IRoleUseCase
+}
+
+func NewRoleController(roleUseCase domain.IRoleUseCase) *RoleController {
+	return &RoleController{roleUseCase: roleUseCase}
+}
+
+func toRoleResponse(r *domain.RoleDefinition) dto.RoleResponse {
+	return dto.RoleResponse{
+		Name:        string(r.Name),
+		Description: r.Description,
+		Permissions: fromPermissions(r.Permissions),
+		RequireMFA:  r.RequireMFA,
+		Builtin:     r.Name.IsBuiltin(),
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func toPermissions(names []string) []domain.Permission {
+	permissions := make([]domain.Permission, len(names))
+	for i, name := range names {
+		permissions[i] = domain.Permission(name)
+	}
+	return permissions
+}
+
+func fromPermissions(permissions []domain.Permission) []string {
+	names := make([]string, len(permissions))
+	for i, p := range permissions {
+		names[i] = string(p)
+	}
+	return names
+}
+
+func (rc *RoleController) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.PermissionListResponse{Items: fromPermissions(domain.Permissions)})
+}
+
+func (rc *RoleController) ListRoles(c *gin.Context) {
+	roles, err := rc.roleUseCase.ListRoles(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	res := dto.RoleListResponse{Items: []dto.RoleResponse{}}
+	for i := range roles {
+		res.Items = append(res.Items, toRoleResponse(&roles[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	role := domain.RoleDefinition{Name: domain.Role(req.Name), Description: req.Description, Permissions: toPermissions(req.Permissions), RequireMFA: req.RequireMFA}
+	created, err := rc.roleUseCase.CreateRole(c.Request.Context(), role, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toRoleResponse(created))
+}
+
+func (rc *RoleController) UpdateRole(c *gin.Context) {
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	patch := domain.RoleDefinitionPatch{Description: req.Description, RequireMFA: req.RequireMFA}
+	if req.Permissions != nil {
+		permissions := toPermissions(*req.Permissions)
+		patch.Permissions = &permissions
+	}
+	role, err := rc.roleUseCase.UpdateRole(c.Request.Context(), domain.Role(c.Param("name")), patch, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+func (rc *RoleController) DeleteRole(c *gin.Context) {
+	if err := rc.roleUseCase.DeleteRole(c.Request.Context(), domain.Role(c.Param("name")), c.GetString("userID")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// --- COMMENT CONTROLLER ---
+
+type CommentController struct {
+	commentUseCase domain.ICommentUseCase
+}
+
+func NewCommentController(commentUseCase domain.ICommentUseCase) *CommentController {
+	return &CommentController{commentUseCase: commentUseCase}
+}
+
+func (cc *CommentController) ListComments(c *gin.Context) {
+	var req dto.CommentListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	query := domain.CommentQuery{Cursor: req.Cursor, Limit: req.Limit}
+	page, err := cc.commentUseCase.ListComments(c.Request.Context(), c.Param("pid"), c.Param("id"), query, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := dto.CommentPageResponse{Items: []dto.CommentResponse{}, NextCursor: page.NextCursor}
+	for _, thread := range page.Threads {
+		res.Items = append(res.Items, toCommentThreadResponse(thread))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (cc *CommentController) AddComment(c *gin.Context) {
+	var req dto.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	comment := domain.Comment{Body: req.Body, ParentID: req.ParentID}
+	created, err := cc.commentUseCase.AddComment(c.Request.Context(), c.Param("pid"), c.Param("id"), comment, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toCommentResponse(created))
+}
+
+func (cc *CommentController) UpdateComment(c *gin.Context) {
+	var req dto.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	comment, err := cc.commentUseCase.UpdateComment(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("commentId"), req.Body, requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toCommentResponse(comment))
+}
+
+func (cc *CommentController) DeleteComment(c *gin.Context) {
+	if err := cc.commentUseCase.DeleteComment(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("commentId"), requesterFromContext(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func toCommentResponse(comment *domain.Comment) dto.CommentResponse {
+	return dto.CommentResponse{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		ParentID:  comment.ParentID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Replies:   []dto.CommentResponse{},
+	}
+}
+
+// toCommentThreadResponse nests the thread's flat reply list under the
+// comments they answer.
+func toCommentThreadResponse(thread domain.CommentThread) dto.CommentResponse {
+	children := make(map[string][]*domain.Comment)
+	for i := range thread.Replies {
+		reply := &thread.Replies[i]
+		children[reply.ParentID] = append(children[reply.ParentID], reply)
+	}
+
+	var build func(comment *domain.Comment) dto.CommentResponse
+	build = func(comment *domain.Comment) dto.CommentResponse {
+		res := toCommentResponse(comment)
+		for _, child := range children[comment.ID] {
+			res.Replies = append(res.Replies, build(child))
+		}
+		return res
+	}
+	return build(&thread.Comment)
+}
+
+// --- ATTACHMENT CONTROLLER ---
+
+// multipartOverhead is the room left above the upload limit for the
+// multipart boundaries and part headers around the file.
+const multipartOverhead = 64 << 10
+
+type AttachmentController struct {
+	attachmentUseCase domain.IAttachmentUseCase
+	maxUploadSize     int64
+}
+
+func NewAttachmentController(attachmentUseCase domain.IAttachmentUseCase, maxUploadSize int64) *AttachmentController {
+	return &AttachmentController{attachmentUseCase: attachmentUseCase, maxUploadSize: maxUploadSize}
+}
+
+func toAttachmentResponse(a *domain.Attachment) dto.AttachmentResponse {
+	return dto.AttachmentResponse{
+		ID:          a.ID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		UploadedBy:  a.UploadedBy,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+func (ac *AttachmentController) ListAttachments(c *gin.Context) {
+	attachments, err := ac.attachmentUseCase.ListAttachments(c.Request.Context(), c.Param("pid"), c.Param("id"), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	res := dto.AttachmentListResponse{Items: []dto.AttachmentResponse{}}
+	for i := range attachments {
+		res.Items = append(res.Items, toAttachmentResponse(&attachments[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// UploadAttachment streams the "file" part of a multipart/form-data request
+// to the use case without buffering it in memory or on disk.
+func (ac *AttachmentController) UploadAttachment(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ac.maxUploadSize+multipartOverhead)
+	part, err := fileFormPart(c.Request)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer part.Close()
+
+	upload := domain.AttachmentUpload{Filename: part.FileName(), Content: part}
+	attachment, err := ac.attachmentUseCase.AddAttachment(c.Request.Context(), c.Param("pid"), c.Param("id"), upload, requesterFromContext(c))
+	if err != nil {
+		respondError(c, bodyTooLarge(err))
+		return
+	}
+	c.JSON(http.StatusCreated, toAttachmentResponse(attachment))
+}
+
+// DownloadAttachment serves the content as a download with the sniffed
+// content type, so browsers never render it inline.
+func (ac *AttachmentController) DownloadAttachment(c *gin.Context) {
+	attachment, content, err := ac.attachmentUseCase.OpenAttachment(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("attachmentId"), requesterFromContext(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    disposition,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (ac *AttachmentController) DeleteAttachment(c *gin.Context) {
+	if err := ac.attachmentUseCase.DeleteAttachment(c.Request.Context(), c.Param("pid"), c.Param("id"), c.Param("attachmentId"), requesterFromContext(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+// fileFormPart returns the first part of a multipart request that carries a
+// file in the "file" field.
+func fileFormPart(r *http.Request) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, invalidInput(errors.New("missing file field"))
+		}
+		if err != nil {
+			if err = bodyTooLarge(err); errors.Is(err, domain.ErrTooLarge) {
+				return nil, err
+			}
+			return nil, invalidInput(err)
+		}
+		if part.FormName() == "file" && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// bodyTooLarge reports a request body cut off by http.MaxBytesReader as
+// ErrTooLarge.
+func bodyTooLarge(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return domain.ErrTooLarge
+	}
+	return err
+}
+
+// --- AUDIT CONTROLLER ---
+
+type AuditController struct {
+	auditUseCase domain.IAuditUseCase
+}
+
+func NewAuditController(auditUseCase domain.IAuditUseCase) *AuditController {
+	return &AuditController{auditUseCase: auditUseCase}
+}
+
+func (ac *AuditController) GetTaskHistory(c *gin.Context) {
+	query, ok := bindAuditQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := ac.auditUseCase.GetTaskHistory(c.Request.Context(), c.Param("pid"), c.Param("id"), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAuditPageResponse(page))
+}
+
+func (ac *AuditController) ListEntries(c *gin.Context) {
+	query, ok := bindAuditQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := ac.auditUseCase.ListEntries(c.Request.Context(), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAuditPageResponse(page))
+}
+
+func bindAuditQuery(c *gin.Context) (domain.AuditQuery, bool) {
+	var req dto.AuditListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return domain.AuditQuery{}, false
+	}
+	return domain.AuditQuery{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Since:      req.Since,
+		Until:      req.Until,
+		Cursor:     req.Cursor,
+		Limit:      req.Limit,
+	}, true
+}
+
+func toAuditPageResponse(page *domain.AuditPage) dto.AuditPageResponse {
+	res := dto.AuditPageResponse{Items: []dto.AuditEntryResponse{}, NextCursor: page.NextCursor}
+	for _, e := range page.Entries {
+		entry := dto.AuditEntryResponse{
+			ID:         e.ID,
+			ActorID:    e.ActorID,
+			Action:     e.Action,
+			EntityType: e.EntityType,
+			EntityID:   e.EntityID,
+			Changes:    []dto.FieldChangeResponse{},
+			CreatedAt:  e.CreatedAt,
+		}
+		for _, ch := range e.Changes {
+			entry.Changes = append(entry.Changes, dto.FieldChangeResponse{Field: ch.Field, Before: ch.Before, After: ch.After})
+		}
+		res.Items = append(res.Items, entry)
+	}
+	return res
+}
+
+// --- WEBHOOK CONTROLLER ---
+
+type WebhookController struct {
+	webhookUseCase domain.IWebhookUseCase
+}
+
+func NewWebhookController(webhookUseCase domain.IWebhookUseCase) *WebhookController {
+	return &WebhookController{webhookUseCase: webhookUseCase}
+}
+
+func toWebhookResponse(w *domain.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(d *domain.WebhookDelivery) dto.WebhookDeliveryResponse {
+	res := dto.WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		Error:          d.Error,
+		ReplayOf:       d.ReplayOf,
+		CreatedAt:      d.CreatedAt,
+	}
+	if !d.NextAttemptAt.IsZero() {
+		res.NextAttemptAt = &d.NextAttemptAt
+	}
+	if !d.LastAttemptAt.IsZero() {
+		res.LastAttemptAt = &d.LastAttemptAt
+	}
+	return res
+}
+
+func (wc *WebhookController) ListWebhooks(c *gin.Context) {
+	webhooks, err := wc.webhookUseCase.ListWebhooks(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	res := dto.WebhookListResponse{Items: []dto.WebhookResponse{}}
+	for i := range webhooks {
+		res.Items = append(res.Items, toWebhookResponse(&webhooks[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (wc *WebhookController) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	webhook := domain.Webhook{URL: req.URL, Events: req.Events, Active: true, Secret: req.Secret}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	created, err := wc.webhookUseCase.CreateWebhook(c.Request.Context(), webhook, c.GetString("userID"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// The secret is shown once, so the caller can configure the receiver
+	res := toWebhookResponse(created)
+	res.Secret = created.Secret
+	c.JSON(http.StatusCreated, res)
+}
+
+func (wc *WebhookController) GetWebhook(c *gin.Context) {
+	webhook, err := wc.webhookUseCase.GetWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(webhook))
+}
+
+func (wc *WebhookController) UpdateWebhook(c *gin.Context) {
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	patch := domain.WebhookPatch{URL: req.URL, Events: req.Events, Active: req.Active, Secret: req.Secret}
+	webhook, err := wc.webhookUseCase.UpdateWebhook(c.Request.Context(), c.Param("id"), patch)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(webhook))
+}
+
+func (wc *WebhookController) DeleteWebhook(c *gin.Context) {
+	if err := wc.webhookUseCase.DeleteWebhook(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func (wc *WebhookController) ListDeliveries(c *gin.Context) {
+	var req dto.WebhookDeliveryListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, invalidInput(err))
+		return
+	}
+
+	query := domain.WebhookDeliveryQuery{
+		Status: domain.WebhookDeliveryStatus(req.Status),
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+	}
+	page, err := wc.webhookUseCase.ListDeliveries(c.Request.Context(), c.Param("id"), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res := dto.WebhookDeliveryPageResponse{Items: []dto.WebhookDeliveryResponse{}, NextCursor: page.NextCursor}
+	for i := range page.Deliveries {
+		res.Items = append(res.Items, toWebhookDeliveryResponse(&page.Deliveries[i]))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ReplayDelivery queues the payload again. The new delivery is sent by the
+// dispatcher, hence 202.
+func (wc *WebhookController) ReplayDelivery(c *gin.Context) {
+	delivery, err := wc.webhookUseCase.ReplayDelivery(c.Request.Context(), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, toWebhookDeliveryResponse(delivery))
+}
@@ -1,10 +1,16 @@
 package controllers
 
 import (
+	"context"
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"task-manager/Delivery/dto"
 	domain "task-manager/Domain"
 	"task-manager/Repositories/mocks"
@@ -15,15 +21,32 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/websocket"
 )
 
 type ControllerTestSuite struct {
 	suite.Suite
-	router          *gin.Engine
-	mockTaskUseCase *mocks.MockTaskUseCase
-	mockUserUseCase *mocks.MockUserUseCase
-	taskController  *TaskController
-	userController  *UserController
+	router           *gin.Engine
+	mockTaskUseCase  *mocks.MockTaskUseCase
+	mockUserUseCase  *mocks.MockUserUseCase
+	mockAuditUseCase *mocks.MockAuditUseCase
+	mockLabelUseCase   *mocks.MockLabelUseCase
+	mockCommentUseCase *mocks.MockCommentUseCase
+	mockAttachmentUseCase *mocks.MockAttachmentUseCase
+	mockWebhookUseCase    *mocks.MockWebhookUseCase
+	mockTaskStreamUseCase *mocks.MockTaskStreamUseCase
+	mockProjectUseCase    *mocks.MockProjectUseCase
+	mockRoleUseCase       *mocks.MockRoleUseCase
+	taskController     *TaskController
+	userController     *UserController
+	auditController    *AuditController
+	labelController    *LabelController
+	commentController  *CommentController
+	attachmentController *AttachmentController
+	webhookController    *WebhookController
+	taskStreamController *TaskStreamController
+	projectController    *ProjectController
+	roleController       *RoleController
 }
 
 func (suite *ControllerTestSuite) SetupTest() {
@@ -32,18 +55,117 @@ func (suite *ControllerTestSuite) SetupTest() {
 
 	suite.mockTaskUseCase = new(mocks.MockTaskUseCase)
 	suite.mockUserUseCase = new(mocks.MockUserUseCase)
+	suite.mockAuditUseCase = new(mocks.MockAuditUseCase)
+	suite.mockLabelUseCase = new(mocks.MockLabelUseCase)
+	suite.mockCommentUseCase = new(mocks.MockCommentUseCase)
+	suite.mockAttachmentUseCase = new(mocks.MockAttachmentUseCase)
+	suite.mockWebhookUseCase = new(mocks.MockWebhookUseCase)
+	suite.mockTaskStreamUseCase = new(mocks.MockTaskStreamUseCase)
+	suite.mockProjectUseCase = new(mocks.MockProjectUseCase)
+	suite.mockRoleUseCase = new(mocks.MockRoleUseCase)
 
 	suite.taskController = NewTaskController(suite.mockTaskUseCase)
 	suite.userController = NewUserController(suite.mockUserUseCase)
+	suite.auditController = NewAuditController(suite.mockAuditUseCase)
+	suite.labelController = NewLabelController(suite.mockLabelUseCase)
+	suite.commentController = NewCommentController(suite.mockCommentUseCase)
+	suite.attachmentController = NewAttachmentController(suite.mockAttachmentUseCase, 1<<10)
+	suite.webhookController = NewWebhookController(suite.mockWebhookUseCase)
+	suite.taskStreamController = NewTaskStreamController(suite.mockTaskStreamUseCase)
+	suite.projectController = NewProjectController(suite.mockProjectUseCase)
+	suite.roleController = NewRoleController(suite.mockRoleUseCase)
 
 	// Setup routes
 	suite.router.POST("/register", suite.userController.Register)
 	suite.router.POST("/login", suite.userController.Login)
-	suite.router.GET("/tasks", suite.taskController.GetAllTasks)
-	suite.router.GET("/tasks/:id", suite.taskController.GetTaskByID)
-	suite.router.POST("/tasks", suite.taskController.CreateTask)
-	suite.router.PUT("/tasks/:id", suite.taskController.UpdateTask)
-	suite.router.DELETE("/tasks/:id", suite.taskController.DeleteTask)
+	suite.router.POST("/login/mfa", suite.userController.CompleteMFALogin)
+	suite.router.POST("/refresh", suite.userController.Refresh)
+	suite.router.POST("/logout", suite.userController.Logout)
+	suite.router.GET("/projects", suite.projectController.ListProjects)
+	suite.router.POST("/projects", suite.projectController.CreateProject)
+	suite.router.GET("/projects/:pid", suite.projectController.GetProject)
+	suite.router.PATCH("/projects/:pid", suite.projectController.UpdateProject)
+	suite.router.DELETE("/projects/:pid", suite.projectController.DeleteProject)
+	suite.router.PUT("/projects/:pid/members/:userId", suite.projectController.SetMember)
+	suite.router.DELETE("/projects/:pid/members/:userId", suite.projectController.RemoveMember)
+	suite.router.GET("/projects/:pid/tasks", suite.taskController.GetAllTasks)
+	suite.router.GET("/projects/:pid/tasks/stream", suite.taskStreamController.Stream)
+	suite.router.GET("/projects/:pid/tasks/search", suite.taskController.SearchTasks)
+	suite.router.GET("/projects/:pid/tasks/:id", suite.taskController.GetTaskByID)
+	suite.router.POST("/projects/:pid/tasks", suite.taskController.CreateTask)
+	suite.router.PUT("/projects/:pid/tasks/:id", suite.taskController.UpdateTask)
+	suite.router.PATCH("/projects/:pid/tasks/:id", suite.taskController.PatchTask)
+	suite.router.PATCH("/projects/:pid/tasks/:id/series", suite.taskController.UpdateSeries)
+	suite.router.DELETE("/projects/:pid/tasks/:id", suite.taskController.DeleteTask)
+	suite.router.PUT("/projects/:pid/tasks/:id/assignee", suite.taskController.AssignTask)
+	suite.router.DELETE("/projects/:pid/tasks/:id/assignee", suite.taskController.UnassignTask)
+	suite.router.POST("/projects/:pid/tasks/:id/transition", suite.taskController.TransitionTask)
+	suite.router.GET("/projects/:pid/tasks/:id/subtasks", suite.taskController.GetSubtasks)
+	suite.router.POST("/projects/:pid/tasks/:id/subtasks", suite.taskController.CreateSubtask)
+	suite.router.POST("/projects/:pid/tasks/:id/checklist", suite.taskController.AddChecklistItem)
+	suite.router.PUT("/projects/:pid/tasks/:id/checklist/order", suite.taskController.ReorderChecklist)
+	suite.router.PATCH("/projects/:pid/tasks/:id/checklist/:itemId", suite.taskController.UpdateChecklistItem)
+	suite.router.DELETE("/projects/:pid/tasks/:id/checklist/:itemId", suite.taskController.RemoveChecklistItem)
+	suite.router.PUT("/projects/:pid/tasks/:id/labels/:name", suite.taskController.AttachLabel)
+	suite.router.DELETE("/projects/:pid/tasks/:id/labels/:name", suite.taskController.DetachLabel)
+	suite.router.GET("/projects/:pid/tasks/:id/comments", suite.commentController.ListComments)
+	suite.router.POST("/projects/:pid/tasks/:id/comments", suite.commentController.AddComment)
+	suite.router.PATCH("/projects/:pid/tasks/:id/comments/:commentId", suite.commentController.UpdateComment)
+	suite.router.DELETE("/projects/:pid/tasks/:id/comments/:commentId", suite.commentController.DeleteComment)
+	suite.router.GET("/projects/:pid/tasks/:id/attachments", suite.attachmentController.ListAttachments)
+	suite.router.POST("/projects/:pid/tasks/:id/attachments", suite.attachmentController.UploadAttachment)
+	suite.router.GET("/projects/:pid/tasks/:id/attachments/:attachmentId", suite.attachmentController.DownloadAttachment)
+	suite.router.DELETE("/projects/:pid/tasks/:id/attachments/:attachmentId", suite.attachmentController.DeleteAttachment)
+	suite.router.GET("/projects/:pid/tasks/:id/history", suite.auditController.GetTaskHistory)
+	suite.router.GET("/labels", suite.labelController.ListLabels)
+	suite.router.POST("/admin/labels", suite.labelController.CreateLabel)
+	suite.router.PATCH("/admin/labels/:name", suite.labelController.UpdateLabel)
+	suite.router.DELETE("/admin/labels/:name", suite.labelController.DeleteLabel)
+	suite.router.GET("/admin/audit", suite.auditController.ListEntries)
+	suite.router.GET("/admin/webhooks", suite.webhookController.ListWebhooks)
+	suite.router.POST("/admin/webhooks", suite.webhookController.CreateWebhook)
+	suite.router.PATCH("/admin/webhooks/:id", suite.webhookController.UpdateWebhook)
+	suite.router.DELETE("/admin/webhooks/:id", suite.webhookController.DeleteWebhook)
+	suite.router.GET("/admin/webhooks/:id/deliveries", suite.webhookController.ListDeliveries)
+	suite.router.POST("/admin/webhooks/:id/deliveries/:deliveryId/replay", suite.webhookController.ReplayDelivery)
+	suite.router.POST("/admin/promote", func(c *gin.Context) {
+		c.Set("userID", "admin-1")
+	}, suite.userController.PromoteUser)
+	suite.router.PUT("/admin/users/:id/role", func(c *gin.Context) {
+		c.Set("userID", "admin-1")
+	}, suite.userController.AssignRole)
+	asAdmin := func(c *gin.Context) { c.Set("userID", "admin-1") }
+	suite.router.POST("/me/password", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+	}, suite.userController.ChangePassword)
+	suite.router.POST("/me/mfa/confirm", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+	}, suite.userController.ConfirmMFAEnrollment)
+	suite.router.POST("/password-reset", suite.userController.RequestPasswordReset)
+	suite.router.POST("/password-reset/confirm", suite.userController.ConfirmPasswordReset)
+	suite.router.GET("/admin/users", suite.userController.ListUsers)
+	suite.router.GET("/admin/users/:id", suite.userController.GetUser)
+	suite.router.DELETE("/admin/users/:id", asAdmin, suite.userController.DeleteUser)
+	suite.router.POST("/admin/users/:id/demote", asAdmin, suite.userController.DemoteUser)
+	suite.router.POST("/admin/users/:id/disable", asAdmin, suite.userController.DisableUser)
+	suite.router.POST("/admin/users/:id/enable", asAdmin, suite.userController.EnableUser)
+	suite.router.DELETE("/admin/users/:id/mfa", asAdmin, suite.userController.ResetMFA)
+	suite.router.GET("/admin/permissions", suite.roleController.ListPermissions)
+	suite.router.GET("/admin/roles", suite.roleController.ListRoles)
+	suite.router.POST("/admin/roles", suite.roleController.CreateRole)
+	suite.router.PATCH("/admin/roles/:name", suite.roleController.UpdateRole)
+	suite.router.DELETE("/admin/roles/:name", suite.roleController.DeleteRole)
+}
+
+// assertProblem checks that w holds a problem+json response with the given status and code.
+func (suite *ControllerTestSuite) assertProblem(w *httptest.ResponseRecorder, status int, code string) dto.Problem {
+	var problem dto.Problem
+	assert.Equal(suite.T(), status, w.Code)
+	assert.Equal(suite.T(), dto.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), status, problem.Status)
+	assert.Equal(suite.T(), code, problem.Code)
+	return problem
 }
 
 func (suite *ControllerTestSuite) TestRegister_Success() {
@@ -53,7 +175,7 @@ func (suite *ControllerTestSuite) TestRegister_Success() {
 		Role:     domain.RoleUser,
 	}
 
-	suite.mockUserUseCase.On("Register", mock.AnythingOfType("domain.User")).Return(&user, nil)
+	suite.mockUserUseCase.On("Register", mock.Anything, mock.AnythingOfType("domain.User")).Return(&user, nil)
 
 	reqBody := dto.RegisterUserRequest{
 		Username: "testuser",
@@ -77,6 +199,38 @@ func (suite *ControllerTestSuite) TestRegister_Success() {
 	suite.mockUserUseCase.AssertExpectations(suite.T())
 }
 
+func (suite *ControllerTestSuite) TestRegister_WithEmail() {
+	user := domain.User{ID: "1", Username: "testuser", Email: "test@example.com", Role: domain.RoleUser}
+	suite.mockUserUseCase.On("Register", mock.Anything, mock.MatchedBy(func(u domain.User) bool {
+		return u.Email == "test@example.com"
+	})).Return(&user, nil)
+
+	jsonBody, _ := json.Marshal(dto.RegisterUserRequest{Username: "testuser", Password: "password123", Email: "test@example.com"})
+	req := httptest.NewRequest("POST", "/register", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.UserResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "test@example.com", response.Email)
+	suite.mockUserUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestRegister_InvalidEmail() {
+	jsonBody, _ := json.Marshal(dto.RegisterUserRequest{Username: "testuser", Password: "password123", Email: "nope"})
+	req := httptest.NewRequest("POST", "/register", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	suite.mockUserUseCase.AssertNotCalled(suite.T(), "Register", mock.Anything, mock.Anything)
+}
+
 func (suite *ControllerTestSuite) TestRegister_InvalidRequest() {
 	reqBody := dto.RegisterUserRequest{
 		Username: "",
@@ -94,7 +248,7 @@ func (suite *ControllerTestSuite) TestRegister_InvalidRequest() {
 }
 
 func (suite *ControllerTestSuite) TestRegister_UseCaseError() {
-	suite.mockUserUseCase.On("Register", mock.AnythingOfType("domain.User")).Return(nil, domain.ErrDuplicateEntry)
+	suite.mockUserUseCase.On("Register", mock.Anything, mock.AnythingOfType("domain.User")).Return(nil, domain.ErrDuplicateEntry)
 
 	reqBody := dto.RegisterUserRequest{
 		Username: "testuser",
@@ -108,13 +262,28 @@ func (suite *ControllerTestSuite) TestRegister_UseCaseError() {
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
-	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+	suite.assertProblem(w, http.StatusConflict, CodeDuplicateEntry)
 
 	suite.mockUserUseCase.AssertExpectations(suite.T())
 }
 
+func (suite *ControllerTestSuite) TestRegister_StorageError() {
+	suite.mockUserUseCase.On("Register", mock.Anything, mock.AnythingOfType("domain.User")).Return(nil, errors.New("connection refused"))
+
+	jsonBody, _ := json.Marshal(dto.RegisterUserRequest{Username: "testuser", Password: "password123"})
+	req := httptest.NewRequest("POST", "/register", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	problem := suite.assertProblem(w, http.StatusInternalServerError, CodeInternal)
+	assert.NotContains(suite.T(), problem.Detail, "connection refused")
+}
+
 func (suite *ControllerTestSuite) TestLogin_Success() {
-	suite.mockUserUseCase.On("Login", "testuser", "password123").Return("jwt-token", nil)
+	suite.mockUserUseCase.On("Login", mock.Anything, "testuser", "password123", "192.0.2.1").Return(&domain.LoginResult{Tokens: &domain.TokenPair{AccessToken: "jwt-token", RefreshToken: "refresh-token"}}, nil)
 
 	reqBody := dto.LoginRequest{
 		Username: "testuser",
@@ -134,12 +303,13 @@ func (suite *ControllerTestSuite) TestLogin_Success() {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "jwt-token", response.Token)
+	assert.Equal(suite.T(), "refresh-token", response.RefreshToken)
 
 	suite.mockUserUseCase.AssertExpectations(suite.T())
 }
 
 func (suite *ControllerTestSuite) TestLogin_InvalidCredentials() {
-	suite.mockUserUseCase.On("Login", "testuser", "wrongpassword").Return("", domain.ErrInvalidCredentials)
+	suite.mockUserUseCase.On("Login", mock.Anything, "testuser", "wrongpassword", mock.Anything).Return(nil, domain.ErrInvalidCredentials)
 
 	reqBody := dto.LoginRequest{
 		Username: "testuser",
@@ -158,6 +328,68 @@ func (suite *ControllerTestSuite) TestLogin_InvalidCredentials() {
 	suite.mockUserUseCase.AssertExpectations(suite.T())
 }
 
+func (suite *ControllerTestSuite) TestLogin_Throttled() {
+	suite.mockUserUseCase.On("Login", mock.Anything, "testuser", "password123", mock.Anything).Return(nil, &domain.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+
+	jsonBody, _ := json.Marshal(dto.LoginRequest{Username: "testuser", Password: "password123"})
+	req := httptest.NewRequest("POST", "/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusTooManyRequests, CodeTooManyAttempts)
+	assert.Equal(suite.T(), "2", w.Header().Get("Retry-After"))
+}
+
+func (suite *ControllerTestSuite) TestLogin_MFAPending() {
+	expiresAt := time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)
+	suite.mockUserUseCase.On("Login", mock.Anything, "testuser", "password123", mock.Anything).Return(&domain.LoginResult{MFAPending: "challenge", MFAExpiresAt: expiresAt}, nil)
+
+	jsonBody, _ := json.Marshal(dto.LoginRequest{Username: "testuser", Password: "password123"})
+	req := httptest.NewRequest("POST", "/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	// No tokens until the second factor is passed
+	assert.NotContains(suite.T(), w.Body.String(), `"token"`)
+	var response dto.LoginResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "challenge", response.MFAPending)
+	assert.True(suite.T(), expiresAt.Equal(*response.MFAExpiresAt))
+	assert.False(suite.T(), response.MFAEnrollmentRequired)
+}
+
+func (suite *ControllerTestSuite) TestCompleteMFALogin() {
+	suite.mockUserUseCase.On("CompleteMFALogin", mock.Anything, "challenge", "123456", "192.0.2.1").Return(&domain.LoginResult{Tokens: &domain.TokenPair{AccessToken: "jwt-token", RefreshToken: "refresh-token"}}, nil)
+	suite.mockUserUseCase.On("CompleteMFALogin", mock.Anything, "challenge", "000000", "192.0.2.1").Return(nil, domain.ErrInvalidMFACode)
+
+	req := httptest.NewRequest("POST", "/login/mfa", strings.NewReader(`{"mfa_pending":"challenge","code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.LoginResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "jwt-token", response.Token)
+	assert.Empty(suite.T(), response.MFAPending)
+
+	req = httptest.NewRequest("POST", "/login/mfa", strings.NewReader(`{"mfa_pending":"challenge","code":"000000"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusUnauthorized, CodeInvalidMFACode)
+
+	req = httptest.NewRequest("POST", "/login/mfa", strings.NewReader(`{"code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+}
+
 func (suite *ControllerTestSuite) TestGetAllTasks_Success() {
 	tasks := []domain.Task{
 		{
@@ -170,28 +402,90 @@ func (suite *ControllerTestSuite) TestGetAllTasks_Success() {
 			UpdatedAt:   time.Now(),
 		},
 	}
+	page := &domain.TaskPage{Tasks: tasks, NextCursor: "next", Total: 3}
 
-	suite.mockTaskUseCase.On("GetAllTasks").Return(tasks, nil)
+	suite.mockTaskUseCase.On("GetAllTasks", mock.Anything, "p1", domain.TaskQuery{}, domain.Claims{}).Return(page, nil)
 
-	req := httptest.NewRequest("GET", "/tasks", nil)
+	req := httptest.NewRequest("GET", "/projects/p1/tasks", nil)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 
-	var response []dto.TaskResponse
+	var response dto.TaskPageResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(suite.T(), err)
-	assert.Len(suite.T(), response, 1)
-	assert.Equal(suite.T(), "Task 1", response[0].Title)
+	assert.Len(suite.T(), response.Items, 1)
+	assert.Equal(suite.T(), "Task 1", response.Items[0].Title)
+	assert.Equal(suite.T(), "next", response.NextCursor)
+	assert.Equal(suite.T(), int64(3), response.Total)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestGetAllTasks_QueryParameters() {
+	dueAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := domain.TaskQuery{
+		Status:   "pending",
+		DueAfter: dueAfter,
+		Title:    "report",
+		SortBy:   domain.SortByDueDate,
+		SortDesc: true,
+		Cursor:   "abc",
+		Limit:    5,
+	}
+	suite.mockTaskUseCase.On("GetAllTasks", mock.Anything, "p1", mock.MatchedBy(func(q domain.TaskQuery) bool {
+		return q.Status == expected.Status && q.DueAfter.Equal(expected.DueAfter) && q.Title == expected.Title &&
+			q.SortBy == expected.SortBy && q.SortDesc && q.Cursor == expected.Cursor && q.Limit == expected.Limit
+	}), domain.Claims{}).Return(&domain.TaskPage{}, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks?status=pending&due_after=2030-01-01T00:00:00Z&q=report&sort=due_date&order=desc&cursor=abc&limit=5", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestGetAllTasks_InvalidOrder() {
+	req := httptest.NewRequest("GET", "/projects/p1/tasks?order=sideways", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *ControllerTestSuite) TestGetAllTasks_InvalidQuery() {
+	suite.mockTaskUseCase.On("GetAllTasks", mock.Anything, "p1", mock.AnythingOfType("domain.TaskQuery"), domain.Claims{}).Return(nil, domain.ErrInvalidInput)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks?sort=password", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
 
+func (suite *ControllerTestSuite) TestGetAllTasks_LabelFilters() {
+	suite.mockTaskUseCase.On("GetAllTasks", mock.Anything, "p1", domain.TaskQuery{
+		LabelsAny: []string{"bug", "ops"},
+		LabelsAll: []string{"customer-x"},
+	}, domain.Claims{}).Return(&domain.TaskPage{}, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks?labels_any=bug,%20ops,&labels_all=customer-x", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
 	suite.mockTaskUseCase.AssertExpectations(suite.T())
 }
 
 func (suite *ControllerTestSuite) TestGetAllTasks_Error() {
-	suite.mockTaskUseCase.On("GetAllTasks").Return(nil, domain.ErrNotFound)
+	suite.mockTaskUseCase.On("GetAllTasks", mock.Anything, "p1", domain.TaskQuery{}, domain.Claims{}).Return(nil, errors.New("database error"))
 
-	req := httptest.NewRequest("GET", "/tasks", nil)
+	req := httptest.NewRequest("GET", "/projects/p1/tasks", nil)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -200,6 +494,42 @@ func (suite *ControllerTestSuite) TestGetAllTasks_Error() {
 	suite.mockTaskUseCase.AssertExpectations(suite.T())
 }
 
+func (suite *ControllerTestSuite) TestSearchTasks_Success() {
+	page := &domain.TaskSearchPage{
+		Hits: []domain.TaskSearchHit{{
+			Task:           domain.Task{ID: "1", Title: "Release notes"},
+			Score:          4.5,
+			TitleHighlight: "<mark>Release</mark> notes",
+		}},
+		NextCursor: "10",
+	}
+	suite.mockTaskUseCase.On("SearchTasks", mock.Anything, "p1", domain.TaskSearchQuery{Text: `"release notes"`, Cursor: "5", Limit: 5}, domain.Claims{}).Return(page, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/search?q=%22release+notes%22&cursor=5&limit=5", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var res dto.TaskSearchPageResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(suite.T(), "10", res.NextCursor)
+	if assert.Len(suite.T(), res.Items, 1) {
+		assert.Equal(suite.T(), "1", res.Items[0].Task.ID)
+		assert.Equal(suite.T(), 4.5, res.Items[0].Score)
+		assert.Equal(suite.T(), "<mark>Release</mark> notes", res.Items[0].TitleHighlight)
+	}
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestSearchTasks_MissingQuery() {
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/search", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	suite.mockTaskUseCase.AssertNotCalled(suite.T(), "SearchTasks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (suite *ControllerTestSuite) TestGetTaskByID_Success() {
 	task := domain.Task{
 		ID:          "1",
@@ -211,9 +541,9 @@ func (suite *ControllerTestSuite) TestGetTaskByID_Success() {
 		UpdatedAt:   time.Now(),
 	}
 
-	suite.mockTaskUseCase.On("GetTaskByID", "1").Return(&task, nil)
+	suite.mockTaskUseCase.On("GetTaskByID", mock.Anything, "p1", "1", domain.Claims{}).Return(&task, nil)
 
-	req := httptest.NewRequest("GET", "/tasks/1", nil)
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1", nil)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -228,9 +558,9 @@ func (suite *ControllerTestSuite) TestGetTaskByID_Success() {
 }
 
 func (suite *ControllerTestSuite) TestGetTaskByID_NotFound() {
-	suite.mockTaskUseCase.On("GetTaskByID", "999").Return(nil, domain.ErrNotFound)
+	suite.mockTaskUseCase.On("GetTaskByID", mock.Anything, "p1", "999", domain.Claims{}).Return(nil, domain.ErrNotFound)
 
-	req := httptest.NewRequest("GET", "/tasks/999", nil)
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/999", nil)
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
@@ -250,7 +580,7 @@ func (suite *ControllerTestSuite) TestCreateTask_Success() {
 		UpdatedAt:   time.Now(),
 	}
 
-	suite.mockTaskUseCase.On("CreateTask", mock.AnythingOfType("domain.Task")).Return(&task, nil)
+	suite.mockTaskUseCase.On("CreateTask", mock.Anything, "p1", mock.AnythingOfType("domain.Task"), domain.Claims{}).Return(&task, nil)
 
 	reqBody := dto.CreateTaskRequest{
 		Title:       "New Task",
@@ -260,7 +590,7 @@ func (suite *ControllerTestSuite) TestCreateTask_Success() {
 	}
 
 	jsonBody, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(jsonBody))
+	req := httptest.NewRequest("POST", "/projects/p1/tasks", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -276,6 +606,53 @@ func (suite *ControllerTestSuite) TestCreateTask_Success() {
 	suite.mockTaskUseCase.AssertExpectations(suite.T())
 }
 
+func (suite *ControllerTestSuite) TestCreateTask_Recurring() {
+	scheduled := time.Date(2099, 1, 5, 9, 0, 0, 0, time.UTC)
+	task := domain.Task{ID: "1", Title: "Water plants", DueDate: scheduled, Status: domain.StatusPending,
+		Recurrence: &domain.Recurrence{Rule: "FREQ=WEEKLY;BYDAY=MO", SeriesID: "s1", Index: 1, ScheduledAt: scheduled}}
+	suite.mockTaskUseCase.On("CreateTask", mock.Anything, "p1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Recurrence != nil && t.Recurrence.Rule == "FREQ=WEEKLY;BYDAY=MO"
+	}), domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("POST", "/projects/p1/tasks", strings.NewReader(`{"title": "Water plants", "due_date": "2099-01-05T09:00:00Z", "recurrence": "FREQ=WEEKLY;BYDAY=MO"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.TaskResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().NotNil(response.Recurrence)
+	assert.Equal(suite.T(), dto.RecurrenceResponse{Rule: "FREQ=WEEKLY;BYDAY=MO", SeriesID: "s1", Occurrence: 1, ScheduledAt: scheduled}, *response.Recurrence)
+}
+
+func (suite *ControllerTestSuite) TestUpdateSeries() {
+	title, rule := "Water all plants", ""
+	task := domain.Task{ID: "1", Title: title, Version: 4}
+	suite.mockTaskUseCase.On("UpdateSeries", mock.Anything, "p1", "1", domain.SeriesPatch{Title: &title, Rule: &rule}, int64(3), domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("PATCH", "/projects/p1/tasks/1/series", strings.NewReader(`{"title": "Water all plants", "recurrence": ""}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"3"`)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), `"4"`, w.Header().Get("ETag"))
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestUpdateSeries_NotRecurring() {
+	suite.mockTaskUseCase.On("UpdateSeries", mock.Anything, "p1", "1", mock.Anything, int64(0), domain.Claims{}).Return(nil, domain.ErrInvalidInput)
+
+	req := httptest.NewRequest("PATCH", "/projects/p1/tasks/1/series", strings.NewReader(`{"title": "Renamed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+}
+
 func (suite *ControllerTestSuite) TestCreateTask_InvalidRequest() {
 	reqBody := dto.CreateTaskRequest{
 		Title:       "",
@@ -285,7 +662,7 @@ func (suite *ControllerTestSuite) TestCreateTask_InvalidRequest() {
 	}
 
 	jsonBody, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("POST", "/tasks", bytes.NewBuffer(jsonBody))
+	req := httptest.NewRequest("POST", "/projects/p1/tasks", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -294,6 +671,1230 @@ func (suite *ControllerTestSuite) TestCreateTask_InvalidRequest() {
 	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
 }
 
+func (suite *ControllerTestSuite) TestCreateTask_ValidationError() {
+	suite.mockTaskUseCase.On("CreateTask", mock.Anything, "p1", mock.AnythingOfType("domain.Task"), domain.Claims{}).Return(nil, domain.ErrInvalidInput)
+
+	jsonBody, _ := json.Marshal(dto.CreateTaskRequest{Title: "New Task", DueDate: time.Now().Add(-24 * time.Hour)})
+	req := httptest.NewRequest("POST", "/projects/p1/tasks", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	problem := suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	assert.Equal(suite.T(), "/projects/p1/tasks", problem.Instance)
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestPromoteUser_Errors() {
+	cases := []struct {
+		username string
+		err      error
+		status   int
+		code     string
+	}{
+		{"not-admin", domain.ErrForbidden, http.StatusForbidden, CodeForbidden},
+		{"missing", domain.ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{"admin", domain.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+	}
+	for _, tc := range cases {
+		suite.mockUserUseCase.On("PromoteUser", mock.Anything, tc.username, "admin-1").Return(tc.err)
+
+		jsonBody, _ := json.Marshal(dto.PromoteUserRequest{Username: tc.username})
+		req := httptest.NewRequest("POST", "/admin/promote", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		suite.assertProblem(w, tc.status, tc.code)
+	}
+}
+
+func (suite *ControllerTestSuite) TestAssignRole() {
+	user := domain.User{ID: "user-1", Username: "alice", Role: "triager"}
+	suite.mockUserUseCase.On("AssignRole", mock.Anything, "user-1", domain.Role("triager"), "admin-1").Return(&user, nil)
+	suite.mockUserUseCase.On("AssignRole", mock.Anything, "user-2", domain.RoleAdmin, "admin-1").Return(nil, domain.ErrForbidden)
+
+	req := httptest.NewRequest("PUT", "/admin/users/user-1/role", strings.NewReader(`{"role":"triager"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.UserResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "triager", response.Role)
+
+	req = httptest.NewRequest("PUT", "/admin/users/user-2/role", strings.NewReader(`{"role":"admin"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusForbidden, CodeForbidden)
+}
+
+func (suite *ControllerTestSuite) TestChangePassword() {
+	suite.mockUserUseCase.On("ChangePassword", mock.Anything, "user-1", "oldpassword", "newpassword", "192.0.2.1").Return(&domain.TokenPair{AccessToken: "jwt-token", RefreshToken: "refresh-token"}, nil)
+	suite.mockUserUseCase.On("ChangePassword", mock.Anything, "user-1", "wrong", "newpassword", mock.Anything).Return(nil, domain.ErrInvalidCredentials)
+
+	req := httptest.NewRequest("POST", "/me/password", strings.NewReader(`{"old_password":"oldpassword","new_password":"newpassword"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var res dto.LoginResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(suite.T(), "jwt-token", res.Token)
+	assert.Equal(suite.T(), "refresh-token", res.RefreshToken)
+
+	req = httptest.NewRequest("POST", "/me/password", strings.NewReader(`{"old_password":"wrong","new_password":"newpassword"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusUnauthorized, CodeInvalidCredentials)
+
+	req = httptest.NewRequest("POST", "/me/password", strings.NewReader(`{"new_password":"newpassword"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+func (suite *ControllerTestSuite) TestConfirmMFAEnrollment() {
+	codes := []string{"abcde-fghij", "klmno-pqrst"}
+	suite.mockUserUseCase.On("ConfirmMFAEnrollment", mock.Anything, "user-1", "123456").Return(codes, nil)
+	suite.mockUserUseCase.On("ConfirmMFAEnrollment", mock.Anything, "user-1", "654321").Return(nil, domain.ErrMFAEnabled)
+
+	req := httptest.NewRequest("POST", "/me/mfa/confirm", strings.NewReader(`{"code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.RecoveryCodesResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), codes, response.RecoveryCodes)
+
+	req = httptest.NewRequest("POST", "/me/mfa/confirm", strings.NewReader(`{"code":"654321"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusConflict, CodeMFAEnabled)
+}
+
+func (suite *ControllerTestSuite) TestResetMFA() {
+	suite.mockUserUseCase.On("ResetMFA", mock.Anything, "user-1", "admin-1").Return(nil)
+	suite.mockUserUseCase.On("ResetMFA", mock.Anything, "user-2", "admin-1").Return(domain.ErrNotFound)
+
+	req := httptest.NewRequest("DELETE", "/admin/users/user-1/mfa", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("DELETE", "/admin/users/user-2/mfa", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *ControllerTestSuite) TestPasswordReset() {
+	suite.mockUserUseCase.On("RequestPasswordReset", mock.Anything, "alice").Return(nil)
+	suite.mockUserUseCase.On("ResetPassword", mock.Anything, "good", "newpassword").Return(nil)
+	suite.mockUserUseCase.On("ResetPassword", mock.Anything, "used", "newpassword").Return(domain.ErrInvalidResetToken)
+
+	req := httptest.NewRequest("POST", "/password-reset", strings.NewReader(`{"username":"alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusAccepted, w.Code)
+
+	req = httptest.NewRequest("POST", "/password-reset/confirm", strings.NewReader(`{"token":"good","new_password":"newpassword"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("POST", "/password-reset/confirm", strings.NewReader(`{"token":"used","new_password":"newpassword"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidResetToken)
+}
+
+func (suite *ControllerTestSuite) TestListUsers() {
+	disabled := true
+	suite.mockUserUseCase.On("ListUsers", mock.Anything, domain.UserQuery{Role: "user", Disabled: &disabled, Cursor: "c1", Limit: 10}).
+		Return(&domain.UserPage{Users: []domain.User{{ID: "user-1", Username: "alice", Role: domain.RoleUser, Disabled: true}}, NextCursor: "user-1"}, nil)
+
+	req := httptest.NewRequest("GET", "/admin/users?role=user&disabled=true&cursor=c1&limit=10", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.UserPageResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(suite.T(), response.Items, 1)
+	assert.True(suite.T(), response.Items[0].Disabled)
+	assert.Equal(suite.T(), "user-1", response.NextCursor)
+
+	for _, query := range []string{"limit=-1", "disabled=maybe"} {
+		req = httptest.NewRequest("GET", "/admin/users?"+query, nil)
+		w = httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	}
+}
+
+func (suite *ControllerTestSuite) TestGetUser_NotFound() {
+	suite.mockUserUseCase.On("GetUser", mock.Anything, "ghost").Return(nil, domain.ErrNotFound)
+
+	req := httptest.NewRequest("GET", "/admin/users/ghost", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.assertProblem(w, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *ControllerTestSuite) TestUserAdministration() {
+	suite.mockUserUseCase.On("DemoteUser", mock.Anything, "admin-1", "admin-1").Return(nil, domain.ErrLastAdmin)
+	suite.mockUserUseCase.On("SetUserDisabled", mock.Anything, "user-1", true, "admin-1").
+		Return(&domain.User{ID: "user-1", Role: domain.RoleUser, Disabled: true}, nil)
+	suite.mockUserUseCase.On("SetUserDisabled", mock.Anything, "user-1", false, "admin-1").
+		Return(&domain.User{ID: "user-1", Role: domain.RoleUser}, nil)
+	suite.mockUserUseCase.On("DeleteUser", mock.Anything, "user-1", "admin-1").Return(nil)
+
+	cases := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"POST", "/admin/users/user-1/disable", http.StatusOK},
+		{"POST", "/admin/users/user-1/enable", http.StatusOK},
+		{"DELETE", "/admin/users/user-1", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(suite.T(), tc.status, w.Code, tc.path)
+	}
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/users/admin-1/demote", nil))
+	suite.assertProblem(w, http.StatusConflict, CodeLastAdmin)
+	suite.mockUserUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestGetTaskByID_WrappedError() {
+	suite.mockTaskUseCase.On("GetTaskByID", mock.Anything, "p1", "1", domain.Claims{}).Return(nil, fmt.Errorf("loading task 1: %w", domain.ErrNotFound))
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	problem := suite.assertProblem(w, http.StatusNotFound, CodeNotFound)
+	assert.Equal(suite.T(), "loading task 1: not found", problem.Detail)
+}
+
+func (suite *ControllerTestSuite) TestAssignTask_Success() {
+	task := domain.Task{
+		ID:         "1",
+		Title:      "Task 1",
+		Status:     "pending",
+		CreatedBy:  "admin-1",
+		AssigneeID: "user-2",
+		DueDate:    time.Now().Add(24 * time.Hour),
+	}
+
+	suite.mockTaskUseCase.On("AssignTask", mock.Anything, "p1", "1", "user-2", mock.Anything).Return(&task, nil)
+
+	jsonBody, _ := json.Marshal(dto.AssignTaskRequest{AssigneeID: "user-2"})
+	req := httptest.NewRequest("PUT", "/projects/p1/tasks/1/assignee", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response dto.TaskResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "user-2", response.AssigneeID)
+	assert.Equal(suite.T(), "admin-1", response.CreatedBy)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestUnassignTask_NotFound() {
+	suite.mockTaskUseCase.On("AssignTask", mock.Anything, "p1", "999", "", mock.Anything).Return(nil, domain.ErrNotFound)
+
+	req := httptest.NewRequest("DELETE", "/projects/p1/tasks/999/assignee", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestTransitionTask_Success() {
+	task := domain.Task{ID: "1", Title: "Task 1", Status: domain.StatusInProgress}
+	suite.mockTaskUseCase.On("TransitionTask", mock.Anything, "p1", "1", domain.StatusInProgress, mock.Anything).Return(&task, nil)
+
+	jsonBody, _ := json.Marshal(dto.TransitionTaskRequest{Status: "in_progress"})
+	req := httptest.NewRequest("POST", "/projects/p1/tasks/1/transition", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response dto.TaskResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "in_progress", response.Status)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestCreateSubtask() {
+	task := domain.Task{ID: "2", Title: "Child", Status: domain.StatusPending, ParentID: "1", Version: 1}
+	suite.mockTaskUseCase.On("CreateTask", mock.Anything, "p1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.ParentID == "1" && t.Title == "Child"
+	}), domain.Claims{}).Return(&task, nil)
+
+	jsonBody, _ := json.Marshal(dto.CreateTaskRequest{Title: "Child", DueDate: time.Now().Add(time.Hour)})
+	req := httptest.NewRequest("POST", "/projects/p1/tasks/1/subtasks", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.TaskResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "1", response.ParentID)
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestGetSubtasks() {
+	suite.mockTaskUseCase.On("GetSubtasks", mock.Anything, "p1", "1", domain.Claims{}).Return([]domain.Task{{ID: "2", ParentID: "1"}}, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1/subtasks", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.TaskListResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Items, 1)
+	assert.Equal(suite.T(), "2", response.Items[0].ID)
+}
+
+func (suite *ControllerTestSuite) TestGetTaskByID_IncludesChecklistAndProgress() {
+	task := domain.Task{
+		ID:        "1",
+		Checklist: []domain.ChecklistItem{{ID: "a", Text: "Draft", Done: true}},
+		Progress:  &domain.TaskProgress{Completed: 1, Total: 2, Percent: 50},
+	}
+	suite.mockTaskUseCase.On("GetTaskByID", mock.Anything, "p1", "1", domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	var response dto.TaskResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), []dto.ChecklistItemResponse{{ID: "a", Text: "Draft", Done: true}}, response.Checklist)
+	assert.Equal(suite.T(), &dto.TaskProgressResponse{Completed: 1, Total: 2, Percent: 50}, response.Progress)
+}
+
+func (suite *ControllerTestSuite) TestAddChecklistItem() {
+	task := domain.Task{ID: "1", Checklist: []domain.ChecklistItem{{ID: "a", Text: "Draft"}}, Version: 2}
+	suite.mockTaskUseCase.On("AddChecklistItem", mock.Anything, "p1", "1", "Draft", domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("POST", "/projects/p1/tasks/1/checklist", strings.NewReader(`{"text":"Draft"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	assert.Equal(suite.T(), `"2"`, w.Header().Get("ETag"))
+}
+
+func (suite *ControllerTestSuite) TestUpdateChecklistItem() {
+	task := domain.Task{ID: "1"}
+	suite.mockTaskUseCase.On("UpdateChecklistItem", mock.Anything, "p1", "1", "a", mock.MatchedBy(func(p domain.ChecklistItemPatch) bool {
+		return p.Text == nil && p.Done != nil && *p.Done
+	}), domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("PATCH", "/projects/p1/tasks/1/checklist/a", strings.NewReader(`{"done":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestReorderChecklist_InvalidOrder() {
+	suite.mockTaskUseCase.On("ReorderChecklist", mock.Anything, "p1", "1", []string{"b"}, domain.Claims{}).Return(nil, domain.ErrInvalidInput)
+
+	req := httptest.NewRequest("PUT", "/projects/p1/tasks/1/checklist/order", strings.NewReader(`{"item_ids":["b"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+func (suite *ControllerTestSuite) TestRemoveChecklistItem_NotFound() {
+	suite.mockTaskUseCase.On("RemoveChecklistItem", mock.Anything, "p1", "1", "x", domain.Claims{}).Return(nil, domain.ErrNotFound)
+
+	req := httptest.NewRequest("DELETE", "/projects/p1/tasks/1/checklist/x", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *ControllerTestSuite) TestAttachLabel() {
+	task := domain.Task{ID: "1", Title: "Task", Status: domain.StatusPending, Labels: []string{"bug"}, Version: 2}
+	suite.mockTaskUseCase.On("AttachLabel", mock.Anything, "p1", "1", "bug", domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("PUT", "/projects/p1/tasks/1/labels/bug", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), `"2"`, w.Header().Get("ETag"))
+	var response dto.TaskResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), []string{"bug"}, response.Labels)
+}
+
+func (suite *ControllerTestSuite) TestAttachLabel_UnknownLabel() {
+	suite.mockTaskUseCase.On("AttachLabel", mock.Anything, "p1", "1", "nope", domain.Claims{}).Return(nil, domain.ErrNotFound)
+
+	req := httptest.NewRequest("PUT", "/projects/p1/tasks/1/labels/nope", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *ControllerTestSuite) TestDetachLabel() {
+	task := domain.Task{ID: "1", Title: "Task", Status: domain.StatusPending, Version: 3}
+	suite.mockTaskUseCase.On("DetachLabel", mock.Anything, "p1", "1", "bug", domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("DELETE", "/projects/p1/tasks/1/labels/bug", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.TaskResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(suite.T(), response.Labels)
+}
+
+func (suite *ControllerTestSuite) TestListLabels() {
+	suite.mockLabelUseCase.On("ListLabels", mock.Anything).Return([]domain.Label{{Name: "bug", Color: "#ff0000"}}, nil)
+
+	req := httptest.NewRequest("GET", "/labels", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.LabelListResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Items, 1)
+	assert.Equal(suite.T(), "#ff0000", response.Items[0].Color)
+}
+
+func (suite *ControllerTestSuite) TestCreateLabel() {
+	label := domain.Label{Name: "bug", Color: "#ff0000", CreatedAt: time.Now()}
+	suite.mockLabelUseCase.On("CreateLabel", mock.Anything, domain.Label{Name: "Bug", Color: "#FF0000"}, "").Return(&label, nil)
+
+	jsonBody, _ := json.Marshal(dto.CreateLabelRequest{Name: "Bug", Color: "#FF0000"})
+	req := httptest.NewRequest("POST", "/admin/labels", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.LabelResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "bug", response.Name)
+}
+
+func (suite *ControllerTestSuite) TestCreateLabel_Duplicate() {
+	suite.mockLabelUseCase.On("CreateLabel", mock.Anything, mock.Anything, "").Return(nil, domain.ErrDuplicateEntry)
+
+	jsonBody, _ := json.Marshal(dto.CreateLabelRequest{Name: "bug", Color: "#ff0000"})
+	req := httptest.NewRequest("POST", "/admin/labels", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusConflict, CodeDuplicateEntry)
+}
+
+func (suite *ControllerTestSuite) TestUpdateLabel_MissingColor() {
+	req := httptest.NewRequest("PATCH", "/admin/labels/bug", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	suite.mockLabelUseCase.AssertNotCalled(suite.T(), "UpdateLabel", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ControllerTestSuite) TestDeleteLabel() {
+	suite.mockLabelUseCase.On("DeleteLabel", mock.Anything, "bug", "").Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/admin/labels/bug", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockLabelUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestCreateRole() {
+	role := domain.RoleDefinition{Name: "triager", Permissions: []domain.Permission{domain.PermTasksUpdate}}
+	suite.mockRoleUseCase.On("CreateRole", mock.Anything, domain.RoleDefinition{
+		Name: "triager", Description: "Sorts tasks", Permissions: []domain.Permission{domain.PermTasksUpdate},
+	}, "").Return(&role, nil)
+
+	req := httptest.NewRequest("POST", "/admin/roles", strings.NewReader(`{"name":"triager","description":"Sorts tasks","permissions":["tasks:update"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.RoleResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), []string{"tasks:update"}, response.Permissions)
+	assert.False(suite.T(), response.Builtin)
+}
+
+func (suite *ControllerTestSuite) TestUpdateRole_ClearsPermissions() {
+	role := domain.RoleDefinition{Name: "triager", Permissions: []domain.Permission{}}
+	suite.mockRoleUseCase.On("UpdateRole", mock.Anything, domain.Role("triager"), mock.MatchedBy(func(p domain.RoleDefinitionPatch) bool {
+		return p.Description == nil && p.Permissions != nil && len(*p.Permissions) == 0
+	}), "").Return(&role, nil)
+
+	req := httptest.NewRequest("PATCH", "/admin/roles/triager", strings.NewReader(`{"permissions":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockRoleUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestDeleteRole_Errors() {
+	suite.mockRoleUseCase.On("DeleteRole", mock.Anything, domain.RoleUser, "").Return(domain.ErrBuiltinRole)
+	suite.mockRoleUseCase.On("DeleteRole", mock.Anything, domain.Role("triager"), "").Return(domain.ErrRoleInUse)
+
+	for path, code := range map[string]string{"/admin/roles/user": CodeBuiltinRole, "/admin/roles/triager": CodeRoleInUse} {
+		req := httptest.NewRequest("DELETE", path, nil)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		suite.assertProblem(w, http.StatusConflict, code)
+	}
+}
+
+func (suite *ControllerTestSuite) TestListComments_NestsReplies() {
+	page := domain.CommentPage{
+		Threads: []domain.CommentThread{{
+			Comment: domain.Comment{ID: "c1", TaskID: "1", ThreadID: "c1", Body: "first"},
+			Replies: []domain.Comment{
+				{ID: "c2", TaskID: "1", ThreadID: "c1", ParentID: "c1", Body: "reply"},
+				{ID: "c3", TaskID: "1", ThreadID: "c1", ParentID: "c2", Body: "nested"},
+				{ID: "c4", TaskID: "1", ThreadID: "c1", ParentID: "c1", Body: "another reply"},
+			},
+		}},
+		NextCursor: "c1",
+	}
+	suite.mockCommentUseCase.On("ListComments", mock.Anything, "p1", "1", domain.CommentQuery{Limit: 1}, domain.Claims{}).Return(&page, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1/comments?limit=1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.CommentPageResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "c1", response.NextCursor)
+	suite.Require().Len(response.Items, 1)
+	replies := response.Items[0].Replies
+	suite.Require().Len(replies, 2)
+	assert.Equal(suite.T(), "reply", replies[0].Body)
+	assert.Equal(suite.T(), "nested", replies[0].Replies[0].Body)
+	assert.Equal(suite.T(), "another reply", replies[1].Body)
+}
+
+func (suite *ControllerTestSuite) TestAddComment() {
+	comment := domain.Comment{ID: "c2", TaskID: "1", ThreadID: "c1", ParentID: "c1", Body: "reply"}
+	suite.mockCommentUseCase.On("AddComment", mock.Anything, "p1", "1", domain.Comment{Body: "reply", ParentID: "c1"}, domain.Claims{}).Return(&comment, nil)
+
+	req := httptest.NewRequest("POST", "/projects/p1/tasks/1/comments", strings.NewReader(`{"body": "reply", "parent_id": "c1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.CommentResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "c1", response.ParentID)
+}
+
+func (suite *ControllerTestSuite) TestAddComment_MissingBody() {
+	req := httptest.NewRequest("POST", "/projects/p1/tasks/1/comments", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+func (suite *ControllerTestSuite) TestUpdateComment_Forbidden() {
+	suite.mockCommentUseCase.On("UpdateComment", mock.Anything, "p1", "1", "c1", "edited", domain.Claims{}).Return(nil, domain.ErrForbidden)
+
+	req := httptest.NewRequest("PATCH", "/projects/p1/tasks/1/comments/c1", strings.NewReader(`{"body": "edited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusForbidden, CodeForbidden)
+}
+
+func (suite *ControllerTestSuite) TestDeleteComment() {
+	suite.mockCommentUseCase.On("DeleteComment", mock.Anything, "p1", "1", "c1", domain.Claims{}).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/projects/p1/tasks/1/comments/c1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockCommentUseCase.AssertExpectations(suite.T())
+}
+
+// newUploadRequest builds a multipart upload of content under the given form field.
+func newUploadRequest(field string, filename string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile(field, filename)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/projects/p1/tasks/1/attachments", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func (suite *ControllerTestSuite) TestListAttachments() {
+	attachments := []domain.Attachment{{ID: "a1", Filename: "notes.txt", ContentType: "text/plain; charset=utf-8", Size: 5}}
+	suite.mockAttachmentUseCase.On("ListAttachments", mock.Anything, "p1", "1", domain.Claims{}).Return(attachments, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1/attachments", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.AttachmentListResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Items, 1)
+	assert.Equal(suite.T(), "notes.txt", response.Items[0].Filename)
+}
+
+func (suite *ControllerTestSuite) TestUploadAttachment() {
+	var received []byte
+	attachment := domain.Attachment{ID: "a1", Filename: "notes.txt", ContentType: "text/plain; charset=utf-8", Size: 5}
+	suite.mockAttachmentUseCase.On("AddAttachment", mock.Anything, "p1", "1", mock.MatchedBy(func(upload domain.AttachmentUpload) bool {
+		return upload.Filename == "notes.txt"
+	}), domain.Claims{}).Run(func(args mock.Arguments) {
+		received, _ = io.ReadAll(args.Get(3).(domain.AttachmentUpload).Content)
+	}).Return(&attachment, nil)
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, newUploadRequest("file", "notes.txt", []byte("hello")))
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	assert.Equal(suite.T(), "hello", string(received))
+	var response dto.AttachmentResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "a1", response.ID)
+}
+
+func (suite *ControllerTestSuite) TestUploadAttachment_RejectedRequests() {
+	suite.Run("missing file field", func() {
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, newUploadRequest("document", "notes.txt", []byte("hello")))
+		suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	})
+
+	suite.Run("not multipart", func() {
+		req := httptest.NewRequest("POST", "/projects/p1/tasks/1/attachments", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+		suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	})
+
+	suite.Run("body too large", func() {
+		suite.mockAttachmentUseCase.On("AddAttachment", mock.Anything, "p1", "1", mock.Anything, domain.Claims{}).
+			Return(nil, fmt.Errorf("storing attachment: %w", &http.MaxBytesError{Limit: 1 << 10})).Once()
+
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, newUploadRequest("file", "big.bin", bytes.Repeat([]byte("x"), 128<<10)))
+		suite.assertProblem(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge)
+	})
+}
+
+func (suite *ControllerTestSuite) TestDownloadAttachment() {
+	attachment := domain.Attachment{ID: "a1", Filename: "résumé.pdf", ContentType: "application/pdf", Size: 5}
+	suite.mockAttachmentUseCase.On("OpenAttachment", mock.Anything, "p1", "1", "a1", domain.Claims{}).
+		Return(&attachment, io.NopCloser(strings.NewReader("%PDF-")), nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1/attachments/a1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "%PDF-", w.Body.String())
+	assert.Equal(suite.T(), "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(suite.T(), "attachment; filename*=utf-8''r%C3%A9sum%C3%A9.pdf", w.Header().Get("Content-Disposition"))
+}
+
+func (suite *ControllerTestSuite) TestDownloadAttachment_NotFound() {
+	suite.mockAttachmentUseCase.On("OpenAttachment", mock.Anything, "p1", "1", "missing", domain.Claims{}).Return(nil, nil, domain.ErrNotFound)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1/attachments/missing", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *ControllerTestSuite) TestDeleteAttachment_Forbidden() {
+	suite.mockAttachmentUseCase.On("DeleteAttachment", mock.Anything, "p1", "1", "a1", domain.Claims{}).Return(domain.ErrForbidden)
+
+	req := httptest.NewRequest("DELETE", "/projects/p1/tasks/1/attachments/a1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusForbidden, CodeForbidden)
+}
+
+func (suite *ControllerTestSuite) TestTransitionTask_Errors() {
+	cases := []struct {
+		status string
+		err    error
+		code   int
+	}{
+		{"finished", domain.ErrInvalidStatus, http.StatusBadRequest},
+		{"done", domain.ErrInvalidTransition, http.StatusConflict},
+		{"review", domain.ErrNotFound, http.StatusNotFound},
+		{"in_progress", domain.ErrOpenSubtasks, http.StatusConflict},
+	}
+	for _, tc := range cases {
+		suite.mockTaskUseCase.On("TransitionTask", mock.Anything, "p1", "1", domain.TaskStatus(tc.status), mock.Anything).Return(nil, tc.err)
+
+		jsonBody, _ := json.Marshal(dto.TransitionTaskRequest{Status: tc.status})
+		req := httptest.NewRequest("POST", "/projects/p1/tasks/1/transition", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), tc.code, w.Code, tc.status)
+	}
+}
+
+func (suite *ControllerTestSuite) TestPatchTask_Success() {
+	task := domain.Task{ID: "1", Title: "Renamed", Description: "", Status: domain.StatusPending, Version: 4}
+	description := ""
+	title := "Renamed"
+	patch := domain.TaskPatch{Title: &title, Description: &description}
+	suite.mockTaskUseCase.On("PatchTask", mock.Anything, "p1", "1", patch, int64(3), mock.Anything).Return(&task, nil)
+
+	req := httptest.NewRequest("PATCH", "/projects/p1/tasks/1", strings.NewReader(`{"title":"Renamed","description":null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"3"`)
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), `"4"`, w.Header().Get("ETag"))
+
+	var response dto.TaskResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(4), response.Version)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestPatchTask_VersionConflict() {
+	suite.mockTaskUseCase.On("PatchTask", mock.Anything, "p1", "1", mock.Anything, int64(3), mock.Anything).Return(nil, domain.ErrVersionConflict)
+
+	req := httptest.NewRequest("PATCH", "/projects/p1/tasks/1", strings.NewReader(`{"title":"Renamed"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"3"`)
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, w.Code)
+}
+
+func (suite *ControllerTestSuite) TestPatchTask_RejectedRequests() {
+	cases := []struct {
+		name        string
+		body        string
+		contentType string
+		ifMatch     string
+		code        int
+	}{
+		{"missing If-Match", `{"title":"x"}`, "application/merge-patch+json", "", http.StatusPreconditionRequired},
+		{"malformed If-Match", `{"title":"x"}`, "application/merge-patch+json", "abc", http.StatusPreconditionFailed},
+		{"wrong content type", `{"title":"x"}`, "text/plain", `"1"`, http.StatusUnsupportedMediaType},
+		{"not an object", `["title"]`, "application/merge-patch+json", `"1"`, http.StatusBadRequest},
+		{"null title", `{"title":null}`, "application/merge-patch+json", `"1"`, http.StatusBadRequest},
+		{"read-only field", `{"created_by":"me"}`, "application/merge-patch+json", `"1"`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("PATCH", "/projects/p1/tasks/1", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		if tc.ifMatch != "" {
+			req.Header.Set("If-Match", tc.ifMatch)
+		}
+
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), tc.code, w.Code, tc.name)
+	}
+	suite.mockTaskUseCase.AssertNotCalled(suite.T(), "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ControllerTestSuite) TestUpdateTask_StaleIfMatch() {
+	suite.mockTaskUseCase.On("UpdateTask", mock.Anything, "p1", "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Version == 2
+	}), mock.Anything).Return(nil, domain.ErrVersionConflict)
+
+	jsonBody, _ := json.Marshal(dto.UpdateTaskRequest{Title: "New", DueDate: time.Now().Add(time.Hour)})
+	req := httptest.NewRequest("PUT", "/projects/p1/tasks/1", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `W/"2"`)
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusPreconditionFailed, w.Code)
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestGetTaskHistory_Success() {
+	page := &domain.AuditPage{
+		Entries: []domain.AuditEntry{{
+			ID:         "a1",
+			ActorID:    "admin-1",
+			Action:     domain.AuditTaskUpdated,
+			EntityType: domain.AuditEntityTask,
+			EntityID:   "1",
+			Changes:    []domain.FieldChange{{Field: "title", Before: "Old", After: "New"}},
+		}},
+		NextCursor: "a1",
+	}
+	suite.mockAuditUseCase.On("GetTaskHistory", mock.Anything, "p1", "1", domain.AuditQuery{Limit: 1}).Return(page, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1/history?limit=1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response dto.AuditPageResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), response.Items, 1)
+	assert.Equal(suite.T(), "New", response.Items[0].Changes[0].After)
+	assert.Equal(suite.T(), "a1", response.NextCursor)
+
+	suite.mockAuditUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestListAuditEntries_Filters() {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := domain.AuditQuery{ActorID: "admin-1", Action: domain.AuditUserPromoted, Since: since}
+	suite.mockAuditUseCase.On("ListEntries", mock.Anything, mock.MatchedBy(func(q domain.AuditQuery) bool {
+		return q.ActorID == expected.ActorID && q.Action == expected.Action && q.Since.Equal(since)
+	})).Return(&domain.AuditPage{}, nil)
+
+	req := httptest.NewRequest("GET", "/admin/audit?actor_id=admin-1&action=user.promoted&since=2024-01-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.JSONEq(suite.T(), `{"items":[]}`, w.Body.String())
+	suite.mockAuditUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestListAuditEntries_InvalidQuery() {
+	suite.mockAuditUseCase.On("ListEntries", mock.Anything, mock.Anything).Return(nil, domain.ErrInvalidInput)
+
+	req := httptest.NewRequest("GET", "/admin/audit?cursor=bad", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *ControllerTestSuite) TestListWebhooks_HidesSecret() {
+	webhooks := []domain.Webhook{{ID: "w1", URL: "https://example.com/hook", Secret: "0123456789abcdef", Events: []string{domain.EventTaskCreated}, Active: true}}
+	suite.mockWebhookUseCase.On("ListWebhooks", mock.Anything).Return(webhooks, nil)
+
+	req := httptest.NewRequest("GET", "/admin/webhooks", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.NotContains(suite.T(), w.Body.String(), "0123456789abcdef")
+	var response dto.WebhookListResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Items, 1)
+	assert.Equal(suite.T(), []string{domain.EventTaskCreated}, response.Items[0].Events)
+}
+
+func (suite *ControllerTestSuite) TestCreateWebhook_ReturnsSecret() {
+	created := domain.Webhook{ID: "w1", URL: "https://example.com/hook", Secret: "generated-secret-value", Events: []string{domain.EventTaskCreated}}
+	suite.mockWebhookUseCase.On("CreateWebhook", mock.Anything, domain.Webhook{
+		URL:    "https://example.com/hook",
+		Events: []string{domain.EventTaskCreated},
+	}, "").Return(&created, nil)
+
+	req := httptest.NewRequest("POST", "/admin/webhooks", strings.NewReader(`{"url":"https://example.com/hook","events":["task.created"],"active":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.WebhookResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "generated-secret-value", response.Secret)
+}
+
+func (suite *ControllerTestSuite) TestCreateWebhook_InvalidRequest() {
+	req := httptest.NewRequest("POST", "/admin/webhooks", strings.NewReader(`{"url":"https://example.com/hook"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	suite.mockWebhookUseCase.AssertNotCalled(suite.T(), "CreateWebhook", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ControllerTestSuite) TestUpdateWebhook_NotFound() {
+	suite.mockWebhookUseCase.On("UpdateWebhook", mock.Anything, "w9", mock.Anything).Return(nil, domain.ErrNotFound)
+
+	req := httptest.NewRequest("PATCH", "/admin/webhooks/w9", strings.NewReader(`{"active":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *ControllerTestSuite) TestListWebhookDeliveries() {
+	attempted := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	page := &domain.WebhookDeliveryPage{
+		Deliveries: []domain.WebhookDelivery{{ID: "d1", WebhookID: "w1", Payload: `{"type":"task.created"}`, Status: domain.DeliveryFailed, Attempts: 3, LastAttemptAt: attempted}},
+		NextCursor: "d1",
+	}
+	suite.mockWebhookUseCase.On("ListDeliveries", mock.Anything, "w1", domain.WebhookDeliveryQuery{Status: domain.DeliveryFailed, Limit: 1}).Return(page, nil)
+
+	req := httptest.NewRequest("GET", "/admin/webhooks/w1/deliveries?status=failed&limit=1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.WebhookDeliveryPageResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Items, 1)
+	assert.JSONEq(suite.T(), `{"type":"task.created"}`, string(response.Items[0].Payload))
+	assert.Nil(suite.T(), response.Items[0].NextAttemptAt)
+	assert.Equal(suite.T(), "d1", response.NextCursor)
+}
+
+func (suite *ControllerTestSuite) TestReplayWebhookDelivery() {
+	replay := &domain.WebhookDelivery{ID: "d2", WebhookID: "w1", Payload: `{}`, Status: domain.DeliveryPending, ReplayOf: "d1"}
+	suite.mockWebhookUseCase.On("ReplayDelivery", mock.Anything, "w1", "d1").Return(replay, nil)
+
+	req := httptest.NewRequest("POST", "/admin/webhooks/w1/deliveries/d1/replay", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusAccepted, w.Code)
+	var response dto.WebhookDeliveryResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "d1", response.ReplayOf)
+}
+
+func (suite *ControllerTestSuite) TestDeleteWebhook() {
+	suite.mockWebhookUseCase.On("DeleteWebhook", mock.Anything, "w1").Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/admin/webhooks/w1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockWebhookUseCase.AssertExpectations(suite.T())
+}
+
+// streamEvents returns a closed stream holding the given events, so the
+// handler ends once it has sent them.
+func streamEvents(events ...domain.StreamEvent) chan domain.StreamEvent {
+	ch := make(chan domain.StreamEvent, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func (suite *ControllerTestSuite) TestStreamTasks_SSE() {
+	task := &domain.Task{ID: "t1", Title: "Write docs", Status: domain.StatusPending}
+	events := streamEvents(
+		domain.StreamEvent{ID: "e-1", Event: domain.Event{Type: domain.EventTaskCreated, Task: task}},
+		domain.StreamEvent{ID: "e-2", Event: domain.Event{Type: domain.EventStreamReset}},
+	)
+	suite.mockTaskStreamUseCase.On("Subscribe", mock.Anything, "p1", domain.Claims{}, "").Return(events, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/stream", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "text/event-stream", w.Header().Get("Content-Type"))
+	messages := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	suite.Require().Len(messages, 2)
+	lines := strings.Split(messages[0], "\n")
+	suite.Require().Len(lines, 3)
+	assert.Equal(suite.T(), "id: e-1", lines[0])
+	assert.Equal(suite.T(), "event: task.created", lines[1])
+	var data dto.TaskStreamEvent
+	assert.NoError(suite.T(), json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &data))
+	assert.Equal(suite.T(), "e-1", data.ID)
+	suite.Require().NotNil(data.Task)
+	assert.Equal(suite.T(), "Write docs", data.Task.Title)
+	assert.True(suite.T(), strings.HasPrefix(messages[1], "id: e-2\nevent: stream.reset\n"))
+}
+
+func (suite *ControllerTestSuite) TestStreamTasks_LastEventID() {
+	suite.mockTaskStreamUseCase.On("Subscribe", mock.Anything, "p1", domain.Claims{}, "e-7").Return(streamEvents(), nil).Twice()
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/stream", nil)
+	req.Header.Set("Last-Event-ID", "e-7")
+	suite.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// The query parameter is for clients that cannot set headers
+	req = httptest.NewRequest("GET", "/projects/p1/tasks/stream?last_event_id=e-7", nil)
+	suite.router.ServeHTTP(httptest.NewRecorder(), req)
+
+	suite.mockTaskStreamUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestStreamTasks_MalformedLastEventID() {
+	suite.mockTaskStreamUseCase.On("Subscribe", mock.Anything, "p1", domain.Claims{}, "bad").Return(nil, fmt.Errorf("%w: malformed Last-Event-ID", domain.ErrInvalidInput))
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/stream", nil)
+	req.Header.Set("Last-Event-ID", "bad")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+}
+
+func (suite *ControllerTestSuite) TestStreamTasks_WebSocket() {
+	task := &domain.Task{ID: "t1", Title: "Write docs", Status: domain.StatusDone}
+	events := streamEvents(domain.StreamEvent{ID: "e-2", Event: domain.Event{Type: domain.EventTaskUpdated, Task: task}})
+	suite.mockTaskStreamUseCase.On("Subscribe", mock.Anything, "p1", domain.Claims{}, "e-1").Return(events, nil)
+
+	server := httptest.NewServer(suite.router)
+	defer server.Close()
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/projects/p1/tasks/stream?last_event_id=e-1", "", server.URL)
+	suite.Require().NoError(err)
+	defer ws.Close()
+
+	var message dto.TaskStreamEvent
+	suite.Require().NoError(websocket.JSON.Receive(ws, &message))
+	assert.Equal(suite.T(), "e-2", message.ID)
+	assert.Equal(suite.T(), domain.EventTaskUpdated, message.Type)
+	suite.Require().NotNil(message.Task)
+	assert.Equal(suite.T(), "done", message.Task.Status)
+
+	// The connection is closed once the subscription ends
+	assert.Error(suite.T(), websocket.JSON.Receive(ws, &message))
+}
+
+func (suite *ControllerTestSuite) TestRefresh_Success() {
+	suite.mockUserUseCase.On("Refresh", mock.Anything, "refresh-token").Return(&domain.TokenPair{AccessToken: "new-jwt", RefreshToken: "new-refresh"}, nil)
+
+	jsonBody, _ := json.Marshal(dto.RefreshRequest{RefreshToken: "refresh-token"})
+	req := httptest.NewRequest("POST", "/refresh", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response dto.LoginResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new-jwt", response.Token)
+	assert.Equal(suite.T(), "new-refresh", response.RefreshToken)
+
+	suite.mockUserUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestRefresh_InvalidToken() {
+	suite.mockUserUseCase.On("Refresh", mock.Anything, "stale").Return(nil, domain.ErrUnauthorized)
+
+	jsonBody, _ := json.Marshal(dto.RefreshRequest{RefreshToken: "stale"})
+	req := httptest.NewRequest("POST", "/refresh", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	suite.mockUserUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestLogout_Success() {
+	suite.mockUserUseCase.On("Logout", mock.Anything, domain.Claims{}, "refresh-token").Return(nil)
+
+	jsonBody, _ := json.Marshal(dto.LogoutRequest{RefreshToken: "refresh-token"})
+	req := httptest.NewRequest("POST", "/logout", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	suite.mockUserUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestCreateProject() {
+	project := domain.Project{ID: "p1", Name: "Launch", CreatedBy: "user-1", Members: []domain.ProjectMember{{UserID: "user-1", Role: domain.ProjectOwner}}}
+	suite.mockProjectUseCase.On("CreateProject", mock.Anything, domain.Project{Name: "Launch", Description: "Q3"}, "").Return(&project, nil)
+
+	req := httptest.NewRequest("POST", "/projects", strings.NewReader(`{"name": "Launch", "description": "Q3"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var response dto.ProjectResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(suite.T(), "p1", response.ID)
+	suite.Require().Len(response.Members, 1)
+	assert.Equal(suite.T(), "owner", response.Members[0].Role)
+}
+
+func (suite *ControllerTestSuite) TestCreateProject_MissingName() {
+	req := httptest.NewRequest("POST", "/projects", strings.NewReader(`{"description": "Q3"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusBadRequest, CodeInvalidInput)
+	suite.mockProjectUseCase.AssertNotCalled(suite.T(), "CreateProject", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ControllerTestSuite) TestListProjects() {
+	suite.mockProjectUseCase.On("ListProjects", mock.Anything, domain.Claims{}).Return([]domain.Project{{ID: "p1", Name: "Launch"}}, nil)
+
+	req := httptest.NewRequest("GET", "/projects", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response dto.ProjectListResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.Items, 1)
+	assert.Equal(suite.T(), "Launch", response.Items[0].Name)
+	assert.NotNil(suite.T(), response.Items[0].Members)
+}
+
+func (suite *ControllerTestSuite) TestUpdateProject() {
+	name := "Relaunch"
+	project := domain.Project{ID: "p1", Name: name}
+	suite.mockProjectUseCase.On("UpdateProject", mock.Anything, "p1", domain.ProjectPatch{Name: &name}, "").Return(&project, nil)
+
+	req := httptest.NewRequest("PATCH", "/projects/p1", strings.NewReader(`{"name": "Relaunch"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockProjectUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestDeleteProject_NotEmpty() {
+	suite.mockProjectUseCase.On("DeleteProject", mock.Anything, "p1", "").Return(domain.ErrProjectNotEmpty)
+
+	req := httptest.NewRequest("DELETE", "/projects/p1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusConflict, CodeProjectNotEmpty)
+}
+
+func (suite *ControllerTestSuite) TestSetProjectMember() {
+	project := domain.Project{ID: "p1", Name: "Launch", Members: []domain.ProjectMember{{UserID: "user-2", Role: domain.ProjectEditor}}}
+	suite.mockProjectUseCase.On("SetMember", mock.Anything, "p1", "user-2", domain.ProjectEditor, "").Return(&project, nil)
+
+	req := httptest.NewRequest("PUT", "/projects/p1/members/user-2", strings.NewReader(`{"role": "editor"}`))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.mockProjectUseCase.AssertExpectations(suite.T())
+}
+
+func (suite *ControllerTestSuite) TestRemoveProjectMember_LastOwner() {
+	suite.mockProjectUseCase.On("RemoveMember", mock.Anything, "p1", "user-1", "").Return(nil, domain.ErrLastOwner)
+
+	req := httptest.NewRequest("DELETE", "/projects/p1/members/user-1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.assertProblem(w, http.StatusConflict, CodeLastOwner)
+}
+
+type ctxKey string
+
+func (suite *ControllerTestSuite) TestGetTaskByID_PassesRequestContext() {
+	task := domain.Task{ID: "1", Title: "Task 1"}
+	suite.mockTaskUseCase.On("GetTaskByID", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(ctxKey("request-id")) == "abc"
+	}), "p1", "1", domain.Claims{}).Return(&task, nil)
+
+	req := httptest.NewRequest("GET", "/projects/p1/tasks/1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey("request-id"), "abc"))
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	suite.mockTaskUseCase.AssertExpectations(suite.T())
+}
+
 func TestControllerTestSuite(t *testing.T) {
 	suite.Run(t, new(ControllerTestSuite))
 }
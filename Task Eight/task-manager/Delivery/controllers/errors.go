@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"task-manager/Delivery/dto"
+	domain "task-manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors raised by the HTTP layer itself rather than by a use case.
+var (
+	errUnsupportedMediaType = errors.New("Content-Type must be application/merge-patch+json")
+	errPreconditionRequired = errors.New("If-Match header is required")
+	errPreconditionFailed   = errors.New("If-Match does not match any task version")
+)
+
+// Error codes are part of the API contract, never change an existing one.
+const (
+	CodeInvalidInput         = "invalid_input"
+	CodeInvalidStatus        = "invalid_status"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeDuplicateEntry       = "duplicate_entry"
+	CodeInvalidTransition    = "invalid_transition"
+	CodeOpenSubtasks         = "open_subtasks"
+	CodeHasSubtasks          = "has_subtasks"
+	CodeLastOwner            = "last_owner"
+	CodeProjectNotEmpty      = "project_not_empty"
+	CodeBuiltinRole          = "builtin_role"
+	CodeRoleInUse            = "role_in_use"
+	CodeLastAdmin            = "last_admin"
+	CodeUserDisabled         = "user_disabled"
+	CodeInvalidResetToken    = "invalid_reset_token"
+	CodeTooManyAttempts      = "too_many_attempts"
+	CodeInvalidMFACode       = "invalid_mfa_code"
+	CodeMFAEnabled           = "mfa_enabled"
+	CodeMFARequired          = "mfa_required"
+	CodeVersionConflict      = "version_conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePreconditionRequired = "precondition_required"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeInternal             = "internal_error"
+)
+
+// errorMappings translates known errors to a status and code. Lookups use
+// errors.Is, so use cases and middleware may wrap these with more context.
+var errorMappings = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+	{domain.ErrInvalidStatus, http.StatusBadRequest, CodeInvalidStatus},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+	{domain.ErrInvalidCredentials, http.StatusUnauthorized, CodeInvalidCredentials},
+	{domain.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{domain.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{domain.ErrDuplicateEntry, http.StatusConflict, CodeDuplicateEntry},
+	{domain.ErrInvalidTransition, http.StatusConflict, CodeInvalidTransition},
+	{domain.ErrOpenSubtasks, http.StatusConflict, CodeOpenSubtasks},
+	{domain.ErrHasSubtasks, http.StatusConflict, CodeHasSubtasks},
+	{domain.ErrLastOwner, http.StatusConflict, CodeLastOwner},
+	{domain.ErrProjectNotEmpty, http.StatusConflict, CodeProjectNotEmpty},
+	{domain.ErrBuiltinRole, http.StatusConflict, CodeBuiltinRole},
+	{domain.ErrRoleInUse, http.StatusConflict, CodeRoleInUse},
+	{domain.ErrLastAdmin, http.StatusConflict, CodeLastAdmin},
+	{domain.ErrUserDisabled, http.StatusForbidden, CodeUserDisabled},
+	{domain.ErrInvalidResetToken, http.StatusBadRequest, CodeInvalidResetToken},
+	{domain.ErrInvalidMFACode, http.StatusUnauthorized, CodeInvalidMFACode},
+	{domain.ErrMFAEnabled, http.StatusConflict, CodeMFAEnabled},
+	{domain.ErrMFARequired, http.StatusConflict, CodeMFARequired},
+	{domain.ErrVersionConflict, http.StatusPreconditionFailed, CodeVersionConflict},
+	{errPreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed},
+	{errPreconditionRequired, http.StatusPreconditionRequired, CodePreconditionRequired},
+	{errUnsupportedMediaType, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType},
+	{domain.ErrTooLarge, http.StatusRequestEntityTooLarge, CodePayloadTooLarge},
+	{domain.ErrTooManyAttempts, http.StatusTooManyRequests, CodeTooManyAttempts},
+}
+
+// NewProblem builds the problem document for err. Unknown errors become a 500
+// whose detail is kept generic so internals do not leak to clients.
+func NewProblem(err error) dto.Problem {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return dto.Problem{
+				Type:   "about:blank",
+				Title:  http.StatusText(m.status),
+				Status: m.status,
+				Detail: err.Error(),
+				Code:   m.code,
+			}
+		}
+	}
+	return dto.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: "An unexpected error occurred",
+		Code:   CodeInternal,
+	}
+}
+
+// respondError writes err as an application/problem+json response.
+func respondError(c *gin.Context, err error) {
+	problem := NewProblem(err)
+	if problem.Status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+	problem.Instance = c.Request.URL.Path
+	var throttled *domain.LoginThrottledError
+	if errors.As(err, &throttled) {
+		// Whole seconds, rounded up so clients never retry too early
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+	c.Header("Content-Type", dto.ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// invalidInput marks a request binding or parsing error as a bad request.
+func invalidInput(err error) error {
+	return fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+}
+
+// ErrorHandler renders errors that middleware attached with c.Error and
+// aborted on without writing a response, so they share the controllers'
+// problem format.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		respondError(c, c.Errors.Last().Err)
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"task-manager/Delivery/dto"
+	domain "task-manager/Domain"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProblem(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{domain.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+		{domain.ErrInvalidStatus, http.StatusBadRequest, CodeInvalidStatus},
+		{domain.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+		{domain.ErrInvalidCredentials, http.StatusUnauthorized, CodeInvalidCredentials},
+		{domain.ErrForbidden, http.StatusForbidden, CodeForbidden},
+		{domain.ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{domain.ErrDuplicateEntry, http.StatusConflict, CodeDuplicateEntry},
+		{domain.ErrInvalidTransition, http.StatusConflict, CodeInvalidTransition},
+		{domain.ErrOpenSubtasks, http.StatusConflict, CodeOpenSubtasks},
+		{domain.ErrHasSubtasks, http.StatusConflict, CodeHasSubtasks},
+		{domain.ErrLastOwner, http.StatusConflict, CodeLastOwner},
+		{domain.ErrProjectNotEmpty, http.StatusConflict, CodeProjectNotEmpty},
+		{domain.ErrBuiltinRole, http.StatusConflict, CodeBuiltinRole},
+		{domain.ErrRoleInUse, http.StatusConflict, CodeRoleInUse},
+		{domain.ErrLastAdmin, http.StatusConflict, CodeLastAdmin},
+		{domain.ErrUserDisabled, http.StatusForbidden, CodeUserDisabled},
+		{domain.ErrInvalidResetToken, http.StatusBadRequest, CodeInvalidResetToken},
+		{domain.ErrInvalidMFACode, http.StatusUnauthorized, CodeInvalidMFACode},
+		{domain.ErrMFAEnabled, http.StatusConflict, CodeMFAEnabled},
+		{domain.ErrMFARequired, http.StatusConflict, CodeMFARequired},
+		{&domain.LoginThrottledError{RetryAfter: time.Second}, http.StatusTooManyRequests, CodeTooManyAttempts},
+		{domain.ErrVersionConflict, http.StatusPreconditionFailed, CodeVersionConflict},
+		{errPreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed},
+		{errPreconditionRequired, http.StatusPreconditionRequired, CodePreconditionRequired},
+		{errUnsupportedMediaType, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType},
+		{domain.ErrTooLarge, http.StatusRequestEntityTooLarge, CodePayloadTooLarge},
+		{fmt.Errorf("deleting task: %w", domain.ErrNotFound), http.StatusNotFound, CodeNotFound},
+		{errors.New("connection reset"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			problem := NewProblem(tc.err)
+			assert.Equal(t, tc.status, problem.Status)
+			assert.Equal(t, tc.code, problem.Code)
+			assert.Equal(t, http.StatusText(tc.status), problem.Title)
+			assert.Equal(t, "about:blank", problem.Type)
+		})
+	}
+}
+
+func TestNewProblem_HidesInternalDetails(t *testing.T) {
+	problem := NewProblem(errors.New("dial tcp 10.0.0.1:27017: connection refused"))
+	assert.NotContains(t, problem.Detail, "10.0.0.1")
+}
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/denied", func(c *gin.Context) {
+		c.Status(http.StatusForbidden)
+		_ = c.Error(fmt.Errorf("%w: admin access required", domain.ErrForbidden))
+		c.Abort()
+	})
+	r.GET("/ok", func(c *gin.Context) {
+		_ = c.Error(errors.New("logged only"))
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+
+	t.Run("renders aborted errors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/denied", nil))
+
+		var problem dto.Problem
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, dto.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, CodeForbidden, problem.Code)
+		assert.Equal(t, "forbidden: admin access required", problem.Detail)
+		assert.Equal(t, "/denied", problem.Instance)
+	})
+
+	t.Run("leaves written responses alone", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message":"ok"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type AttachmentResponse struct {
+	ID          string    `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AttachmentListResponse struct {
+	Items []AttachmentResponse `json:"items"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// AuditListQuery holds the query string parameters accepted by the audit endpoints.
+type AuditListQuery struct {
+	ActorID    string    `form:"actor_id"`
+	Action     string    `form:"action"`
+	EntityType string    `form:"entity_type"`
+	EntityID   string    `form:"entity_id"`
+	Since      time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until      time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor     string    `form:"cursor"`
+	Limit      int       `form:"limit" binding:"omitempty,min=1"`
+}
+
+type FieldChangeResponse struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type AuditEntryResponse struct {
+	ID         string                `json:"id"`
+	ActorID    string                `json:"actor_id"`
+	Action     string                `json:"action"`
+	EntityType string                `json:"entity_type"`
+	EntityID   string                `json:"entity_id"`
+	Changes    []FieldChangeResponse `json:"changes"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+type AuditPageResponse struct {
+	Items      []AuditEntryResponse `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type CommentListQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+type CreateCommentRequest struct {
+	Body     string `json:"body" binding:"required"`
+	ParentID string `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentResponse is a comment with the replies that answer it nested below.
+type CommentResponse struct {
+	ID        string            `json:"id"`
+	TaskID    string            `json:"task_id"`
+	ParentID  string            `json:"parent_id,omitempty"`
+	AuthorID  string            `json:"author_id"`
+	Body      string            `json:"body"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Replies   []CommentResponse `json:"replies"`
+}
+
+type CommentPageResponse struct {
+	Items      []CommentResponse `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type CreateLabelRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color" binding:"required"`
+}
+
+type UpdateLabelRequest struct {
+	Color string `json:"color" binding:"required"`
+}
+
+type LabelResponse struct {
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LabelListResponse struct {
+	Items []LabelResponse `json:"items"`
+}
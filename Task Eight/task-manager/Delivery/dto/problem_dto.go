@@ -0,0 +1,16 @@
+package dto
+
+// ProblemContentType is the media type of Problem responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response. Code is a stable,
+// machine-readable identifier clients can switch on; Title and Detail are
+// meant for humans and may change.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
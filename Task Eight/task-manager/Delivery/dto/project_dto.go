@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type CreateProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// UpdateProjectRequest changes only the fields that are present.
+type UpdateProjectRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type SetProjectMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type ProjectMemberResponse struct {
+	UserID  string    `json:"user_id"`
+	Role    string    `json:"role"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type ProjectResponse struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	CreatedBy   string                  `json:"created_by"`
+	Members     []ProjectMemberResponse `json:"members"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+type ProjectListResponse struct {
+	Items []ProjectResponse `json:"items"`
+}
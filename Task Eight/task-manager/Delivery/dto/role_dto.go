@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	RequireMFA  bool     `json:"require_mfa"`
+}
+
+// UpdateRoleRequest changes the fields that are present. An empty permissions
+// list revokes every permission.
+type UpdateRoleRequest struct {
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+	RequireMFA  *bool     `json:"require_mfa"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type RoleResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	RequireMFA  bool      `json:"require_mfa"`
+	Builtin     bool      `json:"builtin"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type RoleListResponse struct {
+	Items []RoleResponse `json:"items"`
+}
+
+type PermissionListResponse struct {
+	Items []string `json:"items"`
+}
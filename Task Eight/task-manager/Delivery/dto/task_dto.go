@@ -7,6 +7,10 @@ type CreateTaskRequest struct {
 	Description string    `json:"description"`
 	DueDate     time.Time `json:"due_date"`
 	Status      string    `json:"status"`
+	AssigneeID  string    `json:"assignee_id"`
+	ParentID    string    `json:"parent_id"`
+	// Recurrence is an RRULE such as "FREQ=WEEKLY;BYDAY=MO"
+	Recurrence string `json:"recurrence"`
 }
 
 type UpdateTaskRequest struct {
@@ -16,12 +20,130 @@ type UpdateTaskRequest struct {
 	Status      string    `json:"status"`
 }
 
+// UpdateSeriesRequest changes only the fields that are present. An empty
+// recurrence ends the series.
+type UpdateSeriesRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+	Recurrence  *string    `json:"recurrence"`
+}
+
 type TaskResponse struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	DueDate     time.Time `json:"due_date"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string                  `json:"id"`
+	ProjectID   string                  `json:"project_id"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	DueDate     time.Time               `json:"due_date"`
+	Status      string                  `json:"status"`
+	CreatedBy   string                  `json:"created_by"`
+	AssigneeID  string                  `json:"assignee_id,omitempty"`
+	ParentID    string                  `json:"parent_id,omitempty"`
+	Checklist   []ChecklistItemResponse `json:"checklist"`
+	Labels      []string                `json:"labels"`
+	Attachments []AttachmentResponse    `json:"attachments"`
+	Recurrence  *RecurrenceResponse     `json:"recurrence,omitempty"`
+	Progress    *TaskProgressResponse   `json:"progress,omitempty"`
+	Version     int64                   `json:"version"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// RecurrenceResponse describes the series a recurring task belongs to.
+type RecurrenceResponse struct {
+	Rule        string    `json:"rule"`
+	SeriesID    string    `json:"series_id"`
+	Occurrence  int       `json:"occurrence"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+type ChecklistItemResponse struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+type TaskProgressResponse struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+	Percent   int `json:"percent"`
+}
+
+type TaskListResponse struct {
+	Items []TaskResponse `json:"items"`
+}
+
+// TaskListQuery holds the query string parameters accepted by GET /tasks.
+type TaskListQuery struct {
+	Status    string    `form:"status"`
+	DueAfter  time.Time `form:"due_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	DueBefore time.Time `form:"due_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Q         string    `form:"q"`
+	Sort      string    `form:"sort"`
+	Order     string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor    string    `form:"cursor"`
+	Limit     int       `form:"limit" binding:"omitempty,min=1"`
+	// Comma-separated label names
+	LabelsAny string `form:"labels_any"`
+	LabelsAll string `form:"labels_all"`
+}
+
+type TaskPageResponse struct {
+	Items      []TaskResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Total      int64          `json:"total"`
+}
+
+// TaskSearchQuery holds the query string parameters accepted by
+// GET /tasks/search.
+type TaskSearchQuery struct {
+	Q      string `form:"q" binding:"required"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+// TaskSearchHitResponse is one search result. The highlights are HTML with
+// the matching words wrapped in <mark>.
+type TaskSearchHitResponse struct {
+	Task           TaskResponse `json:"task"`
+	Score          float64      `json:"score"`
+	TitleHighlight string       `json:"title_highlight"`
+	Snippet        string       `json:"snippet"`
+}
+
+type TaskSearchPageResponse struct {
+	Items      []TaskSearchHitResponse `json:"items"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+type TransitionTaskRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type AssignTaskRequest struct {
+	AssigneeID string `json:"assignee_id" binding:"required"`
+}
+
+type AddChecklistItemRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// UpdateChecklistItemRequest changes only the fields that are present.
+type UpdateChecklistItemRequest struct {
+	Text *string `json:"text"`
+	Done *bool   `json:"done"`
+}
+
+type ReorderChecklistRequest struct {
+	ItemIDs []string `json:"item_ids" binding:"required"`
+}
+
+// TaskStreamEvent is sent on the task stream. ID is the value to resume
+// from; Task is absent on stream.reset.
+type TaskStreamEvent struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	ActorID    string        `json:"actor_id,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Task       *TaskResponse `json:"task,omitempty"`
 }
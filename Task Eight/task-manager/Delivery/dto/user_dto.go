@@ -1,8 +1,12 @@
 package dto
 
+import "time"
+
 type RegisterUserRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// Email is optional and receives notifications such as due-date reminders
+	Email string `json:"email" binding:"omitempty,email"`
 }
 
 type LoginRequest struct {
@@ -10,6 +14,20 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type PasswordResetRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
 type PromoteUserRequest struct {
 	Username string `json:"username" binding:"required"`
 }
@@ -18,8 +36,66 @@ type UserResponse struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Email    string `json:"email,omitempty"`
+	Disabled bool   `json:"disabled"`
 }
 
+// UserListQuery holds the query string parameters accepted by GET /admin/users.
+type UserListQuery struct {
+	Role     string `form:"role"`
+	Disabled *bool  `form:"disabled"`
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+type UserPageResponse struct {
+	Items      []UserResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// LoginResponse carries either the tokens or, for accounts with two-factor
+// authentication, the pending token to complete the login with.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token                 string     `json:"token,omitempty"`
+	RefreshToken          string     `json:"refresh_token,omitempty"`
+	MFAPending            string     `json:"mfa_pending,omitempty"`
+	MFAExpiresAt          *time.Time `json:"mfa_expires_at,omitempty"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty"`
+	// RecoveryCodes are only returned when the login confirmed an enrolment
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// MFALoginRequest completes a pending login with a TOTP or recovery code.
+type MFALoginRequest struct {
+	MFAPending string `json:"mfa_pending" binding:"required"`
+	Code       string `json:"code" binding:"required"`
+}
+
+type MFALoginEnrollmentRequest struct {
+	MFAPending string `json:"mfa_pending" binding:"required"`
+}
+
+type MFASetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type ConfirmMFARequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
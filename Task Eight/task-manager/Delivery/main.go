@@ -2,73 +2,222 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"task-manager/Delivery/controllers"
 	"task-manager/Delivery/routers"
+	domain "task-manager/Domain"
 	infrastructure "task-manager/Infrastructure"
-	repositories "task-manager/Repositories"
 	usecases "task-manager/Usecases"
 	"task-manager/config"
-
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
-	// Initialize database connection
-	client, err := connectToDatabase(cfg.Database.URI)
+	// Initialize infrastructure services
+	passwordService := infrastructure.NewPasswordService()
+
+	// Initialize repositories for the configured storage driver
+	repos, closeStorage, err := openRepositories(cfg, passwordService)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		log.Fatalf("Failed to initialize storage: %v", err)
 	}
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			log.Printf("Failed to disconnect from MongoDB: %v", err)
-		}
-	}()
+	defer closeStorage()
 
-	database := client.Database(cfg.Database.Database)
+	authService := infrastructure.NewAuthService(cfg.JWT.Secret, cfg.JWT.AccessTokenTTL, repos.users, repos.tokens, repos.roles)
 
-	// Initialize infrastructure services
-	passwordService := infrastructure.NewPasswordService()
-	authService := infrastructure.NewAuthService()
+	blobStore, err := infrastructure.NewLocalBlobStore(cfg.Attachments.Dir)
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
 
-	// Initialize repositories
-	taskRepo := repositories.NewTaskRepository(database.Collection("tasks"))
-	userRepo := repositories.NewUserRepository(database.Collection("users"), passwordService)
+	transitions := domain.DefaultStatusTransitions()
+	if cfg.Tasks.StatusTransitions != "" {
+		transitions, err = domain.ParseStatusTransitions(cfg.Tasks.StatusTransitions)
+		if err != nil {
+			log.Fatalf("Invalid TASK_STATUS_TRANSITIONS: %v", err)
+		}
+	}
+
+	if cfg.Webhooks.MaxAttempts < 1 {
+		log.Fatalf("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	retryPolicy := domain.RetryPolicy{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		Backoff:     cfg.Webhooks.Backoff,
+		MaxBackoff:  cfg.Webhooks.MaxBackoff,
+	}
 
 	// Initialize use cases
-	taskUseCase := usecases.NewTaskUseCase(taskRepo)
-	userUseCase := usecases.NewUserUseCase(userRepo, passwordService, authService)
+	webhookUseCase := usecases.NewWebhookUseCase(repos.webhooks, repos.deliveries, infrastructure.NewHTTPWebhookSender(cfg.Webhooks.Timeout), retryPolicy)
+	taskStreamUseCase := usecases.NewTaskStreamUseCase(repos.projects)
+	events := usecases.EventFanout{webhookUseCase, taskStreamUseCase}
+	taskUseCase := usecases.NewTaskUseCase(repos.tasks, repos.search, repos.projects, repos.labels, repos.comments, blobStore, repos.audit, events, transitions)
+	labelUseCase := usecases.NewLabelUseCase(repos.labels, repos.tasks, repos.audit)
+	commentUseCase := usecases.NewCommentUseCase(repos.comments, repos.tasks, repos.projects, repos.audit)
+	attachmentUseCase := usecases.NewAttachmentUseCase(repos.tasks, repos.projects, blobStore, repos.audit, events, cfg.Attachments.MaxSize)
+	mailer, err := newMailer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	loginThrottle := newLoginThrottle(cfg, repos)
+	totpService := infrastructure.NewTOTPService(cfg.MFA.Issuer)
+	userUseCase := usecases.NewUserUseCase(repos.users, repos.roles, repos.tokens, repos.mfa, repos.projects, repos.audit, events, passwordService, authService, totpService, loginThrottle, mailer, cfg.JWT.RefreshTokenTTL, cfg.MFA.ChallengeTTL, cfg.Passwords.ResetTTL, cfg.Passwords.ResetURL)
+	auditUseCase := usecases.NewAuditUseCase(repos.audit, repos.tasks)
+	projectUseCase := usecases.NewProjectUseCase(repos.projects, repos.tasks, repos.users, repos.audit, events)
+	roleUseCase := usecases.NewRoleUseCase(repos.roles, repos.users, repos.audit, authService, events)
+
+	// The built-in roles must exist before anyone can be granted them
+	if err := roleUseCase.EnsureBuiltinRoles(context.Background()); err != nil {
+		log.Fatalf("Failed to create built-in roles: %v", err)
+	}
+
+	// Seed the admin account if one is configured
+	if cfg.Admin.Username != "" {
+		seedAdmin(userUseCase, cfg.Admin)
+	}
 
 	// Initialize controllers
+	projectController := controllers.NewProjectController(projectUseCase)
 	taskController := controllers.NewTaskController(taskUseCase)
 	userController := controllers.NewUserController(userUseCase)
+	auditController := controllers.NewAuditController(auditUseCase)
+	labelController := controllers.NewLabelController(labelUseCase)
+	commentController := controllers.NewCommentController(commentUseCase)
+	attachmentController := controllers.NewAttachmentController(attachmentUseCase, cfg.Attachments.MaxSize)
+	webhookController := controllers.NewWebhookController(webhookUseCase)
+	taskStreamController := controllers.NewTaskStreamController(taskStreamUseCase)
+	roleController := controllers.NewRoleController(roleUseCase)
 
 	// Setup router with middleware
-	r := routers.SetupRouter(taskController, userController, authService)
+	r := routers.SetupRouter(projectController, taskController, userController, auditController, labelController, commentController, attachmentController, webhookController, taskStreamController, roleController, authService, projectUseCase)
+	// Client IPs feed the login throttle, so only believe known proxies
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// Stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start background jobs
+	var jobs sync.WaitGroup
+	dispatcher := infrastructure.NewWebhookDispatcher(webhookUseCase, cfg.Webhooks.Interval)
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		dispatcher.Run(ctx)
+	}()
+	if cfg.Reminders.Enabled {
+		scheduler := newReminderScheduler(cfg, repos)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			scheduler.Run(ctx)
+		}()
+	}
 
 	// Start server
 	serverAddr := cfg.Server.Host + ":" + cfg.Server.Port
-	log.Printf("Starting server on %s", serverAddr)
-	if err := r.Run(serverAddr); err != nil {
-		log.Fatal("Failed to run server: ", err)
+	server := &http.Server{Addr: serverAddr, Handler: r}
+	// Open task streams never go idle, so end them when shutting down
+	server.RegisterOnShutdown(taskStreamUseCase.Close)
+	go func() {
+		log.Printf("Starting server on %s", serverAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to run server: ", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down server: %v", err)
 	}
+	jobs.Wait()
 }
 
-func connectToDatabase(uri string) (*mongo.Client, error) {
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
-	if err != nil {
-		return nil, err
+// newReminderScheduler builds the reminder scheduler with the configured
+// notification channels.
+func newReminderScheduler(cfg *config.Config, repos *repositorySet) *infrastructure.ReminderScheduler {
+	var notifiers []domain.INotifier
+	for _, channel := range cfg.Reminders.Channels {
+		switch channel {
+		case "log":
+			notifiers = append(notifiers, infrastructure.NewLogNotifier(nil))
+		case "smtp":
+			smtp := cfg.SMTP
+			notifiers = append(notifiers, infrastructure.NewSMTPNotifier(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From))
+		default:
+			log.Fatalf("Unknown reminder channel %q", channel)
+		}
 	}
 
-	// Test the connection
-	if err := client.Ping(context.Background(), nil); err != nil {
-		return nil, err
+	// A window shorter than the interval would let reminders slip between runs
+	window := max(cfg.Reminders.Window, 2*cfg.Reminders.Interval)
+	reminderUseCase := usecases.NewReminderUseCase(repos.tasks, repos.users, repos.reminders, notifiers, cfg.Reminders.Offsets, window)
+	return infrastructure.NewReminderScheduler(reminderUseCase, cfg.Reminders.Interval)
+}
+
+// newLoginThrottle builds the login throttle from the configured policy.
+func newLoginThrottle(cfg *config.Config, repos *repositorySet) domain.ILoginThrottle {
+	login := cfg.Login
+	userPolicy := domain.LoginThrottlePolicy{
+		FreeFailures:    login.FreeFailures,
+		Backoff:         login.Backoff,
+		MaxBackoff:      login.MaxBackoff,
+		LockoutAfter:    login.LockoutAfter,
+		LockoutDuration: login.LockoutDuration,
+		// Failures forgotten before a block ends would lift it early
+		Window: max(login.Window, login.LockoutDuration, login.MaxBackoff),
 	}
+	ipPolicy := userPolicy
+	ipPolicy.FreeFailures = login.IPFreeFailures
+	ipPolicy.LockoutAfter = login.IPLockoutAfter
+	return usecases.NewLoginThrottle(repos.loginAttempts, userPolicy, ipPolicy)
+}
 
-	log.Println("Successfully connected to MongoDB")
-	return client, nil
+// newMailer builds the mailer for the configured driver.
+func newMailer(cfg *config.Config) (domain.IMailer, error) {
+	switch cfg.Mail.Driver {
+	case "log":
+		return infrastructure.NewLogMailer(nil), nil
+	case "file":
+		return infrastructure.NewFileMailer(cfg.Mail.Dir, cfg.SMTP.From)
+	case "smtp":
+		smtp := cfg.SMTP
+		return infrastructure.NewSMTPMailer(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+	}
+}
+
+func seedAdmin(userUseCase domain.IUserUseCase, admin config.AdminConfig) {
+	_, err := userUseCase.Register(context.Background(), domain.User{
+		Username: admin.Username,
+		Password: admin.Password,
+		Role:     domain.RoleAdmin,
+	})
+	switch {
+	case err == nil:
+		log.Printf("Created admin user %q", admin.Username)
+	case errors.Is(err, domain.ErrDuplicateEntry):
+		// Already seeded on a previous run
+	default:
+		log.Fatalf("Failed to create admin user: %v", err)
+	}
 }
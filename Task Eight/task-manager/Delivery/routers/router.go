@@ -8,33 +8,117 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(taskController *controllers.TaskController, userController *controllers.UserController, authService domain.IAuthService) *gin.Engine {
+func SetupRouter(projectController *controllers.ProjectController, taskController *controllers.TaskController, userController *controllers.UserController, auditController *controllers.AuditController, labelController *controllers.LabelController, commentController *controllers.CommentController, attachmentController *controllers.AttachmentController, webhookController *controllers.WebhookController, taskStreamController *controllers.TaskStreamController, roleController *controllers.RoleController, authService domain.IAuthService, projectUseCase domain.IProjectUseCase) *gin.Engine {
 	r := gin.Default()
+	r.Use(controllers.ErrorHandler())
 
 	r.POST("/register", userController.Register)
 	r.POST("/login", userController.Login)
+	r.POST("/login/mfa", userController.CompleteMFALogin)
+	r.POST("/login/mfa/enroll", userController.StartMFALoginEnrollment)
+	r.POST("/refresh", userController.Refresh)
+	r.POST("/logout", infrastructure.AuthMiddleware(authService), userController.Logout)
+	r.POST("/password-reset", userController.RequestPasswordReset)
+	r.POST("/password-reset/confirm", userController.ConfirmPasswordReset)
+	r.POST("/me/password", infrastructure.AuthMiddleware(authService), userController.ChangePassword)
+	r.POST("/me/mfa", infrastructure.AuthMiddleware(authService), userController.StartMFAEnrollment)
+	r.POST("/me/mfa/confirm", infrastructure.AuthMiddleware(authService), userController.ConfirmMFAEnrollment)
+	r.POST("/me/mfa/disable", infrastructure.AuthMiddleware(authService), userController.DisableMFA)
 
-	taskRoutes := r.Group("/tasks")
-	taskRoutes.Use(infrastructure.AuthMiddleware(authService))
+	r.GET("/labels", infrastructure.AuthMiddleware(authService), labelController.ListLabels)
+
+	r.GET("/projects", infrastructure.AuthMiddleware(authService), projectController.ListProjects)
+	r.POST("/projects", infrastructure.AuthMiddleware(authService), projectController.CreateProject)
+
+	// Project routes, each requiring a minimum role in the project
+	projectRoutes := r.Group("/projects/:pid")
+	projectRoutes.Use(infrastructure.AuthMiddleware(authService))
+	viewer := infrastructure.RequireProjectRole(projectUseCase, domain.ProjectViewer)
+	editor := infrastructure.RequireProjectRole(projectUseCase, domain.ProjectEditor)
+	owner := infrastructure.RequireProjectRole(projectUseCase, domain.ProjectOwner)
+	{
+		projectRoutes.GET("", viewer, projectController.GetProject)
+		projectRoutes.PATCH("", owner, projectController.UpdateProject)
+		projectRoutes.DELETE("", owner, projectController.DeleteProject)
+		projectRoutes.PUT("/members/:userId", owner, projectController.SetMember)
+		projectRoutes.DELETE("/members/:userId", owner, projectController.RemoveMember)
+	}
+
+	taskRoutes := projectRoutes.Group("/tasks")
 	{
-		taskRoutes.GET("/", taskController.GetAllTasks)
-		taskRoutes.GET("/:id", taskController.GetTaskByID)
+		taskRoutes.GET("/", viewer, taskController.GetAllTasks)
+		taskRoutes.GET("/stream", viewer, taskStreamController.Stream)
+		taskRoutes.GET("/search", viewer, taskController.SearchTasks)
+		taskRoutes.GET("/:id", viewer, taskController.GetTaskByID)
+		taskRoutes.GET("/:id/subtasks", viewer, taskController.GetSubtasks)
+		taskRoutes.GET("/:id/comments", viewer, commentController.ListComments)
+		taskRoutes.GET("/:id/attachments", viewer, attachmentController.ListAttachments)
+		taskRoutes.GET("/:id/attachments/:attachmentId", viewer, attachmentController.DownloadAttachment)
+		taskRoutes.GET("/:id/history", owner, auditController.GetTaskHistory)
 
-		// Admin-only task routes
-		adminTaskRoutes := taskRoutes.Group("/")
-		adminTaskRoutes.Use(infrastructure.AdminOnly())
+		// Task writes need at least the editor role, and a global role that
+		// grants the matching permission
+		editorTaskRoutes := taskRoutes.Group("/")
+		editorTaskRoutes.Use(editor)
+		create := infrastructure.RequirePermission(domain.PermTasksCreate)
+		update := infrastructure.RequirePermission(domain.PermTasksUpdate)
 		{
-			adminTaskRoutes.POST("/", taskController.CreateTask)
-			adminTaskRoutes.PUT("/:id", taskController.UpdateTask)
-			adminTaskRoutes.DELETE("/:id", taskController.DeleteTask)
+			editorTaskRoutes.POST("/", create, taskController.CreateTask)
+			editorTaskRoutes.POST("/:id/subtasks", create, taskController.CreateSubtask)
+			editorTaskRoutes.PUT("/:id", update, taskController.UpdateTask)
+			editorTaskRoutes.PATCH("/:id", update, taskController.PatchTask)
+			editorTaskRoutes.PATCH("/:id/series", update, taskController.UpdateSeries)
+			editorTaskRoutes.DELETE("/:id", infrastructure.RequirePermission(domain.PermTasksDelete), taskController.DeleteTask)
+			editorTaskRoutes.PUT("/:id/assignee", update, taskController.AssignTask)
+			editorTaskRoutes.DELETE("/:id/assignee", update, taskController.UnassignTask)
+			editorTaskRoutes.POST("/:id/transition", update, taskController.TransitionTask)
+			editorTaskRoutes.POST("/:id/checklist", update, taskController.AddChecklistItem)
+			editorTaskRoutes.PUT("/:id/checklist/order", update, taskController.ReorderChecklist)
+			editorTaskRoutes.PATCH("/:id/checklist/:itemId", update, taskController.UpdateChecklistItem)
+			editorTaskRoutes.DELETE("/:id/checklist/:itemId", update, taskController.RemoveChecklistItem)
+			editorTaskRoutes.PUT("/:id/labels/:name", update, taskController.AttachLabel)
+			editorTaskRoutes.DELETE("/:id/labels/:name", update, taskController.DetachLabel)
+			editorTaskRoutes.POST("/:id/comments", commentController.AddComment)
+			editorTaskRoutes.PATCH("/:id/comments/:commentId", commentController.UpdateComment)
+			editorTaskRoutes.DELETE("/:id/comments/:commentId", commentController.DeleteComment)
+			editorTaskRoutes.POST("/:id/attachments", attachmentController.UploadAttachment)
+			editorTaskRoutes.DELETE("/:id/attachments/:attachmentId", attachmentController.DeleteAttachment)
 		}
 	}
 
-	// Admin-only user management routes
+	// Administration routes, each requiring a permission
 	adminRoutes := r.Group("/admin")
-	adminRoutes.Use(infrastructure.AuthMiddleware(authService), infrastructure.AdminOnly())
+	adminRoutes.Use(infrastructure.AuthMiddleware(authService))
+	manageUsers := infrastructure.RequirePermission(domain.PermUsersManage)
+	manageLabels := infrastructure.RequirePermission(domain.PermLabelsManage)
+	manageWebhooks := infrastructure.RequirePermission(domain.PermWebhooksManage)
+	manageRoles := infrastructure.RequirePermission(domain.PermRolesManage)
 	{
-		adminRoutes.POST("/promote", userController.PromoteUser)
+		adminRoutes.POST("/promote", manageUsers, userController.PromoteUser)
+		adminRoutes.GET("/users", manageUsers, userController.ListUsers)
+		adminRoutes.GET("/users/:id", manageUsers, userController.GetUser)
+		adminRoutes.DELETE("/users/:id", manageUsers, userController.DeleteUser)
+		adminRoutes.PUT("/users/:id/role", manageUsers, userController.AssignRole)
+		adminRoutes.POST("/users/:id/demote", manageUsers, userController.DemoteUser)
+		adminRoutes.POST("/users/:id/disable", manageUsers, userController.DisableUser)
+		adminRoutes.POST("/users/:id/enable", manageUsers, userController.EnableUser)
+		adminRoutes.DELETE("/users/:id/mfa", manageUsers, userController.ResetMFA)
+		adminRoutes.GET("/audit", infrastructure.RequirePermission(domain.PermAuditRead), auditController.ListEntries)
+		adminRoutes.POST("/labels", manageLabels, labelController.CreateLabel)
+		adminRoutes.PATCH("/labels/:name", manageLabels, labelController.UpdateLabel)
+		adminRoutes.DELETE("/labels/:name", manageLabels, labelController.DeleteLabel)
+		adminRoutes.GET("/webhooks", manageWebhooks, webhookController.ListWebhooks)
+		adminRoutes.POST("/webhooks", manageWebhooks, webhookController.CreateWebhook)
+		adminRoutes.GET("/webhooks/:id", manageWebhooks, webhookController.GetWebhook)
+		adminRoutes.PATCH("/webhooks/:id", manageWebhooks, webhookController.UpdateWebhook)
+		adminRoutes.DELETE("/webhooks/:id", manageWebhooks, webhookController.DeleteWebhook)
+		adminRoutes.GET("/webhooks/:id/deliveries", manageWebhooks, webhookController.ListDeliveries)
+		adminRoutes.POST("/webhooks/:id/deliveries/:deliveryId/replay", manageWebhooks, webhookController.ReplayDelivery)
+		adminRoutes.GET("/permissions", manageRoles, roleController.ListPermissions)
+		adminRoutes.GET("/roles", manageRoles, roleController.ListRoles)
+		adminRoutes.POST("/roles", manageRoles, roleController.CreateRole)
+		adminRoutes.PATCH("/roles/:name", manageRoles, roleController.UpdateRole)
+		adminRoutes.DELETE("/roles/:name", manageRoles, roleController.DeleteRole)
 	}
 
 	return r
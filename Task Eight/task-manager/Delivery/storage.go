@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	domain "task-manager/Domain"
+	repositories "task-manager/Repositories"
+	"task-manager/config"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// repositorySet groups the repositories the use cases are wired with.
+type repositorySet struct {
+	tasks    domain.ITaskRepository
+	search   domain.ITaskSearcher
+	projects domain.IProjectRepository
+	users    domain.IUserRepository
+	roles    domain.IRoleRepository
+	tokens   domain.ITokenRepository
+	// mfa holds the two-factor enrolments.
+	mfa domain.IMFARepository
+	// loginAttempts counts failed logins for the login throttle.
+	loginAttempts domain.ILoginAttemptRepository
+	labels        domain.ILabelRepository
+	comments      domain.ICommentRepository
+	reminders     domain.IReminderRepository
+	webhooks      domain.IWebhookRepository
+	// deliveries holds the webhook delivery logs.
+	deliveries domain.IWebhookDeliveryRepository
+	audit      domain.IAuditRepository
+}
+
+// openRepositories builds the repositories for the configured storage driver.
+// The returned function releases the underlying connection, if any.
+func openRepositories(cfg *config.Config, passwordService domain.IPasswordService) (*repositorySet, func(), error) {
+	switch cfg.Storage.Driver {
+	case config.StorageMongo:
+		return openMongoRepositories(cfg, passwordService)
+	case config.StorageMemory:
+		log.Println("Using in-memory storage, data will be lost on restart")
+		tasks, err := repositories.NewTaskSearchIndex(context.Background(), repositories.NewMemoryTaskRepository())
+		if err != nil {
+			return nil, nil, err
+		}
+		repos := &repositorySet{
+			tasks:         tasks,
+			search:        tasks,
+			projects:      repositories.NewMemoryProjectRepository(),
+			users:         repositories.NewMemoryUserRepository(),
+			roles:         repositories.NewMemoryRoleRepository(),
+			tokens:        repositories.NewMemoryTokenRepository(),
+			mfa:           repositories.NewMemoryMFARepository(),
+			loginAttempts: repositories.NewMemoryLoginAttemptRepository(),
+			labels:        repositories.NewMemoryLabelRepository(),
+			comments:      repositories.NewMemoryCommentRepository(),
+			reminders:     repositories.NewMemoryReminderRepository(),
+			webhooks:      repositories.NewMemoryWebhookRepository(),
+			deliveries:    repositories.NewMemoryWebhookDeliveryRepository(),
+			audit:         repositories.NewMemoryAuditRepository(),
+		}
+		return repos, func() {}, nil
+	case config.StorageSQLite:
+		return openSQLiteRepositories(cfg)
+	default:
+		return nil, nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+func openMongoRepositories(cfg *config.Config, passwordService domain.IPasswordService) (*repositorySet, func(), error) {
+	client, err := connectToDatabase(cfg.Database.URI)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	closeFn := func() {
+		if err := client.Disconnect(context.Background()); err != nil {
+			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}
+
+	database := client.Database(cfg.Database.Database)
+	timeouts := repositoryTimeouts(cfg)
+
+	tokenRepo := repositories.NewTokenRepository(database.Collection("refresh_tokens"), database.Collection("revoked_tokens"), database.Collection("password_reset_tokens"), database.Collection("mfa_challenges"), timeouts)
+	if err := tokenRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create token indexes: %w", err)
+	}
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(database.Collection("login_failures"), timeouts)
+	if err := loginAttemptRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create login failure indexes: %w", err)
+	}
+	auditRepo := repositories.NewAuditRepository(database.Collection("audit_log"), timeouts)
+	if err := auditRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create audit indexes: %w", err)
+	}
+
+	taskRepo := repositories.NewTaskRepository(database.Collection("tasks"), timeouts)
+	if err := taskRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create task indexes: %w", err)
+	}
+	projectRepo := repositories.NewProjectRepository(database.Collection("projects"), timeouts)
+	if err := projectRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create project indexes: %w", err)
+	}
+	if err := taskRepo.AdoptUnscopedTasks(context.Background(), projectRepo); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to move existing tasks into a project: %w", err)
+	}
+	commentRepo := repositories.NewCommentRepository(database.Collection("comments"), timeouts)
+	if err := commentRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create comment indexes: %w", err)
+	}
+	reminderRepo := repositories.NewReminderRepository(database.Collection("reminder_deliveries"), timeouts)
+	if err := reminderRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create reminder indexes: %w", err)
+	}
+	deliveryRepo := repositories.NewWebhookDeliveryRepository(database.Collection("webhook_deliveries"), timeouts)
+	if err := deliveryRepo.EnsureIndexes(context.Background()); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to create webhook delivery indexes: %w", err)
+	}
+
+	repos := &repositorySet{
+		tasks:         taskRepo,
+		search:        taskRepo,
+		projects:      projectRepo,
+		users:         repositories.NewUserRepository(database.Collection("users"), passwordService, timeouts),
+		roles:         repositories.NewRoleRepository(database.Collection("roles"), timeouts),
+		tokens:        tokenRepo,
+		mfa:           repositories.NewMFARepository(database.Collection("mfa_enrollments"), timeouts),
+		loginAttempts: loginAttemptRepo,
+		labels:        repositories.NewLabelRepository(database.Collection("labels"), timeouts),
+		comments:      commentRepo,
+		reminders:     reminderRepo,
+		webhooks:      repositories.NewWebhookRepository(database.Collection("webhooks"), timeouts),
+		deliveries:    deliveryRepo,
+		audit:         auditRepo,
+	}
+	return repos, closeFn, nil
+}
+
+func openSQLiteRepositories(cfg *config.Config) (*repositorySet, func(), error) {
+	db, err := repositories.OpenSQLite(cfg.Storage.SQLitePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	closeFn := func() {
+		if err := db.Close(); err != nil {
+			log.Printf("Failed to close SQLite database: %v", err)
+		}
+	}
+
+	if err := repositories.MigrateUp(context.Background(), db); err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to migrate SQLite database: %w", err)
+	}
+	log.Printf("Using SQLite database at %s", cfg.Storage.SQLitePath)
+
+	timeouts := repositoryTimeouts(cfg)
+	// SQLite has no text index of its own, so search runs in process
+	tasks, err := repositories.NewTaskSearchIndex(context.Background(), repositories.NewSQLTaskRepository(db, timeouts))
+	if err != nil {
+		closeFn()
+		return nil, nil, fmt.Errorf("failed to build task search index: %w", err)
+	}
+	repos := &repositorySet{
+		tasks:         tasks,
+		search:        tasks,
+		projects:      repositories.NewSQLProjectRepository(db, timeouts),
+		users:         repositories.NewSQLUserRepository(db, timeouts),
+		roles:         repositories.NewSQLRoleRepository(db, timeouts),
+		tokens:        repositories.NewSQLTokenRepository(db, timeouts),
+		mfa:           repositories.NewSQLMFARepository(db, timeouts),
+		loginAttempts: repositories.NewSQLLoginAttemptRepository(db, timeouts),
+		labels:        repositories.NewSQLLabelRepository(db, timeouts),
+		comments:      repositories.NewSQLCommentRepository(db, timeouts),
+		reminders:     repositories.NewSQLReminderRepository(db, timeouts),
+		webhooks:      repositories.NewSQLWebhookRepository(db, timeouts),
+		deliveries:    repositories.NewSQLWebhookDeliveryRepository(db, timeouts),
+		audit:         repositories.NewSQLAuditRepository(db, timeouts),
+	}
+	return repos, closeFn, nil
+}
+
+func repositoryTimeouts(cfg *config.Config) repositories.Timeouts {
+	return repositories.Timeouts{
+		Read:  cfg.Database.ReadTimeout,
+		Write: cfg.Database.WriteTimeout,
+		Query: cfg.Database.QueryTimeout,
+	}
+}
+
+func connectToDatabase(uri string) (*mongo.Client, error) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	// Test the connection
+	if err := client.Ping(context.Background(), nil); err != nil {
+		return nil, err
+	}
+
+	log.Println("Successfully connected to MongoDB")
+	return client, nil
+}
@@ -1,10 +1,22 @@
 package domain
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Role names a set of permissions stored in the database. The built-in
+// roles always exist; admins may define more.
 type Role string
 
 const (
@@ -20,16 +32,204 @@ var (
 	ErrForbidden          = errors.New("forbidden")
 	ErrDuplicateEntry     = errors.New("duplicate entry")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidStatus      = errors.New("invalid task status")
+	ErrInvalidTransition  = errors.New("status transition not allowed")
+	ErrVersionConflict    = errors.New("task was modified by someone else")
+	ErrOpenSubtasks       = errors.New("task has open subtasks")
+	ErrHasSubtasks        = errors.New("task has subtasks")
+	ErrTooLarge           = errors.New("attachment exceeds the size limit")
+	ErrLastOwner          = errors.New("project must keep at least one owner")
+	ErrProjectNotEmpty    = errors.New("project still has tasks")
+	ErrBuiltinRole        = errors.New("built-in role cannot be changed")
+	ErrRoleInUse          = errors.New("role is still assigned to users")
+	ErrLastAdmin          = errors.New("the last active admin cannot be removed")
+	ErrUserDisabled       = errors.New("user is disabled")
+	ErrInvalidResetToken  = errors.New("reset token is invalid or expired")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts")
+	ErrInvalidMFACode     = errors.New("invalid two-factor code")
+	ErrMFAEnabled         = errors.New("two-factor authentication is already enabled")
+	ErrMFARequired        = errors.New("role requires two-factor authentication")
 )
 
+// LoginThrottledError is returned while logins are blocked after too many
+// failures. It matches ErrTooManyAttempts with errors.Is.
+type LoginThrottledError struct {
+	// RetryAfter is how long until the next attempt is allowed.
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// TaskStatus is the lifecycle state of a task.
+type TaskStatus string
+
+const (
+	StatusPending    TaskStatus = "pending"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusReview     TaskStatus = "review"
+	StatusDone       TaskStatus = "done"
+)
+
+// TaskStatuses lists every valid status in lifecycle order.
+var TaskStatuses = []TaskStatus{StatusPending, StatusInProgress, StatusReview, StatusDone}
+
+func (s TaskStatus) IsValid() bool {
+	for _, status := range TaskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransitions maps each status to the statuses a task may move to next.
+type StatusTransitions map[TaskStatus][]TaskStatus
+
+// DefaultStatusTransitions is the workflow used unless one is configured:
+// pending -> in_progress -> review -> done, where work can be sent back a
+// step and a done task can be reopened.
+func DefaultStatusTransitions() StatusTransitions {
+	return StatusTransitions{
+		StatusPending:    {StatusInProgress},
+		StatusInProgress: {StatusPending, StatusReview},
+		StatusReview:     {StatusInProgress, StatusDone},
+		StatusDone:       {StatusInProgress},
+	}
+}
+
+// Allows reports whether a task may move from one status to another.
+func (g StatusTransitions) Allows(from, to TaskStatus) bool {
+	for _, next := range g[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseStatusTransitions reads a transition graph written as
+// "from:to,to;from:to", e.g. "pending:in_progress;in_progress:done,pending".
+func ParseStatusTransitions(spec string) (StatusTransitions, error) {
+	g := StatusTransitions{}
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		from, targets, ok := strings.Cut(rule, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid transition rule %q", rule)
+		}
+		fromStatus := TaskStatus(strings.TrimSpace(from))
+		if !fromStatus.IsValid() {
+			return nil, fmt.Errorf("invalid transition rule %q: %w", rule, ErrInvalidStatus)
+		}
+		for _, to := range strings.Split(targets, ",") {
+			toStatus := TaskStatus(strings.TrimSpace(to))
+			if !toStatus.IsValid() {
+				return nil, fmt.Errorf("invalid transition rule %q: %w", rule, ErrInvalidStatus)
+			}
+			g[fromStatus] = append(g[fromStatus], toStatus)
+		}
+	}
+	if len(g) == 0 {
+		return nil, errors.New("empty transition graph")
+	}
+	return g, nil
+}
+
 type Task struct {
-	ID          string    `bson:"_id,omitempty" json:"id"`
-	Title       string    `bson:"title" json:"title"`
-	Description string    `bson:"description" json:"description"`
-	DueDate     time.Time `bson:"due_date" json:"due_date"`
-	Status      string    `bson:"status" json:"status"`
-	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+	ID          string     `bson:"_id,omitempty" json:"id"`
+	Title       string     `bson:"title" json:"title"`
+	Description string     `bson:"description" json:"description"`
+	DueDate     time.Time  `bson:"due_date" json:"due_date"`
+	Status      TaskStatus `bson:"status" json:"status"`
+	CreatedBy   string     `bson:"created_by" json:"created_by"`
+	AssigneeID  string     `bson:"assignee_id,omitempty" json:"assignee_id,omitempty"`
+	// ProjectID is the project holding the task, fixed once it is created.
+	ProjectID string `bson:"project_id" json:"project_id"`
+	// ParentID is set on subtasks and fixed once the task is created.
+	ParentID  string          `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Checklist []ChecklistItem `bson:"checklist,omitempty" json:"checklist,omitempty"`
+	// Labels holds the names of the attached labels.
+	Labels []string `bson:"labels,omitempty" json:"labels,omitempty"`
+	// Attachments describes the uploaded files; their content lives in the
+	// blob store.
+	Attachments []Attachment `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	// Recurrence is set on the occurrences of a recurring series.
+	Recurrence *Recurrence `bson:"recurrence,omitempty" json:"recurrence,omitempty"`
+	// Version starts at 1 and is incremented by every write, so clients can
+	// detect concurrent modifications.
+	Version   int64     `bson:"version" json:"version"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	// Progress is computed on read and never stored.
+	Progress *TaskProgress `bson:"-" json:"progress,omitempty"`
+}
+
+// MaxChecklistItems caps the size of a task's checklist.
+const MaxChecklistItems = 100
+
+// ChecklistItem is a lightweight to-do inside a task. Items are kept in
+// display order.
+type ChecklistItem struct {
+	ID   string `bson:"id" json:"id"`
+	Text string `bson:"text" json:"text"`
+	Done bool   `bson:"done" json:"done"`
+}
+
+// ChecklistItemPatch is a partial checklist item update. Nil fields are left unchanged.
+type ChecklistItemPatch struct {
+	Text *string
+	Done *bool
+}
+
+// TaskProgress summarises how much of a task is finished. Every subtask and
+// checklist item counts as one unit of work.
+type TaskProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+	Percent   int `json:"percent"`
+}
+
+// ComputeProgress derives the progress of task from its checklist and its
+// direct children. A task without either is 0% or, once done, 100%.
+func ComputeProgress(task *Task, children []Task) TaskProgress {
+	var p TaskProgress
+	for _, item := range task.Checklist {
+		p.Total++
+		if item.Done {
+			p.Completed++
+		}
+	}
+	for _, child := range children {
+		p.Total++
+		if child.Status == StatusDone {
+			p.Completed++
+		}
+	}
+
+	switch {
+	case p.Total > 0:
+		p.Percent = p.Completed * 100 / p.Total
+	case task.Status == StatusDone:
+		p.Percent = 100
+	}
+	return p
+}
+
+// TaskPatch is a partial task update. Nil fields are left unchanged.
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	DueDate     *time.Time
+	Status      *TaskStatus
 }
 
 type User struct {
@@ -37,6 +237,1146 @@ type User struct {
 	Username string `bson:"username" json:"username"`
 	Password string `bson:"password" json:"-"`
 	Role     Role   `bson:"role" json:"role"`
+	// Email is optional and used for notifications.
+	Email string `bson:"email,omitempty" json:"email,omitempty"`
+	// TokenVersion is embedded in every access token. Bumping it invalidates
+	// all access tokens issued to the user before the change.
+	TokenVersion int `bson:"token_version" json:"-"`
+	// Disabled users cannot log in, and their tokens are rejected.
+	Disabled bool `bson:"disabled" json:"disabled"`
+}
+
+const (
+	DefaultUserPageSize = 50
+	MaxUserPageSize     = 200
+)
+
+// UserQuery filters and pages the user list, which is ordered by ID, so by
+// registration time.
+type UserQuery struct {
+	Role Role
+	// Disabled keeps only disabled users when true, only active ones when
+	// false and every user when nil.
+	Disabled *bool
+	Cursor   string
+	Limit    int
+}
+
+// UserPage is one page of users.
+type UserPage struct {
+	Users      []User
+	NextCursor string
+}
+
+// Normalize applies defaults and rejects unsupported values.
+func (q *UserQuery) Normalize() error {
+	if q.Limit < 0 {
+		return ErrInvalidInput
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultUserPageSize
+	}
+	if q.Limit > MaxUserPageSize {
+		q.Limit = MaxUserPageSize
+	}
+	return nil
+}
+
+// RefreshToken is a long-lived, single-use credential that can be exchanged
+// for a new access token. Only the hash of the token is stored.
+type RefreshToken struct {
+	Hash      string    `bson:"_id" json:"-"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	// Revoked is set once the token has been rotated. Presenting a revoked
+	// token again means it was stolen, so all of the user's sessions are ended.
+	Revoked bool `bson:"revoked" json:"revoked"`
+}
+
+// PasswordResetToken lets a user who forgot their password set a new one.
+// Only the hash of the token is stored, and it can be redeemed once.
+type PasswordResetToken struct {
+	Hash      string    `bson:"_id" json:"-"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// MFAChallenge is handed out by a password login when a second factor is
+// still needed. Only the hash of its token is stored.
+type MFAChallenge struct {
+	Hash      string    `bson:"_id" json:"-"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// LoginFailures counts the recent failed logins of one key, a username or a
+// client IP.
+type LoginFailures struct {
+	Key         string    `bson:"_id" json:"key"`
+	Count       int       `bson:"count" json:"count"`
+	LastFailure time.Time `bson:"last_failure" json:"last_failure"`
+	// ExpiresAt is when the failures are forgotten.
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// LoginThrottlePolicy decides how long a key is blocked after failed logins.
+type LoginThrottlePolicy struct {
+	// FreeFailures is the number of failures allowed without delay.
+	FreeFailures int
+	// Backoff is the block after the first failure beyond FreeFailures; it
+	// doubles with every further failure up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// LockoutAfter failures lock the key for LockoutDuration. Zero disables
+	// the lockout.
+	LockoutAfter    int
+	LockoutDuration time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// BlockedUntil returns when the next attempt is allowed, or the zero time if
+// the failures do not block the key.
+func (p LoginThrottlePolicy) BlockedUntil(f LoginFailures) time.Time {
+	switch {
+	case p.LockoutAfter > 0 && f.Count >= p.LockoutAfter:
+		return f.LastFailure.Add(p.LockoutDuration)
+	case f.Count > p.FreeFailures:
+		backoff := RetryPolicy{Backoff: p.Backoff, MaxBackoff: p.MaxBackoff}
+		return f.LastFailure.Add(backoff.Delay(f.Count - p.FreeFailures))
+	}
+	return time.Time{}
+}
+
+// TokenPair is returned on login and refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// LoginResult is the outcome of a login step: either the tokens, or a
+// pending challenge to complete with a second factor.
+type LoginResult struct {
+	Tokens *TokenPair
+	// MFAPending is the challenge token, set instead of Tokens.
+	MFAPending   string
+	MFAExpiresAt time.Time
+	// MFAEnrollmentRequired tells that the user's role requires two-factor
+	// authentication but the user has not enrolled yet. The challenge then
+	// lets them enroll before completing the login.
+	MFAEnrollmentRequired bool
+	// RecoveryCodes are set once, when completing the login confirmed an
+	// enrolment.
+	RecoveryCodes []string
+}
+
+// --- Two-factor authentication ---
+
+const (
+	// RecoveryCodeCount is the number of recovery codes issued on enrolment.
+	RecoveryCodeCount = 10
+	// TOTPDigits is the length of a TOTP code.
+	TOTPDigits = 6
+)
+
+// MFAEnrollment holds a user's TOTP secret and recovery codes.
+type MFAEnrollment struct {
+	UserID string `bson:"_id" json:"user_id"`
+	// Secret is base32 encoded, as authenticator apps expect it.
+	Secret string `bson:"secret" json:"-"`
+	// Confirmed is set once the user has proven with a code that their
+	// authenticator works. Unconfirmed enrolments are not asked for at login.
+	Confirmed bool `bson:"confirmed" json:"confirmed"`
+	// RecoveryCodes holds the hashes of the unused recovery codes.
+	RecoveryCodes []string `bson:"recovery_codes" json:"-"`
+	// LastStep is the time step of the last accepted code, so that no code
+	// is accepted twice.
+	LastStep  int64     `bson:"last_step" json:"-"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// MFASetup is what a user needs to add their secret to an authenticator app.
+type MFASetup struct {
+	Secret string
+	// URI is the otpauth:// URI, usually shown as a QR code.
+	URI string
+}
+
+// IsTOTPCode reports whether code looks like a TOTP code rather than a
+// recovery code.
+func IsTOTPCode(code string) bool {
+	if len(code) != TOTPDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeRecoveryCode lower-cases a recovery code and drops the dashes and
+// spaces users may type along with it.
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, code)
+}
+
+// --- Roles and permissions ---
+
+// Permission names an action a role grants, as "<resource>:<action>".
+type Permission string
+
+const (
+	PermTasksCreate         Permission = "tasks:create"
+	PermTasksUpdate         Permission = "tasks:update"
+	PermTasksDelete         Permission = "tasks:delete"
+	PermTasksReadAll        Permission = "tasks:read_all"
+	PermProjectsManage      Permission = "projects:manage"
+	PermCommentsModerate    Permission = "comments:moderate"
+	PermAttachmentsModerate Permission = "attachments:moderate"
+	PermLabelsManage        Permission = "labels:manage"
+	PermWebhooksManage      Permission = "webhooks:manage"
+	PermAuditRead           Permission = "audit:read"
+	PermUsersManage         Permission = "users:manage"
+	PermRolesManage         Permission = "roles:manage"
+)
+
+// Permissions lists every permission a role can grant.
+var Permissions = []Permission{
+	PermTasksCreate, PermTasksUpdate, PermTasksDelete, PermTasksReadAll,
+	PermProjectsManage, PermCommentsModerate, PermAttachmentsModerate,
+	PermLabelsManage, PermWebhooksManage, PermAuditRead,
+	PermUsersManage, PermRolesManage,
+}
+
+func (p Permission) IsValid() bool {
+	return slices.Contains(Permissions, p)
+}
+
+// RoleDefinition is a role and the permissions it bundles.
+type RoleDefinition struct {
+	Name        Role         `bson:"_id" json:"name"`
+	Description string       `bson:"description" json:"description"`
+	Permissions []Permission `bson:"permissions" json:"permissions"`
+	// RequireMFA makes holders of the role complete two-factor
+	// authentication at every login, enrolling first if they have not.
+	RequireMFA bool      `bson:"require_mfa" json:"require_mfa"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// RoleDefinitionPatch is a partial role update. Nil fields are left unchanged.
+type RoleDefinitionPatch struct {
+	Description *string
+	Permissions *[]Permission
+	RequireMFA  *bool
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// BuiltinRoles returns the roles every installation starts with. Admins
+// hold every permission; users may work on tasks in their projects.
+func BuiltinRoles() []RoleDefinition {
+	return []RoleDefinition{
+		{Name: RoleAdmin, Description: "Full access", Permissions: slices.Clone(Permissions)},
+		{Name: RoleUser, Description: "Works on tasks in their projects", Permissions: []Permission{PermTasksCreate, PermTasksUpdate, PermTasksDelete}},
+	}
+}
+
+// IsBuiltin reports whether the role is one of BuiltinRoles, which cannot be
+// deleted.
+func (r Role) IsBuiltin() bool {
+	return r == RoleAdmin || r == RoleUser
+}
+
+// NormalizeRoleName trims and lower-cases a role name.
+func NormalizeRoleName(name string) Role {
+	return Role(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Validate checks the name and permissions, and sorts and de-duplicates the
+// permissions.
+func (d *RoleDefinition) Validate() error {
+	if !roleNamePattern.MatchString(string(d.Name)) {
+		return ErrInvalidInput
+	}
+	for _, p := range d.Permissions {
+		if !p.IsValid() {
+			return ErrInvalidInput
+		}
+	}
+	slices.Sort(d.Permissions)
+	d.Permissions = slices.Compact(d.Permissions)
+	return nil
+}
+
+// Grants reports whether the role holds every one of the permissions.
+func (d *RoleDefinition) Grants(permissions ...Permission) bool {
+	for _, p := range permissions {
+		if !slices.Contains(d.Permissions, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// --- Projects ---
+
+// ProjectRole is a member's role within a project. Viewers read the
+// project's tasks, editors also change them and owners also manage the
+// project and its members.
+type ProjectRole string
+
+const (
+	ProjectViewer ProjectRole = "viewer"
+	ProjectEditor ProjectRole = "editor"
+	ProjectOwner  ProjectRole = "owner"
+)
+
+// ProjectRoles lists every valid role, each granting more than the last.
+var ProjectRoles = []ProjectRole{ProjectViewer, ProjectEditor, ProjectOwner}
+
+func (r ProjectRole) IsValid() bool {
+	return slices.Contains(ProjectRoles, r)
+}
+
+// Includes reports whether the role grants everything other grants.
+func (r ProjectRole) Includes(other ProjectRole) bool {
+	return r.IsValid() && slices.Index(ProjectRoles, r) >= slices.Index(ProjectRoles, other)
+}
+
+const MaxProjectNameLength = 100
+
+// UnscopedProjectName names the project created for the tasks stored before
+// projects were introduced. It starts without members, so only admins can
+// reach it until they add some.
+const UnscopedProjectName = "Default"
+
+// Project is a workspace whose tasks are only visible to its members.
+type Project struct {
+	ID          string          `bson:"_id,omitempty" json:"id"`
+	Name        string          `bson:"name" json:"name"`
+	Description string          `bson:"description" json:"description"`
+	CreatedBy   string          `bson:"created_by" json:"created_by"`
+	Members     []ProjectMember `bson:"members" json:"members"`
+	CreatedAt   time.Time       `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `bson:"updated_at" json:"updated_at"`
+}
+
+type ProjectMember struct {
+	UserID  string      `bson:"user_id" json:"user_id"`
+	Role    ProjectRole `bson:"role" json:"role"`
+	AddedAt time.Time   `bson:"added_at" json:"added_at"`
+}
+
+// ProjectPatch is a partial project update. Nil fields are left unchanged.
+type ProjectPatch struct {
+	Name        *string
+	Description *string
+}
+
+// Validate checks the name and members. Tasks created before projects
+// existed live in a project without members, so an owner is not required
+// here; removing the last one is refused by the use case instead.
+func (p *Project) Validate() error {
+	if p.Name == "" || utf8.RuneCountInString(p.Name) > MaxProjectNameLength {
+		return ErrInvalidInput
+	}
+	seen := make(map[string]bool, len(p.Members))
+	for _, m := range p.Members {
+		if m.UserID == "" || !m.Role.IsValid() || seen[m.UserID] {
+			return ErrInvalidInput
+		}
+		seen[m.UserID] = true
+	}
+	return nil
+}
+
+// RoleOf returns the role of a member of the project.
+func (p *Project) RoleOf(userID string) (ProjectRole, bool) {
+	for _, m := range p.Members {
+		if m.UserID == userID {
+			return m.Role, true
+		}
+	}
+	return "", false
+}
+
+// RoleFor returns the requester's role in the project. Holders of
+// projects:manage are owners of every project, whether or not they are
+// members.
+func (p *Project) RoleFor(requester Claims) (ProjectRole, bool) {
+	if requester.Can(PermProjectsManage) {
+		return ProjectOwner, true
+	}
+	if requester.UserID == "" {
+		return "", false
+	}
+	return p.RoleOf(requester.UserID)
+}
+
+// CanReadAllTasks reports whether the requester reads every task of the
+// project. Owners and holders of tasks:read_all do; other members only read
+// the tasks they created or are assigned to.
+func (p *Project) CanReadAllTasks(requester Claims) bool {
+	if requester.Can(PermTasksReadAll) {
+		return true
+	}
+	role, ok := p.RoleFor(requester)
+	return ok && role == ProjectOwner
+}
+
+// Owners counts the members with the owner role.
+func (p *Project) Owners() int {
+	n := 0
+	for _, m := range p.Members {
+		if m.Role == ProjectOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// IsVisibleTo reports whether the requester may read the task, which
+// belongs to project. Members who do not read all of the project's tasks
+// only see those they created or that are assigned to them.
+func (t *Task) IsVisibleTo(requester Claims, project *Project) bool {
+	if project.CanReadAllTasks(requester) {
+		return true
+	}
+	return requester.UserID != "" && (t.CreatedBy == requester.UserID || t.AssigneeID == requester.UserID)
+}
+
+// --- Task Queries ---
+
+const (
+	DefaultTaskPageSize = 20
+	MaxTaskPageSize     = 100
+)
+
+// Sortable task fields.
+const (
+	SortByCreatedAt = "created_at"
+	SortByDueDate   = "due_date"
+	SortByTitle     = "title"
+	SortByStatus    = "status"
+)
+
+// TaskQuery describes a filtered, sorted and paginated task listing.
+// Cursor is an opaque token returned as NextCursor by a previous page.
+type TaskQuery struct {
+	Status    TaskStatus
+	DueAfter  time.Time
+	DueBefore time.Time
+	Title     string
+	SortBy    string
+	SortDesc  bool
+	Cursor    string
+	Limit     int
+	// LabelsAny matches tasks carrying at least one of the labels, LabelsAll
+	// tasks carrying every one of them.
+	LabelsAny []string
+	LabelsAll []string
+
+	// ProjectID restricts results to one project. It is set by the use case
+	// from the route, never taken from the query string; only background
+	// jobs such as reminders leave it empty to scan every project.
+	ProjectID string
+	// VisibleTo restricts results to tasks created by or assigned to this
+	// user ID. It is set by the use case, never taken from the request.
+	VisibleTo string
+}
+
+// TaskPage is one page of a task listing.
+type TaskPage struct {
+	Tasks      []Task
+	NextCursor string
+	Total      int64
+}
+
+// Normalize applies defaults and rejects unsupported values.
+func (q *TaskQuery) Normalize() error {
+	switch q.SortBy {
+	case "":
+		q.SortBy = SortByCreatedAt
+	case SortByCreatedAt, SortByDueDate, SortByTitle, SortByStatus:
+	default:
+		return ErrInvalidInput
+	}
+	if q.Status != "" && !q.Status.IsValid() {
+		return ErrInvalidInput
+	}
+	if q.Limit < 0 {
+		return ErrInvalidInput
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultTaskPageSize
+	}
+	if q.Limit > MaxTaskPageSize {
+		q.Limit = MaxTaskPageSize
+	}
+	if !q.DueAfter.IsZero() && !q.DueBefore.IsZero() && q.DueBefore.Before(q.DueAfter) {
+		return ErrInvalidInput
+	}
+	var err error
+	if q.LabelsAny, err = normalizeLabelNames(q.LabelsAny); err != nil {
+		return err
+	}
+	if q.LabelsAll, err = normalizeLabelNames(q.LabelsAll); err != nil {
+		return err
+	}
+	return nil
+}
+
+// --- Labels ---
+
+// MaxTaskLabels caps the number of labels attached to a single task.
+const MaxTaskLabels = 20
+
+// Label is an admin-managed tag for categorising tasks. Tasks refer to labels
+// by name, so a label's name cannot change once it is created.
+type Label struct {
+	Name      string    `bson:"_id" json:"name"`
+	Color     string    `bson:"color" json:"color"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+var (
+	labelNamePattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+	labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+)
+
+// NormalizeLabelName trims and lower-cases a label name, so "Customer-X" and
+// "customer-x" are the same label.
+func NormalizeLabelName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeLabelColor lower-cases a "#rrggbb" colour.
+func NormalizeLabelColor(color string) string {
+	return strings.ToLower(strings.TrimSpace(color))
+}
+
+func IsValidLabelName(name string) bool {
+	return labelNamePattern.MatchString(name)
+}
+
+func IsValidLabelColor(color string) bool {
+	return labelColorPattern.MatchString(color)
+}
+
+func (l *Label) Validate() error {
+	if !IsValidLabelName(l.Name) || !IsValidLabelColor(l.Color) {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// normalizeLabelNames normalizes and de-duplicates a label filter.
+func normalizeLabelNames(names []string) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = NormalizeLabelName(name)
+		if !IsValidLabelName(name) {
+			return nil, ErrInvalidInput
+		}
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+// --- Attachments ---
+
+const (
+	MaxTaskAttachments          = 20
+	MaxAttachmentFilenameLength = 255
+)
+
+// Attachment is the metadata of a file uploaded to a task. ContentType is
+// sniffed from the content, never taken from the client.
+type Attachment struct {
+	ID          string    `bson:"id" json:"id"`
+	Filename    string    `bson:"filename" json:"filename"`
+	ContentType string    `bson:"content_type" json:"content_type"`
+	Size        int64     `bson:"size" json:"size"`
+	UploadedBy  string    `bson:"uploaded_by" json:"uploaded_by"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+}
+
+// AttachmentUpload is a file being uploaded to a task.
+type AttachmentUpload struct {
+	Filename string
+	Content  io.Reader
+}
+
+// AttachmentKey is the blob store key of an attachment's content.
+func AttachmentKey(taskID, attachmentID string) string {
+	return taskID + "/" + attachmentID
+}
+
+// CanDelete reports whether the requester may delete the attachment.
+// Uploaders remove their own files and moderators may remove any file.
+func (a *Attachment) CanDelete(requester Claims) bool {
+	return requester.Can(PermAttachmentsModerate) || (requester.UserID != "" && a.UploadedBy == requester.UserID)
+}
+
+// NormalizeAttachmentFilename strips any directory part from a client
+// supplied filename and rejects empty or overlong names and control
+// characters.
+func NormalizeAttachmentFilename(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" || name == "." || name == ".." || utf8.RuneCountInString(name) > MaxAttachmentFilenameLength ||
+		strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", ErrInvalidInput
+	}
+	return name, nil
+}
+
+// --- Comments ---
+
+const (
+	MaxCommentLength       = 10000
+	DefaultCommentPageSize = 20
+	MaxCommentPageSize     = 100
+)
+
+// Comment is a message in a task's discussion. Top-level comments start a
+// thread; replies point at the comment they answer through ParentID and at
+// the top-level comment through ThreadID, which equals ID for top-level
+// comments.
+type Comment struct {
+	ID        string    `bson:"_id,omitempty" json:"id"`
+	TaskID    string    `bson:"task_id" json:"task_id"`
+	ThreadID  string    `bson:"thread_id" json:"thread_id"`
+	ParentID  string    `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	AuthorID  string    `bson:"author_id" json:"author_id"`
+	Body      string    `bson:"body" json:"body"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CanEdit reports whether the requester may edit or delete the comment.
+// Authors manage their own comments and moderators may change any comment.
+func (c *Comment) CanEdit(requester Claims) bool {
+	return requester.Can(PermCommentsModerate) || (requester.UserID != "" && c.AuthorID == requester.UserID)
+}
+
+// NormalizeCommentBody trims a comment body and rejects empty or oversized ones.
+func NormalizeCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > MaxCommentLength {
+		return "", ErrInvalidInput
+	}
+	return body, nil
+}
+
+// CommentThread is a top-level comment with every reply below it, oldest
+// first. Replies are flat; ParentID tells which comment each one answers.
+type CommentThread struct {
+	Comment
+	Replies []Comment
+}
+
+// CommentQuery pages through the threads of a task, oldest first. Cursor is
+// the NextCursor of a previous page.
+type CommentQuery struct {
+	TaskID string
+	Cursor string
+	Limit  int
+}
+
+// CommentPage is one page of comment threads.
+type CommentPage struct {
+	Threads    []CommentThread
+	NextCursor string
+}
+
+// Normalize applies defaults and rejects unsupported values.
+func (q *CommentQuery) Normalize() error {
+	if q.Limit < 0 {
+		return ErrInvalidInput
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultCommentPageSize
+	}
+	if q.Limit > MaxCommentPageSize {
+		q.Limit = MaxCommentPageSize
+	}
+	return nil
+}
+
+// --- Reminders ---
+
+// ReminderDelivery records a due-date reminder sent to one user through one
+// channel, so that each reminder goes out once.
+type ReminderDelivery struct {
+	TaskID  string `bson:"task_id"`
+	UserID  string `bson:"user_id"`
+	Channel string `bson:"channel"`
+	// Offset is how long before the due date the reminder fires; overdue
+	// reminders have a negative offset.
+	Offset time.Duration `bson:"offset"`
+	// DueDate is part of the key, so moving a task's due date re-arms its
+	// reminders.
+	DueDate time.Time `bson:"due_date"`
+	SentAt  time.Time `bson:"sent_at"`
+}
+
+// Notification is a message for a single user.
+type Notification struct {
+	UserID   string
+	Username string
+	// Email is empty for users who did not give an address.
+	Email   string
+	TaskID  string
+	Subject string
+	Body    string
+}
+
+// Mail is a plain-text email to one recipient.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// --- Events ---
+
+// Published event types.
+const (
+	EventTaskCreated       = "task.created"
+	EventTaskUpdated       = "task.updated"
+	EventTaskDeleted       = "task.deleted"
+	EventTaskStatusChanged = "task.status_changed"
+	EventUserRegistered    = "user.registered"
+	EventUserPromoted      = "user.promoted"
+)
+
+// EventTypes lists every published event type.
+var EventTypes = []string{
+	EventTaskCreated,
+	EventTaskUpdated,
+	EventTaskDeleted,
+	EventTaskStatusChanged,
+	EventUserRegistered,
+	EventUserPromoted,
+}
+
+func IsValidEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// Access event types. They are not offered to webhooks; they tell the task
+// stream that someone may have lost access to what they subscribed to.
+const (
+	// EventProjectMembersChanged carries the project after the change.
+	EventProjectMembersChanged = "project.members_changed"
+	EventProjectDeleted        = "project.deleted"
+	// EventRoleUpdated carries the role whose permissions may have changed.
+	EventRoleUpdated = "role.updated"
+	// EventSessionsEnded carries the user whose access tokens stopped
+	// validating, or only the one in TokenID after a logout.
+	EventSessionsEnded = "user.sessions_ended"
+)
+
+// AccessEventTypes lists the access event types.
+var AccessEventTypes = []string{EventProjectMembersChanged, EventProjectDeleted, EventRoleUpdated, EventSessionsEnded}
+
+// Event is a change that other systems can react to. Task events carry the
+// task and user events the user.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	ActorID    string    `json:"actor_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Task is the task after the change, or the removed task for
+	// task.deleted.
+	Task *Task `json:"task,omitempty"`
+	// PreviousStatus is set on task.status_changed.
+	PreviousStatus TaskStatus `json:"previous_status,omitempty"`
+	User           *User      `json:"user,omitempty"`
+	// Project, Role and TokenID are set on access events.
+	Project *Project `json:"project,omitempty"`
+	Role    Role     `json:"role,omitempty"`
+	TokenID string   `json:"-"`
+}
+
+// TaskEvents describes a task mutation: task.created when before is nil,
+// task.deleted when after is nil and task.updated otherwise, followed by
+// task.status_changed if the status moved.
+func TaskEvents(before, after *Task) []Event {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []Event{{Type: EventTaskCreated, Task: after}}
+	case after == nil:
+		return []Event{{Type: EventTaskDeleted, Task: before}}
+	}
+	events := []Event{{Type: EventTaskUpdated, Task: after}}
+	if before.Status != after.Status {
+		events = append(events, Event{Type: EventTaskStatusChanged, Task: after, PreviousStatus: before.Status})
+	}
+	return events
+}
+
+// --- Task Stream ---
+
+// EventStreamReset tells a resuming stream client that events were missed
+// and its copy of the tasks should be reloaded.
+const EventStreamReset = "stream.reset"
+
+// StreamedEventTypes are the events pushed to task streams.
+var StreamedEventTypes = []string{EventTaskCreated, EventTaskUpdated, EventTaskDeleted}
+
+// StreamEvent is an event sent on a task stream. IDs order the events of a
+// stream; clients send the last one they saw back to resume.
+type StreamEvent struct {
+	ID    string
+	Event Event
+}
+
+// --- Webhooks ---
+
+const (
+	// MinWebhookSecretLength keeps signatures from being guessable.
+	MinWebhookSecretLength = 16
+
+	DefaultWebhookDeliveryPageSize = 50
+	MaxWebhookDeliveryPageSize     = 200
+)
+
+// Webhook is an endpoint subscribed to events. Every payload is signed with
+// the webhook's secret so receivers can verify where it came from.
+type Webhook struct {
+	ID     string `bson:"_id,omitempty" json:"id"`
+	URL    string `bson:"url" json:"url"`
+	Secret string `bson:"secret" json:"-"`
+	// Events holds the subscribed event types.
+	Events []string `bson:"events" json:"events"`
+	// Active webhooks receive deliveries; inactive ones are kept with their
+	// log but receive nothing.
+	Active    bool      `bson:"active" json:"active"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// WebhookPatch is a partial webhook update. Nil fields are left unchanged.
+type WebhookPatch struct {
+	URL    *string
+	Events []string
+	Active *bool
+	Secret *string
+}
+
+// Subscribes reports whether the webhook wants deliveries of eventType.
+func (w *Webhook) Subscribes(eventType string) bool {
+	return w.Active && slices.Contains(w.Events, eventType)
+}
+
+// NormalizeEvents sorts the subscribed event types and drops duplicates.
+func (w *Webhook) NormalizeEvents() {
+	w.Events = slices.Compact(slices.Sorted(slices.Values(w.Events)))
+}
+
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidInput)
+	}
+	if len(w.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidInput)
+	}
+	for _, eventType := range w.Events {
+		if !IsValidEventType(eventType) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidInput, eventType)
+		}
+	}
+	if len(w.Secret) < MinWebhookSecretLength {
+		return fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidInput, MinWebhookSecretLength)
+	}
+	return nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+func (s WebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case DeliveryPending, DeliverySucceeded, DeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to one webhook together with the outcome
+// of its latest attempt. Deliveries make up the webhook's delivery log.
+type WebhookDelivery struct {
+	ID        string `bson:"_id,omitempty" json:"id"`
+	WebhookID string `bson:"webhook_id" json:"webhook_id"`
+	EventID   string `bson:"event_id" json:"event_id"`
+	EventType string `bson:"event_type" json:"event_type"`
+	// Payload is the JSON body, kept verbatim so replays are identical.
+	Payload  string                `bson:"payload" json:"payload"`
+	Status   WebhookDeliveryStatus `bson:"status" json:"status"`
+	Attempts int                   `bson:"attempts" json:"attempts"`
+	// ResponseStatus is the HTTP status of the last attempt, 0 if no
+	// response was received.
+	ResponseStatus int    `bson:"response_status" json:"response_status"`
+	Error          string `bson:"error" json:"error"`
+	// NextAttemptAt is when a pending delivery is due.
+	NextAttemptAt time.Time `bson:"next_attempt_at" json:"next_attempt_at"`
+	LastAttemptAt time.Time `bson:"last_attempt_at" json:"last_attempt_at"`
+	// ReplayOf is the ID of the delivery this one replays.
+	ReplayOf  string    `bson:"replay_of" json:"replay_of"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// WebhookDeliveryQuery filters a webhook's delivery log. Deliveries are
+// returned newest first and Cursor is the NextCursor of a previous page.
+type WebhookDeliveryQuery struct {
+	WebhookID string
+	Status    WebhookDeliveryStatus
+	Cursor    string
+	Limit     int
+}
+
+// WebhookDeliveryPage is one page of a delivery log.
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery
+	NextCursor string
+}
+
+// Normalize applies defaults and rejects unsupported values.
+func (q *WebhookDeliveryQuery) Normalize() error {
+	if q.Limit < 0 || (q.Status != "" && !q.Status.IsValid()) {
+		return ErrInvalidInput
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultWebhookDeliveryPageSize
+	}
+	if q.Limit > MaxWebhookDeliveryPageSize {
+		q.Limit = MaxWebhookDeliveryPageSize
+	}
+	return nil
+}
+
+// RetryPolicy decides when failed deliveries are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts after which a delivery fails
+	// for good.
+	MaxAttempts int
+	// Backoff is the delay after the first failed attempt; it doubles with
+	// every further attempt up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Delay returns how long to wait after the given failed attempt, counting
+// from 1.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// --- Audit ---
+
+// Audited actions.
+const (
+	AuditTaskCreated      = "task.created"
+	AuditTaskUpdated      = "task.updated"
+	AuditTaskDeleted      = "task.deleted"
+	AuditTaskAssigned     = "task.assigned"
+	AuditTaskTransitioned = "task.transitioned"
+	AuditUserRegistered   = "user.registered"
+	AuditUserPromoted     = "user.promoted"
+	AuditUserRoleChanged  = "user.role_changed"
+	AuditUserDemoted      = "user.demoted"
+	AuditUserDisabled     = "user.disabled"
+	AuditUserEnabled      = "user.enabled"
+	AuditUserDeleted      = "user.deleted"
+	AuditPasswordChanged  = "user.password_changed"
+	AuditPasswordReset    = "user.password_reset"
+	AuditMFAEnabled       = "user.mfa_enabled"
+	AuditMFADisabled      = "user.mfa_disabled"
+	AuditRoleCreated      = "role.created"
+	AuditRoleUpdated      = "role.updated"
+	AuditRoleDeleted      = "role.deleted"
+	AuditLabelCreated     = "label.created"
+	AuditLabelUpdated     = "label.updated"
+	AuditLabelDeleted     = "label.deleted"
+	AuditCommentUpdated   = "comment.updated"
+	AuditCommentDeleted   = "comment.deleted"
+	AuditProjectCreated   = "project.created"
+	AuditProjectUpdated   = "project.updated"
+	AuditProjectDeleted   = "project.deleted"
+	AuditMemberSet        = "project.member_set"
+	AuditMemberRemoved    = "project.member_removed"
+)
+
+// Audited entity types.
+const (
+	AuditEntityTask    = "task"
+	AuditEntityUser    = "user"
+	AuditEntityLabel   = "label"
+	AuditEntityComment = "comment"
+	AuditEntityProject = "project"
+	AuditEntityRole    = "role"
+)
+
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 200
+)
+
+// AuditEntry records one mutation: who did what to which entity, and how
+// each changed field looked before and after.
+type AuditEntry struct {
+	ID         string        `bson:"_id,omitempty" json:"id"`
+	ActorID    string        `bson:"actor_id" json:"actor_id"`
+	Action     string        `bson:"action" json:"action"`
+	EntityType string        `bson:"entity_type" json:"entity_type"`
+	EntityID   string        `bson:"entity_id" json:"entity_id"`
+	Changes    []FieldChange `bson:"changes" json:"changes"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// FieldChange is the before and after value of a single field, formatted as
+// strings so entries read the same regardless of the storage backend.
+type FieldChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before string `bson:"before" json:"before"`
+	After  string `bson:"after" json:"after"`
+}
+
+// AuditQuery filters the audit log. Entries are returned newest first and
+// Cursor is the NextCursor of a previous page.
+type AuditQuery struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	Since      time.Time
+	Until      time.Time
+	Cursor     string
+	Limit      int
+}
+
+// AuditPage is one page of audit entries.
+type AuditPage struct {
+	Entries    []AuditEntry
+	NextCursor string
+}
+
+// Normalize applies defaults and rejects unsupported values.
+func (q *AuditQuery) Normalize() error {
+	if q.Limit < 0 {
+		return ErrInvalidInput
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultAuditPageSize
+	}
+	if q.Limit > MaxAuditPageSize {
+		q.Limit = MaxAuditPageSize
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// DiffTasks lists the user-visible fields that differ between two versions of
+// a task. A nil before or after stands for a task that does not exist yet or
+// any more, so every non-empty field of the other side is reported.
+func DiffTasks(before, after *Task) []FieldChange {
+	var b, a Task
+	if before != nil {
+		b = *before
+	}
+	if after != nil {
+		a = *after
+	}
+
+	changes := []FieldChange{}
+	add := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, FieldChange{Field: field, Before: before, After: after})
+		}
+	}
+	add("title", b.Title, a.Title)
+	add("description", b.Description, a.Description)
+	add("due_date", formatAuditTime(b.DueDate), formatAuditTime(a.DueDate))
+	add("status", string(b.Status), string(a.Status))
+	add("created_by", b.CreatedBy, a.CreatedBy)
+	add("assignee_id", b.AssigneeID, a.AssigneeID)
+	add("parent_id", b.ParentID, a.ParentID)
+	add("checklist", formatChecklist(b.Checklist), formatChecklist(a.Checklist))
+	add("labels", strings.Join(b.Labels, ","), strings.Join(a.Labels, ","))
+	add("attachments", formatAttachments(b.Attachments), formatAttachments(a.Attachments))
+	add("recurrence", formatRecurrence(b.Recurrence), formatRecurrence(a.Recurrence))
+	return changes
+}
+
+// formatChecklist renders a checklist one item per line, e.g. "[x] Write docs".
+func formatChecklist(items []ChecklistItem) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		mark := "[ ]"
+		if item.Done {
+			mark = "[x]"
+		}
+		lines[i] = mark + " " + item.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatAttachments renders attachments one per line as "filename (size bytes)".
+func formatAttachments(attachments []Attachment) string {
+	lines := make([]string, len(attachments))
+	for i, a := range attachments {
+		lines[i] = fmt.Sprintf("%s (%d bytes)", a.Filename, a.Size)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatRecurrence renders the series' rule, the part users edit.
+func formatRecurrence(r *Recurrence) string {
+	if r == nil {
+		return ""
+	}
+	return r.Rule
+}
+
+func formatAuditTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
 }
 
 // Validation methods
@@ -47,6 +1387,9 @@ func (t *Task) Validate() error {
 	if t.DueDate.Before(time.Now()) {
 		return ErrInvalidInput
 	}
+	if t.Status != "" && !t.Status.IsValid() {
+		return ErrInvalidStatus
+	}
 	return nil
 }
 
@@ -54,7 +1397,24 @@ func (u *User) Validate() error {
 	if u.Username == "" || len(u.Username) < 3 {
 		return ErrInvalidInput
 	}
-	if u.Password == "" || len(u.Password) < 6 {
+	if err := ValidatePassword(u.Password); err != nil {
+		return err
+	}
+	if u.Email != "" {
+		// Only a bare address, no display name
+		if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+			return ErrInvalidInput
+		}
+	}
+	return nil
+}
+
+// MinPasswordLength is the shortest password accepted.
+const MinPasswordLength = 6
+
+// ValidatePassword checks a plain-text password before it is hashed.
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
 		return ErrInvalidInput
 	}
 	return nil
@@ -68,43 +1428,476 @@ type IPasswordService interface {
 
 type IAuthService interface {
 	GenerateToken(user *User) (string, error)
-	ValidateToken(tokenString string) (*Claims, error)
+	// ValidateToken checks the token and resolves the permissions of its
+	// role, which are cached for a short while.
+	ValidateToken(ctx context.Context, tokenString string) (*Claims, error)
+	// InvalidatePermissions drops the cached permissions of a role after it
+	// was changed.
+	InvalidatePermissions(role Role)
+	GenerateOpaqueToken() (string, error)
+	HashToken(token string) string
 }
 
 type Claims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	Role     Role   `json:"role"`
+	UserID       string    `json:"user_id"`
+	Username     string    `json:"username"`
+	Role         Role      `json:"role"`
+	TokenID      string    `json:"jti"`
+	TokenVersion int       `json:"ver"`
+	ExpiresAt    time.Time `json:"exp"`
+	// Permissions are resolved from the role when the token is validated
+	// rather than carried in it, so role edits apply to live tokens.
+	Permissions []Permission `json:"-"`
+}
+
+// Can reports whether the requester's role grants the permission.
+func (c Claims) Can(permission Permission) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+// INotifier delivers notifications through one channel.
+type INotifier interface {
+	// Name identifies the channel, e.g. "smtp".
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// ILoginThrottle slows down password guessing by blocking usernames and
+// client IPs after repeated failed logins.
+type ILoginThrottle interface {
+	// Reserve counts an attempt as failed before the password is checked,
+	// so concurrent guesses cannot all get past the block. It returns a
+	// *LoginThrottledError, and counts nothing, while the username or the
+	// client IP is blocked.
+	Reserve(ctx context.Context, username, clientIP string) error
+	// Release takes back the count of a reserved attempt that did not fail.
+	Release(ctx context.Context, username, clientIP string) error
+	// RecordSuccess forgets the failures of the username. Those of the
+	// client IP are kept, so one valid account cannot clear them.
+	RecordSuccess(ctx context.Context, username string) error
+}
+
+// ITOTPService generates and checks RFC 6238 time-based one-time passwords
+// and the recovery codes that stand in for them.
+type ITOTPService interface {
+	// GenerateSecret returns a new random secret, base32 encoded.
+	GenerateSecret() (string, error)
+	// URI returns the otpauth:// URI that adds the secret to an
+	// authenticator app under the account name.
+	URI(secret, account string) string
+	// Validate returns the time step the code is valid for, allowing one
+	// step of clock drift either way, or false if it is not valid at t.
+	Validate(secret, code string, t time.Time) (int64, bool)
+	// GenerateRecoveryCodes returns n random, human-typeable codes.
+	GenerateRecoveryCodes(n int) ([]string, error)
+}
+
+// IMailer sends account emails, such as password reset links, to users.
+type IMailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// IEventPublisher is told about every published event. Publishing happens
+// after the change is stored and never fails it.
+type IEventPublisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// IWebhookSender posts a delivery's payload to its webhook.
+type IWebhookSender interface {
+	// Send returns the HTTP status of the response, or 0 if none was
+	// received. Statuses outside 2xx are returned with an error.
+	Send(ctx context.Context, webhook *Webhook, delivery *WebhookDelivery) (int, error)
 }
 
 // --- Repository Interfaces ---
+
+// ITaskRepository stores the tasks of every project. Methods taking a
+// projectID only see that project's tasks, so a task of another project
+// is treated as missing.
 type ITaskRepository interface {
-	GetAll() ([]Task, error)
-	GetByID(id string) (*Task, error)
-	Create(task Task) (*Task, error)
-	Update(id string, task Task) (*Task, error)
-	Delete(id string) error
+	// GetAll returns the tasks of every project, for rebuilding indexes.
+	GetAll(ctx context.Context) ([]Task, error)
+	Find(ctx context.Context, query TaskQuery) (*TaskPage, error)
+	GetByID(ctx context.Context, projectID string, id string) (*Task, error)
+	// Create stores a task in task.ProjectID.
+	Create(ctx context.Context, task Task) (*Task, error)
+	// Update overwrites the task's editable fields provided its stored version
+	// still equals task.Version, and returns ErrVersionConflict otherwise.
+	Update(ctx context.Context, projectID string, id string, task Task) (*Task, error)
+	Delete(ctx context.Context, projectID string, id string) error
+	Assign(ctx context.Context, projectID string, id string, assigneeID string) (*Task, error)
+	// GetChildren returns the direct subtasks of a task, oldest first.
+	GetChildren(ctx context.Context, projectID string, parentID string) ([]Task, error)
+	// RemoveLabelFromAll detaches a label from every task carrying it.
+	RemoveLabelFromAll(ctx context.Context, label string) error
+}
+
+type IProjectRepository interface {
+	Create(ctx context.Context, project Project) (*Project, error)
+	GetByID(ctx context.Context, id string) (*Project, error)
+	// GetAll returns every project, oldest first.
+	GetAll(ctx context.Context) ([]Project, error)
+	// GetByMember returns the projects the user is a member of, oldest first.
+	GetByMember(ctx context.Context, userID string) ([]Project, error)
+	// Update overwrites the project's name, description and members.
+	Update(ctx context.Context, project Project) (*Project, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ITaskSearcher runs full-text searches over tasks.
+type ITaskSearcher interface {
+	// Search returns a page of the tasks matching a normalized query, best
+	// matches first. Highlights are left to the caller.
+	Search(ctx context.Context, query TaskSearchQuery) (*TaskSearchPage, error)
+}
+
+type IRoleRepository interface {
+	Create(ctx context.Context, role RoleDefinition) (*RoleDefinition, error)
+	// GetAll returns every role ordered by name.
+	GetAll(ctx context.Context) ([]RoleDefinition, error)
+	GetByName(ctx context.Context, name Role) (*RoleDefinition, error)
+	// Update overwrites the role's description, permissions and two-factor
+	// requirement.
+	Update(ctx context.Context, role RoleDefinition) (*RoleDefinition, error)
+	Delete(ctx context.Context, name Role) error
+}
+
+type ILabelRepository interface {
+	Create(ctx context.Context, label Label) (*Label, error)
+	// GetAll returns every label ordered by name.
+	GetAll(ctx context.Context) ([]Label, error)
+	GetByName(ctx context.Context, name string) (*Label, error)
+	Update(ctx context.Context, label Label) (*Label, error)
+	Delete(ctx context.Context, name string) error
+}
+
+type ICommentRepository interface {
+	// Create stores a comment. A comment without a ThreadID starts a thread.
+	Create(ctx context.Context, comment Comment) (*Comment, error)
+	GetByID(ctx context.Context, id string) (*Comment, error)
+	// FindThreads returns a page of a task's threads with their replies.
+	FindThreads(ctx context.Context, query CommentQuery) (*CommentPage, error)
+	// Update changes the body of a comment.
+	Update(ctx context.Context, comment Comment) (*Comment, error)
+	// Delete removes a comment together with every reply below it.
+	Delete(ctx context.Context, id string) error
+	DeleteByTask(ctx context.Context, taskID string) error
+}
+
+// IBlobStore keeps binary content, such as attachments, under string keys.
+type IBlobStore interface {
+	// Put stores content under key, replacing any previous blob, and returns
+	// the number of bytes written.
+	Put(ctx context.Context, key string, content io.Reader) (int64, error)
+	// Open returns the blob stored under key, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Missing blobs are not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+type IReminderRepository interface {
+	// Claim records a delivery before it is attempted and returns
+	// ErrDuplicateEntry if it has already been claimed.
+	Claim(ctx context.Context, delivery ReminderDelivery) error
+	// Release forgets a claim whose delivery failed, so it is retried.
+	Release(ctx context.Context, delivery ReminderDelivery) error
+	// DeleteDueBefore forgets the deliveries for due dates before t.
+	DeleteDueBefore(ctx context.Context, t time.Time) error
+}
+
+type IWebhookRepository interface {
+	Create(ctx context.Context, webhook Webhook) (*Webhook, error)
+	GetByID(ctx context.Context, id string) (*Webhook, error)
+	// GetAll returns every webhook, oldest first.
+	GetAll(ctx context.Context) ([]Webhook, error)
+	// Update overwrites the webhook's URL, secret, events and active flag.
+	Update(ctx context.Context, webhook Webhook) (*Webhook, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type IWebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery WebhookDelivery) (*WebhookDelivery, error)
+	GetByID(ctx context.Context, id string) (*WebhookDelivery, error)
+	// Update stores the outcome of an attempt.
+	Update(ctx context.Context, delivery WebhookDelivery) error
+	Find(ctx context.Context, query WebhookDeliveryQuery) (*WebhookDeliveryPage, error)
+	// FindDue returns up to limit pending deliveries due by now, the most
+	// overdue first.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+	DeleteByWebhook(ctx context.Context, webhookID string) error
+}
+
+type IAuditRepository interface {
+	Record(ctx context.Context, entry AuditEntry) error
+	Find(ctx context.Context, query AuditQuery) (*AuditPage, error)
 }
 
 type IUserRepository interface {
-	Create(user User) (*User, error)
-	GetByUsername(username string) (*User, error)
-	GetByID(id string) (*User, error)
-	Promote(username string) error
-	Exists(username string) (bool, error)
+	Create(ctx context.Context, user User) (*User, error)
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	GetByID(ctx context.Context, id string) (*User, error)
+	Promote(ctx context.Context, username string) error
+	SetRole(ctx context.Context, id string, role Role) error
+	// CountByRole counts the users holding the role.
+	CountByRole(ctx context.Context, role Role) (int64, error)
+	// Find returns the page of users matching query, which must be
+	// normalized.
+	Find(ctx context.Context, query UserQuery) (*UserPage, error)
+	SetDisabled(ctx context.Context, id string, disabled bool) error
+	// SetPassword stores a new password hash.
+	SetPassword(ctx context.Context, id string, hash string) error
+	Delete(ctx context.Context, id string) error
+	Exists(ctx context.Context, username string) (bool, error)
+	IncrementTokenVersion(ctx context.Context, id string) error
+}
+
+// IMFARepository stores the two-factor enrolments of users.
+type IMFARepository interface {
+	// Get returns ErrNotFound when the user has not started enrolling.
+	Get(ctx context.Context, userID string) (*MFAEnrollment, error)
+	// Save creates or replaces the user's enrolment.
+	Save(ctx context.Context, enrollment MFAEnrollment) error
+	// UseStep records that a code of the given time step was accepted. It
+	// returns ErrDuplicateEntry unless step is newer than the last one.
+	UseStep(ctx context.Context, userID string, step int64) error
+	// UseRecoveryCode removes the recovery code with the given hash, or
+	// returns ErrNotFound if the user has no such code left.
+	UseRecoveryCode(ctx context.Context, userID string, hash string) error
+	// Delete removes the enrolment. Missing enrolments are not an error.
+	Delete(ctx context.Context, userID string) error
+}
+
+// ILoginAttemptRepository counts failed logins per key, shared by every
+// instance. Failures are forgotten once window has passed without a new one.
+type ILoginAttemptRepository interface {
+	// Get returns ErrNotFound when the key has no remembered failures.
+	Get(ctx context.Context, key string) (*LoginFailures, error)
+	// RecordFailure counts a failure at the given time and returns the
+	// updated record.
+	RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*LoginFailures, error)
+	// ReleaseFailure takes back one counted failure, if any is left.
+	ReleaseFailure(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+type ITokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash string) (*RefreshToken, error)
+	// RevokeRefreshToken returns ErrNotFound if the token is gone or was
+	// already revoked, so that it can be rotated out only once.
+	RevokeRefreshToken(ctx context.Context, hash string) error
+	DeleteRefreshToken(ctx context.Context, hash string) error
+	DeleteUserRefreshTokens(ctx context.Context, userID string) error
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	CreatePasswordResetToken(ctx context.Context, token PasswordResetToken) error
+	// ConsumePasswordResetToken deletes the token and returns it, so it can
+	// only be redeemed once. Expired tokens are reported as ErrNotFound.
+	ConsumePasswordResetToken(ctx context.Context, hash string) (*PasswordResetToken, error)
+	DeleteUserPasswordResetTokens(ctx context.Context, userID string) error
+	CreateMFAChallenge(ctx context.Context, challenge MFAChallenge) error
+	// GetMFAChallenge reports expired challenges as ErrNotFound.
+	GetMFAChallenge(ctx context.Context, hash string) (*MFAChallenge, error)
+	// DeleteMFAChallenge returns ErrNotFound if the challenge is already
+	// gone, so that it can be completed only once.
+	DeleteMFAChallenge(ctx context.Context, hash string) error
 }
 
 // --- UseCase Interfaces ---
+
+// ITaskUseCase manages the tasks of a project. Callers have already checked
+// that the requester may act on the project; the use case keeps every read
+// and write inside it.
 type ITaskUseCase interface {
-	GetAllTasks() ([]Task, error)
-	GetTaskByID(id string) (*Task, error)
-	CreateTask(task Task) (*Task, error)
-	UpdateTask(id string, task Task) (*Task, error)
-	DeleteTask(id string) error
+	// GetAllTasks lists the project's tasks the requester may read; see
+	// Task.IsVisibleTo. Every other method reports a task the requester may
+	// not read as missing.
+	GetAllTasks(ctx context.Context, projectID string, query TaskQuery, requester Claims) (*TaskPage, error)
+	// SearchTasks runs a full-text search over the project's tasks, with the
+	// matches highlighted.
+	SearchTasks(ctx context.Context, projectID string, query TaskSearchQuery, requester Claims) (*TaskSearchPage, error)
+	GetTaskByID(ctx context.Context, projectID string, id string, requester Claims) (*Task, error)
+	CreateTask(ctx context.Context, projectID string, task Task, requester Claims) (*Task, error)
+	UpdateTask(ctx context.Context, projectID string, id string, task Task, requester Claims) (*Task, error)
+	PatchTask(ctx context.Context, projectID string, id string, patch TaskPatch, version int64, requester Claims) (*Task, error)
+	DeleteTask(ctx context.Context, projectID string, id string, requester Claims) error
+	AssignTask(ctx context.Context, projectID string, id string, assigneeID string, requester Claims) (*Task, error)
+	TransitionTask(ctx context.Context, projectID string, id string, status TaskStatus, requester Claims) (*Task, error)
+	// GetSubtasks returns the direct children the requester may read.
+	GetSubtasks(ctx context.Context, projectID string, id string, requester Claims) ([]Task, error)
+	AddChecklistItem(ctx context.Context, projectID string, id string, text string, requester Claims) (*Task, error)
+	UpdateChecklistItem(ctx context.Context, projectID string, id string, itemID string, patch ChecklistItemPatch, requester Claims) (*Task, error)
+	ReorderChecklist(ctx context.Context, projectID string, id string, itemIDs []string, requester Claims) (*Task, error)
+	RemoveChecklistItem(ctx context.Context, projectID string, id string, itemID string, requester Claims) (*Task, error)
+	AttachLabel(ctx context.Context, projectID string, id string, label string, requester Claims) (*Task, error)
+	DetachLabel(ctx context.Context, projectID string, id string, label string, requester Claims) (*Task, error)
+	// UpdateSeries edits the series of a recurring task from that occurrence
+	// on, or turns a plain task into the first occurrence of a series.
+	UpdateSeries(ctx context.Context, projectID string, id string, patch SeriesPatch, version int64, requester Claims) (*Task, error)
+}
+
+type IProjectUseCase interface {
+	// ListProjects returns the projects the requester is a member of, or
+	// every project for admins.
+	ListProjects(ctx context.Context, requester Claims) ([]Project, error)
+	GetProject(ctx context.Context, id string) (*Project, error)
+	// CreateProject creates a project owned by its creator.
+	CreateProject(ctx context.Context, project Project, creatorID string) (*Project, error)
+	UpdateProject(ctx context.Context, id string, patch ProjectPatch, actorID string) (*Project, error)
+	// DeleteProject removes a project once it has no tasks left.
+	DeleteProject(ctx context.Context, id string, actorID string) error
+	// SetMember adds a user to the project or changes their role.
+	SetMember(ctx context.Context, id string, userID string, role ProjectRole, actorID string) (*Project, error)
+	RemoveMember(ctx context.Context, id string, userID string, actorID string) (*Project, error)
+	// Authorize returns the requester's role in the project. It fails with
+	// ErrNotFound when the project does not exist or the requester is not a
+	// member, and with ErrForbidden when the role does not include minimum.
+	Authorize(ctx context.Context, projectID string, requester Claims, minimum ProjectRole) (ProjectRole, error)
+}
+
+type ILabelUseCase interface {
+	ListLabels(ctx context.Context) ([]Label, error)
+	CreateLabel(ctx context.Context, label Label, actorID string) (*Label, error)
+	UpdateLabel(ctx context.Context, name string, color string, actorID string) (*Label, error)
+	DeleteLabel(ctx context.Context, name string, actorID string) error
+}
+
+type ICommentUseCase interface {
+	ListComments(ctx context.Context, projectID string, taskID string, query CommentQuery, requester Claims) (*CommentPage, error)
+	// AddComment posts a comment; a non-empty ParentID makes it a reply.
+	AddComment(ctx context.Context, projectID string, taskID string, comment Comment, requester Claims) (*Comment, error)
+	UpdateComment(ctx context.Context, projectID string, taskID string, commentID string, body string, requester Claims) (*Comment, error)
+	DeleteComment(ctx context.Context, projectID string, taskID string, commentID string, requester Claims) error
+}
+
+type IAttachmentUseCase interface {
+	ListAttachments(ctx context.Context, projectID string, taskID string, requester Claims) ([]Attachment, error)
+	AddAttachment(ctx context.Context, projectID string, taskID string, upload AttachmentUpload, requester Claims) (*Attachment, error)
+	// OpenAttachment returns the attachment's metadata and content; the
+	// caller closes the content.
+	OpenAttachment(ctx context.Context, projectID string, taskID string, attachmentID string, requester Claims) (*Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, projectID string, taskID string, attachmentID string, requester Claims) error
 }
 
 type IUserUseCase interface {
-	Register(user User) (*User, error)
-	Login(username, password string) (string, error)
-	PromoteUser(username string, promoterID string) error
+	Register(ctx context.Context, user User) (*User, error)
+	// Login checks the password, refusing with a *LoginThrottledError while
+	// the username or client IP is blocked after failed attempts. Users
+	// with two-factor authentication get a pending challenge instead of the
+	// tokens.
+	Login(ctx context.Context, username, password, clientIP string) (*LoginResult, error)
+	// CompleteMFALogin exchanges a pending challenge and a TOTP or recovery
+	// code for the tokens. Failed codes are throttled like passwords. If the
+	// login was waiting on an enrolment, the code confirms it and the
+	// recovery codes are returned.
+	CompleteMFALogin(ctx context.Context, challenge, code, clientIP string) (*LoginResult, error)
+	// StartMFALoginEnrollment starts the enrolment of a user whose pending
+	// login requires it.
+	StartMFALoginEnrollment(ctx context.Context, challenge string) (*MFASetup, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, requester Claims, refreshToken string) error
+	PromoteUser(ctx context.Context, username string, promoterID string) error
+	// ChangePassword sets a new password for a user who knows the current
+	// one. Wrong guesses count towards the login throttle. All of the user's
+	// sessions end, and a new token pair is returned for the caller's.
+	ChangePassword(ctx context.Context, userID string, oldPassword, newPassword, clientIP string) (*TokenPair, error)
+	// RequestPasswordReset mails a reset token to the user's address. It
+	// succeeds without sending anything for unknown users, so callers cannot
+	// find out which accounts exist.
+	RequestPasswordReset(ctx context.Context, username string) error
+	// ResetPassword redeems a reset token and ends all of the user's
+	// sessions.
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+	// StartMFAEnrollment creates a new TOTP secret for the user, replacing
+	// an unconfirmed one. It fails with ErrMFAEnabled once enrolment is
+	// confirmed.
+	StartMFAEnrollment(ctx context.Context, userID string) (*MFASetup, error)
+	// ConfirmMFAEnrollment turns two-factor authentication on once the code
+	// matches, and returns the recovery codes, which are not shown again.
+	ConfirmMFAEnrollment(ctx context.Context, userID string, code string) ([]string, error)
+	// DisableMFA turns two-factor authentication off for a user who knows
+	// their password, unless their role requires it.
+	DisableMFA(ctx context.Context, userID string, password string) error
+	// The administration methods below refuse to act on users whose role
+	// grants more than the actor's, and to remove the last active admin.
+
+	// AssignRole gives a user another role. Actors cannot hand out
+	// permissions they do not hold themselves.
+	AssignRole(ctx context.Context, userID string, role Role, actorID string) (*User, error)
+	ListUsers(ctx context.Context, query UserQuery) (*UserPage, error)
+	GetUser(ctx context.Context, id string) (*User, error)
+	// DemoteUser gives an admin the user role back.
+	DemoteUser(ctx context.Context, id string, actorID string) (*User, error)
+	// SetUserDisabled disables or re-enables an account. Disabling ends all
+	// of the user's sessions.
+	SetUserDisabled(ctx context.Context, id string, disabled bool, actorID string) (*User, error)
+	// DeleteUser removes the account, its sessions and its project
+	// memberships. Tasks and comments keep referring to the user's ID.
+	DeleteUser(ctx context.Context, id string, actorID string) error
+	// ResetMFA removes the two-factor enrolment of a user who lost their
+	// authenticator and recovery codes.
+	ResetMFA(ctx context.Context, id string, actorID string) error
+}
+
+type IRoleUseCase interface {
+	ListRoles(ctx context.Context) ([]RoleDefinition, error)
+	CreateRole(ctx context.Context, role RoleDefinition, actorID string) (*RoleDefinition, error)
+	// UpdateRole changes a role's description, permissions or two-factor
+	// requirement. The admin role always holds every permission, so only
+	// its two-factor requirement can be changed.
+	UpdateRole(ctx context.Context, name Role, patch RoleDefinitionPatch, actorID string) (*RoleDefinition, error)
+	// DeleteRole removes a role no user holds. Built-in roles cannot be
+	// deleted.
+	DeleteRole(ctx context.Context, name Role, actorID string) error
+	// EnsureBuiltinRoles creates the built-in roles that are missing and
+	// grants the admin role any permission added since it was stored.
+	EnsureBuiltinRoles(ctx context.Context) error
+}
+
+type IReminderUseCase interface {
+	// SendDueReminders sends every reminder that has fallen due by now and
+	// has not been sent yet.
+	SendDueReminders(ctx context.Context, now time.Time) error
+}
+
+type ITaskStreamUseCase interface {
+	// Publish forwards task events to the subscribers of the task's project,
+	// and ends the subscriptions that access events took access away from.
+	IEventPublisher
+	// Subscribe streams the events of the project's tasks the requester may
+	// read until ctx is done, judging visibility on the task after the
+	// change. Given the ID of the last event of an earlier subscription, the
+	// missed events are sent first, or a stream.reset event if they are no
+	// longer known. The channel is closed when the subscription ends, which
+	// also happens when the subscriber falls too far behind, leaves the
+	// project or the requester's access token expires or is revoked.
+	Subscribe(ctx context.Context, projectID string, requester Claims, lastEventID string) (<-chan StreamEvent, error)
+	// Close ends every subscription and refuses new ones.
+	Close()
+}
+
+type IWebhookUseCase interface {
+	// Publish queues a delivery of the event to every subscribed webhook.
+	IEventPublisher
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*Webhook, error)
+	// CreateWebhook registers an endpoint. A secret is generated unless one
+	// is given; the returned webhook is the only place it is revealed.
+	CreateWebhook(ctx context.Context, webhook Webhook, actorID string) (*Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, patch WebhookPatch) (*Webhook, error)
+	// DeleteWebhook removes the webhook together with its delivery log.
+	DeleteWebhook(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, webhookID string, query WebhookDeliveryQuery) (*WebhookDeliveryPage, error)
+	// ReplayDelivery queues a new delivery of a logged payload.
+	ReplayDelivery(ctx context.Context, webhookID string, deliveryID string) (*WebhookDelivery, error)
+	// DeliverDue attempts the pending deliveries due by now and schedules
+	// retries for the ones that fail.
+	DeliverDue(ctx context.Context, now time.Time) error
+}
+
+type IAuditUseCase interface {
+	// GetTaskHistory returns the audit entries of a task of the project.
+	GetTaskHistory(ctx context.Context, projectID string, taskID string, query AuditQuery) (*AuditPage, error)
+	ListEntries(ctx context.Context, query AuditQuery) (*AuditPage, error)
 }
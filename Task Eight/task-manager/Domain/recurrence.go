@@ -0,0 +1,393 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of a recurrence rule.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// rruleWeekdays maps the two-letter RRULE day codes to weekdays.
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// RuleDay is one BYDAY entry. Ordinal is only used with FREQ=MONTHLY, where
+// 2 means the second and -1 the last such weekday of the month; 0 means
+// every one.
+type RuleDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RecurrenceRule is the supported subset of an RFC 5545 RRULE: FREQ,
+// INTERVAL, BYDAY, COUNT and UNTIL. Weeks start on Monday.
+type RecurrenceRule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []RuleDay
+	// Count limits the series to that many occurrences, Until to the
+	// occurrences up to and including that instant. At most one is set.
+	Count int
+	Until time.Time
+}
+
+// ParseRecurrenceRule parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+// An "RRULE:" prefix is accepted. Errors wrap ErrInvalidInput.
+func ParseRecurrenceRule(spec string) (*RecurrenceRule, error) {
+	spec = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(spec)), "RRULE:")
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: recurrence rule: %s", ErrInvalidInput, fmt.Sprintf(format, args...))
+	}
+
+	rule := RecurrenceRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, invalid("malformed part %q", part)
+		}
+		if seen[name] {
+			return nil, invalid("%s given twice", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			if rule.Freq != FreqDaily && rule.Freq != FreqWeekly && rule.Freq != FreqMonthly && rule.Freq != FreqYearly {
+				return nil, invalid("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 1000 {
+				return nil, invalid("INTERVAL must be between 1 and 1000")
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, invalid("COUNT must be positive")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRuleUntil(value)
+			if err != nil {
+				return nil, invalid("malformed UNTIL %q", value)
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, err := parseRuleDay(code)
+				if err != nil {
+					return nil, invalid("malformed BYDAY %q", code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		default:
+			return nil, invalid("unsupported part %s", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, invalid("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, invalid("COUNT and UNTIL are mutually exclusive")
+	}
+	for _, day := range rule.ByDay {
+		if rule.Freq == FreqYearly || (day.Ordinal != 0 && rule.Freq != FreqMonthly) {
+			return nil, invalid("BYDAY %s is not supported with FREQ=%s", day, rule.Freq)
+		}
+	}
+	return &rule, nil
+}
+
+// parseRuleUntil accepts the UTC date-time, floating date-time and date forms
+// of UNTIL. Floating times are read as UTC and dates include the whole day.
+func parseRuleUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// parseRuleDay parses a BYDAY entry such as "MO", "2TU" or "-1FR".
+func parseRuleDay(code string) (RuleDay, error) {
+	if len(code) < 2 {
+		return RuleDay{}, ErrInvalidInput
+	}
+	weekday, ok := rruleWeekdays[code[len(code)-2:]]
+	if !ok {
+		return RuleDay{}, ErrInvalidInput
+	}
+	day := RuleDay{Weekday: weekday}
+	if prefix := code[:len(code)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RuleDay{}, ErrInvalidInput
+		}
+		day.Ordinal = n
+	}
+	return day, nil
+}
+
+func (d RuleDay) String() string {
+	code := strings.ToUpper(d.Weekday.String()[:2])
+	if d.Ordinal != 0 {
+		return strconv.Itoa(d.Ordinal) + code
+	}
+	return code
+}
+
+// String renders the rule in canonical form, so equivalent rules compare equal.
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// maxRecurrencePeriods bounds the search for the next occurrence, so a rule
+// that rarely matches, like the fifth Friday every twelve months, ends.
+const maxRecurrencePeriods = 1000
+
+// Next returns the first occurrence strictly after prev, keeping prev's time
+// of day and location, or the zero time if there is none. prev must itself
+// be an occurrence; it anchors the interval and, without BYDAY, the day of
+// the week, month or year. COUNT and UNTIL are not checked, see Includes.
+func (r *RecurrenceRule) Next(prev time.Time) time.Time {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	start := r.periodStart(prev)
+	for i := 0; i <= maxRecurrencePeriods; i++ {
+		for _, candidate := range r.candidates(start, prev) {
+			if candidate.After(prev) {
+				return candidate
+			}
+		}
+		start = r.addPeriods(start, interval)
+	}
+	return time.Time{}
+}
+
+// Includes reports whether the index-th occurrence (counting from 1), falling
+// on at, is still within the rule's COUNT and UNTIL.
+func (r *RecurrenceRule) Includes(index int, at time.Time) bool {
+	if r.Count > 0 && index > r.Count {
+		return false
+	}
+	return r.Until.IsZero() || !at.After(r.Until)
+}
+
+// periodStart returns the first day of the day, week, month or year holding t.
+func (r *RecurrenceRule) periodStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch r.Freq {
+	case FreqWeekly:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case FreqMonthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case FreqYearly:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func (r *RecurrenceRule) addPeriods(start time.Time, n int) time.Time {
+	switch r.Freq {
+	case FreqWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FreqMonthly:
+		return start.AddDate(0, n, 0)
+	case FreqYearly:
+		return start.AddDate(n, 0, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// candidates lists the occurrences in the period beginning at start in
+// chronological order, at anchor's time of day. Dates that do not exist,
+// like February 30, are skipped as RFC 5545 requires.
+func (r *RecurrenceRule) candidates(start, anchor time.Time) []time.Time {
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, anchor.Hour(), anchor.Minute(), anchor.Second(), anchor.Nanosecond(), anchor.Location())
+	}
+	y, m, d := start.Date()
+	var days []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		if len(r.ByDay) == 0 || r.hasWeekday(start.Weekday()) {
+			days = append(days, at(y, m, d))
+		}
+	case FreqWeekly:
+		for i := 0; i < 7; i++ {
+			day := start.AddDate(0, 0, i)
+			if (len(r.ByDay) == 0 && day.Weekday() == anchor.Weekday()) || r.hasWeekday(day.Weekday()) {
+				days = append(days, at(day.Date()))
+			}
+		}
+	case FreqMonthly:
+		length := daysIn(y, m)
+		for i := 1; i <= length; i++ {
+			if (len(r.ByDay) == 0 && i == anchor.Day()) || r.matchesMonthDay(time.Date(y, m, i, 0, 0, 0, 0, time.UTC), length) {
+				days = append(days, at(y, m, i))
+			}
+		}
+	case FreqYearly:
+		if anchor.Day() <= daysIn(y, anchor.Month()) {
+			days = append(days, at(y, anchor.Month(), anchor.Day()))
+		}
+	}
+	return days
+}
+
+func (r *RecurrenceRule) hasWeekday(w time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == w {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay reports whether day, in a month of the given length, is
+// selected by one of the BYDAY entries.
+func (r *RecurrenceRule) matchesMonthDay(day time.Time, length int) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case d.Ordinal == 0,
+			d.Ordinal > 0 && (day.Day()-1)/7+1 == d.Ordinal,
+			d.Ordinal < 0 && (length-day.Day())/7+1 == -d.Ordinal:
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Recurrence makes a task one occurrence of a repeating series. Completing
+// an occurrence generates the next one.
+type Recurrence struct {
+	// Rule is the series' rule in canonical form, see RecurrenceRule.
+	Rule     string `bson:"rule" json:"rule"`
+	SeriesID string `bson:"series_id" json:"series_id"`
+	// Index numbers the occurrences of a series, starting at 1.
+	Index int `bson:"index" json:"index"`
+	// ScheduledAt is the date the rule gave this occurrence. Moving a single
+	// occurrence's due date leaves it alone, so the series keeps its rhythm.
+	ScheduledAt time.Time `bson:"scheduled_at" json:"scheduled_at"`
+	// Title and Description are the series' values, copied to each new
+	// occurrence. Editing a single occurrence only changes the task's own.
+	Title       string `bson:"title" json:"title"`
+	Description string `bson:"description" json:"description"`
+	// Continued is set once the next occurrence has been generated, so
+	// reopening and completing an occurrence again does not repeat it.
+	Continued bool `bson:"continued" json:"continued"`
+}
+
+// SeriesPatch is a partial update of a whole series, applied to its latest
+// occurrence and every later one. Nil fields are left unchanged; an empty
+// Rule ends the series.
+type SeriesPatch struct {
+	Title       *string
+	Description *string
+	DueDate     *time.Time
+	Rule        *string
+}
+
+// NormalizeRecurrenceRule validates a rule and returns its canonical form.
+func NormalizeRecurrenceRule(spec string) (string, error) {
+	rule, err := ParseRecurrenceRule(spec)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// NextOccurrence builds the task following t in its series, or returns false
+// when the series has ended. Occurrences that would already be due by now
+// are skipped, so a late completion does not produce a backlog of overdue
+// tasks. The copy keeps the assignee, labels and checklist, with every item
+// unchecked; status, timestamps and IDs are left to the caller.
+func (t *Task) NextOccurrence(now time.Time) (*Task, bool) {
+	if t.Recurrence == nil {
+		return nil, false
+	}
+	rule, err := ParseRecurrenceRule(t.Recurrence.Rule)
+	if err != nil {
+		return nil, false
+	}
+
+	index, at := t.Recurrence.Index, t.Recurrence.ScheduledAt
+	for {
+		at = rule.Next(at)
+		index++
+		if at.IsZero() || !rule.Includes(index, at) {
+			return nil, false
+		}
+		if at.After(now) {
+			break
+		}
+	}
+
+	next := &Task{
+		Title:       t.Recurrence.Title,
+		Description: t.Recurrence.Description,
+		DueDate:     at,
+		CreatedBy:   t.CreatedBy,
+		AssigneeID:  t.AssigneeID,
+		ProjectID:   t.ProjectID,
+		Labels:      append([]string(nil), t.Labels...),
+	}
+	for _, item := range t.Checklist {
+		next.Checklist = append(next.Checklist, ChecklistItem{ID: item.ID, Text: item.Text})
+	}
+	recurrence := *t.Recurrence
+	recurrence.Index = index
+	recurrence.ScheduledAt = at
+	recurrence.Continued = false
+	next.Recurrence = &recurrence
+	return next, true
+}
@@ -0,0 +1,97 @@
+package domain_test
+
+import (
+	domain "task-manager/Domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	canonical := map[string]string{
+		"FREQ=DAILY": "FREQ=DAILY",
+		"rrule:freq=weekly;byday=mo,th;interval=1": "FREQ=WEEKLY;BYDAY=MO,TH",
+		"FREQ=MONTHLY;BYDAY=-1FR;COUNT=6":          "FREQ=MONTHLY;BYDAY=-1FR;COUNT=6",
+		"FREQ=YEARLY;INTERVAL=2;UNTIL=20300101":    "FREQ=YEARLY;INTERVAL=2;UNTIL=20300101T235959Z",
+		"FREQ=WEEKLY;UNTIL=20300101T120000Z":       "FREQ=WEEKLY;UNTIL=20300101T120000Z",
+	}
+	for spec, want := range canonical {
+		got, err := domain.NormalizeRecurrenceRule(spec)
+		assert.NoError(t, err, spec)
+		assert.Equal(t, want, got, spec)
+	}
+
+	for _, spec := range []string{
+		"",
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;COUNT=3;UNTIL=20300101",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=DAILY;BYMONTH=1",
+		"FREQ=DAILY;UNTIL=tomorrow",
+	} {
+		_, err := domain.ParseRecurrenceRule(spec)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, spec)
+	}
+}
+
+func TestRecurrenceRule_Next(t *testing.T) {
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 30, 0, 0, time.UTC) }
+	cases := []struct {
+		rule string
+		prev time.Time
+		want []time.Time
+	}{
+		{"FREQ=DAILY;INTERVAL=3", at(2030, 1, 30), []time.Time{at(2030, 2, 2), at(2030, 2, 5)}},
+		// 2030-01-04 is a Friday
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", at(2030, 1, 4), []time.Time{at(2030, 1, 7), at(2030, 1, 8)}},
+		{"FREQ=WEEKLY", at(2030, 1, 4), []time.Time{at(2030, 1, 11)}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", at(2030, 1, 4), []time.Time{at(2030, 1, 14), at(2030, 1, 18), at(2030, 1, 28)}},
+		// Months without a 31st are skipped
+		{"FREQ=MONTHLY", at(2030, 1, 31), []time.Time{at(2030, 3, 31), at(2030, 5, 31)}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", at(2030, 1, 25), []time.Time{at(2030, 2, 22), at(2030, 3, 29)}},
+		{"FREQ=MONTHLY;BYDAY=2TU", at(2030, 1, 8), []time.Time{at(2030, 2, 12)}},
+		{"FREQ=YEARLY", at(2028, 2, 29), []time.Time{at(2032, 2, 29)}},
+	}
+	for _, tc := range cases {
+		rule, err := domain.ParseRecurrenceRule(tc.rule)
+		if !assert.NoError(t, err, tc.rule) {
+			continue
+		}
+		prev := tc.prev
+		for _, want := range tc.want {
+			prev = rule.Next(prev)
+			assert.True(t, want.Equal(prev), "%s: want %s, got %s", tc.rule, want, prev)
+		}
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	scheduled := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+	task := &domain.Task{
+		Title:      "Standup notes (late)",
+		DueDate:    scheduled.Add(2 * time.Hour),
+		CreatedBy:  "admin-1",
+		Recurrence: &domain.Recurrence{Rule: "FREQ=DAILY;COUNT=5", SeriesID: "s1", Index: 1, ScheduledAt: scheduled, Title: "Standup notes"},
+	}
+
+	next, ok := task.NextOccurrence(scheduled)
+	assert.True(t, ok)
+	assert.Equal(t, "Standup notes", next.Title)
+	assert.Equal(t, scheduled.AddDate(0, 0, 1), next.DueDate)
+	assert.Equal(t, 2, next.Recurrence.Index)
+
+	// Completed late, so the past occurrences are skipped
+	next, ok = task.NextOccurrence(scheduled.AddDate(0, 0, 2).Add(time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, scheduled.AddDate(0, 0, 3), next.DueDate)
+	assert.Equal(t, 4, next.Recurrence.Index)
+
+	_, ok = task.NextOccurrence(scheduled.AddDate(0, 0, 10))
+	assert.False(t, ok, "COUNT is exhausted")
+}
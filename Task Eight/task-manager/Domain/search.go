@@ -0,0 +1,292 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DefaultTaskSearchPageSize = 20
+	MaxTaskSearchPageSize     = 100
+	// MaxSearchTerms bounds the words, phrases and prefixes of one search.
+	MaxSearchTerms = 16
+	// SearchTitleWeight is how much more a match in the title counts than
+	// one in the description.
+	SearchTitleWeight = 3
+	// SearchSnippetLength is the rough length in bytes of a description
+	// snippet.
+	SearchSnippetLength = 160
+)
+
+// TaskSearchQuery is a full-text search over task titles and descriptions.
+// Normalize parses Text into bare words, "quoted phrases" and prefix*
+// terms. A task matches when it contains every phrase and prefix, and at
+// least one of the words unless the search has a phrase; the words then
+// only affect the ranking.
+type TaskSearchQuery struct {
+	Text string
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+	Limit  int
+
+	// Set by Normalize. Words are lowercase.
+	Terms    []string
+	Phrases  [][]string
+	Prefixes []string
+	Offset   int
+
+	// ProjectID restricts results to one project. It is set by the use
+	// case, never taken from the request.
+	ProjectID string
+	// VisibleTo restricts results to tasks created by or assigned to this
+	// user ID. It is set by the use case, never taken from the request.
+	VisibleTo string
+}
+
+// TaskSearchHit is a matching task, best matches first.
+type TaskSearchHit struct {
+	Task  Task
+	Score float64
+	// TitleHighlight and Snippet are HTML-escaped, with the matching words
+	// wrapped in <mark>.
+	TitleHighlight string
+	Snippet        string
+}
+
+type TaskSearchPage struct {
+	Hits       []TaskSearchHit
+	NextCursor string
+}
+
+// Normalize applies defaults, parses the text and rejects unsupported
+// values.
+func (q *TaskSearchQuery) Normalize() error {
+	if q.Limit < 0 {
+		return ErrInvalidInput
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultTaskSearchPageSize
+	}
+	if q.Limit > MaxTaskSearchPageSize {
+		q.Limit = MaxTaskSearchPageSize
+	}
+	q.Offset = 0
+	if q.Cursor != "" {
+		offset, err := strconv.Atoi(q.Cursor)
+		if err != nil || offset < 0 {
+			return ErrInvalidInput
+		}
+		q.Offset = offset
+	}
+	return q.parse()
+}
+
+func (q *TaskSearchQuery) parse() error {
+	q.Terms, q.Phrases, q.Prefixes = nil, nil, nil
+	rest := q.Text
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+		if rest[0] == '"' {
+			// An unterminated quote runs to the end of the text
+			var phrase string
+			phrase, rest, _ = strings.Cut(rest[1:], `"`)
+			if words := SearchTokens(phrase); len(words) > 0 {
+				q.Phrases = append(q.Phrases, words)
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+		if stem, ok := strings.CutSuffix(word, "*"); ok {
+			words := SearchTokens(stem)
+			if len(words) != 1 || utf8.RuneCountInString(words[0]) < 2 {
+				return fmt.Errorf("%w: prefix %q must be one word of at least two characters", ErrInvalidInput, word)
+			}
+			if !slices.Contains(q.Prefixes, words[0]) {
+				q.Prefixes = append(q.Prefixes, words[0])
+			}
+			continue
+		}
+		for _, w := range SearchTokens(word) {
+			if !slices.Contains(q.Terms, w) {
+				q.Terms = append(q.Terms, w)
+			}
+		}
+	}
+
+	switch n := len(q.Terms) + len(q.Phrases) + len(q.Prefixes); {
+	case n == 0:
+		return fmt.Errorf("%w: search text is empty", ErrInvalidInput)
+	case n > MaxSearchTerms:
+		return fmt.Errorf("%w: search has more than %d terms", ErrInvalidInput, MaxSearchTerms)
+	}
+	return nil
+}
+
+// Matches reports whether a task with the given title and description words
+// satisfies the search.
+func (q *TaskSearchQuery) Matches(title, description []string) bool {
+	for _, phrase := range q.Phrases {
+		if !containsPhrase(title, phrase) && !containsPhrase(description, phrase) {
+			return false
+		}
+	}
+	for _, prefix := range q.Prefixes {
+		if !slices.ContainsFunc(title, hasPrefix(prefix)) && !slices.ContainsFunc(description, hasPrefix(prefix)) {
+			return false
+		}
+	}
+	if len(q.Terms) == 0 || len(q.Phrases) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(q.Terms, func(term string) bool {
+		return slices.Contains(title, term) || slices.Contains(description, term)
+	})
+}
+
+// MatchesWord reports whether a single word is a search word or starts with
+// one of the prefixes.
+func (q *TaskSearchQuery) MatchesWord(word string) bool {
+	return slices.Contains(q.Terms, word) || slices.ContainsFunc(q.Prefixes, func(p string) bool { return strings.HasPrefix(word, p) })
+}
+
+// Highlight HTML-escapes text and wraps the words matching the search in
+// <mark>. With maxLen > 0, longer text is cut to about maxLen bytes around
+// the first match, with an ellipsis where it was cut.
+func (q *TaskSearchQuery) Highlight(text string, maxLen int) string {
+	spans := searchSpans(text)
+	words := make([]string, len(spans))
+	marked := make([]bool, len(spans))
+	for i, s := range spans {
+		words[i] = strings.ToLower(text[s.start:s.end])
+		marked[i] = q.MatchesWord(words[i])
+	}
+	for _, phrase := range q.Phrases {
+		for i := 0; i+len(phrase) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(phrase)], phrase) {
+				for j := range phrase {
+					marked[i+j] = true
+				}
+			}
+		}
+	}
+
+	// Neighbouring matches separated only by spaces share one mark
+	var marks []searchSpan
+	for i, s := range spans {
+		if !marked[i] {
+			continue
+		}
+		if n := len(marks); n > 0 && marked[i-1] && strings.TrimSpace(text[marks[n-1].end:s.start]) == "" {
+			marks[n-1].end = s.end
+			continue
+		}
+		marks = append(marks, s)
+	}
+
+	start, end := 0, len(text)
+	if maxLen > 0 && len(text) > maxLen {
+		center := 0
+		if len(marks) > 0 {
+			center = marks[0].start
+		}
+		start = max(0, center-maxLen/4)
+		end = min(len(text), start+maxLen)
+		start = max(0, end-maxLen)
+		// Cut between words
+		if i := slices.IndexFunc(spans, func(s searchSpan) bool { return s.start >= start }); start > 0 && i >= 0 {
+			start = spans[i].start
+		}
+		if i := slices.IndexFunc(spans, func(s searchSpan) bool { return s.end > end }); end < len(text) && i > 0 && spans[i-1].end > start {
+			end = spans[i-1].end
+		}
+		for start > 0 && start < len(text) && !utf8.RuneStart(text[start]) {
+			start++
+		}
+		for end < len(text) && end > start && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range marks {
+		if m.end <= start || m.start >= end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:max(m.start, pos)]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[max(m.start, pos):min(m.end, end)]))
+		b.WriteString("</mark>")
+		pos = min(m.end, end)
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// SearchTokens splits text into lowercase words, runs of letters and
+// digits, as both search backends index them.
+func SearchTokens(text string) []string {
+	spans := searchSpans(text)
+	words := make([]string, len(spans))
+	for i, s := range spans {
+		words[i] = strings.ToLower(text[s.start:s.end])
+	}
+	return words
+}
+
+// searchSpan is the byte range of a word in a text.
+type searchSpan struct {
+	start, end int
+}
+
+func searchSpans(text string) []searchSpan {
+	var spans []searchSpan
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			spans = append(spans, searchSpan{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, searchSpan{start, len(text)})
+	}
+	return spans
+}
+
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(phrase)], phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(prefix string) func(string) bool {
+	return func(word string) bool { return strings.HasPrefix(word, prefix) }
+}
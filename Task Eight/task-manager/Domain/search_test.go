@@ -0,0 +1,66 @@
+package domain_test
+
+import (
+	"fmt"
+	"strings"
+	domain "task-manager/Domain"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskSearchQuery_Parse(t *testing.T) {
+	q := domain.TaskSearchQuery{Text: `Deploy "release notes" back* deploy "`}
+	assert.NoError(t, q.Normalize())
+	assert.Equal(t, []string{"deploy"}, q.Terms)
+	assert.Equal(t, [][]string{{"release", "notes"}}, q.Phrases)
+	assert.Equal(t, []string{"back"}, q.Prefixes)
+
+	var tooMany []string
+	for i := 0; i <= domain.MaxSearchTerms; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("w%d", i))
+	}
+	for _, text := range []string{"", `"" ...`, "x*", "two-words*", strings.Join(tooMany, " ")} {
+		q := domain.TaskSearchQuery{Text: text}
+		assert.ErrorIs(t, q.Normalize(), domain.ErrInvalidInput, text)
+	}
+}
+
+func TestTaskSearchQuery_Matches(t *testing.T) {
+	title := domain.SearchTokens("Write release notes")
+	description := domain.SearchTokens("Backport the fixes first")
+
+	matches := map[string]bool{
+		"release":                 true,
+		"release missing":         true,
+		"missing":                 false,
+		`"release notes"`:         true,
+		`"notes release"`:         false,
+		`"release notes" missing`: true,
+		"backp*":                  true,
+		"backp* missing":          false,
+		"front*":                  false,
+	}
+	for text, want := range matches {
+		q := domain.TaskSearchQuery{Text: text}
+		assert.NoError(t, q.Normalize(), text)
+		assert.Equal(t, want, q.Matches(title, description), text)
+	}
+}
+
+func TestTaskSearchQuery_Highlight(t *testing.T) {
+	q := domain.TaskSearchQuery{Text: `"release notes" back*`}
+	assert.NoError(t, q.Normalize())
+	assert.Equal(t, "Write <mark>Release Notes</mark> &amp; <mark>backport</mark>",
+		q.Highlight("Write Release Notes & backport", 0))
+	assert.Equal(t, "Notes only", q.Highlight("Notes only", 0))
+
+	long := strings.Repeat("filler ", 30) + "backport " + strings.Repeat("más texto ", 30)
+	snippet := q.Highlight(long, 80)
+	assert.True(t, strings.HasPrefix(snippet, "…"), snippet)
+	assert.True(t, strings.HasSuffix(snippet, "…"), snippet)
+	assert.Contains(t, snippet, "<mark>backport</mark>")
+	assert.True(t, utf8.ValidString(snippet))
+	assert.LessOrEqual(t, len(snippet), 80+len("……<mark></mark>"))
+}
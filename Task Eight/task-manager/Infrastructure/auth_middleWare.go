@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	domain "task-manager/Domain"
@@ -13,41 +15,85 @@ func AuthMiddleware(authService domain.IAuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			abortWithError(c, http.StatusUnauthorized, fmt.Errorf("%w: Authorization header is required", domain.ErrUnauthorized))
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+			abortWithError(c, http.StatusUnauthorized, fmt.Errorf("%w: invalid token format", domain.ErrUnauthorized))
 			return
 		}
 
-		claims, err := authService.ValidateToken(tokenString)
+		claims, err := authService.ValidateToken(c.Request.Context(), tokenString)
+		if errors.Is(err, domain.ErrUserDisabled) {
+			abortWithError(c, http.StatusForbidden, err)
+			return
+		}
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			abortWithError(c, http.StatusUnauthorized, fmt.Errorf("%w: invalid token", domain.ErrUnauthorized))
 			return
 		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("permissions", claims.Permissions)
+		c.Set("tokenID", claims.TokenID)
+		c.Set("tokenExpiresAt", claims.ExpiresAt)
 		c.Next()
 	}
 }
 
-func AdminOnly() gin.HandlerFunc {
+// RequirePermission lets the request through only when the requester's role
+// grants the permission.
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User role not found in context"})
+		if !requesterFromContext(c).Can(permission) {
+			abortWithError(c, http.StatusForbidden, fmt.Errorf("%w: %s permission required", domain.ErrForbidden, permission))
 			return
 		}
+		c.Next()
+	}
+}
 
-		if role.(domain.Role) != domain.RoleAdmin {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+// RequireProjectRole lets the request through only when the requester holds
+// at least minimum in the project named by the :pid route parameter. The
+// resolved role is stored as "projectRole" for the handlers.
+func RequireProjectRole(projects domain.IProjectUseCase, minimum domain.ProjectRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		projectRole, err := projects.Authorize(c.Request.Context(), c.Param("pid"), requesterFromContext(c), minimum)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			abortWithError(c, http.StatusNotFound, fmt.Errorf("%w: project not found", domain.ErrNotFound))
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			abortWithError(c, http.StatusForbidden, fmt.Errorf("%w: %s role required", domain.ErrForbidden, minimum))
+			return
+		case err != nil:
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
+
+		c.Set("projectRole", projectRole)
 		c.Next()
 	}
 }
+
+// requesterFromContext rebuilds the claims AuthMiddleware stored.
+func requesterFromContext(c *gin.Context) domain.Claims {
+	role, _ := c.Get("role")
+	r, _ := role.(domain.Role)
+	permissions, _ := c.Get("permissions")
+	p, _ := permissions.([]domain.Permission)
+	return domain.Claims{UserID: c.GetString("userID"), Role: r, Permissions: p}
+}
+
+// abortWithError stops the chain and attaches err for the router's error
+// handler to render. The status is set but not written, so the handler can
+// still choose the response body and headers.
+func abortWithError(c *gin.Context, status int, err error) {
+	c.Status(status)
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -1,28 +1,53 @@
 package infrastructure
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
-func createTestToken(user *domain.User) string {
-	authService := NewAuthService()
+var (
+	testUser  = &domain.User{ID: "user-1", Username: "testuser", Role: domain.RoleUser}
+	testAdmin = &domain.User{ID: "admin-1", Username: "admin", Role: domain.RoleAdmin}
+)
+
+func newTestAuthService() (domain.IAuthService, *mocks.MockUserRepository, *mocks.MockTokenRepository) {
+	userRepo := new(mocks.MockUserRepository)
+	tokenRepo := new(mocks.MockTokenRepository)
+	userRepo.On("GetByID", mock.Anything, testUser.ID).Return(testUser, nil)
+	userRepo.On("GetByID", mock.Anything, testAdmin.ID).Return(testAdmin, nil)
+	tokenRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+	return NewAuthService("test-secret", 15*time.Minute, userRepo, tokenRepo, newTestRoleRepository()), userRepo, tokenRepo
+}
+
+// newTestRoleRepository serves the built-in roles.
+func newTestRoleRepository() *mocks.MockRoleRepository {
+	roleRepo := new(mocks.MockRoleRepository)
+	for _, role := range domain.BuiltinRoles() {
+		roleRepo.On("GetByName", mock.Anything, role.Name).Return(&role, nil)
+	}
+	return roleRepo
+}
+
+func createTestToken(authService domain.IAuthService, user *domain.User) string {
 	token, _ := authService.GenerateToken(user)
 	return token
 }
 
-func setupRouterForMiddlewareTest() *gin.Engine {
+func setupRouterForMiddlewareTest(authService domain.IAuthService) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
-	authService := NewAuthService()
 	adminRoutes := r.Group("/admin")
 	adminRoutes.Use(AuthMiddleware(authService))
-	adminRoutes.Use(AdminOnly())
+	adminRoutes.Use(RequirePermission(domain.PermUsersManage))
 	{
 		adminRoutes.GET("/test", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "welcome admin"})
@@ -32,7 +57,8 @@ func setupRouterForMiddlewareTest() *gin.Engine {
 }
 
 func TestMiddlewareIntegration(t *testing.T) {
-	router := setupRouterForMiddlewareTest()
+	authService, _, _ := newTestAuthService()
+	router := setupRouterForMiddlewareTest(authService)
 
 	t.Run("No Auth Header on Admin Route", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -42,8 +68,8 @@ func TestMiddlewareIntegration(t *testing.T) {
 	})
 
 	t.Run("Non-Admin User on Admin Route", func(t *testing.T) {
-		user := &domain.User{ID: "user-1", Username: "testuser", Role: domain.RoleUser}
-		token := createTestToken(user)
+		token := createTestToken(authService, testUser)
+
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest(http.MethodGet, "/admin/test", nil)
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -52,8 +78,8 @@ func TestMiddlewareIntegration(t *testing.T) {
 	})
 
 	t.Run("Admin User on Admin Route", func(t *testing.T) {
-		admin := &domain.User{ID: "admin-1", Username: "admin", Role: domain.RoleAdmin}
-		token := createTestToken(admin)
+		token := createTestToken(authService, testAdmin)
+
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest(http.MethodGet, "/admin/test", nil)
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -61,3 +87,203 @@ func TestMiddlewareIntegration(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 }
+
+func TestMiddlewareRevocation(t *testing.T) {
+	t.Run("Revoked Token", func(t *testing.T) {
+		userRepo := new(mocks.MockUserRepository)
+		tokenRepo := new(mocks.MockTokenRepository)
+		tokenRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string")).Return(true, nil)
+		authService := NewAuthService("test-secret", 15*time.Minute, userRepo, tokenRepo, newTestRoleRepository())
+		router := setupRouterForMiddlewareTest(authService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/admin/test", nil)
+		req.Header.Set("Authorization", "Bearer "+createTestToken(authService, testAdmin))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Stale Token Version", func(t *testing.T) {
+		userRepo := new(mocks.MockUserRepository)
+		tokenRepo := new(mocks.MockTokenRepository)
+		promoted := *testUser
+		promoted.Role = domain.RoleAdmin
+		promoted.TokenVersion = 1
+		userRepo.On("GetByID", mock.Anything, testUser.ID).Return(&promoted, nil)
+		tokenRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+		authService := NewAuthService("test-secret", 15*time.Minute, userRepo, tokenRepo, newTestRoleRepository())
+		router := setupRouterForMiddlewareTest(authService)
+
+		// Token issued before the promotion still carries version 0
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/admin/test", nil)
+		req.Header.Set("Authorization", "Bearer "+createTestToken(authService, testUser))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Disabled User", func(t *testing.T) {
+		userRepo := new(mocks.MockUserRepository)
+		tokenRepo := new(mocks.MockTokenRepository)
+		disabled := *testAdmin
+		disabled.Disabled = true
+		userRepo.On("GetByID", mock.Anything, testAdmin.ID).Return(&disabled, nil)
+		tokenRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+		authService := NewAuthService("test-secret", 15*time.Minute, userRepo, tokenRepo, newTestRoleRepository())
+		router := setupRouterForMiddlewareTest(authService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/admin/test", nil)
+		req.Header.Set("Authorization", "Bearer "+createTestToken(authService, testAdmin))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Wrong Signing Secret", func(t *testing.T) {
+		authService, _, _ := newTestAuthService()
+		other, _, _ := newTestAuthService()
+		other.(*AuthService).secret = []byte("another-secret")
+		router := setupRouterForMiddlewareTest(authService)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/admin/test", nil)
+		req.Header.Set("Authorization", "Bearer "+createTestToken(other, testAdmin))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+	userRepo := new(mocks.MockUserRepository)
+	tokenRepo := new(mocks.MockTokenRepository)
+	roleRepo := new(mocks.MockRoleRepository)
+	triager := &domain.User{ID: "user-2", Username: "triager", Role: "triager"}
+	retired := &domain.User{ID: "user-3", Username: "retired", Role: "retired"}
+	userRepo.On("GetByID", mock.Anything, triager.ID).Return(triager, nil)
+	userRepo.On("GetByID", mock.Anything, retired.ID).Return(retired, nil)
+	tokenRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+	roleRepo.On("GetByName", mock.Anything, domain.Role("triager")).
+		Return(&domain.RoleDefinition{Name: "triager", Permissions: []domain.Permission{domain.PermTasksDelete}}, nil)
+	roleRepo.On("GetByName", mock.Anything, domain.Role("retired")).Return(nil, domain.ErrNotFound)
+	authService := NewAuthService("test-secret", 15*time.Minute, userRepo, tokenRepo, roleRepo)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.DELETE("/tasks/:id", AuthMiddleware(authService), RequirePermission(domain.PermTasksDelete), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/tasks", AuthMiddleware(authService), RequirePermission(domain.PermTasksCreate), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	cases := []struct {
+		name   string
+		user   *domain.User
+		method string
+		path   string
+		status int
+	}{
+		{"Granted", triager, http.MethodDelete, "/tasks/1", http.StatusNoContent},
+		{"Not Granted", triager, http.MethodPost, "/tasks", http.StatusForbidden},
+		{"Unknown Role Grants Nothing", retired, http.MethodDelete, "/tasks/1", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+createTestToken(authService, tc.user))
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestValidateToken_CachesPermissions(t *testing.T) {
+	userRepo := new(mocks.MockUserRepository)
+	tokenRepo := new(mocks.MockTokenRepository)
+	roleRepo := newTestRoleRepository()
+	userRepo.On("GetByID", mock.Anything, testUser.ID).Return(testUser, nil)
+	tokenRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+	authService := NewAuthService("test-secret", 15*time.Minute, userRepo, tokenRepo, roleRepo)
+	token := createTestToken(authService, testUser)
+
+	for range 3 {
+		claims, err := authService.ValidateToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.True(t, claims.Can(domain.PermTasksCreate))
+		assert.False(t, claims.Can(domain.PermUsersManage))
+	}
+	roleRepo.AssertNumberOfCalls(t, "GetByName", 1)
+
+	// An edited role is looked up again on the next request
+	authService.InvalidatePermissions(domain.RoleUser)
+	_, err := authService.ValidateToken(context.Background(), token)
+	assert.NoError(t, err)
+	roleRepo.AssertNumberOfCalls(t, "GetByName", 2)
+}
+
+func TestValidateToken_InvalidationDuringLookup(t *testing.T) {
+	userRepo := new(mocks.MockUserRepository)
+	tokenRepo := new(mocks.MockTokenRepository)
+	roleRepo := new(mocks.MockRoleRepository)
+	userRepo.On("GetByID", mock.Anything, testUser.ID).Return(testUser, nil)
+	tokenRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+	authService := NewAuthService("test-secret", 15*time.Minute, userRepo, tokenRepo, roleRepo)
+	token := createTestToken(authService, testUser)
+
+	// The role is edited while the first lookup is still reading it
+	stale := domain.RoleDefinition{Name: domain.RoleUser, Permissions: []domain.Permission{domain.PermTasksCreate}}
+	edited := domain.RoleDefinition{Name: domain.RoleUser}
+	roleRepo.On("GetByName", mock.Anything, domain.RoleUser).Return(&stale, nil).Once().
+		Run(func(mock.Arguments) { authService.InvalidatePermissions(domain.RoleUser) })
+	roleRepo.On("GetByName", mock.Anything, domain.RoleUser).Return(&edited, nil)
+
+	claims, err := authService.ValidateToken(context.Background(), token)
+	assert.NoError(t, err)
+	assert.True(t, claims.Can(domain.PermTasksCreate))
+
+	// The stale read was not cached over the invalidation
+	claims, err = authService.ValidateToken(context.Background(), token)
+	assert.NoError(t, err)
+	assert.False(t, claims.Can(domain.PermTasksCreate))
+	roleRepo.AssertNumberOfCalls(t, "GetByName", 2)
+}
+
+func TestRequireProjectRole(t *testing.T) {
+	authService, _, _ := newTestAuthService()
+	projects := new(mocks.MockProjectUseCase)
+	projects.On("Authorize", mock.Anything, "p1", mock.MatchedBy(func(c domain.Claims) bool { return c.UserID == testUser.ID }), domain.ProjectEditor).
+		Return(domain.ProjectRole(""), domain.ErrForbidden)
+	projects.On("Authorize", mock.Anything, "p1", mock.MatchedBy(func(c domain.Claims) bool { return c.UserID == testAdmin.ID }), domain.ProjectEditor).
+		Return(domain.ProjectOwner, nil)
+	projects.On("Authorize", mock.Anything, "p2", mock.Anything, domain.ProjectEditor).
+		Return(domain.ProjectRole(""), domain.ErrNotFound)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	group := r.Group("/projects/:pid")
+	group.Use(AuthMiddleware(authService), RequireProjectRole(projects, domain.ProjectEditor))
+	group.POST("/tasks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"role": c.MustGet("projectRole")})
+	})
+
+	cases := []struct {
+		name   string
+		user   *domain.User
+		path   string
+		status int
+	}{
+		{"Insufficient Role", testUser, "/projects/p1/tasks", http.StatusForbidden},
+		{"Sufficient Role", testAdmin, "/projects/p1/tasks", http.StatusOK},
+		{"Not A Member", testUser, "/projects/p2/tasks", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+createTestToken(authService, tc.user))
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
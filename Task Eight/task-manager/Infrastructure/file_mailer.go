@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// FileMailer writes each message to its own .eml file in a directory, where
+// it can be opened with a mail client. File names start with the time of
+// sending, so they sort in order. It is meant for local use.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates the directory if it does not exist yet.
+func NewFileMailer(dir string, from string) (domain.IMailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send writes to a temporary file first, so readers never see a partial
+// message.
+func (m *FileMailer) Send(ctx context.Context, mail domain.Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(m.dir, ".mail-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(renderMail(m.from, mail))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	suffix := strings.TrimPrefix(filepath.Base(tmp.Name()), ".mail-")
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-" + suffix + ".eml"
+	return os.Rename(tmp.Name(), filepath.Join(m.dir, name))
+}
@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	domain "task-manager/Domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer, err := NewFileMailer(dir, "tasks@example.com")
+	assert.NoError(t, err)
+
+	for _, subject := range []string{"First", "Second"} {
+		err := mailer.Send(context.Background(), domain.Mail{To: "alice@example.com", Subject: subject, Body: "Hello alice"})
+		assert.NoError(t, err)
+	}
+
+	// One file per message, in the order they were sent, and no temporary
+	// files left behind
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		for i, subject := range []string{"First", "Second"} {
+			assert.True(t, strings.HasSuffix(entries[i].Name(), ".eml"))
+			content, err := os.ReadFile(filepath.Join(dir, entries[i].Name()))
+			assert.NoError(t, err)
+			assert.Contains(t, string(content), "To: alice@example.com\r\n")
+			assert.Contains(t, string(content), "Subject: "+subject+"\r\n")
+			assert.True(t, strings.HasSuffix(string(content), "\r\n\r\nHello alice"))
+		}
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLogMailer(log.New(&buf, "", 0))
+
+	err := mailer.Send(context.Background(), domain.Mail{To: "alice@example.com", Subject: "Reset your password", Body: "token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Mail to alice@example.com: Reset your password\ntoken\n", buf.String())
+}
@@ -1,35 +1,76 @@
 package infrastructure
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"slices"
+	"sync"
 	domain "task-manager/Domain"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("your-very-secret-key")
+// permissionCacheTTL bounds how long a role edit made by another instance
+// can take to reach this one. Edits made here invalidate the cache at once.
+const permissionCacheTTL = 30 * time.Second
 
-type AuthService struct{}
+type AuthService struct {
+	secret    []byte
+	accessTTL time.Duration
+	userRepo  domain.IUserRepository
+	tokenRepo domain.ITokenRepository
+	roleRepo  domain.IRoleRepository
 
-func NewAuthService() domain.IAuthService {
-	return &AuthService{}
+	mu          sync.Mutex
+	permissions map[domain.Role]cachedPermissions
+	// generation counts invalidations. A lookup started before one may
+	// have read the old role, so its result is not cached.
+	generation uint64
+}
+
+type cachedPermissions struct {
+	permissions []domain.Permission
+	expiresAt   time.Time
+}
+
+func NewAuthService(secret string, accessTTL time.Duration, userRepo domain.IUserRepository, tokenRepo domain.ITokenRepository, roleRepo domain.IRoleRepository) domain.IAuthService {
+	return &AuthService{
+		secret:      []byte(secret),
+		accessTTL:   accessTTL,
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		roleRepo:    roleRepo,
+		permissions: make(map[domain.Role]cachedPermissions),
+	}
 }
 
 type Claims struct {
 	UserID   string      `json:"user_id"`
 	Username string      `json:"username"`
 	Role     domain.Role `json:"role"`
+	Version  int         `json:"ver"`
 	jwt.RegisteredClaims
 }
 
 func (s *AuthService) GenerateToken(user *domain.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+	tokenID, err := randomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	expirationTime := time.Now().Add(s.accessTTL)
 	claims := &Claims{
 		UserID:   user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		Version:  user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -37,29 +78,114 @@ func (s *AuthService) GenerateToken(user *domain.User) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(s.secret)
 }
 
-func (s *AuthService) ValidateToken(tokenString string) (*domain.Claims, error) {
+// ValidateToken checks the token signature and expiry, then makes sure it has
+// not been revoked by a logout and that the user's token version still matches.
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*domain.Claims, error) {
 	claims := &Claims{}
+
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return jwtSecret, nil
+		return s.secret, nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
+	if !token.Valid || claims.ID == "" || claims.ExpiresAt == nil {
 		return nil, errors.New("invalid token")
 	}
 
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, domain.ErrUnauthorized
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, domain.ErrUnauthorized
+	}
+	if user.Disabled {
+		return nil, domain.ErrUserDisabled
+	}
+	if user.TokenVersion != claims.Version {
+		return nil, domain.ErrUnauthorized
+	}
+
+	permissions, err := s.resolvePermissions(ctx, claims.Role)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.Claims{
-		UserID:   claims.UserID,
-		Username: claims.Username,
-		Role:     claims.Role,
+		UserID:       claims.UserID,
+		Username:     claims.Username,
+		Role:         claims.Role,
+		TokenID:      claims.ID,
+		TokenVersion: claims.Version,
+		ExpiresAt:    claims.ExpiresAt.Time,
+		Permissions:  permissions,
 	}, nil
 }
+
+// resolvePermissions returns the permissions the role grants, from the cache
+// when possible. A role that no longer exists grants nothing.
+func (s *AuthService) resolvePermissions(ctx context.Context, role domain.Role) ([]domain.Permission, error) {
+	s.mu.Lock()
+	cached, ok := s.permissions[role]
+	generation := s.generation
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return slices.Clone(cached.permissions), nil
+	}
+
+	var permissions []domain.Permission
+	definition, err := s.roleRepo.GetByName(ctx, role)
+	switch {
+	case err == nil:
+		permissions = definition.Permissions
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.permissions[role] = cachedPermissions{permissions: permissions, expiresAt: time.Now().Add(permissionCacheTTL)}
+	}
+	s.mu.Unlock()
+	return slices.Clone(permissions), nil
+}
+
+func (s *AuthService) InvalidatePermissions(role domain.Role) {
+	s.mu.Lock()
+	delete(s.permissions, role)
+	s.generation++
+	s.mu.Unlock()
+}
+
+// GenerateOpaqueToken returns a random URL-safe token suitable for refresh tokens.
+func (s *AuthService) GenerateOpaqueToken() (string, error) {
+	return randomString(32)
+}
+
+// HashToken returns the hex encoded SHA-256 digest under which an opaque token is stored.
+func (s *AuthService) HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	domain "task-manager/Domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "blobs")
+	store, err := NewLocalBlobStore(root)
+	assert.NoError(t, err)
+
+	n, err := store.Put(ctx, "task-1/a1", strings.NewReader("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	r, err := store.Open(ctx, "task-1/a1")
+	if assert.NoError(t, err) {
+		content, _ := io.ReadAll(r)
+		r.Close()
+		assert.Equal(t, "hello", string(content))
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(root, "task-1"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, store.Delete(ctx, "task-1/a1"))
+	_, err = store.Open(ctx, "task-1/a1")
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.NoError(t, store.Delete(ctx, "task-1/a1"))
+	_, err = os.Stat(filepath.Join(root, "task-1"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLocalBlobStore_RejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalBlobStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "../outside", "/etc/passwd", "a/../../b"} {
+		_, err := store.Put(context.Background(), key, strings.NewReader("x"))
+		assert.Equal(t, domain.ErrInvalidInput, err, key)
+	}
+}
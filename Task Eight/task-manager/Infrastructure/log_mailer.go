@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	domain "task-manager/Domain"
+)
+
+// LogMailer writes mail to the application log instead of sending it. Reset
+// links end up in the log, so it is only meant for local use.
+type LogMailer struct {
+	logger *log.Logger
+}
+
+// NewLogMailer logs through logger, or the standard logger if it is nil.
+func NewLogMailer(logger *log.Logger) domain.IMailer {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, mail domain.Mail) error {
+	m.logger.Printf("Mail to %s: %s\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
@@ -0,0 +1,30 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	domain "task-manager/Domain"
+)
+
+// LogNotifier writes notifications to the application log. It needs no
+// setup, which makes it the default channel in development.
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier logs through logger, or the standard logger if it is nil.
+func NewLogNotifier(logger *log.Logger) domain.INotifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Name() string {
+	return "log"
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	n.logger.Printf("Notification for %s (%s): %s", notification.Username, notification.UserID, notification.Subject)
+	return nil
+}
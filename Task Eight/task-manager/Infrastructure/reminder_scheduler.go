@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// ReminderScheduler sends due-date reminders in the background.
+type ReminderScheduler struct {
+	reminders domain.IReminderUseCase
+	interval  time.Duration
+}
+
+func NewReminderScheduler(reminders domain.IReminderUseCase, interval time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{reminders: reminders, interval: interval}
+}
+
+// Run sends reminders right away and then every interval until ctx is
+// cancelled. It returns once the run in progress, if any, has stopped.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	runPeriodically(ctx, s.interval, "send reminders", s.reminders.SendDueReminders)
+}
+
+// runPeriodically calls job right away and then every interval until ctx is
+// cancelled. Errors are logged as "Failed to <name>".
+func runPeriodically(ctx context.Context, interval time.Duration, name string, job func(ctx context.Context, now time.Time) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to %s: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
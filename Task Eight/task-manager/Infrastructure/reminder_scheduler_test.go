@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingReminderUseCase struct {
+	runs chan time.Time
+}
+
+func (uc *countingReminderUseCase) SendDueReminders(ctx context.Context, now time.Time) error {
+	uc.runs <- now
+	return nil
+}
+
+func TestReminderScheduler_RunsUntilCancelled(t *testing.T) {
+	reminders := &countingReminderUseCase{runs: make(chan time.Time, 10)}
+	scheduler := NewReminderScheduler(reminders, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(stopped)
+	}()
+
+	// The first run happens right away and the next one on the tick
+	for range 2 {
+		select {
+		case <-reminders.runs:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not run")
+		}
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	assert.Error(t, ctx.Err())
+}
@@ -0,0 +1,32 @@
+package infrastructure
+
+import (
+	"context"
+	domain "task-manager/Domain"
+)
+
+// SMTPNotifier emails notifications. Users without an email address are
+// skipped.
+type SMTPNotifier struct {
+	mailer domain.IMailer
+}
+
+// NewSMTPNotifier sends through the server at host:port, see NewSMTPMailer.
+func NewSMTPNotifier(host string, port int, username, password, from string) domain.INotifier {
+	return &SMTPNotifier{mailer: NewSMTPMailer(host, port, username, password, from)}
+}
+
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	if notification.Email == "" {
+		return nil
+	}
+	return n.mailer.Send(ctx, domain.Mail{
+		To:      notification.Email,
+		Subject: notification.Subject,
+		Body:    notification.Body,
+	})
+}
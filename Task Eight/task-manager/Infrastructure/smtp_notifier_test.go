@@ -0,0 +1,139 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	domain "task-manager/Domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer accepts one session and records the commands and message
+// it receives.
+type fakeSMTPServer struct {
+	listener net.Listener
+	commands []string
+	message  string
+	done     chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	s := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+// closedPort returns a local port that nothing listens on.
+func closedPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		s.commands = append(s.commands, line)
+		switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				b.WriteString(line)
+			}
+			s.message = b.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier_SendsMessage(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewSMTPNotifier("127.0.0.1", server.port(), "", "", "tasks@example.com")
+	assert.Equal(t, "smtp", notifier.Name())
+
+	err := notifier.Notify(context.Background(), domain.Notification{
+		Email:   "alice@example.com",
+		Subject: "Task due now: Ship",
+		Body:    "Hello alice",
+	})
+	assert.NoError(t, err)
+	<-server.done
+
+	assert.Contains(t, server.commands, "MAIL FROM:<tasks@example.com>")
+	assert.Contains(t, server.commands, "RCPT TO:<alice@example.com>")
+	assert.Contains(t, server.message, "To: alice@example.com\r\n")
+	assert.Contains(t, server.message, "Subject: Task due now: Ship\r\n")
+	assert.True(t, strings.HasSuffix(server.message, "\r\n\r\nHello alice\r\n"))
+}
+
+func TestSMTPNotifier_EncodesSubject(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewSMTPNotifier("127.0.0.1", server.port(), "", "", "tasks@example.com")
+
+	// A title cannot smuggle in extra headers
+	err := notifier.Notify(context.Background(), domain.Notification{
+		Email:   "alice@example.com",
+		Subject: "Task due now: x\r\nBcc: mallory@example.com",
+	})
+	assert.NoError(t, err)
+	<-server.done
+
+	assert.NotContains(t, server.message, "\r\nBcc:")
+	assert.Contains(t, server.message, "Subject: =?utf-8?q?")
+}
+
+func TestSMTPNotifier_SkipsUsersWithoutEmail(t *testing.T) {
+	// Nothing listens on the port, so any dial would fail
+	notifier := NewSMTPNotifier("127.0.0.1", closedPort(t), "", "", "tasks@example.com")
+	assert.NoError(t, notifier.Notify(context.Background(), domain.Notification{UserID: "u1"}))
+}
+
+func TestSMTPNotifier_ConnectionRefused(t *testing.T) {
+	notifier := NewSMTPNotifier("127.0.0.1", closedPort(t), "", "", "tasks@example.com")
+	assert.Error(t, notifier.Notify(context.Background(), domain.Notification{Email: "alice@example.com"}))
+}
@@ -0,0 +1,111 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	domain "task-manager/Domain"
+	"time"
+)
+
+const (
+	// totpPeriod and the SHA-1 digest are the RFC 6238 defaults, the only
+	// settings every authenticator app supports.
+	totpPeriod = 30 * time.Second
+	// totpSkew is the number of steps a code may be off by, to allow for
+	// clock drift and slow typing.
+	totpSkew     = 1
+	secretLength = 20
+	// Recovery codes are two groups of five base32 characters, 50 bits.
+	recoveryCodeGroup = 5
+)
+
+var (
+	secretEncoding       = base32.StdEncoding.WithPadding(base32.NoPadding)
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+type TOTPService struct {
+	// issuer names the service in authenticator apps.
+	issuer string
+}
+
+func NewTOTPService(issuer string) domain.ITOTPService {
+	return &TOTPService{issuer: issuer}
+}
+
+func (s *TOTPService) GenerateSecret() (string, error) {
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(b), nil
+}
+
+func (s *TOTPService) URI(secret, account string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", s.issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(domain.TOTPDigits))
+	q.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + s.issuer + ":" + account,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+func (s *TOTPService) Validate(secret, code string, t time.Time) (int64, bool) {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || !domain.IsTOTPCode(code) {
+		return 0, false
+	}
+	step := t.Unix() / int64(totpPeriod.Seconds())
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step+i)), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+	return 0, false
+}
+
+func (s *TOTPService) GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	b := make([]byte, 2*recoveryCodeGroup)
+	for i := range codes {
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		for j := range b {
+			b[j] = recoveryCodeAlphabet[int(b[j])%len(recoveryCodeAlphabet)]
+		}
+		codes[i] = string(b[:recoveryCodeGroup]) + "-" + string(b[recoveryCodeGroup:])
+	}
+	return codes, nil
+}
+
+// totpCode computes the HOTP value of RFC 4226 for the time step.
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	mod := uint32(1)
+	for range domain.TOTPDigits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", domain.TOTPDigits, value%mod)
+}
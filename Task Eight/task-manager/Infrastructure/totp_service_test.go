@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"net/url"
+	domain "task-manager/Domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA-1 test key of RFC 6238, "12345678901234567890".
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPService_Validate(t *testing.T) {
+	totp := NewTOTPService("Task Manager")
+
+	// The RFC's 8-digit values, cut to the last six digits
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, v := range vectors {
+		step, ok := totp.Validate(rfc6238Secret, v.code, time.Unix(v.unix, 0))
+		assert.True(t, ok, v.code)
+		assert.Equal(t, v.unix/30, step, v.code)
+	}
+
+	// One step of drift is allowed either way, two are not
+	step, ok := totp.Validate(rfc6238Secret, "287082", time.Unix(59+30, 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+	_, ok = totp.Validate(rfc6238Secret, "287082", time.Unix(59+60, 0))
+	assert.False(t, ok)
+
+	for _, code := range []string{"287083", "28708", "abcdef", ""} {
+		_, ok := totp.Validate(rfc6238Secret, code, time.Unix(59, 0))
+		assert.False(t, ok, code)
+	}
+	_, ok = totp.Validate("not base32!", "287082", time.Unix(59, 0))
+	assert.False(t, ok)
+}
+
+func TestTOTPService_Secret(t *testing.T) {
+	totp := NewTOTPService("Task Manager")
+
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+	other, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+
+	uri, err := url.Parse(totp.URI(secret, "alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Task Manager:alice", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Task Manager", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+}
+
+func TestTOTPService_GenerateRecoveryCodes(t *testing.T) {
+	codes, err := NewTOTPService("Task Manager").GenerateRecoveryCodes(domain.RecoveryCodeCount)
+	assert.NoError(t, err)
+	assert.Len(t, codes, domain.RecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, code)
+		assert.False(t, domain.IsTOTPCode(code))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// WebhookDispatcher sends queued webhook deliveries in the background.
+type WebhookDispatcher struct {
+	webhooks domain.IWebhookUseCase
+	interval time.Duration
+}
+
+func NewWebhookDispatcher(webhooks domain.IWebhookUseCase, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{webhooks: webhooks, interval: interval}
+}
+
+// Run sends due deliveries right away and then every interval until ctx is
+// cancelled. It returns once the run in progress, if any, has stopped.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	runPeriodically(ctx, d.interval, "deliver webhooks", d.webhooks.DeliverDue)
+}
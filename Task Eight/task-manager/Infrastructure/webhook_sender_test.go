@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	domain "task-manager/Domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDelivery() *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		ID:        "d1",
+		EventType: domain.EventTaskCreated,
+		Payload:   `{"type":"task.created"}`,
+	}
+}
+
+func TestHTTPWebhookSender_SignsPayload(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := &domain.Webhook{URL: server.URL, Secret: "0123456789abcdef"}
+	status, err := NewHTTPWebhookSender(time.Second).Send(context.Background(), webhook, newTestDelivery())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	if assert.NotNil(t, received) {
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.Equal(t, `{"type":"task.created"}`, string(body))
+		assert.Equal(t, "d1", received.Header.Get(WebhookDeliveryHeader))
+		assert.Equal(t, domain.EventTaskCreated, received.Header.Get(WebhookEventHeader))
+		timestamp := received.Header.Get(WebhookTimestampHeader)
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(t, "sha256="+SignWebhookPayload(webhook.Secret, timestamp, body), received.Header.Get(WebhookSignatureHeader))
+	}
+}
+
+func TestHTTPWebhookSender_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	status, err := NewHTTPWebhookSender(time.Second).Send(context.Background(), &domain.Webhook{URL: server.URL}, newTestDelivery())
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
+
+func TestHTTPWebhookSender_DoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			followed = true
+			return
+		}
+		http.Redirect(w, r, "/moved", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	status, err := NewHTTPWebhookSender(time.Second).Send(context.Background(), &domain.Webhook{URL: server.URL}, newTestDelivery())
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+	assert.False(t, followed)
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Computed with: printf '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		SignWebhookPayload("secret", "1700000000", []byte("{}")))
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditRepository is an append-only log of mutations. Entry IDs are
+// ObjectID hex strings, which sort in creation order and double as the
+// pagination cursor.
+type AuditRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewAuditRepository(collection *mongo.Collection, timeouts Timeouts) *AuditRepository {
+	return &AuditRepository{collection: collection, timeouts: timeouts}
+}
+
+// EnsureIndexes creates the indexes backing the history and actor lookups.
+func (r *AuditRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+func (r *AuditRepository) Record(ctx context.Context, entry domain.AuditEntry) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	entry.ID = primitive.NewObjectID().Hex()
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *AuditRepository) Find(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{}
+	if query.ActorID != "" {
+		filter["actor_id"] = query.ActorID
+	}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.EntityType != "" {
+		filter["entity_type"] = query.EntityType
+	}
+	if query.EntityID != "" {
+		filter["entity_id"] = query.EntityID
+	}
+	createdAt := bson.M{}
+	if !query.Since.IsZero() {
+		createdAt["$gte"] = query.Since
+	}
+	if !query.Until.IsZero() {
+		createdAt["$lte"] = query.Until
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+	if query.Cursor != "" {
+		filter["_id"] = bson.M{"$lt": query.Cursor}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(query.Limit + 1))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []domain.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return newAuditPage(entries, query.Limit), nil
+}
+
+// validateIDCursor rejects cursors that are not ObjectID hex strings, the
+// IDs used as cursors by the audit log and comment threads.
+func validateIDCursor(cursor string) error {
+	if cursor == "" {
+		return nil
+	}
+	if _, err := primitive.ObjectIDFromHex(cursor); err != nil {
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// newAuditPage trims a result fetched with limit+1 entries to a page.
+func newAuditPage(entries []domain.AuditEntry, limit int) *domain.AuditPage {
+	page := &domain.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = page.Entries[limit-1].ID
+	}
+	return page
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CommentRepository stores task comments. Comment IDs are ObjectID hex
+// strings, which sort in creation order and double as the thread cursor.
+type CommentRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewCommentRepository(collection *mongo.Collection, timeouts Timeouts) *CommentRepository {
+	return &CommentRepository{collection: collection, timeouts: timeouts}
+}
+
+// EnsureIndexes creates the indexes backing thread listings and reply lookups.
+func (r *CommentRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "thread_id", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+	return err
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	prepareComment(&comment)
+	if _, err := r.collection.InsertOne(ctx, comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var comment domain.Comment
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *CommentRepository) FindThreads(ctx context.Context, query domain.CommentQuery) (*domain.CommentPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	// parent_id is omitted on top-level comments, and null matches a missing field
+	filter := bson.M{"task_id": query.TaskID, "parent_id": nil}
+	if query.Cursor != "" {
+		filter["_id"] = bson.M{"$gt": query.Cursor}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(query.Limit + 1))
+	roots, err := r.find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	page := newCommentPage(roots, query.Limit)
+	if len(page.Threads) == 0 {
+		return page, nil
+	}
+	replies, err := r.find(ctx, bson.M{"thread_id": bson.M{"$in": commentThreadIDs(page)}, "parent_id": bson.M{"$ne": nil}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	attachReplies(page, replies)
+	return page, nil
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": bson.M{"body": comment.Body, "updated_at": time.Now()}}
+	var updated domain.Comment
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": comment.ID}, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	comment, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	thread, err := r.find(ctx, bson.M{"thread_id": comment.ThreadID}, options.Find())
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": commentSubtree(thread, id)}})
+	return err
+}
+
+func (r *CommentRepository) DeleteByTask(ctx context.Context, taskID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"task_id": taskID})
+	return err
+}
+
+func (r *CommentRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.Comment, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []domain.Comment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// prepareComment assigns the ID and timestamps of a new comment. A comment
+// that does not join an existing thread starts its own.
+func prepareComment(comment *domain.Comment) {
+	comment.ID = primitive.NewObjectID().Hex()
+	if comment.ThreadID == "" {
+		comment.ThreadID = comment.ID
+		comment.ParentID = ""
+	}
+	comment.CreatedAt = time.Now()
+	comment.UpdatedAt = comment.CreatedAt
+}
+
+// newCommentPage trims top-level comments fetched with limit+1 entries to a
+// page of threads without replies.
+func newCommentPage(roots []domain.Comment, limit int) *domain.CommentPage {
+	page := &domain.CommentPage{}
+	if len(roots) > limit {
+		roots = roots[:limit]
+		page.NextCursor = roots[limit-1].ID
+	}
+	page.Threads = make([]domain.CommentThread, len(roots))
+	for i, root := range roots {
+		page.Threads[i] = domain.CommentThread{Comment: root, Replies: []domain.Comment{}}
+	}
+	return page
+}
+
+func commentThreadIDs(page *domain.CommentPage) []string {
+	ids := make([]string, len(page.Threads))
+	for i, t := range page.Threads {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// attachReplies appends replies, already sorted oldest first, to their threads.
+func attachReplies(page *domain.CommentPage, replies []domain.Comment) {
+	index := make(map[string]int, len(page.Threads))
+	for i, t := range page.Threads {
+		index[t.ID] = i
+	}
+	for _, reply := range replies {
+		if i, ok := index[reply.ThreadID]; ok {
+			page.Threads[i].Replies = append(page.Threads[i].Replies, reply)
+		}
+	}
+}
+
+// commentSubtree returns rootID and the IDs of every comment of the thread
+// that replies to it, directly or further down.
+func commentSubtree(thread []domain.Comment, rootID string) []string {
+	ids := []string{rootID}
+	for i := 0; i < len(ids); i++ {
+		for _, c := range thread {
+			if c.ParentID == ids[i] {
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LabelRepository stores label definitions keyed by name.
+type LabelRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewLabelRepository(collection *mongo.Collection, timeouts Timeouts) *LabelRepository {
+	return &LabelRepository{collection: collection, timeouts: timeouts}
+}
+
+func (r *LabelRepository) Create(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	label.CreatedAt = time.Now()
+	if _, err := r.collection.InsertOne(ctx, label); err != nil {
+		// The name is the _id, so duplicates are rejected by MongoDB
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrDuplicateEntry
+		}
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (r *LabelRepository) GetAll(ctx context.Context) ([]domain.Label, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var labels []domain.Label
+	if err = cursor.All(ctx, &labels); err != nil {
+		return nil, err
+	}
+	if labels == nil {
+		return []domain.Label{}, nil
+	}
+	return labels, nil
+}
+
+func (r *LabelRepository) GetByName(ctx context.Context, name string) (*domain.Label, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var label domain.Label
+	if err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&label); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &label, nil
+}
+
+// Update changes the colour of an existing label.
+func (r *LabelRepository) Update(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated domain.Label
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": label.Name}, bson.M{"$set": bson.M{"color": label.Color}}, opts).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *LabelRepository) Delete(ctx context.Context, name string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
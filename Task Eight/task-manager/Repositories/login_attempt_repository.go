@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginAttemptRepository counts failed logins in MongoDB, one document per
+// key.
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewLoginAttemptRepository(collection *mongo.Collection, timeouts Timeouts) *LoginAttemptRepository {
+	return &LoginAttemptRepository{collection: collection, timeouts: timeouts}
+}
+
+// EnsureIndexes creates a TTL index so forgotten failures are removed by
+// MongoDB.
+func (r *LoginAttemptRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginFailures, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	// The TTL monitor only runs once a minute, so check the expiry as well
+	var failures domain.LoginFailures
+	err := r.collection.FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&failures)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &failures, nil
+}
+
+// RecordFailure counts the failure in a single update, so instances failing
+// logins for the same key at once never lose a count. An expired count
+// starts over.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginFailures, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"count": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$expires_at", at}},
+			bson.M{"$add": bson.A{"$count", 1}},
+			1,
+		}},
+		"last_failure": at,
+		"expires_at":   at.Add(window),
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var failures domain.LoginFailures
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&failures); err != nil {
+		return nil, err
+	}
+	return &failures, nil
+}
+
+func (r *LoginAttemptRepository) ReleaseFailure(ctx context.Context, key string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key, "count": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"count": -1}})
+	return err
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryAuditRepository is a thread-safe, in-process IAuditRepository.
+type MemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []domain.AuditEntry
+}
+
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+func (r *MemoryAuditRepository) Record(ctx context.Context, entry domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = primitive.NewObjectID().Hex()
+	entry.Changes = append([]domain.FieldChange{}, entry.Changes...)
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *MemoryAuditRepository) Find(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Entries are appended in ID order, so walk backwards for newest first
+	entries := []domain.AuditEntry{}
+	for i := len(r.entries) - 1; i >= 0 && len(entries) <= query.Limit; i-- {
+		e := r.entries[i]
+		if query.Cursor != "" && e.ID >= query.Cursor {
+			continue
+		}
+		if !matchesAuditQuery(e, query) {
+			continue
+		}
+		e.Changes = append([]domain.FieldChange{}, e.Changes...)
+		entries = append(entries, e)
+	}
+	return newAuditPage(entries, query.Limit), nil
+}
+
+func matchesAuditQuery(e domain.AuditEntry, query domain.AuditQuery) bool {
+	if query.ActorID != "" && e.ActorID != query.ActorID {
+		return false
+	}
+	if query.Action != "" && e.Action != query.Action {
+		return false
+	}
+	if query.EntityType != "" && e.EntityType != query.EntityType {
+		return false
+	}
+	if query.EntityID != "" && e.EntityID != query.EntityID {
+		return false
+	}
+	if !query.Since.IsZero() && e.CreatedAt.Before(query.Since) {
+		return false
+	}
+	if !query.Until.IsZero() && e.CreatedAt.After(query.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// MemoryCommentRepository is a thread-safe, in-process ICommentRepository.
+// Comments are kept in creation order, which is also ID order.
+type MemoryCommentRepository struct {
+	mu       sync.RWMutex
+	comments []domain.Comment
+}
+
+func NewMemoryCommentRepository() *MemoryCommentRepository {
+	return &MemoryCommentRepository{}
+}
+
+func (r *MemoryCommentRepository) Create(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prepareComment(&comment)
+	r.comments = append(r.comments, comment)
+	return &comment, nil
+}
+
+func (r *MemoryCommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.indexOf(id); i >= 0 {
+		comment := r.comments[i]
+		return &comment, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *MemoryCommentRepository) FindThreads(ctx context.Context, query domain.CommentQuery) (*domain.CommentPage, error) {
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roots := []domain.Comment{}
+	for _, c := range r.comments {
+		if len(roots) > query.Limit {
+			break
+		}
+		if c.TaskID == query.TaskID && c.ParentID == "" && c.ID > query.Cursor {
+			roots = append(roots, c)
+		}
+	}
+
+	page := newCommentPage(roots, query.Limit)
+	threads := commentThreadIDs(page)
+	var replies []domain.Comment
+	for _, c := range r.comments {
+		if c.ParentID != "" && slices.Contains(threads, c.ThreadID) {
+			replies = append(replies, c)
+		}
+	}
+	attachReplies(page, replies)
+	return page, nil
+}
+
+func (r *MemoryCommentRepository) Update(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(comment.ID)
+	if i < 0 {
+		return nil, domain.ErrNotFound
+	}
+	r.comments[i].Body = comment.Body
+	r.comments[i].UpdatedAt = time.Now()
+	updated := r.comments[i]
+	return &updated, nil
+}
+
+func (r *MemoryCommentRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return domain.ErrNotFound
+	}
+	threadID := r.comments[i].ThreadID
+	var thread []domain.Comment
+	for _, c := range r.comments {
+		if c.ThreadID == threadID {
+			thread = append(thread, c)
+		}
+	}
+	ids := commentSubtree(thread, id)
+	r.comments = slices.DeleteFunc(r.comments, func(c domain.Comment) bool {
+		return slices.Contains(ids, c.ID)
+	})
+	return nil
+}
+
+func (r *MemoryCommentRepository) DeleteByTask(ctx context.Context, taskID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.comments = slices.DeleteFunc(r.comments, func(c domain.Comment) bool {
+		return c.TaskID == taskID
+	})
+	return nil
+}
+
+func (r *MemoryCommentRepository) indexOf(id string) int {
+	return slices.IndexFunc(r.comments, func(c domain.Comment) bool { return c.ID == id })
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// MemoryLabelRepository is a thread-safe, in-process ILabelRepository.
+type MemoryLabelRepository struct {
+	mu     sync.RWMutex
+	labels map[string]domain.Label
+}
+
+func NewMemoryLabelRepository() *MemoryLabelRepository {
+	return &MemoryLabelRepository{labels: make(map[string]domain.Label)}
+}
+
+func (r *MemoryLabelRepository) Create(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.labels[label.Name]; exists {
+		return nil, domain.ErrDuplicateEntry
+	}
+	label.CreatedAt = time.Now()
+	r.labels[label.Name] = label
+	return &label, nil
+}
+
+func (r *MemoryLabelRepository) GetAll(ctx context.Context) ([]domain.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	labels := make([]domain.Label, 0, len(r.labels))
+	for _, l := range r.labels {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels, nil
+}
+
+func (r *MemoryLabelRepository) GetByName(ctx context.Context, name string) (*domain.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	label, ok := r.labels[name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &label, nil
+}
+
+func (r *MemoryLabelRepository) Update(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.labels[label.Name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	existing.Color = label.Color
+	r.labels[label.Name] = existing
+	return &existing, nil
+}
+
+func (r *MemoryLabelRepository) Delete(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.labels[name]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.labels, name)
+	return nil
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// minLoginSweep is the number of keys kept before expired ones are swept.
+const minLoginSweep = 1024
+
+// MemoryLoginAttemptRepository is a thread-safe, in-process
+// ILoginAttemptRepository. Counts are not shared between instances.
+type MemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	failures map[string]domain.LoginFailures
+	// sweepAt is the number of keys at which expired ones are swept, so
+	// guesses against random usernames cannot grow the map without bound.
+	sweepAt int
+}
+
+func NewMemoryLoginAttemptRepository() *MemoryLoginAttemptRepository {
+	return &MemoryLoginAttemptRepository{failures: make(map[string]domain.LoginFailures), sweepAt: minLoginSweep}
+}
+
+func (r *MemoryLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginFailures, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failures, ok := r.failures[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if !time.Now().Before(failures.ExpiresAt) {
+		delete(r.failures, key)
+		return nil, domain.ErrNotFound
+	}
+	return &failures, nil
+}
+
+func (r *MemoryLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginFailures, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failures, ok := r.failures[key]
+	if !ok || !at.Before(failures.ExpiresAt) {
+		failures = domain.LoginFailures{Key: key}
+	}
+	failures.Count++
+	failures.LastFailure = at
+	failures.ExpiresAt = at.Add(window)
+	r.failures[key] = failures
+
+	if len(r.failures) >= r.sweepAt {
+		for k, f := range r.failures {
+			if !at.Before(f.ExpiresAt) {
+				delete(r.failures, k)
+			}
+		}
+		r.sweepAt = max(2*len(r.failures), minLoginSweep)
+	}
+	return &failures, nil
+}
+
+func (r *MemoryLoginAttemptRepository) ReleaseFailure(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failures, ok := r.failures[key]
+	if !ok || failures.Count == 0 {
+		return nil
+	}
+	failures.Count--
+	r.failures[key] = failures
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.failures, key)
+	return nil
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sync"
+	domain "task-manager/Domain"
+)
+
+// MemoryMFARepository is a thread-safe, in-process IMFARepository.
+type MemoryMFARepository struct {
+	mu          sync.Mutex
+	enrollments map[string]domain.MFAEnrollment
+}
+
+func NewMemoryMFARepository() *MemoryMFARepository {
+	return &MemoryMFARepository{enrollments: make(map[string]domain.MFAEnrollment)}
+}
+
+func (r *MemoryMFARepository) Get(ctx context.Context, userID string) (*domain.MFAEnrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, ok := r.enrollments[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	enrollment.RecoveryCodes = slices.Clone(enrollment.RecoveryCodes)
+	return &enrollment, nil
+}
+
+func (r *MemoryMFARepository) Save(ctx context.Context, enrollment domain.MFAEnrollment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment.RecoveryCodes = slices.Clone(enrollment.RecoveryCodes)
+	r.enrollments[enrollment.UserID] = enrollment
+	return nil
+}
+
+func (r *MemoryMFARepository) UseStep(ctx context.Context, userID string, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, ok := r.enrollments[userID]
+	if !ok || step <= enrollment.LastStep {
+		return domain.ErrDuplicateEntry
+	}
+	enrollment.LastStep = step
+	r.enrollments[userID] = enrollment
+	return nil
+}
+
+func (r *MemoryMFARepository) UseRecoveryCode(ctx context.Context, userID string, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enrollment, ok := r.enrollments[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	i := slices.Index(enrollment.RecoveryCodes, hash)
+	if i < 0 {
+		return domain.ErrNotFound
+	}
+	enrollment.RecoveryCodes = slices.Delete(slices.Clone(enrollment.RecoveryCodes), i, i+1)
+	r.enrollments[userID] = enrollment
+	return nil
+}
+
+func (r *MemoryMFARepository) Delete(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.enrollments, userID)
+	return nil
+}
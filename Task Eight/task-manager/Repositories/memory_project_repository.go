@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryProjectRepository is a thread-safe, in-process IProjectRepository.
+type MemoryProjectRepository struct {
+	mu       sync.RWMutex
+	projects map[string]domain.Project
+}
+
+func NewMemoryProjectRepository() *MemoryProjectRepository {
+	return &MemoryProjectRepository{projects: make(map[string]domain.Project)}
+}
+
+func (r *MemoryProjectRepository) Create(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project.ID = primitive.NewObjectID().Hex()
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = project.CreatedAt
+	r.projects[project.ID] = *cloneProject(project)
+	return cloneProject(project), nil
+}
+
+func (r *MemoryProjectRepository) GetByID(ctx context.Context, id string) (*domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneProject(project), nil
+}
+
+func (r *MemoryProjectRepository) GetAll(ctx context.Context) ([]domain.Project, error) {
+	return r.find(func(domain.Project) bool { return true }), nil
+}
+
+func (r *MemoryProjectRepository) GetByMember(ctx context.Context, userID string) ([]domain.Project, error) {
+	return r.find(func(p domain.Project) bool {
+		_, ok := p.RoleOf(userID)
+		return ok
+	}), nil
+}
+
+func (r *MemoryProjectRepository) find(match func(domain.Project) bool) []domain.Project {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := []domain.Project{}
+	for _, p := range r.projects {
+		if match(p) {
+			projects = append(projects, *cloneProject(p))
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects
+}
+
+func (r *MemoryProjectRepository) Update(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.projects[project.ID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	existing.Name = project.Name
+	existing.Description = project.Description
+	existing.Members = project.Members
+	existing.UpdatedAt = time.Now()
+	r.projects[project.ID] = *cloneProject(existing)
+	return cloneProject(existing), nil
+}
+
+func (r *MemoryProjectRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.projects, id)
+	return nil
+}
+
+func cloneProject(p domain.Project) *domain.Project {
+	p.Members = append([]domain.ProjectMember{}, p.Members...)
+	return &p
+}
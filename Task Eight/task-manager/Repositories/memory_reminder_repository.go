@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// memoryReminderKey identifies a delivery. The due date is kept in UnixNano so
+// equal instants match regardless of location.
+type memoryReminderKey struct {
+	taskID, userID, channel string
+	offset                  time.Duration
+	dueDate                 int64
+}
+
+// MemoryReminderRepository is a thread-safe, in-process IReminderRepository.
+type MemoryReminderRepository struct {
+	mu         sync.Mutex
+	deliveries map[memoryReminderKey]domain.ReminderDelivery
+}
+
+func NewMemoryReminderRepository() *MemoryReminderRepository {
+	return &MemoryReminderRepository{deliveries: make(map[memoryReminderKey]domain.ReminderDelivery)}
+}
+
+func (r *MemoryReminderRepository) Claim(ctx context.Context, delivery domain.ReminderDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := newMemoryReminderKey(delivery)
+	if _, exists := r.deliveries[key]; exists {
+		return domain.ErrDuplicateEntry
+	}
+	r.deliveries[key] = delivery
+	return nil
+}
+
+func (r *MemoryReminderRepository) Release(ctx context.Context, delivery domain.ReminderDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.deliveries, newMemoryReminderKey(delivery))
+	return nil
+}
+
+func (r *MemoryReminderRepository) DeleteDueBefore(ctx context.Context, t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, d := range r.deliveries {
+		if d.DueDate.Before(t) {
+			delete(r.deliveries, key)
+		}
+	}
+	return nil
+}
+
+func newMemoryReminderKey(d domain.ReminderDelivery) memoryReminderKey {
+	return memoryReminderKey{taskID: d.TaskID, userID: d.UserID, channel: d.Channel, offset: d.Offset, dueDate: d.DueDate.UnixNano()}
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// MemoryRoleRepository is a thread-safe, in-process IRoleRepository.
+type MemoryRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[domain.Role]domain.RoleDefinition
+}
+
+func NewMemoryRoleRepository() *MemoryRoleRepository {
+	return &MemoryRoleRepository{roles: make(map[domain.Role]domain.RoleDefinition)}
+}
+
+func (r *MemoryRoleRepository) Create(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.Name]; exists {
+		return nil, domain.ErrDuplicateEntry
+	}
+	role.Permissions = slices.Clone(role.Permissions)
+	role.CreatedAt = time.Now()
+	role.UpdatedAt = role.CreatedAt
+	r.roles[role.Name] = role
+	return cloneRole(role), nil
+}
+
+func (r *MemoryRoleRepository) GetAll(ctx context.Context) ([]domain.RoleDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]domain.RoleDefinition, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, *cloneRole(role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *MemoryRoleRepository) GetByName(ctx context.Context, name domain.Role) (*domain.RoleDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneRole(role), nil
+}
+
+func (r *MemoryRoleRepository) Update(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.roles[role.Name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	existing.Description = role.Description
+	existing.Permissions = slices.Clone(role.Permissions)
+	existing.RequireMFA = role.RequireMFA
+	existing.UpdatedAt = time.Now()
+	r.roles[role.Name] = existing
+	return cloneRole(existing), nil
+}
+
+func (r *MemoryRoleRepository) Delete(ctx context.Context, name domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[name]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.roles, name)
+	return nil
+}
+
+// cloneRole copies the permissions so callers cannot alter the stored role.
+func cloneRole(role domain.RoleDefinition) *domain.RoleDefinition {
+	role.Permissions = slices.Clone(role.Permissions)
+	return &role
+}
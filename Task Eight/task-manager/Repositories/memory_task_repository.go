@@ -0,0 +1,291 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	domain "task-manager/Domain"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryTaskRepository is a thread-safe, in-process ITaskRepository. Data is
+// lost when the process exits, which makes it suited to development and demos.
+type MemoryTaskRepository struct {
+	mu    sync.RWMutex
+	tasks map[string]domain.Task
+}
+
+func NewMemoryTaskRepository() *MemoryTaskRepository {
+	return &MemoryTaskRepository{tasks: make(map[string]domain.Task)}
+}
+
+func (r *MemoryTaskRepository) Create(ctx context.Context, task domain.Task) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task.ID = primitive.NewObjectID().Hex()
+	task.Version = 1
+	task.CreatedAt = time.Now()
+	task.UpdatedAt = time.Now()
+	task.Progress = nil
+	task.Checklist = slices.Clone(task.Checklist)
+	task.Labels = slices.Clone(task.Labels)
+	task.Attachments = slices.Clone(task.Attachments)
+	task.Recurrence = cloneRecurrence(task.Recurrence)
+	r.tasks[task.ID] = task
+
+	return copyTask(task), nil
+}
+
+func (r *MemoryTaskRepository) GetAll(ctx context.Context) ([]domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(domain.Task) bool { return true }), nil
+}
+
+func (r *MemoryTaskRepository) Find(ctx context.Context, query domain.TaskQuery) (*domain.TaskPage, error) {
+	var after *domain.Task
+	if query.Cursor != "" {
+		c, err := decodeTaskCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after, err = cursorTask(c, query.SortBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	matches := r.filter(func(t domain.Task) bool { return matchesTaskQuery(t, query) })
+	r.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		c := compareTasks(matches[i], matches[j], query.SortBy)
+		if query.SortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	page := &domain.TaskPage{Tasks: []domain.Task{}, Total: int64(len(matches))}
+	for _, t := range matches {
+		if after != nil {
+			c := compareTasks(t, *after, query.SortBy)
+			if (!query.SortDesc && c <= 0) || (query.SortDesc && c >= 0) {
+				continue
+			}
+		}
+		if len(page.Tasks) == query.Limit {
+			page.NextCursor = encodeTaskCursor(page.Tasks[len(page.Tasks)-1], query.SortBy)
+			break
+		}
+		page.Tasks = append(page.Tasks, t)
+	}
+	return page, nil
+}
+
+func (r *MemoryTaskRepository) GetByID(ctx context.Context, projectID string, id string) (*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.lookup(projectID, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return copyTask(task), nil
+}
+
+func (r *MemoryTaskRepository) Update(ctx context.Context, projectID string, id string, task domain.Task) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.lookup(projectID, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if existing.Version != task.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	existing.Title = task.Title
+	existing.Description = task.Description
+	existing.DueDate = task.DueDate
+	existing.Status = task.Status
+	existing.Checklist = slices.Clone(task.Checklist)
+	existing.Labels = slices.Clone(task.Labels)
+	existing.Attachments = slices.Clone(task.Attachments)
+	existing.Recurrence = cloneRecurrence(task.Recurrence)
+	existing.Version++
+	existing.UpdatedAt = time.Now()
+	r.tasks[id] = existing
+
+	return copyTask(existing), nil
+}
+
+func (r *MemoryTaskRepository) Delete(ctx context.Context, projectID string, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.lookup(projectID, id); !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.tasks, id)
+	return nil
+}
+
+func (r *MemoryTaskRepository) Assign(ctx context.Context, projectID string, id string, assigneeID string) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.lookup(projectID, id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	task.AssigneeID = assigneeID
+	task.Version++
+	task.UpdatedAt = time.Now()
+	r.tasks[id] = task
+
+	return copyTask(task), nil
+}
+
+func (r *MemoryTaskRepository) GetChildren(ctx context.Context, projectID string, parentID string) ([]domain.Task, error) {
+	r.mu.RLock()
+	children := r.filter(func(t domain.Task) bool { return t.ProjectID == projectID && t.ParentID == parentID })
+	r.mu.RUnlock()
+
+	sort.Slice(children, func(i, j int) bool {
+		return compareTasks(children[i], children[j], domain.SortByCreatedAt) < 0
+	})
+	return children, nil
+}
+
+func (r *MemoryTaskRepository) RemoveLabelFromAll(ctx context.Context, label string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, task := range r.tasks {
+		i := slices.Index(task.Labels, label)
+		if i < 0 {
+			continue
+		}
+		task.Labels = slices.Delete(slices.Clone(task.Labels), i, i+1)
+		task.Version++
+		task.UpdatedAt = time.Now()
+		r.tasks[id] = task
+	}
+	return nil
+}
+
+// filter returns copies of the tasks accepted by keep. Callers must hold the lock.
+// lookup returns the task with the given ID if it belongs to the project.
+// Callers hold the lock.
+func (r *MemoryTaskRepository) lookup(projectID string, id string) (domain.Task, bool) {
+	task, ok := r.tasks[id]
+	return task, ok && task.ProjectID == projectID
+}
+
+func (r *MemoryTaskRepository) filter(keep func(domain.Task) bool) []domain.Task {
+	tasks := []domain.Task{}
+	for _, t := range r.tasks {
+		if keep(t) {
+			tasks = append(tasks, *copyTask(t))
+		}
+	}
+	return tasks
+}
+
+// copyTask returns a copy of a stored task that shares no memory with it.
+func copyTask(t domain.Task) *domain.Task {
+	t.Checklist = slices.Clone(t.Checklist)
+	t.Labels = slices.Clone(t.Labels)
+	t.Attachments = slices.Clone(t.Attachments)
+	t.Recurrence = cloneRecurrence(t.Recurrence)
+	return &t
+}
+
+func cloneRecurrence(r *domain.Recurrence) *domain.Recurrence {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
+}
+
+func matchesTaskQuery(t domain.Task, query domain.TaskQuery) bool {
+	if query.Status != "" && t.Status != query.Status {
+		return false
+	}
+	if !query.DueAfter.IsZero() && t.DueDate.Before(query.DueAfter) {
+		return false
+	}
+	if !query.DueBefore.IsZero() && t.DueDate.After(query.DueBefore) {
+		return false
+	}
+	if query.Title != "" && !strings.Contains(strings.ToLower(t.Title), strings.ToLower(query.Title)) {
+		return false
+	}
+	if query.ProjectID != "" && t.ProjectID != query.ProjectID {
+		return false
+	}
+	if query.VisibleTo != "" && t.CreatedBy != query.VisibleTo && t.AssigneeID != query.VisibleTo {
+		return false
+	}
+	if len(query.LabelsAny) > 0 && !slices.ContainsFunc(query.LabelsAny, func(l string) bool { return slices.Contains(t.Labels, l) }) {
+		return false
+	}
+	for _, l := range query.LabelsAll {
+		if !slices.Contains(t.Labels, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareTasks orders two tasks by the sort field, falling back to the ID.
+func compareTasks(a, b domain.Task, sortBy string) int {
+	var c int
+	switch sortBy {
+	case domain.SortByDueDate:
+		c = a.DueDate.Compare(b.DueDate)
+	case domain.SortByTitle:
+		c = strings.Compare(a.Title, b.Title)
+	case domain.SortByStatus:
+		c = strings.Compare(string(a.Status), string(b.Status))
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// cursorTask rebuilds a task holding just the sort key and ID stored in a cursor.
+func cursorTask(c *taskCursor, sortBy string) (*domain.Task, error) {
+	task := &domain.Task{ID: c.ID}
+	switch sortBy {
+	case domain.SortByTitle:
+		task.Title = c.Value
+	case domain.SortByStatus:
+		task.Status = domain.TaskStatus(c.Value)
+	default:
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+		if sortBy == domain.SortByDueDate {
+			task.DueDate = t
+		} else {
+			task.CreatedAt = t
+		}
+	}
+	return task, nil
+}
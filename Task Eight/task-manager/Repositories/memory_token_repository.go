@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"sync"
+	"time"
+)
+
+// MemoryTokenRepository is a thread-safe, in-process ITokenRepository.
+// Expired entries are dropped lazily when they are looked up.
+type MemoryTokenRepository struct {
+	mu            sync.Mutex
+	refreshTokens map[string]domain.RefreshToken
+	revokedTokens map[string]time.Time
+	resetTokens   map[string]domain.PasswordResetToken
+	challenges    map[string]domain.MFAChallenge
+}
+
+func NewMemoryTokenRepository() *MemoryTokenRepository {
+	return &MemoryTokenRepository{
+		refreshTokens: make(map[string]domain.RefreshToken),
+		revokedTokens: make(map[string]time.Time),
+		resetTokens:   make(map[string]domain.PasswordResetToken),
+		challenges:    make(map[string]domain.MFAChallenge),
+	}
+}
+
+func (r *MemoryTokenRepository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.refreshTokens[token.Hash]; exists {
+		return domain.ErrDuplicateEntry
+	}
+	r.refreshTokens[token.Hash] = token
+	return nil
+}
+
+func (r *MemoryTokenRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if time.Now().After(token.ExpiresAt) {
+		delete(r.refreshTokens, hash)
+		return nil, domain.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *MemoryTokenRepository) RevokeRefreshToken(ctx context.Context, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[hash]
+	if !ok || token.Revoked {
+		return domain.ErrNotFound
+	}
+	token.Revoked = true
+	r.refreshTokens[hash] = token
+	return nil
+}
+
+func (r *MemoryTokenRepository) DeleteRefreshToken(ctx context.Context, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.refreshTokens, hash)
+	return nil
+}
+
+func (r *MemoryTokenRepository) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.refreshTokens {
+		if token.UserID == userID {
+			delete(r.refreshTokens, hash)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTokenRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedTokens[tokenID] = expiresAt
+	return nil
+}
+
+func (r *MemoryTokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expiresAt, ok := r.revokedTokens[tokenID]
+	if !ok {
+		return false, nil
+	}
+	// The token has expired on its own, no need to remember it any longer
+	if time.Now().After(expiresAt) {
+		delete(r.revokedTokens, tokenID)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (r *MemoryTokenRepository) CreatePasswordResetToken(ctx context.Context, token domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.resetTokens[token.Hash]; exists {
+		return domain.ErrDuplicateEntry
+	}
+	r.resetTokens[token.Hash] = token
+	return nil
+}
+
+func (r *MemoryTokenRepository) ConsumePasswordResetToken(ctx context.Context, hash string) (*domain.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.resetTokens[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delete(r.resetTokens, hash)
+	if time.Now().After(token.ExpiresAt) {
+		return nil, domain.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *MemoryTokenRepository) DeleteUserPasswordResetTokens(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.resetTokens {
+		if token.UserID == userID {
+			delete(r.resetTokens, hash)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTokenRepository) CreateMFAChallenge(ctx context.Context, challenge domain.MFAChallenge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.challenges[challenge.Hash]; exists {
+		return domain.ErrDuplicateEntry
+	}
+	// Most challenges are completed, but abandoned ones are never looked up
+	// again
+	for hash, c := range r.challenges {
+		if challenge.CreatedAt.After(c.ExpiresAt) {
+			delete(r.challenges, hash)
+		}
+	}
+	r.challenges[challenge.Hash] = challenge
+	return nil
+}
+
+func (r *MemoryTokenRepository) GetMFAChallenge(ctx context.Context, hash string) (*domain.MFAChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	challenge, ok := r.challenges[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		delete(r.challenges, hash)
+		return nil, domain.ErrNotFound
+	}
+	return &challenge, nil
+}
+
+func (r *MemoryTokenRepository) DeleteMFAChallenge(ctx context.Context, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.challenges[hash]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.challenges, hash)
+	return nil
+}
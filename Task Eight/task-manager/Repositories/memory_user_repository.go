@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	domain "task-manager/Domain"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryUserRepository is a thread-safe, in-process IUserRepository.
+type MemoryUserRepository struct {
+	mu         sync.RWMutex
+	users      map[string]domain.User
+	byUsername map[string]string
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:      make(map[string]domain.User),
+		byUsername: make(map[string]string),
+	}
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user domain.User) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byUsername[user.Username]; exists {
+		return nil, domain.ErrDuplicateEntry
+	}
+
+	// Set default role if not provided
+	if user.Role == "" {
+		user.Role = domain.RoleUser
+	}
+
+	user.ID = primitive.NewObjectID().Hex()
+	r.users[user.ID] = user
+	r.byUsername[user.Username] = user.ID
+
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byUsername[username]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	user := r.users[id]
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) Promote(ctx context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.byUsername[username]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user := r.users[id]
+	user.Role = domain.RoleAdmin
+	r.users[id] = user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) Exists(ctx context.Context, username string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.byUsername[username]
+	return ok, nil
+}
+
+func (r *MemoryUserRepository) SetRole(ctx context.Context, id string, role domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user.Role = role
+	r.users[id] = user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) CountByRole(ctx context.Context, role domain.Role) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var n int64
+	for _, user := range r.users {
+		if user.Role == role {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *MemoryUserRepository) Find(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []domain.User{}
+	for _, user := range r.users {
+		if query.Role != "" && user.Role != query.Role {
+			continue
+		}
+		if query.Disabled != nil && user.Disabled != *query.Disabled {
+			continue
+		}
+		if query.Cursor != "" && user.ID <= query.Cursor {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if len(users) > query.Limit+1 {
+		users = users[:query.Limit+1]
+	}
+	return newUserPage(users, query.Limit), nil
+}
+
+func (r *MemoryUserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user.Disabled = disabled
+	r.users[id] = user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) SetPassword(ctx context.Context, id string, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user.Password = hash
+	r.users[id] = user
+
+	return nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.users, id)
+	delete(r.byUsername, user.Username)
+
+	return nil
+}
+
+func (r *MemoryUserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	user.TokenVersion++
+	r.users[id] = user
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryWebhookRepository is a thread-safe, in-process IWebhookRepository.
+type MemoryWebhookRepository struct {
+	mu       sync.RWMutex
+	webhooks map[string]domain.Webhook
+}
+
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{webhooks: make(map[string]domain.Webhook)}
+}
+
+func (r *MemoryWebhookRepository) Create(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook.ID = primitive.NewObjectID().Hex()
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+	webhook.Events = append([]string{}, webhook.Events...)
+	r.webhooks[webhook.ID] = webhook
+	return cloneWebhook(webhook), nil
+}
+
+func (r *MemoryWebhookRepository) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneWebhook(webhook), nil
+}
+
+func (r *MemoryWebhookRepository) GetAll(ctx context.Context) ([]domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]domain.Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, *cloneWebhook(w))
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (r *MemoryWebhookRepository) Update(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.webhooks[webhook.ID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	existing.URL = webhook.URL
+	existing.Secret = webhook.Secret
+	existing.Events = append([]string{}, webhook.Events...)
+	existing.Active = webhook.Active
+	existing.UpdatedAt = time.Now()
+	r.webhooks[webhook.ID] = existing
+	return cloneWebhook(existing), nil
+}
+
+func (r *MemoryWebhookRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
+
+func cloneWebhook(w domain.Webhook) *domain.Webhook {
+	w.Events = append([]string{}, w.Events...)
+	return &w
+}
+
+// MemoryWebhookDeliveryRepository is a thread-safe, in-process
+// IWebhookDeliveryRepository.
+type MemoryWebhookDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries []domain.WebhookDelivery
+}
+
+func NewMemoryWebhookDeliveryRepository() *MemoryWebhookDeliveryRepository {
+	return &MemoryWebhookDeliveryRepository{}
+}
+
+func (r *MemoryWebhookDeliveryRepository) Create(ctx context.Context, delivery domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.ID = primitive.NewObjectID().Hex()
+	delivery.CreatedAt = time.Now()
+	r.deliveries = append(r.deliveries, delivery)
+	return &delivery, nil
+}
+
+func (r *MemoryWebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.indexOf(id); i >= 0 {
+		delivery := r.deliveries[i]
+		return &delivery, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *MemoryWebhookDeliveryRepository) Update(ctx context.Context, delivery domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(delivery.ID)
+	if i < 0 {
+		return domain.ErrNotFound
+	}
+	stored := &r.deliveries[i]
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.ResponseStatus = delivery.ResponseStatus
+	stored.Error = delivery.Error
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastAttemptAt = delivery.LastAttemptAt
+	return nil
+}
+
+func (r *MemoryWebhookDeliveryRepository) Find(ctx context.Context, query domain.WebhookDeliveryQuery) (*domain.WebhookDeliveryPage, error) {
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Deliveries are appended in ID order, so walk backwards for newest first
+	deliveries := []domain.WebhookDelivery{}
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) <= query.Limit; i-- {
+		d := r.deliveries[i]
+		if d.WebhookID != query.WebhookID || (query.Cursor != "" && d.ID >= query.Cursor) {
+			continue
+		}
+		if query.Status != "" && d.Status != query.Status {
+			continue
+		}
+		deliveries = append(deliveries, d)
+	}
+	return newWebhookDeliveryPage(deliveries, query.Limit), nil
+}
+
+func (r *MemoryWebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []domain.WebhookDelivery{}
+	for _, d := range r.deliveries {
+		if d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (r *MemoryWebhookDeliveryRepository) DeleteByWebhook(ctx context.Context, webhookID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.deliveries[:0]
+	for _, d := range r.deliveries {
+		if d.WebhookID != webhookID {
+			kept = append(kept, d)
+		}
+	}
+	r.deliveries = kept
+	return nil
+}
+
+// indexOf returns the position of a delivery, or -1. Callers hold the lock.
+func (r *MemoryWebhookDeliveryRepository) indexOf(id string) int {
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MFARepository stores two-factor enrolments in MongoDB, one document per
+// user with the recovery code hashes embedded.
+type MFARepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewMFARepository(collection *mongo.Collection, timeouts Timeouts) *MFARepository {
+	return &MFARepository{collection: collection, timeouts: timeouts}
+}
+
+func (r *MFARepository) Get(ctx context.Context, userID string) (*domain.MFAEnrollment, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var enrollment domain.MFAEnrollment
+	err := r.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&enrollment)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+func (r *MFARepository) Save(ctx context.Context, enrollment domain.MFAEnrollment) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": enrollment.UserID}, enrollment, options.Replace().SetUpsert(true))
+	return err
+}
+
+// UseStep only matches while the stored step is older, so two requests
+// presenting the same code cannot both succeed.
+func (r *MFARepository) UseStep(ctx context.Context, userID string, step int64) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "last_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"last_step": step}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrDuplicateEntry
+	}
+	return nil
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID string, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "recovery_codes": hash},
+		bson.M{"$pull": bson.M{"recovery_codes": hash}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MFARepository) Delete(ctx context.Context, userID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": userID})
+	return err
+}
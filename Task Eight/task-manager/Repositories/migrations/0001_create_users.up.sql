@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id            TEXT PRIMARY KEY,
+    username      TEXT    NOT NULL,
+    password      TEXT    NOT NULL,
+    role          TEXT    NOT NULL,
+    token_version INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX users_username_key ON users (username);
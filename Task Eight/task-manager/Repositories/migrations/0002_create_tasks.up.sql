@@ -0,0 +1,17 @@
+-- Timestamps are stored as Unix nanoseconds so they sort correctly; 0 means unset.
+CREATE TABLE tasks (
+    id          TEXT PRIMARY KEY,
+    title       TEXT    NOT NULL,
+    description TEXT    NOT NULL DEFAULT '',
+    due_date    INTEGER NOT NULL DEFAULT 0,
+    status      TEXT    NOT NULL,
+    created_by  TEXT    NOT NULL DEFAULT '',
+    assignee_id TEXT    NOT NULL DEFAULT '',
+    created_at  INTEGER NOT NULL,
+    updated_at  INTEGER NOT NULL
+);
+
+CREATE INDEX tasks_created_by_idx ON tasks (created_by);
+CREATE INDEX tasks_assignee_id_idx ON tasks (assignee_id);
+CREATE INDEX tasks_status_idx ON tasks (status);
+CREATE INDEX tasks_due_date_idx ON tasks (due_date);
@@ -0,0 +1,14 @@
+CREATE TABLE refresh_tokens (
+    hash       TEXT PRIMARY KEY,
+    user_id    TEXT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    revoked    INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+
+CREATE TABLE revoked_tokens (
+    id         TEXT PRIMARY KEY,
+    expires_at INTEGER NOT NULL
+);
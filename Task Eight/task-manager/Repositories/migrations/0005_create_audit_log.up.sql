@@ -0,0 +1,13 @@
+-- Entry IDs are ObjectID hex strings, so ordering by id is creation order.
+CREATE TABLE audit_log (
+    id          TEXT PRIMARY KEY,
+    actor_id    TEXT    NOT NULL DEFAULT '',
+    action      TEXT    NOT NULL,
+    entity_type TEXT    NOT NULL,
+    entity_id   TEXT    NOT NULL,
+    changes     TEXT    NOT NULL DEFAULT '[]',
+    created_at  INTEGER NOT NULL
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+CREATE INDEX audit_log_actor_id_idx ON audit_log (actor_id);
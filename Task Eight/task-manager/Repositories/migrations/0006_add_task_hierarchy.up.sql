@@ -0,0 +1,5 @@
+-- The checklist is a JSON array of {id, text, done} objects in display order.
+ALTER TABLE tasks ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN checklist TEXT NOT NULL DEFAULT '[]';
+
+CREATE INDEX tasks_parent_id_idx ON tasks (parent_id, created_at);
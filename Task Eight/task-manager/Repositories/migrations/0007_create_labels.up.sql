@@ -0,0 +1,8 @@
+CREATE TABLE labels (
+    name       TEXT PRIMARY KEY,
+    color      TEXT    NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+-- Label names attached to a task, as a JSON array.
+ALTER TABLE tasks ADD COLUMN labels TEXT NOT NULL DEFAULT '[]';
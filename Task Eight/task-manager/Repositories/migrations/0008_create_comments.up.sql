@@ -0,0 +1,15 @@
+-- Comment IDs are ObjectID hex strings, so ordering by id is creation order.
+-- Top-level comments have an empty parent_id and are their own thread.
+CREATE TABLE comments (
+    id         TEXT PRIMARY KEY,
+    task_id    TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    thread_id  TEXT    NOT NULL,
+    parent_id  TEXT    NOT NULL DEFAULT '',
+    author_id  TEXT    NOT NULL,
+    body       TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX comments_task_id_idx ON comments (task_id, parent_id, id);
+CREATE INDEX comments_thread_id_idx ON comments (thread_id, id);
@@ -0,0 +1,2 @@
+-- Attachment metadata as a JSON array; the content lives in the blob store.
+ALTER TABLE tasks ADD COLUMN attachments TEXT NOT NULL DEFAULT '[]';
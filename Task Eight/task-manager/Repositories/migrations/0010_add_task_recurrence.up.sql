@@ -0,0 +1,2 @@
+-- Recurrence of a series occurrence as a JSON object, empty for plain tasks.
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
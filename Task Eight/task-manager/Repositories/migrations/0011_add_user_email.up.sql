@@ -0,0 +1,2 @@
+-- Optional address for notifications, empty when not given.
+ALTER TABLE users ADD COLUMN email TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+CREATE TABLE reminder_deliveries (
+    task_id   TEXT    NOT NULL,
+    user_id   TEXT    NOT NULL,
+    channel   TEXT    NOT NULL,
+    offset_ns INTEGER NOT NULL,
+    due_date  INTEGER NOT NULL,
+    sent_at   INTEGER NOT NULL,
+    PRIMARY KEY (task_id, user_id, channel, offset_ns, due_date)
+);
+
+CREATE INDEX reminder_deliveries_due_date_idx ON reminder_deliveries (due_date);
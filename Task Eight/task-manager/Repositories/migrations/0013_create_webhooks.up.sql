@@ -0,0 +1,29 @@
+CREATE TABLE webhooks (
+    id         TEXT    PRIMARY KEY,
+    url        TEXT    NOT NULL,
+    secret     TEXT    NOT NULL,
+    events     TEXT    NOT NULL,
+    active     INTEGER NOT NULL,
+    created_by TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id              TEXT    PRIMARY KEY,
+    webhook_id      TEXT    NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        TEXT    NOT NULL,
+    event_type      TEXT    NOT NULL,
+    payload         TEXT    NOT NULL,
+    status          TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL,
+    response_status INTEGER NOT NULL,
+    error           TEXT    NOT NULL,
+    next_attempt_at INTEGER NOT NULL,
+    last_attempt_at INTEGER NOT NULL,
+    replay_of       TEXT    NOT NULL,
+    created_at      INTEGER NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,4 @@
+DROP INDEX tasks_project_id_idx;
+ALTER TABLE tasks DROP COLUMN project_id;
+DROP TABLE project_members;
+DROP TABLE projects;
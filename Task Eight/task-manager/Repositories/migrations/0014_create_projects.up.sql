@@ -0,0 +1,32 @@
+CREATE TABLE projects (
+    id          TEXT    PRIMARY KEY,
+    name        TEXT    NOT NULL,
+    description TEXT    NOT NULL,
+    created_by  TEXT    NOT NULL,
+    created_at  INTEGER NOT NULL,
+    updated_at  INTEGER NOT NULL
+);
+
+CREATE TABLE project_members (
+    project_id TEXT    NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id    TEXT    NOT NULL,
+    role       TEXT    NOT NULL,
+    added_at   INTEGER NOT NULL,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX project_members_user_id_idx ON project_members (user_id);
+
+ALTER TABLE tasks ADD COLUMN project_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX tasks_project_id_idx ON tasks (project_id, created_at);
+
+-- Tasks created before projects existed move into a "Default" project that
+-- only platform admins can see until they add members to it.
+INSERT INTO projects (id, name, description, created_by, created_at, updated_at)
+SELECT lower(hex(randomblob(12))), 'Default', 'Tasks created before projects existed', '',
+       CAST(strftime('%s', 'now') AS INTEGER) * 1000000000,
+       CAST(strftime('%s', 'now') AS INTEGER) * 1000000000
+WHERE EXISTS (SELECT 1 FROM tasks);
+
+UPDATE tasks SET project_id = (SELECT id FROM projects LIMIT 1) WHERE project_id = '';
@@ -0,0 +1,8 @@
+CREATE TABLE roles (
+    name        TEXT    PRIMARY KEY,
+    description TEXT    NOT NULL,
+    -- Granted permissions, as a JSON array.
+    permissions TEXT    NOT NULL,
+    created_at  INTEGER NOT NULL,
+    updated_at  INTEGER NOT NULL
+);
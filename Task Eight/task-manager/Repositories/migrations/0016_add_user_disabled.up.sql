@@ -0,0 +1,2 @@
+-- Disabled users cannot log in; 0 or 1.
+ALTER TABLE users ADD COLUMN disabled INTEGER NOT NULL DEFAULT 0;
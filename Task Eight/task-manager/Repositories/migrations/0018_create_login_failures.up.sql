@@ -0,0 +1,7 @@
+-- Failed logins per key, a username or client IP, forgotten at expires_at.
+CREATE TABLE login_failures (
+    id           TEXT PRIMARY KEY,
+    count        INTEGER NOT NULL,
+    last_failure INTEGER NOT NULL,
+    expires_at   INTEGER NOT NULL
+);
@@ -0,0 +1,4 @@
+ALTER TABLE roles DROP COLUMN require_mfa;
+DROP TABLE mfa_challenges;
+DROP TABLE mfa_recovery_codes;
+DROP TABLE mfa_enrollments;
@@ -0,0 +1,26 @@
+-- TOTP enrolments; confirmed is 0 until the user has entered a valid code.
+CREATE TABLE mfa_enrollments (
+    user_id    TEXT    PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret     TEXT    NOT NULL,
+    confirmed  INTEGER NOT NULL,
+    last_step  INTEGER NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+-- Hashes of the unused recovery codes.
+CREATE TABLE mfa_recovery_codes (
+    user_id TEXT NOT NULL REFERENCES mfa_enrollments (user_id) ON DELETE CASCADE,
+    hash    TEXT NOT NULL,
+    PRIMARY KEY (user_id, hash)
+);
+
+-- Pending logins waiting for a second factor.
+CREATE TABLE mfa_challenges (
+    hash       TEXT PRIMARY KEY,
+    user_id    TEXT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at INTEGER NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+-- Holders of the role must use two-factor authentication; 0 or 1.
+ALTER TABLE roles ADD COLUMN require_mfa INTEGER NOT NULL DEFAULT 0;
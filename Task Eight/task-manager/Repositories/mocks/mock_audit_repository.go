@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+	"task-manager/Domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditRepository is a mock for IAuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, entry domain.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) Find(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditPage), args.Error(1)
+}
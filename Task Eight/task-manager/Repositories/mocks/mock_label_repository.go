@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"task-manager/Domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLabelRepository is a mock for ILabelRepository
+type MockLabelRepository struct {
+	mock.Mock
+}
+
+func (m *MockLabelRepository) Create(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	args := m.Called(ctx, label)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) GetAll(ctx context.Context) ([]domain.Label, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) GetByName(ctx context.Context, name string) (*domain.Label, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) Update(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	args := m.Called(ctx, label)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) Delete(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"task-manager/Domain"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLoginAttemptRepository is a mock for ILoginAttemptRepository
+type MockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginFailures, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginFailures), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginFailures, error) {
+	args := m.Called(ctx, key, at, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginFailures), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) ReleaseFailure(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
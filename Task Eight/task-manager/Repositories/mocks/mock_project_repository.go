@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"task-manager/Domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProjectRepository is a mock for IProjectRepository
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectRepository) Create(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	args := m.Called(ctx, project)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetByID(ctx context.Context, id string) (*domain.Project, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetAll(ctx context.Context) ([]domain.Project, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetByMember(ctx context.Context, userID string) ([]domain.Project, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) Update(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	args := m.Called(ctx, project)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
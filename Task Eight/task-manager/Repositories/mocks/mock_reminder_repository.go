@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"context"
+	"task-manager/Domain"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReminderRepository is a mock for IReminderRepository
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) Claim(ctx context.Context, delivery domain.ReminderDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) Release(ctx context.Context, delivery domain.ReminderDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) DeleteDueBefore(ctx context.Context, t time.Time) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
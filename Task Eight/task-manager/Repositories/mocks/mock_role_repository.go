@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"task-manager/Domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRoleRepository is a mock for IRoleRepository
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) Create(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetAll(ctx context.Context) ([]domain.RoleDefinition, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetByName(ctx context.Context, name domain.Role) (*domain.RoleDefinition, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleRepository) Update(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleRepository) Delete(ctx context.Context, name domain.Role) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
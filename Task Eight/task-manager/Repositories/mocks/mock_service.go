@@ -1,7 +1,9 @@
 package mocks
 
 import (
+	"context"
 	domain "task-manager/Domain"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -28,10 +30,110 @@ func (m *MockAuthService) GenerateToken(user *domain.User) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockAuthService) ValidateToken(tokenString string) (*domain.Claims, error) {
-	args := m.Called(tokenString)
+func (m *MockAuthService) ValidateToken(ctx context.Context, tokenString string) (*domain.Claims, error) {
+	args := m.Called(ctx, tokenString)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Claims), args.Error(1)
 }
+
+func (m *MockAuthService) InvalidatePermissions(role domain.Role) {
+	m.Called(role)
+}
+
+func (m *MockAuthService) GenerateOpaqueToken() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthService) HashToken(token string) string {
+	args := m.Called(token)
+	return args.String(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Name() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, n domain.Notification) error {
+	args := m.Called(ctx, n)
+	return args.Error(0)
+}
+
+type MockLoginThrottle struct {
+	mock.Mock
+}
+
+func (m *MockLoginThrottle) Reserve(ctx context.Context, username, clientIP string) error {
+	args := m.Called(ctx, username, clientIP)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) Release(ctx context.Context, username, clientIP string) error {
+	args := m.Called(ctx, username, clientIP)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) RecordSuccess(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+type MockTOTPService struct {
+	mock.Mock
+}
+
+func (m *MockTOTPService) GenerateSecret() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTOTPService) URI(secret, account string) string {
+	args := m.Called(secret, account)
+	return args.String(0)
+}
+
+func (m *MockTOTPService) Validate(secret, code string, t time.Time) (int64, bool) {
+	args := m.Called(secret, code, t)
+	return args.Get(0).(int64), args.Bool(1)
+}
+
+func (m *MockTOTPService) GenerateRecoveryCodes(n int) ([]string, error) {
+	args := m.Called(n)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, mail domain.Mail) error {
+	args := m.Called(ctx, mail)
+	return args.Error(0)
+}
+
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, event domain.Event) {
+	m.Called(ctx, event)
+}
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	args := m.Called(ctx, webhook, delivery)
+	return args.Int(0), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"github.com/stretchr/testify/mock"
 	"task-manager/Domain"
 )
@@ -10,39 +11,81 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) Create(task domain.Task) (*domain.Task, error) {
-	args := m.Called(task)
+func (m *MockTaskRepository) Create(ctx context.Context, task domain.Task) (*domain.Task, error) {
+	args := m.Called(ctx, task)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) GetAll() ([]domain.Task, error) {
-	args := m.Called()
+func (m *MockTaskRepository) GetAll(ctx context.Context) ([]domain.Task, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) GetByID(id string) (*domain.Task, error) {
-	args := m.Called(id)
+func (m *MockTaskRepository) Find(ctx context.Context, query domain.TaskQuery) (*domain.TaskPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetByID(ctx context.Context, projectID string, id string) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Update(ctx context.Context, projectID string, id string, task domain.Task) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, task)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) Update(id string, task domain.Task) (*domain.Task, error) {
-	args := m.Called(id, task)
+func (m *MockTaskRepository) Delete(ctx context.Context, projectID string, id string) error {
+	args := m.Called(ctx, projectID, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) Assign(ctx context.Context, projectID string, id string, assigneeID string) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, assigneeID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) Delete(id string) error {
-	args := m.Called(id)
+func (m *MockTaskRepository) RemoveLabelFromAll(ctx context.Context, label string) error {
+	args := m.Called(ctx, label)
 	return args.Error(0)
 }
+
+func (m *MockTaskRepository) GetChildren(ctx context.Context, projectID string, parentID string) ([]domain.Task, error) {
+	args := m.Called(ctx, projectID, parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Task), args.Error(1)
+}
+
+// MockTaskSearcher is a mock for ITaskSearcher
+type MockTaskSearcher struct {
+	mock.Mock
+}
+
+func (m *MockTaskSearcher) Search(ctx context.Context, query domain.TaskSearchQuery) (*domain.TaskSearchPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TaskSearchPage), args.Error(1)
+}
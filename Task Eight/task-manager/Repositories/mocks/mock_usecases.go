@@ -1,8 +1,11 @@
 package mocks
 
 import (
+	"context"
+	"io"
 	"github.com/stretchr/testify/mock"
 	"task-manager/Domain"
+	"time"
 )
 
 // MockTaskUseCase is a mock for ITaskUseCase
@@ -10,62 +13,592 @@ type MockTaskUseCase struct {
 	mock.Mock
 }
 
-func (m *MockTaskUseCase) GetAllTasks() ([]domain.Task, error) {
-	args := m.Called()
+func (m *MockTaskUseCase) GetAllTasks(ctx context.Context, projectID string, query domain.TaskQuery, requester domain.Claims) (*domain.TaskPage, error) {
+	args := m.Called(ctx, projectID, query, requester)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]domain.Task), args.Error(1)
+	return args.Get(0).(*domain.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskUseCase) SearchTasks(ctx context.Context, projectID string, query domain.TaskSearchQuery, requester domain.Claims) (*domain.TaskSearchPage, error) {
+	args := m.Called(ctx, projectID, query, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TaskSearchPage), args.Error(1)
 }
 
-func (m *MockTaskUseCase) GetTaskByID(id string) (*domain.Task, error) {
-	args := m.Called(id)
+func (m *MockTaskUseCase) GetTaskByID(ctx context.Context, projectID string, id string, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, requester)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUseCase) CreateTask(task domain.Task) (*domain.Task, error) {
-	args := m.Called(task)
+func (m *MockTaskUseCase) CreateTask(ctx context.Context, projectID string, task domain.Task, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, task, requester)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUseCase) UpdateTask(id string, task domain.Task) (*domain.Task, error) {
-	args := m.Called(id, task)
+func (m *MockTaskUseCase) UpdateTask(ctx context.Context, projectID string, id string, task domain.Task, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, task, requester)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskUseCase) DeleteTask(id string) error {
-	args := m.Called(id)
+func (m *MockTaskUseCase) DeleteTask(ctx context.Context, projectID string, id string, requester domain.Claims) error {
+	args := m.Called(ctx, projectID, id, requester)
 	return args.Error(0)
 }
 
+func (m *MockTaskUseCase) AssignTask(ctx context.Context, projectID string, id string, assigneeID string, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, assigneeID, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) PatchTask(ctx context.Context, projectID string, id string, patch domain.TaskPatch, version int64, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, patch, version, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) UpdateSeries(ctx context.Context, projectID string, id string, patch domain.SeriesPatch, version int64, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, patch, version, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) TransitionTask(ctx context.Context, projectID string, id string, status domain.TaskStatus, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, status, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) GetSubtasks(ctx context.Context, projectID string, id string, requester domain.Claims) ([]domain.Task, error) {
+	args := m.Called(ctx, projectID, id, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) AddChecklistItem(ctx context.Context, projectID string, id string, text string, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, text, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) UpdateChecklistItem(ctx context.Context, projectID string, id string, itemID string, patch domain.ChecklistItemPatch, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, itemID, patch, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) ReorderChecklist(ctx context.Context, projectID string, id string, itemIDs []string, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, itemIDs, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) RemoveChecklistItem(ctx context.Context, projectID string, id string, itemID string, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, itemID, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) AttachLabel(ctx context.Context, projectID string, id string, label string, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, label, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskUseCase) DetachLabel(ctx context.Context, projectID string, id string, label string, requester domain.Claims) (*domain.Task, error) {
+	args := m.Called(ctx, projectID, id, label, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
 // MockUserUseCase is a mock for IUserUseCase
 type MockUserUseCase struct {
 	mock.Mock
 }
 
-func (m *MockUserUseCase) Register(user domain.User) (*domain.User, error) {
-	args := m.Called(user)
+func (m *MockUserUseCase) Register(ctx context.Context, user domain.User) (*domain.User, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) Login(ctx context.Context, username, password, clientIP string) (*domain.LoginResult, error) {
+	args := m.Called(ctx, username, password, clientIP)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginResult), args.Error(1)
+}
+
+func (m *MockUserUseCase) CompleteMFALogin(ctx context.Context, challenge, code, clientIP string) (*domain.LoginResult, error) {
+	args := m.Called(ctx, challenge, code, clientIP)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginResult), args.Error(1)
+}
+
+func (m *MockUserUseCase) StartMFALoginEnrollment(ctx context.Context, challenge string) (*domain.MFASetup, error) {
+	args := m.Called(ctx, challenge)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MFASetup), args.Error(1)
+}
+
+func (m *MockUserUseCase) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+
+func (m *MockUserUseCase) Logout(ctx context.Context, requester domain.Claims, refreshToken string) error {
+	args := m.Called(ctx, requester, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) PromoteUser(ctx context.Context, username string, promoterID string) error {
+	args := m.Called(ctx, username, promoterID)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) ChangePassword(ctx context.Context, userID string, oldPassword, newPassword, clientIP string) (*domain.TokenPair, error) {
+	args := m.Called(ctx, userID, oldPassword, newPassword, clientIP)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+
+func (m *MockUserUseCase) RequestPasswordReset(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) StartMFAEnrollment(ctx context.Context, userID string) (*domain.MFASetup, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MFASetup), args.Error(1)
+}
+
+func (m *MockUserUseCase) ConfirmMFAEnrollment(ctx context.Context, userID string, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserUseCase) DisableMFA(ctx context.Context, userID string, password string) error {
+	args := m.Called(ctx, userID, password)
+	return args.Error(0)
+}
+
+func (m *MockUserUseCase) AssignRole(ctx context.Context, userID string, role domain.Role, actorID string) (*domain.User, error) {
+	args := m.Called(ctx, userID, role, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) ListUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserPage), args.Error(1)
+}
+
+func (m *MockUserUseCase) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) DemoteUser(ctx context.Context, id string, actorID string) (*domain.User, error) {
+	args := m.Called(ctx, id, actorID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserUseCase) Login(username, password string) (string, error) {
-	args := m.Called(username, password)
-	return args.String(0), args.Error(1)
+func (m *MockUserUseCase) SetUserDisabled(ctx context.Context, id string, disabled bool, actorID string) (*domain.User, error) {
+	args := m.Called(ctx, id, disabled, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserUseCase) PromoteUser(username string, promoterID string) error {
-	args := m.Called(username, promoterID)
+func (m *MockUserUseCase) DeleteUser(ctx context.Context, id string, actorID string) error {
+	args := m.Called(ctx, id, actorID)
 	return args.Error(0)
-} 
\ No newline at end of file
+}
+
+func (m *MockUserUseCase) ResetMFA(ctx context.Context, id string, actorID string) error {
+	args := m.Called(ctx, id, actorID)
+	return args.Error(0)
+}
+
+// MockAuditUseCase is a mock for IAuditUseCase
+type MockAuditUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAuditUseCase) GetTaskHistory(ctx context.Context, projectID string, taskID string, query domain.AuditQuery) (*domain.AuditPage, error) {
+	args := m.Called(ctx, projectID, taskID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditPage), args.Error(1)
+}
+
+func (m *MockAuditUseCase) ListEntries(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditPage), args.Error(1)
+}
+
+// MockLabelUseCase is a mock for ILabelUseCase
+type MockLabelUseCase struct {
+	mock.Mock
+}
+
+func (m *MockLabelUseCase) ListLabels(ctx context.Context) ([]domain.Label, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Label), args.Error(1)
+}
+
+func (m *MockLabelUseCase) CreateLabel(ctx context.Context, label domain.Label, actorID string) (*domain.Label, error) {
+	args := m.Called(ctx, label, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Label), args.Error(1)
+}
+
+func (m *MockLabelUseCase) UpdateLabel(ctx context.Context, name string, color string, actorID string) (*domain.Label, error) {
+	args := m.Called(ctx, name, color, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Label), args.Error(1)
+}
+
+func (m *MockLabelUseCase) DeleteLabel(ctx context.Context, name string, actorID string) error {
+	args := m.Called(ctx, name, actorID)
+	return args.Error(0)
+}
+
+// MockRoleUseCase is a mock for IRoleUseCase
+type MockRoleUseCase struct {
+	mock.Mock
+}
+
+func (m *MockRoleUseCase) ListRoles(ctx context.Context) ([]domain.RoleDefinition, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleUseCase) CreateRole(ctx context.Context, role domain.RoleDefinition, actorID string) (*domain.RoleDefinition, error) {
+	args := m.Called(ctx, role, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleUseCase) UpdateRole(ctx context.Context, name domain.Role, patch domain.RoleDefinitionPatch, actorID string) (*domain.RoleDefinition, error) {
+	args := m.Called(ctx, name, patch, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleUseCase) DeleteRole(ctx context.Context, name domain.Role, actorID string) error {
+	args := m.Called(ctx, name, actorID)
+	return args.Error(0)
+}
+
+func (m *MockRoleUseCase) EnsureBuiltinRoles(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// MockCommentUseCase is a mock for ICommentUseCase
+type MockCommentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCommentUseCase) ListComments(ctx context.Context, projectID string, taskID string, query domain.CommentQuery, requester domain.Claims) (*domain.CommentPage, error) {
+	args := m.Called(ctx, projectID, taskID, query, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CommentPage), args.Error(1)
+}
+
+func (m *MockCommentUseCase) AddComment(ctx context.Context, projectID string, taskID string, comment domain.Comment, requester domain.Claims) (*domain.Comment, error) {
+	args := m.Called(ctx, projectID, taskID, comment, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Comment), args.Error(1)
+}
+
+func (m *MockCommentUseCase) UpdateComment(ctx context.Context, projectID string, taskID string, commentID string, body string, requester domain.Claims) (*domain.Comment, error) {
+	args := m.Called(ctx, projectID, taskID, commentID, body, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Comment), args.Error(1)
+}
+
+func (m *MockCommentUseCase) DeleteComment(ctx context.Context, projectID string, taskID string, commentID string, requester domain.Claims) error {
+	args := m.Called(ctx, projectID, taskID, commentID, requester)
+	return args.Error(0)
+}
+
+// MockAttachmentUseCase is a mock for IAttachmentUseCase
+type MockAttachmentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentUseCase) ListAttachments(ctx context.Context, projectID string, taskID string, requester domain.Claims) ([]domain.Attachment, error) {
+	args := m.Called(ctx, projectID, taskID, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentUseCase) AddAttachment(ctx context.Context, projectID string, taskID string, upload domain.AttachmentUpload, requester domain.Claims) (*domain.Attachment, error) {
+	args := m.Called(ctx, projectID, taskID, upload, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentUseCase) OpenAttachment(ctx context.Context, projectID string, taskID string, attachmentID string, requester domain.Claims) (*domain.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, projectID, taskID, attachmentID, requester)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*domain.Attachment), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func (m *MockAttachmentUseCase) DeleteAttachment(ctx context.Context, projectID string, taskID string, attachmentID string, requester domain.Claims) error {
+	args := m.Called(ctx, projectID, taskID, attachmentID, requester)
+	return args.Error(0)
+}
+
+// MockWebhookUseCase is a mock for IWebhookUseCase
+type MockWebhookUseCase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUseCase) Publish(ctx context.Context, event domain.Event) {
+	m.Called(ctx, event)
+}
+
+func (m *MockWebhookUseCase) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) CreateWebhook(ctx context.Context, webhook domain.Webhook, actorID string) (*domain.Webhook, error) {
+	args := m.Called(ctx, webhook, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) UpdateWebhook(ctx context.Context, id string, patch domain.WebhookPatch) (*domain.Webhook, error) {
+	args := m.Called(ctx, id, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) DeleteWebhook(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUseCase) ListDeliveries(ctx context.Context, webhookID string, query domain.WebhookDeliveryQuery) (*domain.WebhookDeliveryPage, error) {
+	args := m.Called(ctx, webhookID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDeliveryPage), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) ReplayDelivery(ctx context.Context, webhookID string, deliveryID string) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) DeliverDue(ctx context.Context, now time.Time) error {
+	args := m.Called(ctx, now)
+	return args.Error(0)
+}
+
+// MockTaskStreamUseCase is a mock for ITaskStreamUseCase
+type MockTaskStreamUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTaskStreamUseCase) Publish(ctx context.Context, event domain.Event) {
+	m.Called(ctx, event)
+}
+
+func (m *MockTaskStreamUseCase) Subscribe(ctx context.Context, projectID string, requester domain.Claims, lastEventID string) (<-chan domain.StreamEvent, error) {
+	args := m.Called(ctx, projectID, requester, lastEventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(chan domain.StreamEvent), args.Error(1)
+}
+
+func (m *MockTaskStreamUseCase) Close() {
+	m.Called()
+}
+
+// MockProjectUseCase is a mock for IProjectUseCase
+type MockProjectUseCase struct {
+	mock.Mock
+}
+
+func (m *MockProjectUseCase) ListProjects(ctx context.Context, requester domain.Claims) ([]domain.Project, error) {
+	args := m.Called(ctx, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Project), args.Error(1)
+}
+
+func (m *MockProjectUseCase) GetProject(ctx context.Context, id string) (*domain.Project, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectUseCase) CreateProject(ctx context.Context, project domain.Project, creatorID string) (*domain.Project, error) {
+	args := m.Called(ctx, project, creatorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectUseCase) UpdateProject(ctx context.Context, id string, patch domain.ProjectPatch, actorID string) (*domain.Project, error) {
+	args := m.Called(ctx, id, patch, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectUseCase) DeleteProject(ctx context.Context, id string, actorID string) error {
+	args := m.Called(ctx, id, actorID)
+	return args.Error(0)
+}
+
+func (m *MockProjectUseCase) SetMember(ctx context.Context, id string, userID string, role domain.ProjectRole, actorID string) (*domain.Project, error) {
+	args := m.Called(ctx, id, userID, role, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectUseCase) RemoveMember(ctx context.Context, id string, userID string, actorID string) (*domain.Project, error) {
+	args := m.Called(ctx, id, userID, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Project), args.Error(1)
+}
+
+func (m *MockProjectUseCase) Authorize(ctx context.Context, projectID string, requester domain.Claims, minimum domain.ProjectRole) (domain.ProjectRole, error) {
+	args := m.Called(ctx, projectID, requester, minimum)
+	return args.Get(0).(domain.ProjectRole), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"github.com/stretchr/testify/mock"
 	"task-manager/Domain"
 )
@@ -10,36 +11,74 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Create(user domain.User) (*domain.User, error) {
-	args := m.Called(user)
+func (m *MockUserRepository) Create(ctx context.Context, user domain.User) (*domain.User, error) {
+	args := m.Called(ctx, user)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetByUsername(username string) (*domain.User, error) {
-	args := m.Called(username)
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetByID(id string) (*domain.User, error) {
-	args := m.Called(id)
+func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Promote(username string) error {
-	args := m.Called(username)
+func (m *MockUserRepository) Promote(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) Exists(username string) (bool, error) {
-	args := m.Called(username)
+func (m *MockUserRepository) SetRole(ctx context.Context, id string, role domain.Role) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CountByRole(ctx context.Context, role domain.Role) (int64, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) Find(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserPage), args.Error(1)
+}
+
+func (m *MockUserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	args := m.Called(ctx, id, disabled)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetPassword(ctx context.Context, id string, hash string) error {
+	args := m.Called(ctx, id, hash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Exists(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockUserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
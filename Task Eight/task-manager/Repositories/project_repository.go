@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProjectRepository stores projects with their members embedded. Project
+// IDs are ObjectID hex strings, which sort in creation order.
+type ProjectRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewProjectRepository(collection *mongo.Collection, timeouts Timeouts) *ProjectRepository {
+	return &ProjectRepository{collection: collection, timeouts: timeouts}
+}
+
+// EnsureIndexes creates the index backing the lookup of a user's projects.
+func (r *ProjectRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "members.user_id", Value: 1}},
+	})
+	return err
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	project.ID = primitive.NewObjectID().Hex()
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = project.CreatedAt
+	if project.Members == nil {
+		project.Members = []domain.ProjectMember{}
+	}
+	if _, err := r.collection.InsertOne(ctx, project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*domain.Project, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var project domain.Project
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&project); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *ProjectRepository) GetAll(ctx context.Context) ([]domain.Project, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *ProjectRepository) GetByMember(ctx context.Context, userID string) ([]domain.Project, error) {
+	return r.find(ctx, bson.M{"members.user_id": userID})
+}
+
+func (r *ProjectRepository) find(ctx context.Context, filter bson.M) ([]domain.Project, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	projects := []domain.Project{}
+	if err := cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	if project.Members == nil {
+		project.Members = []domain.ProjectMember{}
+	}
+	update := bson.M{"$set": bson.M{
+		"name":        project.Name,
+		"description": project.Description,
+		"members":     project.Members,
+		"updated_at":  time.Now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated domain.Project
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": project.ID}, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReminderRepository records sent reminders in MongoDB.
+type ReminderRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewReminderRepository(collection *mongo.Collection, timeouts Timeouts) *ReminderRepository {
+	return &ReminderRepository{collection: collection, timeouts: timeouts}
+}
+
+// EnsureIndexes creates the unique index that makes Claim atomic.
+func (r *ReminderRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "task_id", Value: 1},
+			{Key: "user_id", Value: 1},
+			{Key: "channel", Value: 1},
+			{Key: "offset", Value: 1},
+			{Key: "due_date", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *ReminderRepository) Claim(ctx context.Context, delivery domain.ReminderDelivery) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	if _, err := r.collection.InsertOne(ctx, delivery); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrDuplicateEntry
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *ReminderRepository) Release(ctx context.Context, delivery domain.ReminderDelivery) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, reminderKey(delivery))
+	return err
+}
+
+func (r *ReminderRepository) DeleteDueBefore(ctx context.Context, t time.Time) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"due_date": bson.M{"$lt": t}})
+	return err
+}
+
+func reminderKey(d domain.ReminderDelivery) bson.M {
+	return bson.M{"task_id": d.TaskID, "user_id": d.UserID, "channel": d.Channel, "offset": d.Offset, "due_date": d.DueDate}
+}
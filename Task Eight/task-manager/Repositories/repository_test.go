@@ -0,0 +1,1240 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	domain "task-manager/Domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// testProjectID is the project the shared repository tests store tasks in.
+const testProjectID = "project-1"
+
+// TaskRepositoryTestSuite exercises an ITaskRepository implementation; it is
+// run against every backend that does not need an external server.
+type TaskRepositoryTestSuite struct {
+	suite.Suite
+	newRepo func(t *testing.T) domain.ITaskRepository
+	repo    domain.ITaskRepository
+	ctx     context.Context
+}
+
+func (suite *TaskRepositoryTestSuite) SetupTest() {
+	suite.repo = suite.newRepo(suite.T())
+	suite.ctx = context.Background()
+
+	base := time.Now().Add(24 * time.Hour)
+	for i := 0; i < 5; i++ {
+		projectID := testProjectID
+		if i%2 == 1 {
+			projectID = "project-2"
+		}
+		_, err := suite.repo.Create(suite.ctx, domain.Task{
+			ProjectID: projectID,
+			Title:     fmt.Sprintf("Task %d", i),
+			Status:    "pending",
+			DueDate:   base.Add(time.Duration(5-i) * time.Hour),
+			CreatedBy: "user-1",
+		})
+		suite.Require().NoError(err)
+	}
+}
+
+func (suite *TaskRepositoryTestSuite) TestFind_PaginatesWithCursor() {
+	query := domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 2}
+
+	var titles []string
+	for {
+		page, err := suite.repo.Find(suite.ctx, query)
+		suite.Require().NoError(err)
+		assert.Equal(suite.T(), int64(5), page.Total)
+		for _, t := range page.Tasks {
+			titles = append(titles, t.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	assert.Equal(suite.T(), []string{"Task 0", "Task 1", "Task 2", "Task 3", "Task 4"}, titles)
+}
+
+func (suite *TaskRepositoryTestSuite) TestFind_SortsDescendingByDueDate() {
+	query := domain.TaskQuery{SortBy: domain.SortByDueDate, SortDesc: true, Limit: 3}
+	page, err := suite.repo.Find(suite.ctx, query)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Task 0", page.Tasks[0].Title)
+	assert.NotEmpty(suite.T(), page.NextCursor)
+
+	query.Cursor = page.NextCursor
+	page, err = suite.repo.Find(suite.ctx, query)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), page.Tasks, 2)
+	assert.Equal(suite.T(), "Task 4", page.Tasks[1].Title)
+	assert.Empty(suite.T(), page.NextCursor)
+}
+
+func (suite *TaskRepositoryTestSuite) TestFind_FiltersByProjectAndTitle() {
+	page, err := suite.repo.Find(suite.ctx, domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 10, ProjectID: "project-2"})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(2), page.Total)
+
+	page, err = suite.repo.Find(suite.ctx, domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 10, Title: "task 3"})
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), page.Tasks, 1)
+	assert.Equal(suite.T(), "Task 3", page.Tasks[0].Title)
+}
+
+func (suite *TaskRepositoryTestSuite) TestFind_FiltersByVisibleTo() {
+	page, err := suite.repo.Find(suite.ctx, domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 10, ProjectID: testProjectID})
+	suite.Require().NoError(err)
+	_, err = suite.repo.Assign(suite.ctx, testProjectID, page.Tasks[0].ID, "user-2")
+	suite.Require().NoError(err)
+	_, err = suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Own", Status: domain.StatusPending, CreatedBy: "user-2"})
+	suite.Require().NoError(err)
+
+	page, err = suite.repo.Find(suite.ctx, domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 10, ProjectID: testProjectID, VisibleTo: "user-2"})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(2), page.Total)
+	assert.Equal(suite.T(), "Own", page.Tasks[0].Title)
+	assert.Equal(suite.T(), "Task 0", page.Tasks[1].Title)
+
+	page, err = suite.repo.Find(suite.ctx, domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 10, VisibleTo: "user-3"})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), page.Tasks)
+}
+
+func (suite *TaskRepositoryTestSuite) TestFind_InvalidCursor() {
+	_, err := suite.repo.Find(suite.ctx, domain.TaskQuery{SortBy: domain.SortByTitle, Limit: 10, Cursor: "not-a-cursor"})
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+}
+
+func (suite *TaskRepositoryTestSuite) TestUpdate_ChecksVersion() {
+	created, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Versioned", Status: domain.StatusPending, CreatedBy: "user-1"})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), created.Version)
+
+	edit := *created
+	edit.Title = "First edit"
+	updated, err := suite.repo.Update(suite.ctx, testProjectID, created.ID, edit)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(2), updated.Version)
+
+	// A second writer still holding version 1 must not overwrite the first
+	edit.Title = "Second edit"
+	_, err = suite.repo.Update(suite.ctx, testProjectID, created.ID, edit)
+	assert.Equal(suite.T(), domain.ErrVersionConflict, err)
+
+	stored, err := suite.repo.GetByID(suite.ctx, testProjectID, created.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "First edit", stored.Title)
+}
+
+func (suite *TaskRepositoryTestSuite) TestGetChildren() {
+	parent, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Parent", Status: domain.StatusPending, CreatedBy: "user-1"})
+	suite.Require().NoError(err)
+	first, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "First", Status: domain.StatusPending, CreatedBy: "user-1", ParentID: parent.ID})
+	suite.Require().NoError(err)
+	second, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Second", Status: domain.StatusDone, CreatedBy: "user-1", ParentID: parent.ID})
+	suite.Require().NoError(err)
+
+	children, err := suite.repo.GetChildren(suite.ctx, testProjectID, parent.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(children, 2)
+	assert.Equal(suite.T(), first.ID, children[0].ID)
+	assert.Equal(suite.T(), second.ID, children[1].ID)
+	assert.Equal(suite.T(), parent.ID, children[0].ParentID)
+
+	none, err := suite.repo.GetChildren(suite.ctx, testProjectID, first.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), none)
+}
+
+func (suite *TaskRepositoryTestSuite) TestUpdate_StoresChecklist() {
+	created, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Checklist", Status: domain.StatusPending, CreatedBy: "user-1"})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), created.Checklist)
+
+	edit := *created
+	edit.Checklist = []domain.ChecklistItem{{ID: "a", Text: "Draft", Done: true}, {ID: "b", Text: "Review"}}
+	_, err = suite.repo.Update(suite.ctx, testProjectID, created.ID, edit)
+	suite.Require().NoError(err)
+
+	// Changing the caller's copy must not leak into storage
+	edit.Checklist[1].Done = true
+
+	stored, err := suite.repo.GetByID(suite.ctx, testProjectID, created.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []domain.ChecklistItem{{ID: "a", Text: "Draft", Done: true}, {ID: "b", Text: "Review"}}, stored.Checklist)
+}
+
+func (suite *TaskRepositoryTestSuite) TestUpdate_StoresAttachments() {
+	created, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Attachments", Status: domain.StatusPending, CreatedBy: "user-1"})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), created.Attachments)
+
+	uploadedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	edit := *created
+	edit.Attachments = []domain.Attachment{{ID: "a", Filename: "notes.txt", ContentType: "text/plain; charset=utf-8", Size: 5, UploadedBy: "user-1", CreatedAt: uploadedAt}}
+	_, err = suite.repo.Update(suite.ctx, testProjectID, created.ID, edit)
+	suite.Require().NoError(err)
+
+	stored, err := suite.repo.GetByID(suite.ctx, testProjectID, created.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(stored.Attachments, 1)
+	assert.Equal(suite.T(), "notes.txt", stored.Attachments[0].Filename)
+	assert.Equal(suite.T(), int64(5), stored.Attachments[0].Size)
+	assert.True(suite.T(), uploadedAt.Equal(stored.Attachments[0].CreatedAt))
+}
+
+func (suite *TaskRepositoryTestSuite) TestRecurrenceRoundTrip() {
+	scheduled := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	recurrence := domain.Recurrence{Rule: "FREQ=WEEKLY;BYDAY=MO", SeriesID: "s1", Index: 1, ScheduledAt: scheduled, Title: "Water plants"}
+	created, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Water plants", Status: domain.StatusPending, CreatedBy: "user-1", DueDate: scheduled, Recurrence: &recurrence})
+	suite.Require().NoError(err)
+
+	stored, err := suite.repo.GetByID(suite.ctx, testProjectID, created.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(stored.Recurrence)
+	assert.Equal(suite.T(), "s1", stored.Recurrence.SeriesID)
+	assert.True(suite.T(), scheduled.Equal(stored.Recurrence.ScheduledAt))
+
+	edit := *stored
+	edit.Recurrence = nil
+	_, err = suite.repo.Update(suite.ctx, testProjectID, created.ID, edit)
+	suite.Require().NoError(err)
+
+	stored, err = suite.repo.GetByID(suite.ctx, testProjectID, created.ID)
+	suite.Require().NoError(err)
+	assert.Nil(suite.T(), stored.Recurrence)
+}
+
+func (suite *TaskRepositoryTestSuite) TestAssignAndUnassign() {
+	page, err := suite.repo.Find(suite.ctx, domain.TaskQuery{ProjectID: testProjectID, SortBy: domain.SortByTitle, Limit: 1})
+	suite.Require().NoError(err)
+	id := page.Tasks[0].ID
+
+	task, err := suite.repo.Assign(suite.ctx, testProjectID, id, "user-3")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "user-3", task.AssigneeID)
+
+	page, err = suite.repo.Find(suite.ctx, domain.TaskQuery{ProjectID: testProjectID, VisibleTo: "user-3", SortBy: domain.SortByTitle, Limit: 10})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), page.Total)
+
+	task, err = suite.repo.Assign(suite.ctx, testProjectID, id, "")
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), task.AssigneeID)
+}
+
+func (suite *TaskRepositoryTestSuite) TestOtherProjectCannotReachTask() {
+	created, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Scoped", Status: domain.StatusPending, CreatedBy: "user-1"})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.GetByID(suite.ctx, "project-2", created.ID)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	_, err = suite.repo.Update(suite.ctx, "project-2", created.ID, *created)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	_, err = suite.repo.Assign(suite.ctx, "project-2", created.ID, "user-3")
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	assert.Equal(suite.T(), domain.ErrNotFound, suite.repo.Delete(suite.ctx, "project-2", created.ID))
+
+	stored, err := suite.repo.GetByID(suite.ctx, testProjectID, created.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), testProjectID, stored.ProjectID)
+	assert.Equal(suite.T(), created.Version, stored.Version)
+	assert.Empty(suite.T(), stored.AssigneeID)
+}
+
+func (suite *TaskRepositoryTestSuite) TestFind_FiltersByLabels() {
+	var ids []string
+	for i, labels := range [][]string{{"bug"}, {"bug", "ops"}, {"ops"}} {
+		created, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: fmt.Sprintf("Labelled %d", i), Status: domain.StatusPending, CreatedBy: "user-1", Labels: labels})
+		suite.Require().NoError(err)
+		ids = append(ids, created.ID)
+	}
+
+	page, err := suite.repo.Find(suite.ctx, domain.TaskQuery{LabelsAny: []string{"bug", "ops"}, SortBy: domain.SortByTitle, Limit: 10})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(3), page.Total)
+
+	page, err = suite.repo.Find(suite.ctx, domain.TaskQuery{LabelsAll: []string{"bug", "ops"}, SortBy: domain.SortByTitle, Limit: 10})
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Tasks, 1)
+	assert.Equal(suite.T(), ids[1], page.Tasks[0].ID)
+	assert.Equal(suite.T(), []string{"bug", "ops"}, page.Tasks[0].Labels)
+}
+
+func (suite *TaskRepositoryTestSuite) TestRemoveLabelFromAll() {
+	created, err := suite.repo.Create(suite.ctx, domain.Task{ProjectID: testProjectID, Title: "Labelled", Status: domain.StatusPending, CreatedBy: "user-1", Labels: []string{"bug", "ops"}})
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.repo.RemoveLabelFromAll(suite.ctx, "bug"))
+
+	stored, err := suite.repo.GetByID(suite.ctx, testProjectID, created.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), []string{"ops"}, stored.Labels)
+	assert.Equal(suite.T(), created.Version+1, stored.Version)
+
+	// Tasks without the label keep their version
+	page, err := suite.repo.Find(suite.ctx, domain.TaskQuery{Title: "Task 0", SortBy: domain.SortByTitle, Limit: 1})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), page.Tasks[0].Version)
+}
+
+func TestMemoryTaskRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, &TaskRepositoryTestSuite{newRepo: func(t *testing.T) domain.ITaskRepository {
+		return NewMemoryTaskRepository()
+	}})
+}
+
+func TestSQLTaskRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, &TaskRepositoryTestSuite{newRepo: func(t *testing.T) domain.ITaskRepository {
+		return NewSQLTaskRepository(newTestSQLite(t), DefaultTimeouts())
+	}})
+}
+
+// The search index must not change how the repository it wraps behaves.
+func TestTaskSearchIndexTestSuite(t *testing.T) {
+	suite.Run(t, &TaskRepositoryTestSuite{newRepo: func(t *testing.T) domain.ITaskRepository {
+		index, err := NewTaskSearchIndex(context.Background(), NewMemoryTaskRepository())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return index
+	}})
+}
+
+func TestMemoryUserRepository(t *testing.T) {
+	testUserRepository(t, NewMemoryUserRepository())
+}
+
+func TestSQLUserRepository(t *testing.T) {
+	testUserRepository(t, NewSQLUserRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func TestMemoryTokenRepository(t *testing.T) {
+	testTokenRepository(t, NewMemoryTokenRepository(), "user-1")
+}
+
+// Concurrent refreshes with the same token must rotate it out only once.
+func TestMemoryTokenRepository_ConcurrentRevoke(t *testing.T) {
+	repo := NewMemoryTokenRepository()
+	ctx := context.Background()
+	assert.NoError(t, repo.CreateRefreshToken(ctx, domain.RefreshToken{Hash: "h1", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	var wins int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if repo.RevokeRefreshToken(ctx, "h1") == nil {
+				atomic.AddInt32(&wins, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), wins)
+}
+
+func TestSQLTokenRepository(t *testing.T) {
+	db := newTestSQLite(t)
+	// refresh_tokens.user_id references users, so the owner must exist
+	user, err := NewSQLUserRepository(db, DefaultTimeouts()).Create(context.Background(), domain.User{Username: "alice", Password: "hash"})
+	assert.NoError(t, err)
+	testTokenRepository(t, NewSQLTokenRepository(db, DefaultTimeouts()), user.ID)
+}
+
+func testUserRepository(t *testing.T, repo domain.IUserRepository) {
+	ctx := context.Background()
+
+	user, err := repo.Create(ctx, domain.User{Username: "alice", Password: "hash", Email: "alice@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleUser, user.Role)
+
+	_, err = repo.Create(ctx, domain.User{Username: "alice", Password: "hash"})
+	assert.Equal(t, domain.ErrDuplicateEntry, err)
+
+	assert.NoError(t, repo.Promote(ctx, "alice"))
+	assert.NoError(t, repo.IncrementTokenVersion(ctx, user.ID))
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RoleAdmin, stored.Role)
+	assert.Equal(t, 1, stored.TokenVersion)
+	assert.Equal(t, "alice@example.com", stored.Email)
+
+	assert.NoError(t, repo.SetRole(ctx, user.ID, "triager"))
+	stored, err = repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Role("triager"), stored.Role)
+	count, err := repo.CountByRole(ctx, "triager")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = repo.CountByRole(ctx, domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	assert.Equal(t, domain.ErrNotFound, repo.SetRole(ctx, "missing", domain.RoleUser))
+
+	_, err = repo.GetByUsername(ctx, "bob")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	for _, name := range []string{"bob", "carol", "dave"} {
+		_, err := repo.Create(ctx, domain.User{Username: name, Password: "hash"})
+		assert.NoError(t, err)
+	}
+	bob, err := repo.GetByUsername(ctx, "bob")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SetDisabled(ctx, bob.ID, true))
+	assert.Equal(t, domain.ErrNotFound, repo.SetDisabled(ctx, "missing", true))
+
+	page, err := repo.Find(ctx, domain.UserQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 2)
+	assert.NotEmpty(t, page.NextCursor)
+	rest, err := repo.Find(ctx, domain.UserQuery{Cursor: page.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, rest.Users, 2)
+	assert.Empty(t, rest.NextCursor)
+	assert.Less(t, page.Users[1].ID, rest.Users[0].ID)
+
+	enabled := false
+	page, err = repo.Find(ctx, domain.UserQuery{Role: domain.RoleUser, Disabled: &enabled, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 2)
+	disabled := true
+	page, err = repo.Find(ctx, domain.UserQuery{Disabled: &disabled, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.True(t, page.Users[0].Disabled)
+	_, err = repo.Find(ctx, domain.UserQuery{Cursor: "not-a-cursor", Limit: 10})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+
+	assert.NoError(t, repo.SetPassword(ctx, bob.ID, "newhash"))
+	stored, err = repo.GetByID(ctx, bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "newhash", stored.Password)
+	assert.Equal(t, domain.ErrNotFound, repo.SetPassword(ctx, "missing", "hash"))
+
+	assert.NoError(t, repo.Delete(ctx, bob.ID))
+	assert.Equal(t, domain.ErrNotFound, repo.Delete(ctx, bob.ID))
+	_, err = repo.GetByUsername(ctx, "bob")
+	assert.Equal(t, domain.ErrNotFound, err)
+	_, err = repo.Create(ctx, domain.User{Username: "bob", Password: "hash"})
+	assert.NoError(t, err)
+}
+
+func testTokenRepository(t *testing.T, repo domain.ITokenRepository, userID string) {
+	ctx := context.Background()
+
+	token := domain.RefreshToken{Hash: "h1", UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+	assert.NoError(t, repo.CreateRefreshToken(ctx, token))
+	assert.NoError(t, repo.RevokeRefreshToken(ctx, "h1"))
+	assert.Equal(t, domain.ErrNotFound, repo.RevokeRefreshToken(ctx, "h1"))
+	assert.Equal(t, domain.ErrNotFound, repo.RevokeRefreshToken(ctx, "missing"))
+
+	stored, err := repo.GetRefreshToken(ctx, "h1")
+	assert.NoError(t, err)
+	assert.True(t, stored.Revoked)
+
+	assert.NoError(t, repo.DeleteUserRefreshTokens(ctx, userID))
+	_, err = repo.GetRefreshToken(ctx, "h1")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	assert.NoError(t, repo.RevokeAccessToken(ctx, "jti-1", time.Now().Add(time.Minute)))
+	revoked, err := repo.IsAccessTokenRevoked(ctx, "jti-1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	assert.NoError(t, repo.RevokeAccessToken(ctx, "jti-2", time.Now().Add(-time.Minute)))
+	revoked, err = repo.IsAccessTokenRevoked(ctx, "jti-2")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	now := time.Now()
+	reset := domain.PasswordResetToken{Hash: "r1", UserID: userID, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	assert.NoError(t, repo.CreatePasswordResetToken(ctx, reset))
+	assert.Equal(t, domain.ErrDuplicateEntry, repo.CreatePasswordResetToken(ctx, reset))
+	consumed, err := repo.ConsumePasswordResetToken(ctx, "r1")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, consumed.UserID)
+	assert.WithinDuration(t, reset.ExpiresAt, consumed.ExpiresAt, time.Millisecond)
+	_, err = repo.ConsumePasswordResetToken(ctx, "r1")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	expired := domain.PasswordResetToken{Hash: "r2", UserID: userID, ExpiresAt: now.Add(-time.Minute), CreatedAt: now}
+	assert.NoError(t, repo.CreatePasswordResetToken(ctx, expired))
+	_, err = repo.ConsumePasswordResetToken(ctx, "r2")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	reset.Hash = "r3"
+	assert.NoError(t, repo.CreatePasswordResetToken(ctx, reset))
+	assert.NoError(t, repo.DeleteUserPasswordResetTokens(ctx, userID))
+	_, err = repo.ConsumePasswordResetToken(ctx, "r3")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	challenge := domain.MFAChallenge{Hash: "c1", UserID: userID, ExpiresAt: now.Add(time.Minute), CreatedAt: now}
+	assert.NoError(t, repo.CreateMFAChallenge(ctx, challenge))
+	pending, err := repo.GetMFAChallenge(ctx, "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, pending.UserID)
+	assert.NoError(t, repo.DeleteMFAChallenge(ctx, "c1"))
+	assert.Equal(t, domain.ErrNotFound, repo.DeleteMFAChallenge(ctx, "c1"))
+	_, err = repo.GetMFAChallenge(ctx, "c1")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	challenge = domain.MFAChallenge{Hash: "c2", UserID: userID, ExpiresAt: now.Add(-time.Second), CreatedAt: now}
+	assert.NoError(t, repo.CreateMFAChallenge(ctx, challenge))
+	_, err = repo.GetMFAChallenge(ctx, "c2")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestMemoryMFARepository(t *testing.T) {
+	testMFARepository(t, NewMemoryMFARepository(), "user-1")
+}
+
+func TestSQLMFARepository(t *testing.T) {
+	db := newTestSQLite(t)
+	// mfa_enrollments.user_id references users, so the owner must exist
+	user, err := NewSQLUserRepository(db, DefaultTimeouts()).Create(context.Background(), domain.User{Username: "alice", Password: "hash"})
+	assert.NoError(t, err)
+	testMFARepository(t, NewSQLMFARepository(db, DefaultTimeouts()), user.ID)
+}
+
+func testMFARepository(t *testing.T, repo domain.IMFARepository, userID string) {
+	ctx := context.Background()
+
+	_, err := repo.Get(ctx, userID)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Equal(t, domain.ErrDuplicateEntry, repo.UseStep(ctx, userID, 1))
+	assert.NoError(t, repo.Delete(ctx, userID))
+
+	enrollment := domain.MFAEnrollment{UserID: userID, Secret: "SECRET", CreatedAt: time.Now()}
+	assert.NoError(t, repo.Save(ctx, enrollment))
+	stored, err := repo.Get(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "SECRET", stored.Secret)
+	assert.False(t, stored.Confirmed)
+	assert.Empty(t, stored.RecoveryCodes)
+
+	// Steps only move forward, so a code is accepted once
+	assert.NoError(t, repo.UseStep(ctx, userID, 100))
+	assert.Equal(t, domain.ErrDuplicateEntry, repo.UseStep(ctx, userID, 100))
+	assert.Equal(t, domain.ErrDuplicateEntry, repo.UseStep(ctx, userID, 99))
+	assert.NoError(t, repo.UseStep(ctx, userID, 101))
+
+	// Saving replaces the enrolment, recovery codes included
+	enrollment.Confirmed = true
+	enrollment.LastStep = 101
+	enrollment.RecoveryCodes = []string{"h1", "h2"}
+	assert.NoError(t, repo.Save(ctx, enrollment))
+	enrollment.RecoveryCodes = []string{"h3", "h4"}
+	assert.NoError(t, repo.Save(ctx, enrollment))
+	stored, err = repo.Get(ctx, userID)
+	assert.NoError(t, err)
+	assert.True(t, stored.Confirmed)
+	assert.Equal(t, int64(101), stored.LastStep)
+	assert.ElementsMatch(t, []string{"h3", "h4"}, stored.RecoveryCodes)
+
+	assert.Equal(t, domain.ErrNotFound, repo.UseRecoveryCode(ctx, userID, "h1"))
+	assert.NoError(t, repo.UseRecoveryCode(ctx, userID, "h3"))
+	assert.Equal(t, domain.ErrNotFound, repo.UseRecoveryCode(ctx, userID, "h3"))
+	stored, err = repo.Get(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"h4"}, stored.RecoveryCodes)
+
+	assert.NoError(t, repo.Delete(ctx, userID))
+	_, err = repo.Get(ctx, userID)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Equal(t, domain.ErrNotFound, repo.UseRecoveryCode(ctx, userID, "h4"))
+}
+
+func TestMemoryAuditRepository(t *testing.T) {
+	testAuditRepository(t, NewMemoryAuditRepository())
+}
+
+func TestSQLAuditRepository(t *testing.T) {
+	testAuditRepository(t, NewSQLAuditRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func testAuditRepository(t *testing.T, repo domain.IAuditRepository) {
+	ctx := context.Background()
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		entry := domain.AuditEntry{
+			ActorID:    "admin-1",
+			Action:     domain.AuditTaskUpdated,
+			EntityType: domain.AuditEntityTask,
+			EntityID:   "task-1",
+			Changes:    []domain.FieldChange{{Field: "title", Before: fmt.Sprint(i), After: fmt.Sprint(i + 1)}},
+			CreatedAt:  start.Add(time.Duration(i) * time.Second),
+		}
+		if i == 4 {
+			entry.ActorID = "user-1"
+			entry.EntityID = "task-2"
+		}
+		assert.NoError(t, repo.Record(ctx, entry))
+	}
+
+	// Newest first, paged by cursor
+	var afters []string
+	query := domain.AuditQuery{EntityType: domain.AuditEntityTask, EntityID: "task-1", Limit: 3}
+	for {
+		page, err := repo.Find(ctx, query)
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, e := range page.Entries {
+			afters = append(afters, e.Changes[0].After)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"4", "3", "2", "1"}, afters)
+
+	page, err := repo.Find(ctx, domain.AuditQuery{ActorID: "user-1", Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Equal(t, "task-2", page.Entries[0].EntityID)
+
+	page, err = repo.Find(ctx, domain.AuditQuery{Since: start.Add(1500 * time.Millisecond), Until: start.Add(3500 * time.Millisecond), Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 2)
+
+	_, err = repo.Find(ctx, domain.AuditQuery{Cursor: "not-a-cursor", Limit: 10})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+}
+
+func TestMemoryLabelRepository(t *testing.T) {
+	testLabelRepository(t, NewMemoryLabelRepository())
+}
+
+func TestSQLLabelRepository(t *testing.T) {
+	testLabelRepository(t, NewSQLLabelRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func testLabelRepository(t *testing.T, repo domain.ILabelRepository) {
+	ctx := context.Background()
+
+	_, err := repo.Create(ctx, domain.Label{Name: "ops", Color: "#00ff00"})
+	assert.NoError(t, err)
+	created, err := repo.Create(ctx, domain.Label{Name: "bug", Color: "#ff0000"})
+	assert.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	_, err = repo.Create(ctx, domain.Label{Name: "bug", Color: "#0000ff"})
+	assert.Equal(t, domain.ErrDuplicateEntry, err)
+
+	labels, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, labels, 2) {
+		assert.Equal(t, "bug", labels[0].Name)
+		assert.Equal(t, "ops", labels[1].Name)
+	}
+
+	updated, err := repo.Update(ctx, domain.Label{Name: "bug", Color: "#0000ff"})
+	assert.NoError(t, err)
+	assert.Equal(t, "#0000ff", updated.Color)
+
+	assert.NoError(t, repo.Delete(ctx, "bug"))
+	_, err = repo.GetByName(ctx, "bug")
+	assert.Equal(t, domain.ErrNotFound, err)
+	_, err = repo.Update(ctx, domain.Label{Name: "bug", Color: "#0000ff"})
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Equal(t, domain.ErrNotFound, repo.Delete(ctx, "bug"))
+}
+
+func TestMemoryCommentRepository(t *testing.T) {
+	testCommentRepository(t, NewMemoryCommentRepository(), "task-1", "task-2")
+}
+
+func TestSQLCommentRepository(t *testing.T) {
+	db := newTestSQLite(t)
+	// comments.task_id references tasks, so the tasks must exist
+	tasks := NewSQLTaskRepository(db, DefaultTimeouts())
+	var ids []string
+	for i := 0; i < 2; i++ {
+		task, err := tasks.Create(context.Background(), domain.Task{Title: fmt.Sprint("Task ", i), Status: domain.StatusPending})
+		assert.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+	testCommentRepository(t, NewSQLCommentRepository(db, DefaultTimeouts()), ids[0], ids[1])
+}
+
+func testCommentRepository(t *testing.T, repo domain.ICommentRepository, taskID, otherTaskID string) {
+	ctx := context.Background()
+
+	create := func(taskID string, parent *domain.Comment, body string) *domain.Comment {
+		comment := domain.Comment{TaskID: taskID, AuthorID: "user-1", Body: body}
+		if parent != nil {
+			comment.ParentID = parent.ID
+			comment.ThreadID = parent.ThreadID
+		}
+		created, err := repo.Create(ctx, comment)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return created
+	}
+
+	first := create(taskID, nil, "first")
+	assert.Equal(t, first.ID, first.ThreadID)
+	reply := create(taskID, first, "reply")
+	nested := create(taskID, reply, "nested")
+	create(taskID, nil, "second")
+	third := create(taskID, nil, "third")
+	create(otherTaskID, nil, "elsewhere")
+
+	// Threads are paged oldest first, each with all of its replies
+	var bodies []string
+	query := domain.CommentQuery{TaskID: taskID, Limit: 2}
+	for {
+		page, err := repo.FindThreads(ctx, query)
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, thread := range page.Threads {
+			bodies = append(bodies, thread.Body)
+			for _, r := range thread.Replies {
+				bodies = append(bodies, "  "+r.Body)
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"first", "  reply", "  nested", "second", "third"}, bodies)
+
+	updated, err := repo.Update(ctx, domain.Comment{ID: reply.ID, Body: "edited"})
+	assert.NoError(t, err)
+	assert.Equal(t, "edited", updated.Body)
+	assert.Equal(t, reply.ParentID, updated.ParentID)
+
+	// Deleting a reply removes the replies below it
+	assert.NoError(t, repo.Delete(ctx, reply.ID))
+	_, err = repo.GetByID(ctx, nested.ID)
+	assert.Equal(t, domain.ErrNotFound, err)
+	stored, err := repo.GetByID(ctx, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", stored.Body)
+	assert.Equal(t, domain.ErrNotFound, repo.Delete(ctx, reply.ID))
+
+	assert.NoError(t, repo.DeleteByTask(ctx, taskID))
+	_, err = repo.GetByID(ctx, third.ID)
+	assert.Equal(t, domain.ErrNotFound, err)
+	page, err := repo.FindThreads(ctx, domain.CommentQuery{TaskID: otherTaskID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Threads, 1)
+
+	_, err = repo.FindThreads(ctx, domain.CommentQuery{TaskID: taskID, Cursor: "not-a-cursor", Limit: 10})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+}
+
+func TestMemoryReminderRepository(t *testing.T) {
+	testReminderRepository(t, NewMemoryReminderRepository())
+}
+
+func TestSQLReminderRepository(t *testing.T) {
+	testReminderRepository(t, NewSQLReminderRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func testReminderRepository(t *testing.T, repo domain.IReminderRepository) {
+	ctx := context.Background()
+	due := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	delivery := domain.ReminderDelivery{TaskID: "task-1", UserID: "user-1", Channel: "log", Offset: time.Hour, DueDate: due, SentAt: due.Add(-time.Hour)}
+
+	assert.NoError(t, repo.Claim(ctx, delivery))
+	assert.Equal(t, domain.ErrDuplicateEntry, repo.Claim(ctx, delivery))
+
+	// Other channels, offsets and due dates are claimed separately
+	other := delivery
+	other.Channel = "smtp"
+	assert.NoError(t, repo.Claim(ctx, other))
+	other = delivery
+	other.Offset = 0
+	assert.NoError(t, repo.Claim(ctx, other))
+	moved := delivery
+	moved.DueDate = due.Add(24 * time.Hour)
+	assert.NoError(t, repo.Claim(ctx, moved))
+
+	assert.NoError(t, repo.Release(ctx, delivery))
+	assert.NoError(t, repo.Claim(ctx, delivery))
+
+	assert.NoError(t, repo.DeleteDueBefore(ctx, due.Add(time.Hour)))
+	assert.NoError(t, repo.Claim(ctx, delivery))
+	assert.Equal(t, domain.ErrDuplicateEntry, repo.Claim(ctx, moved))
+}
+
+func TestMemoryWebhookRepository(t *testing.T) {
+	testWebhookRepository(t, NewMemoryWebhookRepository(), NewMemoryWebhookDeliveryRepository())
+}
+
+func TestSQLWebhookRepository(t *testing.T) {
+	db := newTestSQLite(t)
+	testWebhookRepository(t, NewSQLWebhookRepository(db, DefaultTimeouts()), NewSQLWebhookDeliveryRepository(db, DefaultTimeouts()))
+}
+
+func testWebhookRepository(t *testing.T, webhooks domain.IWebhookRepository, deliveries domain.IWebhookDeliveryRepository) {
+	ctx := context.Background()
+
+	webhook, err := webhooks.Create(ctx, domain.Webhook{
+		URL:       "https://example.com/hook",
+		Secret:    "0123456789abcdef",
+		Events:    []string{domain.EventTaskCreated},
+		Active:    true,
+		CreatedBy: "admin-1",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := webhooks.Create(ctx, domain.Webhook{URL: "https://example.org", Secret: "0123456789abcdef", Events: []string{domain.EventUserRegistered}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stored, err := webhooks.GetByID(ctx, webhook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.EventTaskCreated}, stored.Events)
+	assert.Equal(t, "0123456789abcdef", stored.Secret)
+	assert.True(t, stored.Active)
+
+	stored.Events = []string{domain.EventTaskCreated, domain.EventTaskDeleted}
+	stored.Active = false
+	updated, err := webhooks.Update(ctx, *stored)
+	assert.NoError(t, err)
+	assert.Equal(t, stored.Events, updated.Events)
+	assert.False(t, updated.Active)
+	assert.Equal(t, "admin-1", updated.CreatedBy)
+
+	all, err := webhooks.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	// Deliveries are listed newest first and paged by ID
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var created []*domain.WebhookDelivery
+	for i := 0; i < 3; i++ {
+		d, err := deliveries.Create(ctx, domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       fmt.Sprint("event-", i),
+			EventType:     domain.EventTaskCreated,
+			Payload:       `{}`,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now.Add(-time.Duration(i) * time.Minute),
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		created = append(created, d)
+	}
+	_, err = deliveries.Create(ctx, domain.WebhookDelivery{WebhookID: other.ID, Status: domain.DeliveryPending, NextAttemptAt: now.Add(time.Hour)})
+	assert.NoError(t, err)
+
+	page, err := deliveries.Find(ctx, domain.WebhookDeliveryQuery{WebhookID: webhook.ID, Limit: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Deliveries, 2) {
+		assert.Equal(t, created[2].ID, page.Deliveries[0].ID)
+		assert.Equal(t, created[1].ID, page.NextCursor)
+	}
+	page, err = deliveries.Find(ctx, domain.WebhookDeliveryQuery{WebhookID: webhook.ID, Cursor: page.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Deliveries, 1) {
+		assert.Equal(t, created[0].ID, page.Deliveries[0].ID)
+		assert.Empty(t, page.NextCursor)
+	}
+
+	// Only pending deliveries that are due come back, oldest first
+	due, err := deliveries.FindDue(ctx, now, 10)
+	assert.NoError(t, err)
+	var ids []string
+	for _, d := range due {
+		ids = append(ids, d.ID)
+	}
+	assert.Equal(t, []string{created[2].ID, created[1].ID, created[0].ID}, ids)
+
+	sent := *created[1]
+	sent.Status = domain.DeliverySucceeded
+	sent.Attempts = 1
+	sent.ResponseStatus = 204
+	sent.LastAttemptAt = now
+	sent.NextAttemptAt = time.Time{}
+	assert.NoError(t, deliveries.Update(ctx, sent))
+	attempted, err := deliveries.GetByID(ctx, sent.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeliverySucceeded, attempted.Status)
+	assert.Equal(t, 204, attempted.ResponseStatus)
+	assert.True(t, attempted.LastAttemptAt.Equal(now))
+	assert.True(t, attempted.NextAttemptAt.IsZero())
+
+	due, err = deliveries.FindDue(ctx, now, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, created[2].ID, due[0].ID)
+	}
+
+	page, err = deliveries.Find(ctx, domain.WebhookDeliveryQuery{WebhookID: webhook.ID, Status: domain.DeliverySucceeded, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Deliveries, 1)
+
+	_, err = deliveries.Find(ctx, domain.WebhookDeliveryQuery{WebhookID: webhook.ID, Cursor: "not-a-cursor", Limit: 10})
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Equal(t, domain.ErrNotFound, deliveries.Update(ctx, domain.WebhookDelivery{ID: "missing"}))
+
+	assert.NoError(t, deliveries.DeleteByWebhook(ctx, webhook.ID))
+	assert.NoError(t, webhooks.Delete(ctx, webhook.ID))
+	_, err = deliveries.GetByID(ctx, created[0].ID)
+	assert.Equal(t, domain.ErrNotFound, err)
+	_, err = webhooks.GetByID(ctx, webhook.ID)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Equal(t, domain.ErrNotFound, webhooks.Delete(ctx, webhook.ID))
+	due, err = deliveries.FindDue(ctx, now.Add(time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+}
+
+func TestMemoryTaskSearchIndex(t *testing.T) {
+	testTaskSearchIndex(t, NewMemoryTaskRepository())
+}
+
+func TestSQLTaskSearchIndex(t *testing.T) {
+	testTaskSearchIndex(t, NewSQLTaskRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func testTaskSearchIndex(t *testing.T, repo domain.ITaskRepository) {
+	ctx := context.Background()
+	index, err := NewTaskSearchIndex(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(title, description, projectID string) *domain.Task {
+		task, err := index.Create(ctx, domain.Task{ProjectID: projectID, Title: title, Description: description, Status: domain.StatusPending, CreatedBy: "user-1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	notes := create("Release notes", "Draft the notes for the release", testProjectID)
+	login := create("Fix login", "Release blocker: login fails after the notes update", "project-2")
+	backport := create("Backport fixes", "Backport the release fixes", testProjectID)
+
+	search := func(searcher domain.ITaskSearcher, text string, projectID string) []string {
+		query := domain.TaskSearchQuery{Text: text, Limit: 1, ProjectID: projectID}
+		if err := query.Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for {
+			page, err := searcher.Search(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, hit := range page.Hits {
+				ids = append(ids, hit.Task.ID)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			query.Cursor = page.NextCursor
+			if err := query.Normalize(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// A title match outranks description matches; equal scores put the
+	// newest task first
+	assert.Equal(t, []string{notes.ID, backport.ID, login.ID}, search(index, "release", ""))
+	assert.Equal(t, []string{notes.ID}, search(index, `"release notes"`, ""))
+	assert.Equal(t, []string{backport.ID}, search(index, "BACK*", ""))
+	assert.Equal(t, []string{login.ID}, search(index, "login fix* missing", ""))
+	assert.Empty(t, search(index, "missing", ""))
+	assert.Equal(t, []string{notes.ID, backport.ID}, search(index, "release", testProjectID))
+	assert.Equal(t, []string{login.ID}, search(index, "release", "project-2"))
+
+	// A new index over the same repository finds the same tasks
+	reopened, err := NewTaskSearchIndex(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{notes.ID, backport.ID, login.ID}, search(reopened, "release", ""))
+
+	// Writes keep the index current
+	edit := *backport
+	edit.Description = "Backport the fixes"
+	_, err = index.Update(ctx, testProjectID, backport.ID, edit)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{notes.ID}, search(index, "release", testProjectID))
+
+	// A write indexed late does not undo a newer one
+	index.reindex(backport)
+	assert.Equal(t, []string{notes.ID}, search(index, "release", testProjectID))
+
+	// Writes through the wrong project leave the index alone
+	assert.Equal(t, domain.ErrNotFound, index.Delete(ctx, "project-2", notes.ID))
+	assert.Equal(t, []string{notes.ID}, search(index, "release", testProjectID))
+
+	// Members who do not read every task only find their own
+	visibleTo := func(userID string) []string {
+		query := domain.TaskSearchQuery{Text: "release", Limit: 10, ProjectID: testProjectID, VisibleTo: userID}
+		if err := query.Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		page, err := index.Search(ctx, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, hit := range page.Hits {
+			ids = append(ids, hit.Task.ID)
+		}
+		return ids
+	}
+	assert.Empty(t, visibleTo("user-2"))
+	_, err = index.Assign(ctx, testProjectID, notes.ID, "user-2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{notes.ID}, visibleTo("user-2"))
+	assert.Equal(t, []string{notes.ID}, visibleTo("user-1"))
+
+	assert.NoError(t, index.Delete(ctx, testProjectID, notes.ID))
+	assert.Equal(t, []string{login.ID}, search(index, "release", ""))
+	assert.Empty(t, search(index, `"release notes"`, ""))
+
+	// A task deleted behind the index's back is skipped without the next
+	// page repeating the hit that took its place
+	var reports []string
+	for i := 0; i < 4; i++ {
+		reports = append([]string{create("Quarterly report", "", testProjectID).ID}, reports...)
+	}
+	assert.NoError(t, repo.Delete(ctx, testProjectID, reports[1]))
+	assert.Equal(t, []string{reports[0], reports[2], reports[3]}, search(index, "quarterly", ""))
+}
+
+func TestMemoryProjectRepository(t *testing.T) {
+	testProjectRepository(t, NewMemoryProjectRepository())
+}
+
+func TestSQLProjectRepository(t *testing.T) {
+	testProjectRepository(t, NewSQLProjectRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func testProjectRepository(t *testing.T, repo domain.IProjectRepository) {
+	ctx := context.Background()
+
+	launch, err := repo.Create(ctx, domain.Project{Name: "Launch", CreatedBy: "user-1", Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectOwner},
+		{UserID: "user-2", Role: domain.ProjectViewer},
+	}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, launch.ID)
+	ops, err := repo.Create(ctx, domain.Project{Name: "Ops", CreatedBy: "user-3", Members: []domain.ProjectMember{
+		{UserID: "user-3", Role: domain.ProjectOwner},
+	}})
+	assert.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, launch.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Launch", stored.Name)
+	assert.Len(t, stored.Members, 2)
+	role, ok := stored.RoleOf("user-2")
+	assert.True(t, ok)
+	assert.Equal(t, domain.ProjectViewer, role)
+
+	all, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+	mine, err := repo.GetByMember(ctx, "user-2")
+	assert.NoError(t, err)
+	if assert.Len(t, mine, 1) {
+		assert.Equal(t, launch.ID, mine[0].ID)
+	}
+
+	// Update replaces the member list
+	stored.Description = "Ship it"
+	stored.Members = []domain.ProjectMember{{UserID: "user-1", Role: domain.ProjectOwner}, {UserID: "user-3", Role: domain.ProjectEditor}}
+	updated, err := repo.Update(ctx, *stored)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ship it", updated.Description)
+	mine, err = repo.GetByMember(ctx, "user-2")
+	assert.NoError(t, err)
+	assert.Empty(t, mine)
+	mine, err = repo.GetByMember(ctx, "user-3")
+	assert.NoError(t, err)
+	assert.Len(t, mine, 2)
+
+	assert.NoError(t, repo.Delete(ctx, ops.ID))
+	_, err = repo.GetByID(ctx, ops.ID)
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Equal(t, domain.ErrNotFound, repo.Delete(ctx, ops.ID))
+	_, err = repo.Update(ctx, domain.Project{ID: ops.ID, Name: "Ops"})
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestMemoryRoleRepository(t *testing.T) {
+	testRoleRepository(t, NewMemoryRoleRepository())
+}
+
+func TestSQLRoleRepository(t *testing.T) {
+	testRoleRepository(t, NewSQLRoleRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func testRoleRepository(t *testing.T, repo domain.IRoleRepository) {
+	ctx := context.Background()
+
+	for _, role := range domain.BuiltinRoles() {
+		_, err := repo.Create(ctx, role)
+		assert.NoError(t, err)
+	}
+	created, err := repo.Create(ctx, domain.RoleDefinition{Name: "triager", Permissions: []domain.Permission{}})
+	assert.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	_, err = repo.Create(ctx, domain.RoleDefinition{Name: "triager"})
+	assert.Equal(t, domain.ErrDuplicateEntry, err)
+
+	roles, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, roles, 3) {
+		assert.Equal(t, domain.RoleAdmin, roles[0].Name)
+		assert.Equal(t, domain.Role("triager"), roles[1].Name)
+		assert.Equal(t, domain.RoleUser, roles[2].Name)
+	}
+
+	updated, err := repo.Update(ctx, domain.RoleDefinition{
+		Name:        "triager",
+		Description: "Sorts tasks",
+		Permissions: []domain.Permission{domain.PermTasksUpdate},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Sorts tasks", updated.Description)
+	stored, err := repo.GetByName(ctx, "triager")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Permission{domain.PermTasksUpdate}, stored.Permissions)
+	assert.False(t, stored.RequireMFA)
+
+	admin, err := repo.GetByName(ctx, domain.RoleAdmin)
+	assert.NoError(t, err)
+	admin.RequireMFA = true
+	_, err = repo.Update(ctx, *admin)
+	assert.NoError(t, err)
+	admin, err = repo.GetByName(ctx, domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.True(t, admin.RequireMFA)
+
+	assert.NoError(t, repo.Delete(ctx, "triager"))
+	_, err = repo.GetByName(ctx, "triager")
+	assert.Equal(t, domain.ErrNotFound, err)
+	_, err = repo.Update(ctx, domain.RoleDefinition{Name: "triager"})
+	assert.Equal(t, domain.ErrNotFound, err)
+	assert.Equal(t, domain.ErrNotFound, repo.Delete(ctx, "triager"))
+}
+
+func TestMemoryLoginAttemptRepository(t *testing.T) {
+	testLoginAttemptRepository(t, NewMemoryLoginAttemptRepository())
+}
+
+func TestSQLLoginAttemptRepository(t *testing.T) {
+	testLoginAttemptRepository(t, NewSQLLoginAttemptRepository(newTestSQLite(t), DefaultTimeouts()))
+}
+
+func testLoginAttemptRepository(t *testing.T, repo domain.ILoginAttemptRepository) {
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := repo.Get(ctx, "user:alice")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	for i := 1; i <= 3; i++ {
+		failures, err := repo.RecordFailure(ctx, "user:alice", now.Add(time.Duration(i)*time.Second), time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, i, failures.Count)
+	}
+	stored, err := repo.Get(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stored.Count)
+	assert.WithinDuration(t, now.Add(3*time.Second), stored.LastFailure, time.Millisecond)
+	assert.WithinDuration(t, now.Add(time.Hour+3*time.Second), stored.ExpiresAt, time.Millisecond)
+
+	// Failures outside the window are forgotten and the count starts over
+	_, err = repo.RecordFailure(ctx, "ip:203.0.113.1", now.Add(-2*time.Hour), time.Hour)
+	assert.NoError(t, err)
+	_, err = repo.Get(ctx, "ip:203.0.113.1")
+	assert.Equal(t, domain.ErrNotFound, err)
+	failures, err := repo.RecordFailure(ctx, "ip:203.0.113.1", now, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures.Count)
+
+	assert.NoError(t, repo.ReleaseFailure(ctx, "user:alice"))
+	stored, err = repo.Get(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stored.Count)
+	assert.NoError(t, repo.ReleaseFailure(ctx, "user:nobody"))
+
+	assert.NoError(t, repo.Reset(ctx, "user:alice"))
+	_, err = repo.Get(ctx, "user:alice")
+	assert.Equal(t, domain.ErrNotFound, err)
+	stored, err = repo.Get(ctx, "ip:203.0.113.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stored.Count)
+	assert.NoError(t, repo.Reset(ctx, "user:nobody"))
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLite(t)
+
+	migrations, err := loadMigrations()
+	assert.NoError(t, err)
+	latest := migrations[len(migrations)-1].version
+
+	version, err := SchemaVersion(ctx, db)
+	assert.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	// Re-running is a no-op
+	assert.NoError(t, MigrateUp(ctx, db))
+
+	// Every down migration must undo its up migration cleanly
+	for i := len(migrations) - 1; i >= 0; i-- {
+		assert.NoError(t, MigrateDown(ctx, db, 1))
+		version, err = SchemaVersion(ctx, db)
+		assert.NoError(t, err)
+		if i > 0 {
+			assert.Equal(t, migrations[i-1].version, version)
+		}
+	}
+	assert.Equal(t, 0, version)
+	_, err = db.ExecContext(ctx, "SELECT 1 FROM users")
+	assert.Error(t, err)
+
+	assert.NoError(t, MigrateUp(ctx, db))
+	version, err = SchemaVersion(ctx, db)
+	assert.NoError(t, err)
+	assert.Equal(t, latest, version)
+}
+
+func newTestSQLite(t *testing.T) *sql.DB {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := MigrateUp(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RoleRepository stores role definitions keyed by name.
+type RoleRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewRoleRepository(collection *mongo.Collection, timeouts Timeouts) *RoleRepository {
+	return &RoleRepository{collection: collection, timeouts: timeouts}
+}
+
+func (r *RoleRepository) Create(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	role.CreatedAt = time.Now()
+	role.UpdatedAt = role.CreatedAt
+	if _, err := r.collection.InsertOne(ctx, role); err != nil {
+		// The name is the _id, so duplicates are rejected by MongoDB
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrDuplicateEntry
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) GetAll(ctx context.Context) ([]domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var roles []domain.RoleDefinition
+	if err = cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	if roles == nil {
+		return []domain.RoleDefinition{}, nil
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) GetByName(ctx context.Context, name domain.Role) (*domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var role domain.RoleDefinition
+	if err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&role); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Update replaces the description, permissions and two-factor requirement of
+// an existing role.
+func (r *RoleRepository) Update(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": bson.M{
+		"description": role.Description,
+		"permissions": role.Permissions,
+		"require_mfa": role.RequireMFA,
+		"updated_at":  time.Now(),
+	}}
+	var updated domain.RoleDefinition
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": role.Name}, update, opts).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, name domain.Role) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLAuditRepository is an IAuditRepository backed by the audit_log table.
+// Field changes are stored as a JSON array.
+type SQLAuditRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLAuditRepository(db *sql.DB, timeouts Timeouts) *SQLAuditRepository {
+	return &SQLAuditRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLAuditRepository) Record(ctx context.Context, entry domain.AuditEntry) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO audit_log (id, actor_id, action, entity_type, entity_id, changes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		primitive.NewObjectID().Hex(), entry.ActorID, entry.Action, entry.EntityType, entry.EntityID, string(changes), toNanos(entry.CreatedAt))
+	return err
+}
+
+func (r *SQLAuditRepository) Find(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+	if query.ActorID != "" {
+		add("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		add("action = ?", query.Action)
+	}
+	if query.EntityType != "" {
+		add("entity_type = ?", query.EntityType)
+	}
+	if query.EntityID != "" {
+		add("entity_id = ?", query.EntityID)
+	}
+	if !query.Since.IsZero() {
+		add("created_at >= ?", toNanos(query.Since))
+	}
+	if !query.Until.IsZero() {
+		add("created_at <= ?", toNanos(query.Until))
+	}
+	if query.Cursor != "" {
+		add("id < ?", query.Cursor)
+	}
+
+	stmt := "SELECT id, actor_id, action, entity_type, entity_id, changes, created_at FROM audit_log"
+	if len(conds) > 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	stmt += " ORDER BY id DESC LIMIT ?"
+
+	rows, err := r.db.QueryContext(ctx, stmt, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		var e domain.AuditEntry
+		var changes string
+		var createdAt int64
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.EntityType, &e.EntityID, &changes, &createdAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &e.Changes); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = fromNanos(createdAt)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return newAuditPage(entries, query.Limit), nil
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// SQLCommentRepository is an ICommentRepository backed by the comments table.
+type SQLCommentRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLCommentRepository(db *sql.DB, timeouts Timeouts) *SQLCommentRepository {
+	return &SQLCommentRepository{db: db, timeouts: timeouts}
+}
+
+const commentColumns = "id, task_id, thread_id, parent_id, author_id, body, created_at, updated_at"
+
+func (r *SQLCommentRepository) Create(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	prepareComment(&comment)
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO comments ("+commentColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		comment.ID, comment.TaskID, comment.ThreadID, comment.ParentID, comment.AuthorID, comment.Body,
+		toNanos(comment.CreatedAt), toNanos(comment.UpdatedAt))
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *SQLCommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	comment, err := scanComment(r.db.QueryRowContext(ctx, "SELECT "+commentColumns+" FROM comments WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return comment, err
+}
+
+func (r *SQLCommentRepository) FindThreads(ctx context.Context, query domain.CommentQuery) (*domain.CommentPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	roots, err := r.queryComments(ctx,
+		"SELECT "+commentColumns+" FROM comments WHERE task_id = ? AND parent_id = '' AND id > ? ORDER BY id LIMIT ?",
+		query.TaskID, query.Cursor, query.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := newCommentPage(roots, query.Limit)
+	if len(page.Threads) == 0 {
+		return page, nil
+	}
+	ids := commentThreadIDs(page)
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	replies, err := r.queryComments(ctx,
+		"SELECT "+commentColumns+" FROM comments WHERE thread_id IN ("+placeholders+") AND parent_id != '' ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	attachReplies(page, replies)
+	return page, nil
+}
+
+func (r *SQLCommentRepository) Update(ctx context.Context, comment domain.Comment) (*domain.Comment, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE comments SET body = ?, updated_at = ? WHERE id = ?",
+		comment.Body, toNanos(time.Now()), comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return r.GetByID(ctx, comment.ID)
+}
+
+// Delete removes the comment and, through a recursive query, every reply
+// below it.
+func (r *SQLCommentRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM comments WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM comments c JOIN subtree s ON c.parent_id = s.id
+		)
+		DELETE FROM comments WHERE id IN subtree`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLCommentRepository) DeleteByTask(ctx context.Context, taskID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM comments WHERE task_id = ?", taskID)
+	return err
+}
+
+func (r *SQLCommentRepository) queryComments(ctx context.Context, stmt string, args ...interface{}) ([]domain.Comment, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []domain.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+	return comments, rows.Err()
+}
+
+func scanComment(row rowScanner) (*domain.Comment, error) {
+	var c domain.Comment
+	var createdAt, updatedAt int64
+	if err := row.Scan(&c.ID, &c.TaskID, &c.ThreadID, &c.ParentID, &c.AuthorID, &c.Body, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	c.CreatedAt = fromNanos(createdAt)
+	c.UpdatedAt = fromNanos(updatedAt)
+	return &c, nil
+}
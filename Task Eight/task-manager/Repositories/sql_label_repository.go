@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// SQLLabelRepository is an ILabelRepository backed by the labels table.
+type SQLLabelRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLLabelRepository(db *sql.DB, timeouts Timeouts) *SQLLabelRepository {
+	return &SQLLabelRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLLabelRepository) Create(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	label.CreatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, "INSERT INTO labels (name, color, created_at) VALUES (?, ?, ?)",
+		label.Name, label.Color, toNanos(label.CreatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrDuplicateEntry
+		}
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (r *SQLLabelRepository) GetAll(ctx context.Context) ([]domain.Label, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT name, color, created_at FROM labels ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := []domain.Label{}
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, *label)
+	}
+	return labels, rows.Err()
+}
+
+func (r *SQLLabelRepository) GetByName(ctx context.Context, name string) (*domain.Label, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	label, err := scanLabel(r.db.QueryRowContext(ctx, "SELECT name, color, created_at FROM labels WHERE name = ?", name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return label, err
+}
+
+func (r *SQLLabelRepository) Update(ctx context.Context, label domain.Label) (*domain.Label, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE labels SET color = ? WHERE name = ?", label.Color, label.Name)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return r.GetByName(ctx, label.Name)
+}
+
+func (r *SQLLabelRepository) Delete(ctx context.Context, name string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM labels WHERE name = ?", name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanLabel(row rowScanner) (*domain.Label, error) {
+	var label domain.Label
+	var createdAt int64
+	if err := row.Scan(&label.Name, &label.Color, &createdAt); err != nil {
+		return nil, err
+	}
+	label.CreatedAt = fromNanos(createdAt)
+	return &label, nil
+}
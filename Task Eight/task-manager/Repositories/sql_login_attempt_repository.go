@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// SQLLoginAttemptRepository is an ILoginAttemptRepository backed by the
+// login_failures table.
+type SQLLoginAttemptRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLLoginAttemptRepository(db *sql.DB, timeouts Timeouts) *SQLLoginAttemptRepository {
+	return &SQLLoginAttemptRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginFailures, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	return scanLoginFailures(r.db.QueryRowContext(ctx,
+		"SELECT id, count, last_failure, expires_at FROM login_failures WHERE id = ? AND expires_at > ?",
+		key, toNanos(time.Now())))
+}
+
+// RecordFailure counts the failure in a single upsert; an expired count
+// starts over.
+func (r *SQLLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginFailures, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	// Piggyback cleanup of failures that have been forgotten
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM login_failures WHERE expires_at <= ?", toNanos(at)); err != nil {
+		return nil, err
+	}
+
+	return scanLoginFailures(r.db.QueryRowContext(ctx,
+		`INSERT INTO login_failures (id, count, last_failure, expires_at) VALUES (?, 1, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET count = count + 1, last_failure = excluded.last_failure, expires_at = excluded.expires_at
+		RETURNING id, count, last_failure, expires_at`,
+		key, toNanos(at), toNanos(at.Add(window))))
+}
+
+func (r *SQLLoginAttemptRepository) ReleaseFailure(ctx context.Context, key string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE login_failures SET count = count - 1 WHERE id = ? AND count > 0", key)
+	return err
+}
+
+func (r *SQLLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM login_failures WHERE id = ?", key)
+	return err
+}
+
+func scanLoginFailures(row rowScanner) (*domain.LoginFailures, error) {
+	var failures domain.LoginFailures
+	var lastFailure, expiresAt int64
+	if err := row.Scan(&failures.Key, &failures.Count, &lastFailure, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	failures.LastFailure = fromNanos(lastFailure)
+	failures.ExpiresAt = fromNanos(expiresAt)
+	return &failures, nil
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	domain "task-manager/Domain"
+)
+
+// SQLMFARepository is an IMFARepository backed by the mfa_enrollments table,
+// with the recovery code hashes in mfa_recovery_codes.
+type SQLMFARepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLMFARepository(db *sql.DB, timeouts Timeouts) *SQLMFARepository {
+	return &SQLMFARepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLMFARepository) Get(ctx context.Context, userID string) (*domain.MFAEnrollment, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var enrollment domain.MFAEnrollment
+	var createdAt int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT user_id, secret, confirmed, last_step, created_at FROM mfa_enrollments WHERE user_id = ?", userID).
+		Scan(&enrollment.UserID, &enrollment.Secret, &enrollment.Confirmed, &enrollment.LastStep, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	enrollment.CreatedAt = fromNanos(createdAt)
+
+	rows, err := r.db.QueryContext(ctx, "SELECT hash FROM mfa_recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	enrollment.RecoveryCodes = []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		enrollment.RecoveryCodes = append(enrollment.RecoveryCodes, hash)
+	}
+	return &enrollment, rows.Err()
+}
+
+// Save upserts the enrolment and replaces its recovery codes in one
+// transaction.
+func (r *SQLMFARepository) Save(ctx context.Context, enrollment domain.MFAEnrollment) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO mfa_enrollments (user_id, secret, confirmed, last_step, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET secret = excluded.secret, confirmed = excluded.confirmed,
+			last_step = excluded.last_step, created_at = excluded.created_at`,
+		enrollment.UserID, enrollment.Secret, enrollment.Confirmed, enrollment.LastStep, toNanos(enrollment.CreatedAt))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = ?", enrollment.UserID); err != nil {
+		return err
+	}
+	for _, hash := range enrollment.RecoveryCodes {
+		_, err := tx.ExecContext(ctx, "INSERT INTO mfa_recovery_codes (user_id, hash) VALUES (?, ?)", enrollment.UserID, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UseStep only updates while the stored step is older, so two requests
+// presenting the same code cannot both succeed.
+func (r *SQLMFARepository) UseStep(ctx context.Context, userID string, step int64) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE mfa_enrollments SET last_step = ? WHERE user_id = ? AND last_step < ?", step, userID, step)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrDuplicateEntry
+	}
+	return nil
+}
+
+func (r *SQLMFARepository) UseRecoveryCode(ctx context.Context, userID string, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = ? AND hash = ?", userID, hash)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the enrolment; its recovery codes go with it through the
+// foreign key.
+func (r *SQLMFARepository) Delete(ctx context.Context, userID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM mfa_enrollments WHERE user_id = ?", userID)
+	return err
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const projectColumns = "id, name, description, created_by, created_at, updated_at"
+
+// SQLProjectRepository is an IProjectRepository backed by the projects
+// table, with the members in project_members.
+type SQLProjectRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLProjectRepository(db *sql.DB, timeouts Timeouts) *SQLProjectRepository {
+	return &SQLProjectRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLProjectRepository) Create(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	project.ID = primitive.NewObjectID().Hex()
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = project.CreatedAt
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO projects ("+projectColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		project.ID, project.Name, project.Description, project.CreatedBy,
+		toNanos(project.CreatedAt), toNanos(project.UpdatedAt))
+	if err != nil {
+		return nil, err
+	}
+	if err := insertProjectMembers(ctx, tx, project.ID, project.Members); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return cloneProject(project), nil
+}
+
+func (r *SQLProjectRepository) GetByID(ctx context.Context, id string) (*domain.Project, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	projects, err := r.queryProjects(ctx, "SELECT "+projectColumns+" FROM projects WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &projects[0], nil
+}
+
+func (r *SQLProjectRepository) GetAll(ctx context.Context) ([]domain.Project, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryProjects(ctx, "SELECT "+projectColumns+" FROM projects ORDER BY id")
+}
+
+func (r *SQLProjectRepository) GetByMember(ctx context.Context, userID string) ([]domain.Project, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryProjects(ctx,
+		"SELECT "+projectColumns+" FROM projects WHERE id IN (SELECT project_id FROM project_members WHERE user_id = ?) ORDER BY id",
+		userID)
+}
+
+// Update replaces the project's fields and its member rows in one
+// transaction.
+func (r *SQLProjectRepository) Update(ctx context.Context, project domain.Project) (*domain.Project, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE projects SET name = ?, description = ?, updated_at = ? WHERE id = ?",
+		project.Name, project.Description, toNanos(time.Now()), project.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM project_members WHERE project_id = ?", project.ID); err != nil {
+		return nil, err
+	}
+	if err := insertProjectMembers(ctx, tx, project.ID, project.Members); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, project.ID)
+}
+
+// Delete removes the project; its members go with it through the foreign
+// key.
+func (r *SQLProjectRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM projects WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// queryProjects loads the projects selected by the query, then their
+// members in the order they were added.
+func (r *SQLProjectRepository) queryProjects(ctx context.Context, query string, args ...interface{}) ([]domain.Project, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	projects := []domain.Project{}
+	for rows.Next() {
+		var project domain.Project
+		var createdAt, updatedAt int64
+		err := rows.Scan(&project.ID, &project.Name, &project.Description, &project.CreatedBy, &createdAt, &updatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		project.CreatedAt = fromNanos(createdAt)
+		project.UpdatedAt = fromNanos(updatedAt)
+		project.Members = []domain.ProjectMember{}
+		projects = append(projects, project)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range projects {
+		if projects[i].Members, err = r.members(ctx, projects[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return projects, nil
+}
+
+func (r *SQLProjectRepository) members(ctx context.Context, projectID string) ([]domain.ProjectMember, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT user_id, role, added_at FROM project_members WHERE project_id = ? ORDER BY added_at, user_id", projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []domain.ProjectMember{}
+	for rows.Next() {
+		var member domain.ProjectMember
+		var addedAt int64
+		if err := rows.Scan(&member.UserID, &member.Role, &addedAt); err != nil {
+			return nil, err
+		}
+		member.AddedAt = fromNanos(addedAt)
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func insertProjectMembers(ctx context.Context, tx *sql.Tx, projectID string, members []domain.ProjectMember) error {
+	for _, m := range members {
+		_, err := tx.ExecContext(ctx, "INSERT INTO project_members (project_id, user_id, role, added_at) VALUES (?, ?, ?, ?)",
+			projectID, m.UserID, m.Role, toNanos(m.AddedAt))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// SQLReminderRepository is an IReminderRepository backed by the
+// reminder_deliveries table.
+type SQLReminderRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLReminderRepository(db *sql.DB, timeouts Timeouts) *SQLReminderRepository {
+	return &SQLReminderRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLReminderRepository) Claim(ctx context.Context, delivery domain.ReminderDelivery) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO reminder_deliveries (task_id, user_id, channel, offset_ns, due_date, sent_at) VALUES (?, ?, ?, ?, ?, ?)",
+		delivery.TaskID, delivery.UserID, delivery.Channel, int64(delivery.Offset), toNanos(delivery.DueDate), toNanos(delivery.SentAt))
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateEntry
+	}
+	return err
+}
+
+func (r *SQLReminderRepository) Release(ctx context.Context, delivery domain.ReminderDelivery) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM reminder_deliveries WHERE task_id = ? AND user_id = ? AND channel = ? AND offset_ns = ? AND due_date = ?",
+		delivery.TaskID, delivery.UserID, delivery.Channel, int64(delivery.Offset), toNanos(delivery.DueDate))
+	return err
+}
+
+func (r *SQLReminderRepository) DeleteDueBefore(ctx context.Context, t time.Time) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM reminder_deliveries WHERE due_date < ?", toNanos(t))
+	return err
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	domain "task-manager/Domain"
+	"time"
+)
+
+const roleColumns = "name, description, permissions, require_mfa, created_at, updated_at"
+
+// SQLRoleRepository is an IRoleRepository backed by the roles table. The
+// granted permissions are stored as a JSON array.
+type SQLRoleRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLRoleRepository(db *sql.DB, timeouts Timeouts) *SQLRoleRepository {
+	return &SQLRoleRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLRoleRepository) Create(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	permissions, err := marshalPermissions(role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	role.CreatedAt = time.Now()
+	role.UpdatedAt = role.CreatedAt
+	_, err = r.db.ExecContext(ctx, "INSERT INTO roles ("+roleColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+		role.Name, role.Description, permissions, role.RequireMFA, toNanos(role.CreatedAt), toNanos(role.UpdatedAt))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrDuplicateEntry
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *SQLRoleRepository) GetAll(ctx context.Context) ([]domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+roleColumns+" FROM roles ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []domain.RoleDefinition{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *SQLRoleRepository) GetByName(ctx context.Context, name domain.Role) (*domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	role, err := scanRole(r.db.QueryRowContext(ctx, "SELECT "+roleColumns+" FROM roles WHERE name = ?", name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return role, err
+}
+
+func (r *SQLRoleRepository) Update(ctx context.Context, role domain.RoleDefinition) (*domain.RoleDefinition, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	permissions, err := marshalPermissions(role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.db.ExecContext(ctx, "UPDATE roles SET description = ?, permissions = ?, require_mfa = ?, updated_at = ? WHERE name = ?",
+		role.Description, permissions, role.RequireMFA, toNanos(time.Now()), role.Name)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return r.GetByName(ctx, role.Name)
+}
+
+func (r *SQLRoleRepository) Delete(ctx context.Context, name domain.Role) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM roles WHERE name = ?", name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func marshalPermissions(permissions []domain.Permission) (string, error) {
+	if permissions == nil {
+		permissions = []domain.Permission{}
+	}
+	encoded, err := json.Marshal(permissions)
+	return string(encoded), err
+}
+
+func scanRole(row rowScanner) (*domain.RoleDefinition, error) {
+	var role domain.RoleDefinition
+	var permissions string
+	var createdAt, updatedAt int64
+	if err := row.Scan(&role.Name, &role.Description, &permissions, &role.RequireMFA, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(permissions), &role.Permissions); err != nil {
+		return nil, err
+	}
+	role.CreatedAt = fromNanos(createdAt)
+	role.UpdatedAt = fromNanos(updatedAt)
+	return &role, nil
+}
@@ -0,0 +1,362 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SQLTaskRepository is an ITaskRepository backed by the tasks table.
+type SQLTaskRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLTaskRepository(db *sql.DB, timeouts Timeouts) *SQLTaskRepository {
+	return &SQLTaskRepository{db: db, timeouts: timeouts}
+}
+
+const taskColumns = "id, title, description, due_date, status, created_by, assignee_id, project_id, parent_id, checklist, labels, attachments, recurrence, version, created_at, updated_at"
+
+func (r *SQLTaskRepository) Create(ctx context.Context, task domain.Task) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	task.ID = primitive.NewObjectID().Hex()
+	task.Version = 1
+	task.CreatedAt = time.Now()
+	task.UpdatedAt = time.Now()
+
+	checklist, labels, attachments, recurrence, err := encodeTaskJSON(task)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO tasks ("+taskColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		task.ID, task.Title, task.Description, toNanos(task.DueDate), task.Status,
+		task.CreatedBy, task.AssigneeID, task.ProjectID, task.ParentID, checklist, labels, attachments, recurrence, task.Version,
+		toNanos(task.CreatedAt), toNanos(task.UpdatedAt))
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (r *SQLTaskRepository) GetAll(ctx context.Context) ([]domain.Task, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryTasks(ctx, "SELECT "+taskColumns+" FROM tasks ORDER BY created_at, id")
+}
+
+func (r *SQLTaskRepository) Find(ctx context.Context, query domain.TaskQuery) (*domain.TaskPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	where, args := taskQueryWhere(query)
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks"+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	// query.SortBy has been validated by Normalize and matches a column name
+	direction, op := "ASC", ">"
+	if query.SortDesc {
+		direction, op = "DESC", "<"
+	}
+
+	if query.Cursor != "" {
+		c, err := decodeTaskCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{} = c.Value
+		if isTimeSortField(query.SortBy) {
+			t, err := time.Parse(time.RFC3339Nano, c.Value)
+			if err != nil {
+				return nil, domain.ErrInvalidInput
+			}
+			value = toNanos(t)
+		}
+		cond := "(" + query.SortBy + " " + op + " ? OR (" + query.SortBy + " = ? AND id " + op + " ?))"
+		if where == "" {
+			where = " WHERE " + cond
+		} else {
+			where += " AND " + cond
+		}
+		args = append(args, value, value, c.ID)
+	}
+
+	stmt := "SELECT " + taskColumns + " FROM tasks" + where +
+		" ORDER BY " + query.SortBy + " " + direction + ", id " + direction + " LIMIT ?"
+	tasks, err := r.queryTasks(ctx, stmt, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.TaskPage{Tasks: tasks, Total: total}
+	if len(tasks) > query.Limit {
+		page.Tasks = tasks[:query.Limit]
+		page.NextCursor = encodeTaskCursor(page.Tasks[query.Limit-1], query.SortBy)
+	}
+	return page, nil
+}
+
+func (r *SQLTaskRepository) GetByID(ctx context.Context, projectID string, id string) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ? AND project_id = ?", id, projectID)
+	task, err := scanTask(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+func (r *SQLTaskRepository) Update(ctx context.Context, projectID string, id string, task domain.Task) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	checklist, labels, attachments, recurrence, err := encodeTaskJSON(task)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE tasks SET title = ?, description = ?, due_date = ?, status = ?, checklist = ?, labels = ?, attachments = ?, recurrence = ?, version = version + 1, updated_at = ? WHERE id = ? AND project_id = ? AND version = ?",
+		task.Title, task.Description, toNanos(task.DueDate), task.Status, checklist, labels, attachments, recurrence, toNanos(time.Now()), id, projectID, task.Version)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// Either the task is gone or its version moved on
+		if _, err := r.GetByID(ctx, projectID, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrVersionConflict
+	}
+
+	return r.GetByID(ctx, projectID, id)
+}
+
+func (r *SQLTaskRepository) Delete(ctx context.Context, projectID string, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM tasks WHERE id = ? AND project_id = ?", id, projectID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLTaskRepository) Assign(ctx context.Context, projectID string, id string, assigneeID string) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE tasks SET assignee_id = ?, version = version + 1, updated_at = ? WHERE id = ? AND project_id = ?",
+		assigneeID, toNanos(time.Now()), id, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	return r.GetByID(ctx, projectID, id)
+}
+
+func (r *SQLTaskRepository) GetChildren(ctx context.Context, projectID string, parentID string) ([]domain.Task, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryTasks(ctx, "SELECT "+taskColumns+" FROM tasks WHERE project_id = ? AND parent_id = ? ORDER BY created_at, id", projectID, parentID)
+}
+
+func (r *SQLTaskRepository) RemoveLabelFromAll(ctx context.Context, label string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tasks SET labels = (SELECT json_group_array(value) FROM json_each(tasks.labels) WHERE value <> ?),
+			version = version + 1, updated_at = ?
+		WHERE EXISTS (SELECT 1 FROM json_each(tasks.labels) WHERE value = ?)`,
+		label, toNanos(time.Now()), label)
+	return err
+}
+
+func (r *SQLTaskRepository) queryTasks(ctx context.Context, stmt string, args ...interface{}) ([]domain.Task, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []domain.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+// taskQueryWhere translates the query's filters (but not its cursor) into a WHERE clause.
+func taskQueryWhere(query domain.TaskQuery) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	if query.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, query.Status)
+	}
+	if !query.DueAfter.IsZero() {
+		conds = append(conds, "due_date >= ?")
+		args = append(args, toNanos(query.DueAfter))
+	}
+	if !query.DueBefore.IsZero() {
+		conds = append(conds, "due_date <= ?")
+		args = append(args, toNanos(query.DueBefore))
+	}
+	if query.Title != "" {
+		conds = append(conds, `title LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(query.Title)+"%")
+	}
+	if query.ProjectID != "" {
+		conds = append(conds, "project_id = ?")
+		args = append(args, query.ProjectID)
+	}
+	if query.VisibleTo != "" {
+		conds = append(conds, "(created_by = ? OR assignee_id = ?)")
+		args = append(args, query.VisibleTo, query.VisibleTo)
+	}
+	if len(query.LabelsAny) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(query.LabelsAny)), ", ")
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(tasks.labels) WHERE value IN ("+placeholders+"))")
+		for _, l := range query.LabelsAny {
+			args = append(args, l)
+		}
+	}
+	for _, l := range query.LabelsAll {
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(tasks.labels) WHERE value = ?)")
+		args = append(args, l)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(row rowScanner) (*domain.Task, error) {
+	var task domain.Task
+	var checklist, labels, attachments, recurrence string
+	var dueDate, createdAt, updatedAt int64
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &dueDate, &task.Status,
+		&task.CreatedBy, &task.AssigneeID, &task.ProjectID, &task.ParentID, &checklist, &labels, &attachments, &recurrence, &task.Version, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeList(checklist, &task.Checklist); err != nil {
+		return nil, err
+	}
+	if err := decodeList(labels, &task.Labels); err != nil {
+		return nil, err
+	}
+	if err := decodeList(attachments, &task.Attachments); err != nil {
+		return nil, err
+	}
+	if recurrence != "" {
+		if err := json.Unmarshal([]byte(recurrence), &task.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+	task.DueDate = fromNanos(dueDate)
+	task.CreatedAt = fromNanos(createdAt)
+	task.UpdatedAt = fromNanos(updatedAt)
+	return &task, nil
+}
+
+// encodeTaskJSON encodes the task's list columns as JSON arrays and its
+// recurrence as a JSON object, or an empty string when there is none.
+func encodeTaskJSON(task domain.Task) (checklist, labels, attachments, recurrence string, err error) {
+	if checklist, err = encodeList(task.Checklist); err != nil {
+		return "", "", "", "", err
+	}
+	if labels, err = encodeList(task.Labels); err != nil {
+		return "", "", "", "", err
+	}
+	if attachments, err = encodeList(task.Attachments); err != nil {
+		return "", "", "", "", err
+	}
+	if task.Recurrence != nil {
+		b, err := json.Marshal(task.Recurrence)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		recurrence = string(b)
+	}
+	return checklist, labels, attachments, recurrence, nil
+}
+
+// encodeList encodes items as a JSON array, never as null.
+func encodeList[T any](items []T) (string, error) {
+	if items == nil {
+		items = []T{}
+	}
+	b, err := json.Marshal(items)
+	return string(b), err
+}
+
+// decodeList decodes a JSON array column, leaving dst nil when it is empty.
+func decodeList[T any](raw string, dst *[]T) error {
+	if err := json.Unmarshal([]byte(raw), dst); err != nil {
+		return err
+	}
+	if len(*dst) == 0 {
+		*dst = nil
+	}
+	return nil
+}
+
+// toNanos and fromNanos convert between time.Time and the integer timestamps
+// stored in SQL tables, mapping the zero time to 0.
+func toNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromNanos(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// SQLTokenRepository is an ITokenRepository backed by the refresh_tokens,
+// revoked_tokens and password_reset_tokens tables.
+type SQLTokenRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLTokenRepository(db *sql.DB, timeouts Timeouts) *SQLTokenRepository {
+	return &SQLTokenRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLTokenRepository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO refresh_tokens (hash, user_id, expires_at, created_at, revoked) VALUES (?, ?, ?, ?, ?)",
+		token.Hash, token.UserID, toNanos(token.ExpiresAt), toNanos(token.CreatedAt), token.Revoked)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateEntry
+	}
+	return err
+}
+
+func (r *SQLTokenRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var token domain.RefreshToken
+	var expiresAt, createdAt int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT hash, user_id, expires_at, created_at, revoked FROM refresh_tokens WHERE hash = ?", hash).
+		Scan(&token.Hash, &token.UserID, &expiresAt, &createdAt, &token.Revoked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	token.ExpiresAt = fromNanos(expiresAt)
+	token.CreatedAt = fromNanos(createdAt)
+	return &token, nil
+}
+
+func (r *SQLTokenRepository) RevokeRefreshToken(ctx context.Context, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked = 1 WHERE hash = ? AND revoked = 0", hash)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLTokenRepository) DeleteRefreshToken(ctx context.Context, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE hash = ?", hash)
+	return err
+}
+
+func (r *SQLTokenRepository) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE user_id = ?", userID)
+	return err
+}
+
+func (r *SQLTokenRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	// Piggyback cleanup of entries whose tokens have expired anyway
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < ?", toNanos(time.Now())); err != nil {
+		return err
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO revoked_tokens (id, expires_at) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET expires_at = excluded.expires_at",
+		tokenID, toNanos(expiresAt))
+	return err
+}
+
+func (r *SQLTokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM revoked_tokens WHERE id = ? AND expires_at >= ?", tokenID, toNanos(time.Now())).
+		Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *SQLTokenRepository) CreatePasswordResetToken(ctx context.Context, token domain.PasswordResetToken) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO password_reset_tokens (hash, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)",
+		token.Hash, token.UserID, toNanos(token.ExpiresAt), toNanos(token.CreatedAt))
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateEntry
+	}
+	return err
+}
+
+func (r *SQLTokenRepository) ConsumePasswordResetToken(ctx context.Context, hash string) (*domain.PasswordResetToken, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	var token domain.PasswordResetToken
+	var expiresAt, createdAt int64
+	err := r.db.QueryRowContext(ctx,
+		"DELETE FROM password_reset_tokens WHERE hash = ? RETURNING hash, user_id, expires_at, created_at", hash).
+		Scan(&token.Hash, &token.UserID, &expiresAt, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	token.ExpiresAt = fromNanos(expiresAt)
+	token.CreatedAt = fromNanos(createdAt)
+	if time.Now().After(token.ExpiresAt) {
+		return nil, domain.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *SQLTokenRepository) DeleteUserPasswordResetTokens(ctx context.Context, userID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	// Piggyback cleanup of tokens that expired without being redeemed
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM password_reset_tokens WHERE user_id = ? OR expires_at < ?", userID, toNanos(time.Now()))
+	return err
+}
+
+func (r *SQLTokenRepository) CreateMFAChallenge(ctx context.Context, challenge domain.MFAChallenge) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	// Piggyback cleanup of challenges that were abandoned
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM mfa_challenges WHERE expires_at < ?", toNanos(challenge.CreatedAt)); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO mfa_challenges (hash, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)",
+		challenge.Hash, challenge.UserID, toNanos(challenge.ExpiresAt), toNanos(challenge.CreatedAt))
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateEntry
+	}
+	return err
+}
+
+func (r *SQLTokenRepository) GetMFAChallenge(ctx context.Context, hash string) (*domain.MFAChallenge, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var challenge domain.MFAChallenge
+	var expiresAt, createdAt int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT hash, user_id, expires_at, created_at FROM mfa_challenges WHERE hash = ? AND expires_at > ?",
+		hash, toNanos(time.Now())).
+		Scan(&challenge.Hash, &challenge.UserID, &expiresAt, &createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	challenge.ExpiresAt = fromNanos(expiresAt)
+	challenge.CreatedAt = fromNanos(createdAt)
+	return &challenge, nil
+}
+
+func (r *SQLTokenRepository) DeleteMFAChallenge(ctx context.Context, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM mfa_challenges WHERE hash = ?", hash)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	domain "task-manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLUserRepository is an IUserRepository backed by the users table.
+type SQLUserRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLUserRepository(db *sql.DB, timeouts Timeouts) *SQLUserRepository {
+	return &SQLUserRepository{db: db, timeouts: timeouts}
+}
+
+const userColumns = "id, username, password, role, email, token_version, disabled"
+
+func (r *SQLUserRepository) Create(ctx context.Context, user domain.User) (*domain.User, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	// Set default role if not provided
+	if user.Role == "" {
+		user.Role = domain.RoleUser
+	}
+	user.ID = primitive.NewObjectID().Hex()
+
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		user.ID, user.Username, user.Password, user.Role, user.Email, user.TokenVersion, user.Disabled)
+	if err != nil {
+		// The unique index on username is the source of truth for duplicates
+		if isUniqueViolation(err) {
+			return nil, domain.ErrDuplicateEntry
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *SQLUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	return scanUser(r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE username = ?", username))
+}
+
+func (r *SQLUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	return scanUser(r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = ?", id))
+}
+
+func (r *SQLUserRepository) Promote(ctx context.Context, username string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET role = ? WHERE username = ?", domain.RoleAdmin, username)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLUserRepository) SetRole(ctx context.Context, id string, role domain.Role) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET role = ? WHERE id = ?", role, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLUserRepository) CountByRole(ctx context.Context, role domain.Role) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE role = ?", role).Scan(&count)
+	return count, err
+}
+
+func (r *SQLUserRepository) Find(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	var conds []string
+	var args []interface{}
+	if query.Role != "" {
+		conds = append(conds, "role = ?")
+		args = append(args, query.Role)
+	}
+	if query.Disabled != nil {
+		conds = append(conds, "disabled = ?")
+		args = append(args, *query.Disabled)
+	}
+	if query.Cursor != "" {
+		conds = append(conds, "id > ?")
+		args = append(args, query.Cursor)
+	}
+
+	stmt := "SELECT " + userColumns + " FROM users"
+	if len(conds) > 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	stmt += " ORDER BY id LIMIT ?"
+
+	rows, err := r.db.QueryContext(ctx, stmt, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return newUserPage(users, query.Limit), nil
+}
+
+func (r *SQLUserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET disabled = ? WHERE id = ?", disabled, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLUserRepository) SetPassword(ctx context.Context, id string, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET password = ? WHERE id = ?", hash, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes the user. Their refresh and password reset tokens go with
+// them through the foreign keys.
+func (r *SQLUserRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLUserRepository) Exists(ctx context.Context, username string) (bool, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE username = ?", username).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *SQLUserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "UPDATE users SET token_version = token_version + 1 WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var user domain.User
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.Email, &user.TokenVersion, &user.Disabled)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+			sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
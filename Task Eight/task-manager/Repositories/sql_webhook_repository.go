@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const webhookColumns = "id, url, secret, events, active, created_by, created_at, updated_at"
+
+// SQLWebhookRepository is an IWebhookRepository backed by the webhooks
+// table. The subscribed events are stored as a JSON array.
+type SQLWebhookRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLWebhookRepository(db *sql.DB, timeouts Timeouts) *SQLWebhookRepository {
+	return &SQLWebhookRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLWebhookRepository) Create(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return nil, err
+	}
+	webhook.ID = primitive.NewObjectID().Hex()
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+	_, err = r.db.ExecContext(ctx, "INSERT INTO webhooks ("+webhookColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		webhook.ID, webhook.URL, webhook.Secret, string(events), webhook.Active, webhook.CreatedBy,
+		toNanos(webhook.CreatedAt), toNanos(webhook.UpdatedAt))
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *SQLWebhookRepository) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return webhook, err
+}
+
+func (r *SQLWebhookRepository) GetAll(ctx context.Context) ([]domain.Webhook, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []domain.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *SQLWebhookRepository) Update(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.db.ExecContext(ctx, "UPDATE webhooks SET url = ?, secret = ?, events = ?, active = ?, updated_at = ? WHERE id = ?",
+		webhook.URL, webhook.Secret, string(events), webhook.Active, toNanos(time.Now()), webhook.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return r.GetByID(ctx, webhook.ID)
+}
+
+// Delete removes the webhook; its deliveries go with it through the
+// foreign key.
+func (r *SQLWebhookRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanWebhook(row rowScanner) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	var events string
+	var createdAt, updatedAt int64
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.Active, &webhook.CreatedBy, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &webhook.Events); err != nil {
+		return nil, err
+	}
+	webhook.CreatedAt = fromNanos(createdAt)
+	webhook.UpdatedAt = fromNanos(updatedAt)
+	return &webhook, nil
+}
+
+const webhookDeliveryColumns = "id, webhook_id, event_id, event_type, payload, status, attempts, response_status, error, next_attempt_at, last_attempt_at, replay_of, created_at"
+
+// SQLWebhookDeliveryRepository is an IWebhookDeliveryRepository backed by
+// the webhook_deliveries table.
+type SQLWebhookDeliveryRepository struct {
+	db       *sql.DB
+	timeouts Timeouts
+}
+
+func NewSQLWebhookDeliveryRepository(db *sql.DB, timeouts Timeouts) *SQLWebhookDeliveryRepository {
+	return &SQLWebhookDeliveryRepository{db: db, timeouts: timeouts}
+}
+
+func (r *SQLWebhookDeliveryRepository) Create(ctx context.Context, delivery domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	delivery.ID = primitive.NewObjectID().Hex()
+	delivery.CreatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, "INSERT INTO webhook_deliveries ("+webhookDeliveryColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.Status,
+		delivery.Attempts, delivery.ResponseStatus, delivery.Error, toNanos(delivery.NextAttemptAt),
+		toNanos(delivery.LastAttemptAt), delivery.ReplayOf, toNanos(delivery.CreatedAt))
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *SQLWebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return delivery, err
+}
+
+func (r *SQLWebhookDeliveryRepository) Update(ctx context.Context, delivery domain.WebhookDelivery) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE webhook_deliveries SET status = ?, attempts = ?, response_status = ?, error = ?, next_attempt_at = ?, last_attempt_at = ? WHERE id = ?",
+		delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		toNanos(delivery.NextAttemptAt), toNanos(delivery.LastAttemptAt), delivery.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLWebhookDeliveryRepository) Find(ctx context.Context, query domain.WebhookDeliveryQuery) (*domain.WebhookDeliveryPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	stmt := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE webhook_id = ?"
+	args := []interface{}{query.WebhookID}
+	if query.Status != "" {
+		stmt += " AND status = ?"
+		args = append(args, query.Status)
+	}
+	if query.Cursor != "" {
+		stmt += " AND id < ?"
+		args = append(args, query.Cursor)
+	}
+	stmt += " ORDER BY id DESC LIMIT ?"
+
+	deliveries, err := r.query(ctx, stmt, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	return newWebhookDeliveryPage(deliveries, query.Limit), nil
+}
+
+func (r *SQLWebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.query(ctx,
+		"SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?",
+		domain.DeliveryPending, toNanos(now), limit)
+}
+
+func (r *SQLWebhookDeliveryRepository) DeleteByWebhook(ctx context.Context, webhookID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE webhook_id = ?", webhookID)
+	return err
+}
+
+func (r *SQLWebhookDeliveryRepository) query(ctx context.Context, stmt string, args ...interface{}) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhookDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var nextAttemptAt, lastAttemptAt, createdAt int64
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.Error, &nextAttemptAt, &lastAttemptAt, &d.ReplayOf, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	d.NextAttemptAt = fromNanos(nextAttemptAt)
+	d.LastAttemptAt = fromNanos(lastAttemptAt)
+	d.CreatedAt = fromNanos(createdAt)
+	return &d, nil
+}
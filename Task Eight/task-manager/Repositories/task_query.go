@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// taskCursor is the decoded form of the opaque pagination cursor. It holds
+// the sort key and ID of the last task on the previous page so the next
+// page can resume right after it.
+type taskCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeTaskCursor(task domain.Task, sortBy string) string {
+	c := taskCursor{Value: taskSortValue(task, sortBy), ID: task.ID}
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeTaskCursor(cursor string) (*taskCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	var c taskCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	return &c, nil
+}
+
+// taskSortValue returns the string form of the task's sort key as stored in a cursor.
+func taskSortValue(task domain.Task, sortBy string) string {
+	switch sortBy {
+	case domain.SortByDueDate:
+		return task.DueDate.UTC().Format(time.RFC3339Nano)
+	case domain.SortByTitle:
+		return task.Title
+	case domain.SortByStatus:
+		return string(task.Status)
+	default:
+		return task.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// isTimeSortField reports whether the cursor value must be parsed as a timestamp.
+func isTimeSortField(sortBy string) bool {
+	return sortBy == domain.SortByCreatedAt || sortBy == domain.SortByDueDate
+}
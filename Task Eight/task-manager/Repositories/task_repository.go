@@ -3,27 +3,75 @@ package repositories
 import (
 	"context"
 	"errors"
+	"regexp"
+	"strconv"
+	"strings"
 	"task-manager/Domain"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TaskRepository struct {
 	collection *mongo.Collection
+	timeouts   Timeouts
 }
 
-func NewTaskRepository(collection *mongo.Collection) *TaskRepository {
-	return &TaskRepository{collection: collection}
+func NewTaskRepository(collection *mongo.Collection, timeouts Timeouts) *TaskRepository {
+	return &TaskRepository{collection: collection, timeouts: timeouts}
 }
 
-func (r *TaskRepository) Create(task domain.Task) (*domain.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// EnsureIndexes creates the indexes backing project listings, subtask
+// lookups, label filters and search.
+func (r *TaskRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		// Multikey index, one entry per attached label
+		{Keys: bson.D{{Key: "labels", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			// No stemming or stop words, so words match as they do in the
+			// in-process index
+			Options: options.Index().
+				SetWeights(bson.D{{Key: "title", Value: domain.SearchTitleWeight}, {Key: "description", Value: 1}}).
+				SetDefaultLanguage("none"),
+		},
+	})
+	return err
+}
+
+// AdoptUnscopedTasks moves the tasks stored before projects were introduced
+// into a new project, which is only created when there are such tasks.
+func (r *TaskRepository) AdoptUnscopedTasks(ctx context.Context, projects domain.IProjectRepository) error {
+	unscoped := bson.M{"project_id": bson.M{"$exists": false}}
+	n, err := r.collection.CountDocuments(ctx, unscoped)
+	if err != nil || n == 0 {
+		return err
+	}
+	project, err := projects.Create(ctx, domain.Project{
+		Name:        domain.UnscopedProjectName,
+		Description: "Tasks created before projects existed",
+		Members:     []domain.ProjectMember{},
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+	_, err = r.collection.UpdateMany(ctx, unscoped, bson.M{"$set": bson.M{"project_id": project.ID}})
+	return err
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task domain.Task) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	task.ID = ""
+	task.Version = 1
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
 
@@ -36,9 +84,9 @@ func (r *TaskRepository) Create(task domain.Task) (*domain.Task, error) {
 	return &task, nil
 }
 
-func (r *TaskRepository) GetAll() ([]domain.Task, error) {
+func (r *TaskRepository) GetAll(ctx context.Context) ([]domain.Task, error) {
 	var tasks []domain.Task
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := r.timeouts.query(ctx)
 	defer cancel()
 
 	cursor, err := r.collection.Find(ctx, bson.M{})
@@ -56,8 +104,198 @@ func (r *TaskRepository) GetAll() ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (r *TaskRepository) GetByID(id string) (*domain.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// Find returns one page of tasks matching the query, ordered by the requested
+// sort field with the task ID as a tie-breaker so cursors stay stable.
+func (r *TaskRepository) Find(ctx context.Context, query domain.TaskQuery) (*domain.TaskPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	filter := taskQueryFilter(query)
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Cursor != "" {
+		after, err := cursorFilter(query)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.M{"$and": []bson.M{filter, after}}
+	}
+
+	direction := 1
+	if query.SortDesc {
+		direction = -1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: query.SortBy, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(query.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []domain.Task
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+
+	page := &domain.TaskPage{Tasks: tasks, Total: total}
+	if len(tasks) > query.Limit {
+		page.Tasks = tasks[:query.Limit]
+		page.NextCursor = encodeTaskCursor(page.Tasks[query.Limit-1], query.SortBy)
+	}
+	if page.Tasks == nil {
+		page.Tasks = []domain.Task{}
+	}
+	return page, nil
+}
+
+// Search runs the query against the text index. Words and phrases go to
+// $text, which ranks the results; prefixes, which $text cannot express, are
+// matched with anchored regular expressions. A search of prefixes alone has
+// no text score and lists the newest tasks first.
+func (r *TaskRepository) Search(ctx context.Context, query domain.TaskSearchQuery) (*domain.TaskSearchPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var conditions []bson.M
+	var search []string
+	search = append(search, query.Terms...)
+	for _, phrase := range query.Phrases {
+		search = append(search, `"`+strings.Join(phrase, " ")+`"`)
+	}
+	if len(search) > 0 {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": strings.Join(search, " ")}})
+	}
+	for _, prefix := range query.Prefixes {
+		pattern := bson.M{"$regex": `\b` + regexp.QuoteMeta(prefix), "$options": "i"}
+		conditions = append(conditions, bson.M{"$or": []bson.M{{"title": pattern}, {"description": pattern}}})
+	}
+	conditions = append(conditions, bson.M{"project_id": query.ProjectID})
+	if query.VisibleTo != "" {
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"created_by": query.VisibleTo},
+			{"assignee_id": query.VisibleTo},
+		}})
+	}
+
+	opts := options.Find().SetSkip(int64(query.Offset)).SetLimit(int64(query.Limit + 1))
+	if len(search) > 0 {
+		score := bson.M{"$meta": "textScore"}
+		opts.SetProjection(bson.M{"score": score}).SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}})
+	} else {
+		opts.SetSort(bson.D{{Key: "_id", Value: -1}})
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"$and": conditions}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		domain.Task `bson:",inline"`
+		Score       float64 `bson:"score"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	hits := make([]domain.TaskSearchHit, len(results))
+	for i, res := range results {
+		hits[i] = domain.TaskSearchHit{Task: res.Task, Score: res.Score}
+	}
+	return newTaskSearchPage(hits, query.Limit, query.Offset+query.Limit), nil
+}
+
+// newTaskSearchPage trims hits fetched with limit+1 results to a page. next
+// is the offset of the first result after the page.
+func newTaskSearchPage(hits []domain.TaskSearchHit, limit int, next int) *domain.TaskSearchPage {
+	page := &domain.TaskSearchPage{Hits: hits}
+	if len(hits) > limit {
+		page.Hits = hits[:limit]
+		page.NextCursor = strconv.Itoa(next)
+	}
+	return page
+}
+
+// taskQueryFilter translates the query's filters (but not its cursor) into a Mongo filter.
+func taskQueryFilter(query domain.TaskQuery) bson.M {
+	filter := bson.M{}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if !query.DueAfter.IsZero() || !query.DueBefore.IsZero() {
+		due := bson.M{}
+		if !query.DueAfter.IsZero() {
+			due["$gte"] = query.DueAfter
+		}
+		if !query.DueBefore.IsZero() {
+			due["$lte"] = query.DueBefore
+		}
+		filter["due_date"] = due
+	}
+	if query.Title != "" {
+		filter["title"] = bson.M{"$regex": regexp.QuoteMeta(query.Title), "$options": "i"}
+	}
+	if query.ProjectID != "" {
+		filter["project_id"] = query.ProjectID
+	}
+	if query.VisibleTo != "" {
+		filter["$or"] = []bson.M{
+			{"created_by": query.VisibleTo},
+			{"assignee_id": query.VisibleTo},
+		}
+	}
+	if len(query.LabelsAny) > 0 || len(query.LabelsAll) > 0 {
+		labels := bson.M{}
+		if len(query.LabelsAny) > 0 {
+			labels["$in"] = query.LabelsAny
+		}
+		if len(query.LabelsAll) > 0 {
+			labels["$all"] = query.LabelsAll
+		}
+		filter["labels"] = labels
+	}
+	return filter
+}
+
+// cursorFilter matches the tasks that sort strictly after the cursor position.
+func cursorFilter(query domain.TaskQuery) (bson.M, error) {
+	c, err := decodeTaskCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	var value interface{} = c.Value
+	if isTimeSortField(query.SortBy) {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+		value = t
+	}
+
+	op := "$gt"
+	if query.SortDesc {
+		op = "$lt"
+	}
+	return bson.M{"$or": []bson.M{
+		{query.SortBy: bson.M{op: value}},
+		{query.SortBy: value, "_id": bson.M{op: objID}},
+	}}, nil
+}
+
+func (r *TaskRepository) GetByID(ctx context.Context, projectID string, id string) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -66,17 +304,17 @@ func (r *TaskRepository) GetByID(id string) (*domain.Task, error) {
 	}
 
 	var task domain.Task
-	if err := r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&task); err != nil {
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objID, "project_id": projectID}).Decode(&task); err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("task not found")
+			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (r *TaskRepository) Update(id string, task domain.Task) (*domain.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *TaskRepository) Update(ctx context.Context, projectID string, id string, task domain.Task) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -90,20 +328,32 @@ func (r *TaskRepository) Update(id string, task domain.Task) (*domain.Task, erro
 			"description": task.Description,
 			"due_date":    task.DueDate,
 			"status":      task.Status,
+			"checklist":   task.Checklist,
+			"labels":      task.Labels,
+			"attachments": task.Attachments,
+			"recurrence":  task.Recurrence,
 			"updated_at":  time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID, "project_id": projectID, "version": versionFilter(task.Version)}, update)
 	if err != nil {
 		return nil, err
 	}
+	if res.MatchedCount == 0 {
+		// Either the task is gone or its version moved on
+		if _, err := r.GetByID(ctx, projectID, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrVersionConflict
+	}
 
-	return r.GetByID(id) // Return the updated document
+	return r.GetByID(ctx, projectID, id) // Return the updated document
 }
 
-func (r *TaskRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *TaskRepository) Delete(ctx context.Context, projectID string, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -111,14 +361,90 @@ func (r *TaskRepository) Delete(id string) error {
 		return errors.New("invalid task ID format")
 	}
 
-	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID, "project_id": projectID})
 	if err != nil {
 		return err
 	}
 
 	if res.DeletedCount == 0 {
-		return errors.New("task not found")
+		return domain.ErrNotFound
 	}
 
 	return nil
 }
+
+// Assign sets the assignee of a task. An empty assigneeID removes the assignee.
+func (r *TaskRepository) Assign(ctx context.Context, projectID string, id string, assigneeID string) (*domain.Task, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid task ID format")
+	}
+
+	update := bson.M{
+		"$set": bson.M{"assignee_id": assigneeID, "updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	if assigneeID == "" {
+		update = bson.M{
+			"$unset": bson.M{"assignee_id": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$inc":   bson.M{"version": 1},
+		}
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID, "project_id": projectID}, update)
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	return r.GetByID(ctx, projectID, id)
+}
+
+// GetChildren returns the direct subtasks of a task, oldest first.
+func (r *TaskRepository) GetChildren(ctx context.Context, projectID string, parentID string) ([]domain.Task, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"project_id": projectID, "parent_id": parentID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []domain.Task
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	if tasks == nil {
+		return []domain.Task{}, nil
+	}
+	return tasks, nil
+}
+
+func (r *TaskRepository) RemoveLabelFromAll(ctx context.Context, label string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.UpdateMany(ctx, bson.M{"labels": label}, bson.M{
+		"$pull": bson.M{"labels": label},
+		"$set":  bson.M{"updated_at": time.Now()},
+		"$inc":  bson.M{"version": 1},
+	})
+	return err
+}
+
+// versionFilter matches the given task version. Tasks stored before versioning
+// was introduced have no version field and are treated as version 0.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	domain "task-manager/Domain"
+)
+
+// TaskSearchIndex adds full-text search to a task repository without one of
+// its own. It wraps the repository and keeps an inverted index of task
+// titles and descriptions up to date with the writes made through it, so
+// every write must go through the index. Results are ranked by TF-IDF, with
+// title words weighted by domain.SearchTitleWeight.
+type TaskSearchIndex struct {
+	domain.ITaskRepository
+
+	mu   sync.RWMutex
+	docs map[string]*indexedTask
+	// postings maps each word to the tasks containing it.
+	postings map[string]map[string]struct{}
+}
+
+// indexedTask is what the index keeps of a task.
+type indexedTask struct {
+	title       []string
+	description []string
+	// weights holds each word's weighted number of occurrences.
+	weights    map[string]float64
+	projectID  string
+	createdBy  string
+	assigneeID string
+	version    int64
+}
+
+// NewTaskSearchIndex indexes every task already in the repository.
+func NewTaskSearchIndex(ctx context.Context, repo domain.ITaskRepository) (*TaskSearchIndex, error) {
+	tasks, err := repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := &TaskSearchIndex{
+		ITaskRepository: repo,
+		docs:            make(map[string]*indexedTask),
+		postings:        make(map[string]map[string]struct{}),
+	}
+	for i := range tasks {
+		index.add(&tasks[i])
+	}
+	return index, nil
+}
+
+func (r *TaskSearchIndex) Create(ctx context.Context, task domain.Task) (*domain.Task, error) {
+	created, err := r.ITaskRepository.Create(ctx, task)
+	if err == nil {
+		r.reindex(created)
+	}
+	return created, err
+}
+
+func (r *TaskSearchIndex) Update(ctx context.Context, projectID string, id string, task domain.Task) (*domain.Task, error) {
+	updated, err := r.ITaskRepository.Update(ctx, projectID, id, task)
+	if err == nil {
+		r.reindex(updated)
+	}
+	return updated, err
+}
+
+func (r *TaskSearchIndex) Assign(ctx context.Context, projectID string, id string, assigneeID string) (*domain.Task, error) {
+	assigned, err := r.ITaskRepository.Assign(ctx, projectID, id, assigneeID)
+	if err == nil {
+		r.reindex(assigned)
+	}
+	return assigned, err
+}
+
+func (r *TaskSearchIndex) Delete(ctx context.Context, projectID string, id string) error {
+	if err := r.ITaskRepository.Delete(ctx, projectID, id); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remove(id)
+	return nil
+}
+
+func (r *TaskSearchIndex) Search(ctx context.Context, query domain.TaskSearchQuery) (*domain.TaskSearchPage, error) {
+	hits := r.rank(query)
+
+	// Fetch one task more than the page so the page knows whether it is the
+	// last. Tasks deleted or reassigned since ranking are skipped, so the
+	// next page starts after the last hit on this one rather than a page on.
+	var page []domain.TaskSearchHit
+	next := 0
+	for i, hit := range hits[min(query.Offset, len(hits)):] {
+		if len(page) > query.Limit {
+			break
+		}
+		task, err := r.ITaskRepository.GetByID(ctx, hit.Task.ProjectID, hit.Task.ID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if query.VisibleTo != "" && task.CreatedBy != query.VisibleTo && task.AssigneeID != query.VisibleTo {
+			continue
+		}
+		hit.Task = *task
+		page = append(page, hit)
+		if len(page) == query.Limit {
+			next = query.Offset + i + 1
+		}
+	}
+	return newTaskSearchPage(page, query.Limit, next), nil
+}
+
+// rank returns every match of the query, best first, holding only the task
+// and project IDs.
+func (r *TaskSearchIndex) rank(query domain.TaskSearchQuery) []domain.TaskSearchHit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Every word that counts towards the score
+	words := append([]string{}, query.Terms...)
+	for _, phrase := range query.Phrases {
+		words = append(words, phrase...)
+	}
+	for _, prefix := range query.Prefixes {
+		for word := range r.postings {
+			if strings.HasPrefix(word, prefix) {
+				words = append(words, word)
+			}
+		}
+	}
+
+	var hits []domain.TaskSearchHit
+	for id := range r.candidates(query) {
+		doc := r.docs[id]
+		if query.ProjectID != "" && doc.projectID != query.ProjectID {
+			continue
+		}
+		if query.VisibleTo != "" && doc.createdBy != query.VisibleTo && doc.assigneeID != query.VisibleTo {
+			continue
+		}
+		if !query.Matches(doc.title, doc.description) {
+			continue
+		}
+		score := 0.0
+		for _, word := range words {
+			if weight := doc.weights[word]; weight > 0 {
+				score += weight * math.Log(1+float64(len(r.docs))/float64(len(r.postings[word])))
+			}
+		}
+		hits = append(hits, domain.TaskSearchHit{Task: domain.Task{ID: id, ProjectID: doc.projectID}, Score: score})
+	}
+
+	// Newest first among equal scores, as IDs grow over time
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Task.ID > hits[j].Task.ID
+	})
+	return hits
+}
+
+// candidates narrows the search to the tasks containing a word every match
+// must have: one of the words, the first word of a phrase or a word with
+// the first prefix. Callers hold the lock.
+func (r *TaskSearchIndex) candidates(query domain.TaskSearchQuery) map[string]struct{} {
+	var words []string
+	switch {
+	case len(query.Phrases) > 0:
+		words = query.Phrases[0][:1]
+	case len(query.Terms) > 0:
+		words = query.Terms
+	default:
+		for word := range r.postings {
+			if strings.HasPrefix(word, query.Prefixes[0]) {
+				words = append(words, word)
+			}
+		}
+	}
+
+	ids := make(map[string]struct{})
+	for _, word := range words {
+		for id := range r.postings[word] {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// reindex replaces the indexed copy of a task after a write. Writes are
+// indexed outside the repository's lock, so a slower one may arrive after a
+// newer write and is dropped.
+func (r *TaskSearchIndex) reindex(task *domain.Task) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if doc, ok := r.docs[task.ID]; ok && doc.version > task.Version {
+		return
+	}
+	r.remove(task.ID)
+	r.add(task)
+}
+
+// add indexes a task. Callers hold the lock.
+func (r *TaskSearchIndex) add(task *domain.Task) {
+	doc := &indexedTask{
+		title:       domain.SearchTokens(task.Title),
+		description: domain.SearchTokens(task.Description),
+		weights:     make(map[string]float64),
+		projectID:   task.ProjectID,
+		createdBy:   task.CreatedBy,
+		assigneeID:  task.AssigneeID,
+		version:     task.Version,
+	}
+	for _, word := range doc.title {
+		doc.weights[word] += domain.SearchTitleWeight
+	}
+	for _, word := range doc.description {
+		doc.weights[word]++
+	}
+
+	r.docs[task.ID] = doc
+	for word := range doc.weights {
+		if r.postings[word] == nil {
+			r.postings[word] = make(map[string]struct{})
+		}
+		r.postings[word][task.ID] = struct{}{}
+	}
+}
+
+// remove drops a task from the index. Callers hold the lock.
+func (r *TaskSearchIndex) remove(id string) {
+	doc, ok := r.docs[id]
+	if !ok {
+		return
+	}
+	delete(r.docs, id)
+	for word := range doc.weights {
+		delete(r.postings[word], id)
+		if len(r.postings[word]) == 0 {
+			delete(r.postings, word)
+		}
+	}
+}
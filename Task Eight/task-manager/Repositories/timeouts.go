@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts bounds how long each kind of repository operation may run. The
+// deadline is applied on top of the caller's context, so a cancelled request
+// still stops the operation early.
+type Timeouts struct {
+	Read  time.Duration // single document lookups
+	Write time.Duration // inserts, updates and deletes
+	Query time.Duration // listings, counts and other multi-document reads
+}
+
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Read:  5 * time.Second,
+		Write: 5 * time.Second,
+		Query: 10 * time.Second,
+	}
+}
+
+func (t Timeouts) read(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Read)
+}
+
+func (t Timeouts) write(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Write)
+}
+
+func (t Timeouts) query(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Query)
+}
+
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TokenRepository stores hashed refresh tokens, password reset tokens and
+// two-factor challenges, and the IDs of access tokens revoked before their
+// natural expiry.
+type TokenRepository struct {
+	refreshTokens *mongo.Collection
+	revokedTokens *mongo.Collection
+	resetTokens   *mongo.Collection
+	challenges    *mongo.Collection
+	timeouts      Timeouts
+}
+
+func NewTokenRepository(refreshTokens *mongo.Collection, revokedTokens *mongo.Collection, resetTokens *mongo.Collection, challenges *mongo.Collection, timeouts Timeouts) *TokenRepository {
+	return &TokenRepository{
+		refreshTokens: refreshTokens,
+		revokedTokens: revokedTokens,
+		resetTokens:   resetTokens,
+		challenges:    challenges,
+		timeouts:      timeouts,
+	}
+}
+
+// EnsureIndexes creates TTL indexes so expired entries are removed by MongoDB.
+func (r *TokenRepository) EnsureIndexes(ctx context.Context) error {
+	ttl := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := r.refreshTokens.Indexes().CreateOne(ctx, ttl); err != nil {
+		return err
+	}
+	if _, err := r.refreshTokens.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}}); err != nil {
+		return err
+	}
+	if _, err := r.revokedTokens.Indexes().CreateOne(ctx, ttl); err != nil {
+		return err
+	}
+	if _, err := r.resetTokens.Indexes().CreateOne(ctx, ttl); err != nil {
+		return err
+	}
+	if _, err := r.resetTokens.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}}); err != nil {
+		return err
+	}
+	_, err := r.challenges.Indexes().CreateOne(ctx, ttl)
+	return err
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.refreshTokens.InsertOne(ctx, token)
+	return err
+}
+
+func (r *TokenRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var token domain.RefreshToken
+	if err := r.refreshTokens.FindOne(ctx, bson.M{"_id": hash}).Decode(&token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	// Only one of two concurrent refreshes may rotate the token out
+	res, err := r.refreshTokens.UpdateOne(ctx, bson.M{"_id": hash, "revoked": false}, bson.M{"$set": bson.M{"revoked": true}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *TokenRepository) DeleteRefreshToken(ctx context.Context, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.refreshTokens.DeleteOne(ctx, bson.M{"_id": hash})
+	return err
+}
+
+func (r *TokenRepository) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.refreshTokens.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	opts := options.Update().SetUpsert(true)
+	_, err := r.revokedTokens.UpdateOne(ctx,
+		bson.M{"_id": tokenID},
+		bson.M{"$set": bson.M{"expires_at": expiresAt}},
+		opts,
+	)
+	return err
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	count, err := r.revokedTokens.CountDocuments(ctx, bson.M{"_id": tokenID})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *TokenRepository) CreatePasswordResetToken(ctx context.Context, token domain.PasswordResetToken) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.resetTokens.InsertOne(ctx, token)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateEntry
+	}
+	return err
+}
+
+func (r *TokenRepository) ConsumePasswordResetToken(ctx context.Context, hash string) (*domain.PasswordResetToken, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	// The TTL monitor only runs once a minute, so check the expiry as well
+	var token domain.PasswordResetToken
+	err := r.resetTokens.FindOneAndDelete(ctx, bson.M{"_id": hash}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, domain.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *TokenRepository) DeleteUserPasswordResetTokens(ctx context.Context, userID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.resetTokens.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+func (r *TokenRepository) CreateMFAChallenge(ctx context.Context, challenge domain.MFAChallenge) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.challenges.InsertOne(ctx, challenge)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateEntry
+	}
+	return err
+}
+
+func (r *TokenRepository) GetMFAChallenge(ctx context.Context, hash string) (*domain.MFAChallenge, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	// The TTL monitor only runs once a minute, so check the expiry as well
+	var challenge domain.MFAChallenge
+	err := r.challenges.FindOne(ctx, bson.M{"_id": hash, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&challenge)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *TokenRepository) DeleteMFAChallenge(ctx context.Context, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.challenges.DeleteOne(ctx, bson.M{"_id": hash})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -4,31 +4,33 @@ import (
 	"context"
 	"errors"
 	domain "task-manager/Domain"
-	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository struct {
 	collection      *mongo.Collection
 	passwordService domain.IPasswordService
+	timeouts        Timeouts
 }
 
-func NewUserRepository(collection *mongo.Collection, passwordService domain.IPasswordService) *UserRepository {
+func NewUserRepository(collection *mongo.Collection, passwordService domain.IPasswordService, timeouts Timeouts) *UserRepository {
 	return &UserRepository{
 		collection:      collection,
 		passwordService: passwordService,
+		timeouts:        timeouts,
 	}
 }
 
-func (r *UserRepository) Create(user domain.User) (*domain.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) Create(ctx context.Context, user domain.User) (*domain.User, error) {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	// Check if user already exists
-	exists, err := r.Exists(user.Username)
+	exists, err := r.Exists(ctx, user.Username)
 	if err != nil {
 		return nil, err
 	}
@@ -50,8 +52,8 @@ func (r *UserRepository) Create(user domain.User) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) GetByUsername(username string) (*domain.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	var user domain.User
@@ -65,13 +67,13 @@ func (r *UserRepository) GetByUsername(username string) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) GetByID(id string) (*domain.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return nil, domain.ErrNotFound
 	}
 
 	var user domain.User
@@ -85,8 +87,8 @@ func (r *UserRepository) GetByID(id string) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) Promote(username string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) Promote(ctx context.Context, username string) error {
+	ctx, cancel := r.timeouts.write(ctx)
 	defer cancel()
 
 	update := bson.M{
@@ -107,8 +109,125 @@ func (r *UserRepository) Promote(username string) error {
 	return nil
 }
 
-func (r *UserRepository) Exists(username string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *UserRepository) SetRole(ctx context.Context, id string, role domain.Role) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrNotFound
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"role": role}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) CountByRole(ctx context.Context, role domain.Role) (int64, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	return r.collection.CountDocuments(ctx, bson.M{"role": role})
+}
+
+func (r *UserRepository) Find(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	filter := bson.M{}
+	if query.Role != "" {
+		filter["role"] = query.Role
+	}
+	if query.Disabled != nil {
+		// Users stored before accounts could be disabled lack the field
+		filter["disabled"] = bson.M{"$ne": !*query.Disabled}
+	}
+	if query.Cursor != "" {
+		after, err := primitive.ObjectIDFromHex(query.Cursor)
+		if err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(query.Limit + 1))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []domain.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return newUserPage(users, query.Limit), nil
+}
+
+func (r *UserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrNotFound
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"disabled": disabled}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) SetPassword(ctx context.Context, id string, hash string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrNotFound
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"password": hash}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) Exists(ctx context.Context, username string) (bool, error) {
+	ctx, cancel := r.timeouts.read(ctx)
 	defer cancel()
 
 	count, err := r.collection.CountDocuments(ctx, bson.M{"username": username})
@@ -118,3 +237,35 @@ func (r *UserRepository) Exists(username string) (bool, error) {
 
 	return count > 0, nil
 }
+
+// IncrementTokenVersion invalidates every access token issued to the user so far.
+func (r *UserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$inc": bson.M{"token_version": 1}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// newUserPage trims the extra user fetched to detect a further page.
+func newUserPage(users []domain.User, limit int) *domain.UserPage {
+	page := &domain.UserPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.NextCursor = page.Users[limit-1].ID
+	}
+	return page
+}
@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookRepository stores webhook subscriptions. Webhook IDs are ObjectID
+// hex strings.
+type WebhookRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewWebhookRepository(collection *mongo.Collection, timeouts Timeouts) *WebhookRepository {
+	return &WebhookRepository{collection: collection, timeouts: timeouts}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	webhook.ID = primitive.NewObjectID().Hex()
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+	if _, err := r.collection.InsertOne(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var webhook domain.Webhook
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) GetAll(ctx context.Context) ([]domain.Webhook, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	webhooks := []domain.Webhook{}
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"url":        webhook.URL,
+		"secret":     webhook.Secret,
+		"events":     webhook.Events,
+		"active":     webhook.Active,
+		"updated_at": time.Now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var updated domain.Webhook
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": webhook.ID}, update, opts).Decode(&updated); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// WebhookDeliveryRepository stores the delivery logs of all webhooks.
+// Delivery IDs are ObjectID hex strings, which sort in creation order and
+// double as the pagination cursor.
+type WebhookDeliveryRepository struct {
+	collection *mongo.Collection
+	timeouts   Timeouts
+}
+
+func NewWebhookDeliveryRepository(collection *mongo.Collection, timeouts Timeouts) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{collection: collection, timeouts: timeouts}
+}
+
+// EnsureIndexes creates the indexes backing the delivery log and the
+// dispatcher's lookup of due deliveries.
+func (r *WebhookDeliveryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	delivery.ID = primitive.NewObjectID().Hex()
+	delivery.CreatedAt = time.Now()
+	if _, err := r.collection.InsertOne(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var delivery domain.WebhookDelivery
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&delivery); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery domain.WebhookDelivery) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"response_status": delivery.ResponseStatus,
+		"error":           delivery.Error,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"last_attempt_at": delivery.LastAttemptAt,
+	}}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": delivery.ID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) Find(ctx context.Context, query domain.WebhookDeliveryQuery) (*domain.WebhookDeliveryPage, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	if err := validateIDCursor(query.Cursor); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"webhook_id": query.WebhookID}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if query.Cursor != "" {
+		filter["_id"] = bson.M{"$lt": query.Cursor}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(query.Limit + 1))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []domain.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return newWebhookDeliveryPage(deliveries, query.Limit), nil
+}
+
+func (r *WebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	filter := bson.M{"status": domain.DeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+	opts := options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []domain.WebhookDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookDeliveryRepository) DeleteByWebhook(ctx context.Context, webhookID string) error {
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"webhook_id": webhookID})
+	return err
+}
+
+// newWebhookDeliveryPage trims a result fetched with limit+1 deliveries to a
+// page.
+func newWebhookDeliveryPage(deliveries []domain.WebhookDelivery, limit int) *domain.WebhookDeliveryPage {
+	page := &domain.WebhookDeliveryPage{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		page.NextCursor = page.Deliveries[limit-1].ID
+	}
+	return page
+}
@@ -0,0 +1,172 @@
+package usecases
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// sniffLength is the number of leading bytes http.DetectContentType looks at.
+const sniffLength = 512
+
+type AttachmentUseCase struct {
+	taskRepo    domain.ITaskRepository
+	projectRepo domain.IProjectRepository
+	blobStore   domain.IBlobStore
+	auditRepo   domain.IAuditRepository
+	events      domain.IEventPublisher
+	maxSize     int64
+}
+
+// NewAttachmentUseCase creates the attachment use case. maxSize is the
+// largest accepted upload in bytes.
+func NewAttachmentUseCase(taskRepo domain.ITaskRepository, projectRepo domain.IProjectRepository, blobStore domain.IBlobStore, auditRepo domain.IAuditRepository, events domain.IEventPublisher, maxSize int64) domain.IAttachmentUseCase {
+	return &AttachmentUseCase{taskRepo: taskRepo, projectRepo: projectRepo, blobStore: blobStore, auditRepo: auditRepo, events: events, maxSize: maxSize}
+}
+
+func (uc *AttachmentUseCase) ListAttachments(ctx context.Context, projectID string, taskID string, requester domain.Claims) ([]domain.Attachment, error) {
+	task, err := uc.getTask(ctx, projectID, taskID, requester)
+	if err != nil {
+		return nil, err
+	}
+	if task.Attachments == nil {
+		return []domain.Attachment{}, nil
+	}
+	return task.Attachments, nil
+}
+
+// AddAttachment stores the upload in the blob store and records its metadata
+// on the task. The content type is sniffed from the first bytes, and uploads
+// larger than the limit are rejected with ErrTooLarge.
+func (uc *AttachmentUseCase) AddAttachment(ctx context.Context, projectID string, taskID string, upload domain.AttachmentUpload, requester domain.Claims) (*domain.Attachment, error) {
+	filename, err := domain.NormalizeAttachmentFilename(upload.Filename)
+	if err != nil {
+		return nil, err
+	}
+	task, err := uc.getTask(ctx, projectID, taskID, requester)
+	if err != nil {
+		return nil, err
+	}
+	if len(task.Attachments) >= domain.MaxTaskAttachments {
+		return nil, domain.ErrInvalidInput
+	}
+
+	content := bufio.NewReaderSize(upload.Content, sniffLength)
+	head, err := content.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(head) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	id, err := newItemID()
+	if err != nil {
+		return nil, err
+	}
+	key := domain.AttachmentKey(taskID, id)
+	size, err := uc.blobStore.Put(ctx, key, io.LimitReader(content, uc.maxSize+1))
+	if err == nil && size > uc.maxSize {
+		err = domain.ErrTooLarge
+	}
+	if err != nil {
+		uc.removeBlob(ctx, key)
+		return nil, err
+	}
+
+	attachment := domain.Attachment{
+		ID:          id,
+		Filename:    filename,
+		ContentType: http.DetectContentType(head),
+		Size:        size,
+		UploadedBy:  requester.UserID,
+		CreatedAt:   time.Now(),
+	}
+	before := *task
+	task.Attachments = append(slices.Clone(task.Attachments), attachment)
+	if _, err := uc.updateTask(ctx, &before, task, requester.UserID); err != nil {
+		uc.removeBlob(ctx, key)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (uc *AttachmentUseCase) OpenAttachment(ctx context.Context, projectID string, taskID string, attachmentID string, requester domain.Claims) (*domain.Attachment, io.ReadCloser, error) {
+	task, err := uc.getTask(ctx, projectID, taskID, requester)
+	if err != nil {
+		return nil, nil, err
+	}
+	i := slices.IndexFunc(task.Attachments, func(a domain.Attachment) bool { return a.ID == attachmentID })
+	if i < 0 {
+		return nil, nil, domain.ErrNotFound
+	}
+
+	content, err := uc.blobStore.Open(ctx, domain.AttachmentKey(taskID, attachmentID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &task.Attachments[i], content, nil
+}
+
+// DeleteAttachment removes the attachment from the task, then its content.
+func (uc *AttachmentUseCase) DeleteAttachment(ctx context.Context, projectID string, taskID string, attachmentID string, requester domain.Claims) error {
+	task, err := uc.getTask(ctx, projectID, taskID, requester)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(task.Attachments, func(a domain.Attachment) bool { return a.ID == attachmentID })
+	if i < 0 {
+		return domain.ErrNotFound
+	}
+	if !task.Attachments[i].CanDelete(requester) {
+		return domain.ErrForbidden
+	}
+
+	before := *task
+	task.Attachments = slices.Delete(slices.Clone(task.Attachments), i, i+1)
+	if _, err := uc.updateTask(ctx, &before, task, requester.UserID); err != nil {
+		return err
+	}
+	uc.removeBlob(ctx, domain.AttachmentKey(taskID, attachmentID))
+	return nil
+}
+
+// getTask loads a task of the project that the requester may read.
+func (uc *AttachmentUseCase) getTask(ctx context.Context, projectID string, taskID string, requester domain.Claims) (*domain.Task, error) {
+	if taskID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, taskID, requester)
+	return task, err
+}
+
+func (uc *AttachmentUseCase) updateTask(ctx context.Context, before, task *domain.Task, actorID string) (*domain.Task, error) {
+	task.UpdatedAt = time.Now()
+	updated, err := uc.taskRepo.Update(ctx, task.ProjectID, task.ID, *task)
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     domain.AuditTaskUpdated,
+		EntityType: domain.AuditEntityTask,
+		EntityID:   task.ID,
+		Changes:    domain.DiffTasks(before, updated),
+	})
+	publishEvents(ctx, uc.events, actorID, domain.TaskEvents(before, updated)...)
+	return updated, nil
+}
+
+// removeBlob deletes content that is no longer referenced by any task. A
+// leftover blob is harmless, so failures are only logged.
+func (uc *AttachmentUseCase) removeBlob(ctx context.Context, key string) {
+	if err := uc.blobStore.Delete(ctx, key); err != nil {
+		log.Printf("Failed to delete attachment blob %s: %v", key, err)
+	}
+}
@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AttachmentUseCaseTestSuite struct {
+	suite.Suite
+	mockTasks    *mocks.MockTaskRepository
+	mockProjects *mocks.MockProjectRepository
+	mockBlobs    *mocks.MockBlobStore
+	mockAudit    *mocks.MockAuditRepository
+	useCase      domain.IAttachmentUseCase
+	task         domain.Task
+	owner        domain.Claims
+	assignee     domain.Claims
+	stranger     domain.Claims
+	stored       bytes.Buffer
+}
+
+func (suite *AttachmentUseCaseTestSuite) SetupTest() {
+	suite.mockTasks = new(mocks.MockTaskRepository)
+	suite.mockProjects = new(mocks.MockProjectRepository)
+	suite.mockBlobs = new(mocks.MockBlobStore)
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockAudit.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+	events := new(mocks.MockEventPublisher)
+	events.On("Publish", mock.Anything, mock.Anything).Maybe()
+	suite.useCase = NewAttachmentUseCase(suite.mockTasks, suite.mockProjects, suite.mockBlobs, suite.mockAudit, events, 16)
+
+	suite.owner = domain.Claims{UserID: "user-1", Role: domain.RoleUser}
+	suite.assignee = domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	suite.stranger = domain.Claims{UserID: "user-3", Role: domain.RoleUser}
+	project := domain.Project{ID: testProjectID, Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectEditor},
+		{UserID: "user-2", Role: domain.ProjectEditor},
+		{UserID: "user-3", Role: domain.ProjectEditor},
+	}}
+	suite.mockProjects.On("GetByID", mock.Anything, mock.Anything).Return(&project, nil).Maybe()
+	suite.task = domain.Task{
+		ID:          "1",
+		Title:       "Task",
+		CreatedBy:   "user-1",
+		ProjectID:   testProjectID,
+		AssigneeID:  "user-2",
+		Version:     3,
+		Attachments: []domain.Attachment{{ID: "a1", Filename: "spec.pdf", Size: 10, UploadedBy: "user-1"}},
+	}
+	suite.mockTasks.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.task, nil).Maybe()
+
+	// The blob store mock drains the upload like a real store would
+	suite.stored.Reset()
+	suite.mockBlobs.On("Put", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(func(ctx context.Context, key string, content io.Reader) int64 {
+		n, _ := io.Copy(&suite.stored, content)
+		return n
+	}, nil).Maybe()
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestAddAttachment_SniffsContentType() {
+	suite.mockTasks.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return len(t.Attachments) == 2 && t.Attachments[1].Filename == "notes.txt"
+	})).Return(&suite.task, nil)
+
+	upload := domain.AttachmentUpload{Filename: `C:\Users\bob\notes.txt`, Content: strings.NewReader("plain notes")}
+	attachment, err := suite.useCase.AddAttachment(context.Background(), testProjectID, "1", upload, suite.assignee)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "notes.txt", attachment.Filename)
+	assert.Equal(suite.T(), "text/plain; charset=utf-8", attachment.ContentType)
+	assert.Equal(suite.T(), int64(11), attachment.Size)
+	assert.Equal(suite.T(), "user-2", attachment.UploadedBy)
+	assert.Equal(suite.T(), "plain notes", suite.stored.String())
+	suite.mockBlobs.AssertCalled(suite.T(), "Put", mock.Anything, "1/"+attachment.ID, mock.Anything)
+	suite.mockTasks.AssertExpectations(suite.T())
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestAddAttachment_TooLarge() {
+	suite.mockBlobs.On("Delete", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	upload := domain.AttachmentUpload{Filename: "big.bin", Content: strings.NewReader(strings.Repeat("x", 100))}
+	_, err := suite.useCase.AddAttachment(context.Background(), testProjectID, "1", upload, suite.owner)
+	assert.Equal(suite.T(), domain.ErrTooLarge, err)
+	// The store never receives more than one byte over the limit
+	assert.Equal(suite.T(), 17, suite.stored.Len())
+	suite.mockBlobs.AssertNumberOfCalls(suite.T(), "Delete", 1)
+	suite.mockTasks.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestAddAttachment_Invalid() {
+	cases := []domain.AttachmentUpload{
+		{Filename: "empty.txt", Content: strings.NewReader("")},
+		{Filename: "", Content: strings.NewReader("data")},
+		{Filename: "../", Content: strings.NewReader("data")},
+	}
+	for _, upload := range cases {
+		_, err := suite.useCase.AddAttachment(context.Background(), testProjectID, "1", upload, suite.owner)
+		assert.Equal(suite.T(), domain.ErrInvalidInput, err, upload.Filename)
+	}
+	suite.mockBlobs.AssertNotCalled(suite.T(), "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestAddAttachment_OtherProject() {
+	suite.mockTasks.On("GetByID", mock.Anything, "project-2", "1").Return(nil, domain.ErrNotFound)
+	upload := domain.AttachmentUpload{Filename: "notes.txt", Content: strings.NewReader("data")}
+	_, err := suite.useCase.AddAttachment(context.Background(), "project-2", "1", upload, suite.owner)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockBlobs.AssertNotCalled(suite.T(), "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestOpenAttachment() {
+	suite.mockBlobs.On("Open", mock.Anything, "1/a1").Return(io.NopCloser(strings.NewReader("%PDF")), nil)
+
+	attachment, content, err := suite.useCase.OpenAttachment(context.Background(), testProjectID, "1", "a1", suite.assignee)
+	suite.Require().NoError(err)
+	defer content.Close()
+	assert.Equal(suite.T(), "spec.pdf", attachment.Filename)
+
+	_, _, err = suite.useCase.OpenAttachment(context.Background(), testProjectID, "1", "missing", suite.assignee)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestAttachments_HiddenTask() {
+	_, err := suite.useCase.ListAttachments(context.Background(), testProjectID, "1", suite.stranger)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+
+	_, _, err = suite.useCase.OpenAttachment(context.Background(), testProjectID, "1", "a1", suite.stranger)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockBlobs.AssertNotCalled(suite.T(), "Open", mock.Anything, mock.Anything)
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestDeleteAttachment_ByUploader() {
+	suite.mockTasks.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return len(t.Attachments) == 0
+	})).Return(&suite.task, nil)
+	suite.mockBlobs.On("Delete", mock.Anything, "1/a1").Return(nil)
+
+	err := suite.useCase.DeleteAttachment(context.Background(), testProjectID, "1", "a1", suite.owner)
+	assert.NoError(suite.T(), err)
+	suite.mockBlobs.AssertExpectations(suite.T())
+}
+
+func (suite *AttachmentUseCaseTestSuite) TestDeleteAttachment_NotUploader() {
+	err := suite.useCase.DeleteAttachment(context.Background(), testProjectID, "1", "a1", suite.assignee)
+	assert.Equal(suite.T(), domain.ErrForbidden, err)
+	suite.mockTasks.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAttachmentUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AttachmentUseCaseTestSuite))
+}
@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	domain "task-manager/Domain"
+	"time"
+)
+
+type AuditUseCase struct {
+	auditRepo domain.IAuditRepository
+	taskRepo  domain.ITaskRepository
+}
+
+func NewAuditUseCase(auditRepo domain.IAuditRepository, taskRepo domain.ITaskRepository) domain.IAuditUseCase {
+	return &AuditUseCase{auditRepo: auditRepo, taskRepo: taskRepo}
+}
+
+// GetTaskHistory returns the audit entries of a single task of the project,
+// newest first. Audit entries do not record projects, so the history of a
+// deleted task is only available to admins through ListEntries.
+func (uc *AuditUseCase) GetTaskHistory(ctx context.Context, projectID string, taskID string, query domain.AuditQuery) (*domain.AuditPage, error) {
+	if taskID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	if _, err := uc.taskRepo.GetByID(ctx, projectID, taskID); err != nil {
+		return nil, domain.ErrNotFound
+	}
+	query.EntityType = domain.AuditEntityTask
+	query.EntityID = taskID
+	return uc.ListEntries(ctx, query)
+}
+
+func (uc *AuditUseCase) ListEntries(ctx context.Context, query domain.AuditQuery) (*domain.AuditPage, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	return uc.auditRepo.Find(ctx, query)
+}
+
+// recordAudit stores an audit entry for a mutation that has already been
+// applied. Failing the request at that point would misreport the outcome,
+// so a storage error is logged instead.
+func recordAudit(ctx context.Context, auditRepo domain.IAuditRepository, entry domain.AuditEntry) {
+	entry.CreatedAt = time.Now()
+	if entry.Changes == nil {
+		entry.Changes = []domain.FieldChange{}
+	}
+	if err := auditRepo.Record(ctx, entry); err != nil {
+		log.Printf("Failed to record audit entry %s for %s %s: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+	}
+}
@@ -0,0 +1,70 @@
+package usecases
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditUseCaseTestSuite struct {
+	suite.Suite
+	mockAudit *mocks.MockAuditRepository
+	mockTasks *mocks.MockTaskRepository
+	useCase   domain.IAuditUseCase
+}
+
+func (suite *AuditUseCaseTestSuite) SetupTest() {
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockTasks = new(mocks.MockTaskRepository)
+	suite.mockTasks.On("GetByID", mock.Anything, testProjectID, "1").Return(&domain.Task{ID: "1"}, nil).Maybe()
+	suite.useCase = NewAuditUseCase(suite.mockAudit, suite.mockTasks)
+}
+
+func (suite *AuditUseCaseTestSuite) TestGetTaskHistory_ScopesToTask() {
+	expected := domain.AuditQuery{EntityType: domain.AuditEntityTask, EntityID: "1", Limit: domain.DefaultAuditPageSize}
+	suite.mockAudit.On("Find", mock.Anything, expected).Return(&domain.AuditPage{}, nil)
+
+	// The caller cannot redirect the history to another entity
+	_, err := suite.useCase.GetTaskHistory(context.Background(), testProjectID, "1", domain.AuditQuery{EntityType: domain.AuditEntityUser, EntityID: "2"})
+	assert.NoError(suite.T(), err)
+	suite.mockAudit.AssertExpectations(suite.T())
+}
+
+func (suite *AuditUseCaseTestSuite) TestGetTaskHistory_EmptyID() {
+	_, err := suite.useCase.GetTaskHistory(context.Background(), testProjectID, "", domain.AuditQuery{})
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+}
+
+func (suite *AuditUseCaseTestSuite) TestGetTaskHistory_OtherProject() {
+	suite.mockTasks.On("GetByID", mock.Anything, "project-2", "1").Return(nil, domain.ErrNotFound)
+	_, err := suite.useCase.GetTaskHistory(context.Background(), "project-2", "1", domain.AuditQuery{})
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockAudit.AssertNotCalled(suite.T(), "Find", mock.Anything, mock.Anything)
+}
+
+func (suite *AuditUseCaseTestSuite) TestListEntries_LimitIsCapped() {
+	suite.mockAudit.On("Find", mock.Anything, mock.MatchedBy(func(q domain.AuditQuery) bool {
+		return q.Limit == domain.MaxAuditPageSize
+	})).Return(&domain.AuditPage{}, nil)
+
+	_, err := suite.useCase.ListEntries(context.Background(), domain.AuditQuery{Limit: 10000})
+	assert.NoError(suite.T(), err)
+	suite.mockAudit.AssertExpectations(suite.T())
+}
+
+func (suite *AuditUseCaseTestSuite) TestListEntries_InvalidRange() {
+	now := time.Now()
+	_, err := suite.useCase.ListEntries(context.Background(), domain.AuditQuery{Since: now, Until: now.Add(-time.Hour)})
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockAudit.AssertNotCalled(suite.T(), "Find", mock.Anything, mock.Anything)
+}
+
+func TestAuditUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditUseCaseTestSuite))
+}
@@ -0,0 +1,132 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	domain "task-manager/Domain"
+)
+
+type CommentUseCase struct {
+	commentRepo domain.ICommentRepository
+	taskRepo    domain.ITaskRepository
+	projectRepo domain.IProjectRepository
+	auditRepo   domain.IAuditRepository
+}
+
+func NewCommentUseCase(commentRepo domain.ICommentRepository, taskRepo domain.ITaskRepository, projectRepo domain.IProjectRepository, auditRepo domain.IAuditRepository) domain.ICommentUseCase {
+	return &CommentUseCase{commentRepo: commentRepo, taskRepo: taskRepo, projectRepo: projectRepo, auditRepo: auditRepo}
+}
+
+// ListComments returns a page of the task's threads.
+func (uc *CommentUseCase) ListComments(ctx context.Context, projectID string, taskID string, query domain.CommentQuery, requester domain.Claims) (*domain.CommentPage, error) {
+	if err := uc.checkTask(ctx, projectID, taskID, requester); err != nil {
+		return nil, err
+	}
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	query.TaskID = taskID
+	return uc.commentRepo.FindThreads(ctx, query)
+}
+
+func (uc *CommentUseCase) AddComment(ctx context.Context, projectID string, taskID string, comment domain.Comment, requester domain.Claims) (*domain.Comment, error) {
+	body, err := domain.NormalizeCommentBody(comment.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkTask(ctx, projectID, taskID, requester); err != nil {
+		return nil, err
+	}
+
+	newComment := domain.Comment{TaskID: taskID, AuthorID: requester.UserID, Body: body}
+	if comment.ParentID != "" {
+		// Replies join the thread of the comment they answer
+		parent, err := uc.commentRepo.GetByID(ctx, comment.ParentID)
+		if errors.Is(err, domain.ErrNotFound) || (err == nil && parent.TaskID != taskID) {
+			return nil, domain.ErrInvalidInput
+		}
+		if err != nil {
+			return nil, err
+		}
+		newComment.ParentID = parent.ID
+		newComment.ThreadID = parent.ThreadID
+	}
+	return uc.commentRepo.Create(ctx, newComment)
+}
+
+func (uc *CommentUseCase) UpdateComment(ctx context.Context, projectID string, taskID string, commentID string, body string, requester domain.Claims) (*domain.Comment, error) {
+	body, err := domain.NormalizeCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := uc.editableComment(ctx, projectID, taskID, commentID, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	before := comment.Body
+	comment.Body = body
+	updated, err := uc.commentRepo.Update(ctx, *comment)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, domain.AuditCommentUpdated, requester.UserID, commentID, []domain.FieldChange{
+		{Field: "body", Before: before, After: updated.Body},
+	})
+	return updated, nil
+}
+
+// DeleteComment removes a comment and every reply below it.
+func (uc *CommentUseCase) DeleteComment(ctx context.Context, projectID string, taskID string, commentID string, requester domain.Claims) error {
+	comment, err := uc.editableComment(ctx, projectID, taskID, commentID, requester)
+	if err != nil {
+		return err
+	}
+	if err := uc.commentRepo.Delete(ctx, commentID); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, domain.AuditCommentDeleted, requester.UserID, commentID, []domain.FieldChange{
+		{Field: "body", Before: comment.Body},
+	})
+	return nil
+}
+
+// checkTask makes sure the task exists in the project and the requester may
+// read it.
+func (uc *CommentUseCase) checkTask(ctx context.Context, projectID string, taskID string, requester domain.Claims) error {
+	if taskID == "" {
+		return domain.ErrInvalidInput
+	}
+	_, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, taskID, requester)
+	return err
+}
+
+// editableComment loads a comment of the task that the requester may change.
+func (uc *CommentUseCase) editableComment(ctx context.Context, projectID string, taskID string, commentID string, requester domain.Claims) (*domain.Comment, error) {
+	if err := uc.checkTask(ctx, projectID, taskID, requester); err != nil {
+		return nil, err
+	}
+	comment, err := uc.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.TaskID != taskID {
+		return nil, domain.ErrNotFound
+	}
+	if !comment.CanEdit(requester) {
+		return nil, domain.ErrForbidden
+	}
+	return comment, nil
+}
+
+func (uc *CommentUseCase) audit(ctx context.Context, action, actorID, commentID string, changes []domain.FieldChange) {
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityComment,
+		EntityID:   commentID,
+		Changes:    changes,
+	})
+}
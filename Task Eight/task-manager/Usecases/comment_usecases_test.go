@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommentUseCaseTestSuite struct {
+	suite.Suite
+	mockComments *mocks.MockCommentRepository
+	mockTasks    *mocks.MockTaskRepository
+	mockProjects *mocks.MockProjectRepository
+	mockAudit    *mocks.MockAuditRepository
+	useCase      domain.ICommentUseCase
+	owner        domain.Claims
+	other        domain.Claims
+	admin        domain.Claims
+	comment      domain.Comment
+}
+
+func (suite *CommentUseCaseTestSuite) SetupTest() {
+	suite.mockComments = new(mocks.MockCommentRepository)
+	suite.mockTasks = new(mocks.MockTaskRepository)
+	suite.mockProjects = new(mocks.MockProjectRepository)
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockAudit.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.useCase = NewCommentUseCase(suite.mockComments, suite.mockTasks, suite.mockProjects, suite.mockAudit)
+
+	suite.owner = domain.Claims{UserID: "user-1", Role: domain.RoleUser}
+	suite.other = domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	suite.admin = domain.Claims{UserID: "admin-1", Role: domain.RoleAdmin, Permissions: domain.Permissions}
+	suite.comment = domain.Comment{ID: "c1", TaskID: "1", ThreadID: "c1", AuthorID: "user-1", Body: "Looks good"}
+
+	project := domain.Project{ID: testProjectID, Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectEditor},
+		{UserID: "user-2", Role: domain.ProjectEditor},
+		{UserID: "user-3", Role: domain.ProjectViewer},
+	}}
+	suite.mockProjects.On("GetByID", mock.Anything, mock.Anything).Return(&project, nil).Maybe()
+	task := domain.Task{ID: "1", Title: "Task", CreatedBy: "user-1", AssigneeID: "user-2", ProjectID: testProjectID}
+	suite.mockTasks.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil).Maybe()
+}
+
+func (suite *CommentUseCaseTestSuite) TestListComments_ScopesToTask() {
+	expected := domain.CommentQuery{TaskID: "1", Limit: domain.DefaultCommentPageSize}
+	suite.mockComments.On("FindThreads", mock.Anything, expected).Return(&domain.CommentPage{}, nil)
+
+	_, err := suite.useCase.ListComments(context.Background(), testProjectID, "1", domain.CommentQuery{TaskID: "2"}, suite.other)
+	assert.NoError(suite.T(), err)
+	suite.mockComments.AssertExpectations(suite.T())
+}
+
+func (suite *CommentUseCaseTestSuite) TestListComments_HiddenTask() {
+	viewer := domain.Claims{UserID: "user-3", Role: domain.RoleUser}
+	_, err := suite.useCase.ListComments(context.Background(), testProjectID, "1", domain.CommentQuery{}, viewer)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockComments.AssertNotCalled(suite.T(), "FindThreads", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentUseCaseTestSuite) TestListComments_OtherProject() {
+	suite.mockTasks.On("GetByID", mock.Anything, "project-2", "1").Return(nil, domain.ErrNotFound)
+	_, err := suite.useCase.ListComments(context.Background(), "project-2", "1", domain.CommentQuery{}, suite.owner)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockComments.AssertNotCalled(suite.T(), "FindThreads", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentUseCaseTestSuite) TestAddComment() {
+	suite.mockComments.On("Create", mock.Anything, domain.Comment{TaskID: "1", AuthorID: "user-2", Body: "On it"}).Return(&suite.comment, nil)
+
+	_, err := suite.useCase.AddComment(context.Background(), testProjectID, "1", domain.Comment{Body: "  On it  ", AuthorID: "spoofed"}, suite.other)
+	assert.NoError(suite.T(), err)
+	suite.mockComments.AssertExpectations(suite.T())
+}
+
+func (suite *CommentUseCaseTestSuite) TestAddComment_EmptyBody() {
+	_, err := suite.useCase.AddComment(context.Background(), testProjectID, "1", domain.Comment{Body: "   "}, suite.owner)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+}
+
+func (suite *CommentUseCaseTestSuite) TestAddComment_ReplyJoinsThread() {
+	parent := domain.Comment{ID: "c2", TaskID: "1", ThreadID: "c1", ParentID: "c1"}
+	suite.mockComments.On("GetByID", mock.Anything, "c2").Return(&parent, nil)
+	suite.mockComments.On("Create", mock.Anything, mock.MatchedBy(func(c domain.Comment) bool {
+		return c.ParentID == "c2" && c.ThreadID == "c1"
+	})).Return(&domain.Comment{ID: "c3"}, nil)
+
+	_, err := suite.useCase.AddComment(context.Background(), testProjectID, "1", domain.Comment{Body: "Agreed", ParentID: "c2"}, suite.owner)
+	assert.NoError(suite.T(), err)
+	suite.mockComments.AssertExpectations(suite.T())
+}
+
+func (suite *CommentUseCaseTestSuite) TestAddComment_ReplyToOtherTask() {
+	parent := domain.Comment{ID: "c9", TaskID: "2", ThreadID: "c9"}
+	suite.mockComments.On("GetByID", mock.Anything, "c9").Return(&parent, nil)
+
+	_, err := suite.useCase.AddComment(context.Background(), testProjectID, "1", domain.Comment{Body: "Agreed", ParentID: "c9"}, suite.owner)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockComments.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentUseCaseTestSuite) TestUpdateComment_ByAuthor() {
+	suite.mockComments.On("GetByID", mock.Anything, "c1").Return(&suite.comment, nil)
+	suite.mockComments.On("Update", mock.Anything, mock.MatchedBy(func(c domain.Comment) bool {
+		return c.ID == "c1" && c.Body == "Looks great"
+	})).Return(&domain.Comment{ID: "c1", Body: "Looks great"}, nil)
+
+	_, err := suite.useCase.UpdateComment(context.Background(), testProjectID, "1", "c1", "Looks great", suite.owner)
+	assert.NoError(suite.T(), err)
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditCommentUpdated && e.EntityType == domain.AuditEntityComment &&
+			e.Changes[0] == domain.FieldChange{Field: "body", Before: "Looks good", After: "Looks great"}
+	}))
+}
+
+func (suite *CommentUseCaseTestSuite) TestUpdateComment_NotAuthor() {
+	suite.mockComments.On("GetByID", mock.Anything, "c1").Return(&suite.comment, nil)
+
+	_, err := suite.useCase.UpdateComment(context.Background(), testProjectID, "1", "c1", "Hijacked", suite.other)
+	assert.Equal(suite.T(), domain.ErrForbidden, err)
+	suite.mockComments.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentUseCaseTestSuite) TestUpdateComment_WrongTask() {
+	suite.mockTasks.On("GetByID", mock.Anything, testProjectID, "2").Return(&domain.Task{ID: "2", CreatedBy: "user-1"}, nil)
+	suite.mockComments.On("GetByID", mock.Anything, "c1").Return(&suite.comment, nil)
+
+	_, err := suite.useCase.UpdateComment(context.Background(), testProjectID, "2", "c1", "Moved", suite.owner)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+}
+
+func (suite *CommentUseCaseTestSuite) TestDeleteComment_AdminModerates() {
+	suite.mockComments.On("GetByID", mock.Anything, "c1").Return(&suite.comment, nil)
+	suite.mockComments.On("Delete", mock.Anything, "c1").Return(nil)
+
+	err := suite.useCase.DeleteComment(context.Background(), testProjectID, "1", "c1", suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockComments.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditCommentDeleted && e.ActorID == "admin-1" && e.Changes[0].Before == "Looks good"
+	}))
+}
+
+func (suite *CommentUseCaseTestSuite) TestDeleteComment_NotAuthor() {
+	suite.mockComments.On("GetByID", mock.Anything, "c1").Return(&suite.comment, nil)
+
+	err := suite.useCase.DeleteComment(context.Background(), testProjectID, "1", "c1", suite.other)
+	assert.Equal(suite.T(), domain.ErrForbidden, err)
+	suite.mockComments.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func TestCommentUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentUseCaseTestSuite))
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"context"
+	domain "task-manager/Domain"
+)
+
+type LabelUseCase struct {
+	labelRepo domain.ILabelRepository
+	taskRepo  domain.ITaskRepository
+	auditRepo domain.IAuditRepository
+}
+
+func NewLabelUseCase(labelRepo domain.ILabelRepository, taskRepo domain.ITaskRepository, auditRepo domain.IAuditRepository) domain.ILabelUseCase {
+	return &LabelUseCase{labelRepo: labelRepo, taskRepo: taskRepo, auditRepo: auditRepo}
+}
+
+func (uc *LabelUseCase) ListLabels(ctx context.Context) ([]domain.Label, error) {
+	return uc.labelRepo.GetAll(ctx)
+}
+
+func (uc *LabelUseCase) CreateLabel(ctx context.Context, label domain.Label, actorID string) (*domain.Label, error) {
+	label.Name = domain.NormalizeLabelName(label.Name)
+	label.Color = domain.NormalizeLabelColor(label.Color)
+	if err := label.Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.labelRepo.Create(ctx, label)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, domain.AuditLabelCreated, actorID, created.Name, []domain.FieldChange{
+		{Field: "color", After: created.Color},
+	})
+	return created, nil
+}
+
+// UpdateLabel changes a label's colour. Names are immutable because tasks
+// refer to labels by name.
+func (uc *LabelUseCase) UpdateLabel(ctx context.Context, name string, color string, actorID string) (*domain.Label, error) {
+	name = domain.NormalizeLabelName(name)
+	color = domain.NormalizeLabelColor(color)
+	if !domain.IsValidLabelColor(color) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	existing, err := uc.labelRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.labelRepo.Update(ctx, domain.Label{Name: name, Color: color})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []domain.FieldChange{}
+	if existing.Color != updated.Color {
+		changes = append(changes, domain.FieldChange{Field: "color", Before: existing.Color, After: updated.Color})
+	}
+	uc.audit(ctx, domain.AuditLabelUpdated, actorID, name, changes)
+	return updated, nil
+}
+
+// DeleteLabel removes a label definition and detaches it from every task.
+func (uc *LabelUseCase) DeleteLabel(ctx context.Context, name string, actorID string) error {
+	name = domain.NormalizeLabelName(name)
+	existing, err := uc.labelRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.labelRepo.Delete(ctx, name); err != nil {
+		return err
+	}
+	if err := uc.taskRepo.RemoveLabelFromAll(ctx, name); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, domain.AuditLabelDeleted, actorID, name, []domain.FieldChange{
+		{Field: "color", Before: existing.Color},
+	})
+	return nil
+}
+
+func (uc *LabelUseCase) audit(ctx context.Context, action, actorID, name string, changes []domain.FieldChange) {
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityLabel,
+		EntityID:   name,
+		Changes:    changes,
+	})
+}
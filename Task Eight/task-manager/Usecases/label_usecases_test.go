@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LabelUseCaseTestSuite struct {
+	suite.Suite
+	mockLabelRepo *mocks.MockLabelRepository
+	mockTaskRepo  *mocks.MockTaskRepository
+	mockAudit     *mocks.MockAuditRepository
+	useCase       domain.ILabelUseCase
+}
+
+func (suite *LabelUseCaseTestSuite) SetupTest() {
+	suite.mockLabelRepo = new(mocks.MockLabelRepository)
+	suite.mockTaskRepo = new(mocks.MockTaskRepository)
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockAudit.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.useCase = NewLabelUseCase(suite.mockLabelRepo, suite.mockTaskRepo, suite.mockAudit)
+}
+
+func (suite *LabelUseCaseTestSuite) TestCreateLabel_Normalizes() {
+	expected := domain.Label{Name: "customer-x", Color: "#ff00aa"}
+	suite.mockLabelRepo.On("Create", mock.Anything, expected).Return(&expected, nil)
+
+	created, err := suite.useCase.CreateLabel(context.Background(), domain.Label{Name: " Customer-X ", Color: "#FF00AA"}, "admin-1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "customer-x", created.Name)
+	suite.mockLabelRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LabelUseCaseTestSuite) TestCreateLabel_Invalid() {
+	cases := []domain.Label{
+		{Name: "", Color: "#ff0000"},
+		{Name: "has space", Color: "#ff0000"},
+		{Name: "bug", Color: "red"},
+	}
+	for _, label := range cases {
+		_, err := suite.useCase.CreateLabel(context.Background(), label, "admin-1")
+		assert.Equal(suite.T(), domain.ErrInvalidInput, err, label.Name)
+	}
+	suite.mockLabelRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *LabelUseCaseTestSuite) TestCreateLabel_Duplicate() {
+	suite.mockLabelRepo.On("Create", mock.Anything, mock.Anything).Return(nil, domain.ErrDuplicateEntry)
+
+	_, err := suite.useCase.CreateLabel(context.Background(), domain.Label{Name: "bug", Color: "#ff0000"}, "admin-1")
+	assert.Equal(suite.T(), domain.ErrDuplicateEntry, err)
+}
+
+func (suite *LabelUseCaseTestSuite) TestUpdateLabel_AuditsColorChange() {
+	suite.mockLabelRepo.On("GetByName", mock.Anything, "bug").Return(&domain.Label{Name: "bug", Color: "#ff0000"}, nil)
+	suite.mockLabelRepo.On("Update", mock.Anything, domain.Label{Name: "bug", Color: "#00ff00"}).Return(&domain.Label{Name: "bug", Color: "#00ff00"}, nil)
+
+	_, err := suite.useCase.UpdateLabel(context.Background(), "bug", "#00FF00", "admin-1")
+	assert.NoError(suite.T(), err)
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditLabelUpdated && e.EntityType == domain.AuditEntityLabel &&
+			e.Changes[0] == domain.FieldChange{Field: "color", Before: "#ff0000", After: "#00ff00"}
+	}))
+}
+
+func (suite *LabelUseCaseTestSuite) TestUpdateLabel_NotFound() {
+	suite.mockLabelRepo.On("GetByName", mock.Anything, "bug").Return(nil, domain.ErrNotFound)
+
+	_, err := suite.useCase.UpdateLabel(context.Background(), "bug", "#00ff00", "admin-1")
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+}
+
+func (suite *LabelUseCaseTestSuite) TestDeleteLabel_DetachesFromTasks() {
+	suite.mockLabelRepo.On("GetByName", mock.Anything, "bug").Return(&domain.Label{Name: "bug", Color: "#ff0000"}, nil)
+	suite.mockLabelRepo.On("Delete", mock.Anything, "bug").Return(nil)
+	suite.mockTaskRepo.On("RemoveLabelFromAll", mock.Anything, "bug").Return(nil)
+
+	err := suite.useCase.DeleteLabel(context.Background(), "Bug", "admin-1")
+	assert.NoError(suite.T(), err)
+	suite.mockLabelRepo.AssertExpectations(suite.T())
+	suite.mockTaskRepo.AssertExpectations(suite.T())
+}
+
+func TestLabelUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(LabelUseCaseTestSuite))
+}
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	domain "task-manager/Domain"
+	"time"
+)
+
+// LoginThrottle counts failed logins per username and per client IP. Client
+// IPs are often shared, so they usually get a more lenient policy.
+type LoginThrottle struct {
+	attempts   domain.ILoginAttemptRepository
+	userPolicy domain.LoginThrottlePolicy
+	ipPolicy   domain.LoginThrottlePolicy
+}
+
+func NewLoginThrottle(attempts domain.ILoginAttemptRepository, userPolicy, ipPolicy domain.LoginThrottlePolicy) domain.ILoginThrottle {
+	return &LoginThrottle{attempts: attempts, userPolicy: userPolicy, ipPolicy: ipPolicy}
+}
+
+func (t *LoginThrottle) Reserve(ctx context.Context, username, clientIP string) error {
+	now := time.Now()
+	keys := t.keys(username, clientIP)
+
+	// Refuse blocked attempts without counting them, so guessing on while
+	// blocked does not prolong the block
+	seen := make([]int, len(keys))
+	var wait time.Duration
+	for i, k := range keys {
+		failures, err := t.attempts.Get(ctx, k.key)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		seen[i] = failures.Count
+		wait = max(wait, k.policy.BlockedUntil(*failures).Sub(now))
+	}
+	if wait > 0 {
+		return &domain.LoginThrottledError{RetryAfter: wait}
+	}
+
+	// The increment is atomic, so of several concurrent attempts only as
+	// many get through as the count allows. Failures counted since the read
+	// above were reserved by those attempts just now.
+	counts := make([]int, len(keys))
+	for i, k := range keys {
+		failures, err := t.attempts.RecordFailure(ctx, k.key, now, k.policy.Window)
+		if err != nil {
+			t.release(ctx, keys[:i])
+			return err
+		}
+		counts[i] = failures.Count
+		if before := failures.Count - 1; before > seen[i] {
+			wait = max(wait, k.policy.BlockedUntil(domain.LoginFailures{Count: before, LastFailure: now}).Sub(now))
+		}
+	}
+	if wait > 0 {
+		if err := t.release(ctx, keys); err != nil {
+			return err
+		}
+		return &domain.LoginThrottledError{RetryAfter: wait}
+	}
+
+	for i, k := range keys {
+		if k.policy.LockoutAfter > 0 && counts[i] == k.policy.LockoutAfter {
+			log.Printf("Locking out logins for %s after %d failures", k.key, counts[i])
+		}
+	}
+	return nil
+}
+
+func (t *LoginThrottle) Release(ctx context.Context, username, clientIP string) error {
+	return t.release(ctx, t.keys(username, clientIP))
+}
+
+func (t *LoginThrottle) RecordSuccess(ctx context.Context, username string) error {
+	return t.attempts.Reset(ctx, userThrottleKey(username))
+}
+
+type throttleKey struct {
+	key    string
+	policy domain.LoginThrottlePolicy
+}
+
+// keys returns the keys a login attempt is counted against. The IP is
+// missing when the request did not come over the network, as in tests.
+func (t *LoginThrottle) keys(username, clientIP string) []throttleKey {
+	keys := []throttleKey{{userThrottleKey(username), t.userPolicy}}
+	if clientIP != "" {
+		keys = append(keys, throttleKey{"ip:" + clientIP, t.ipPolicy})
+	}
+	return keys
+}
+
+func (t *LoginThrottle) release(ctx context.Context, keys []throttleKey) error {
+	for _, k := range keys {
+		if err := t.attempts.ReleaseFailure(ctx, k.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func userThrottleKey(username string) string {
+	return "user:" + username
+}
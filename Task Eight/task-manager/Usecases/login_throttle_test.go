@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	testUserPolicy = domain.LoginThrottlePolicy{FreeFailures: 3, Backoff: time.Second, MaxBackoff: time.Minute, LockoutAfter: 10, LockoutDuration: 15 * time.Minute, Window: time.Hour}
+	testIPPolicy   = domain.LoginThrottlePolicy{FreeFailures: 20, Backoff: time.Second, MaxBackoff: time.Minute, Window: time.Hour}
+)
+
+func TestLoginThrottle_Reserve(t *testing.T) {
+	attempts := new(mocks.MockLoginAttemptRepository)
+	attempts.On("Get", mock.Anything, "user:alice").Return(&domain.LoginFailures{Count: 3, LastFailure: time.Now()}, nil)
+	attempts.On("Get", mock.Anything, "user:bob").Return(&domain.LoginFailures{Count: 5, LastFailure: time.Now()}, nil)
+	attempts.On("Get", mock.Anything, "ip:203.0.113.1").Return(nil, domain.ErrNotFound)
+	attempts.On("Get", mock.Anything, "ip:203.0.113.2").Return(&domain.LoginFailures{Count: 30, LastFailure: time.Now()}, nil)
+	attempts.On("RecordFailure", mock.Anything, "user:alice", mock.Anything, time.Hour).Return(&domain.LoginFailures{Count: 4}, nil)
+	attempts.On("RecordFailure", mock.Anything, "ip:203.0.113.1", mock.Anything, time.Hour).Return(&domain.LoginFailures{Count: 1}, nil)
+	throttle := NewLoginThrottle(attempts, testUserPolicy, testIPPolicy)
+
+	assert.NoError(t, throttle.Reserve(context.Background(), "alice", "203.0.113.1"))
+
+	// The longer of the two blocks wins, and blocked attempts are not counted
+	var throttled *domain.LoginThrottledError
+	err := throttle.Reserve(context.Background(), "bob", "203.0.113.1")
+	assert.ErrorAs(t, err, &throttled)
+	assert.InDelta(t, float64(2*time.Second), float64(throttled.RetryAfter), float64(time.Second))
+	err = throttle.Reserve(context.Background(), "bob", "203.0.113.2")
+	assert.ErrorAs(t, err, &throttled)
+	assert.InDelta(t, float64(time.Minute), float64(throttled.RetryAfter), float64(time.Second))
+	attempts.AssertNotCalled(t, "RecordFailure", mock.Anything, "user:bob", mock.Anything, mock.Anything)
+
+	// Without a client IP only the username counts
+	assert.NoError(t, throttle.Reserve(context.Background(), "alice", ""))
+	attempts.AssertNumberOfCalls(t, "Get", 7)
+	attempts.AssertNumberOfCalls(t, "RecordFailure", 3)
+}
+
+func TestLoginThrottle_ReserveRace(t *testing.T) {
+	attempts := new(mocks.MockLoginAttemptRepository)
+	attempts.On("Get", mock.Anything, "user:alice").Return(&domain.LoginFailures{Count: 2, LastFailure: time.Now()}, nil)
+	attempts.On("Get", mock.Anything, "ip:203.0.113.1").Return(nil, domain.ErrNotFound)
+	// Concurrent attempts reserved three more failures since the read
+	attempts.On("RecordFailure", mock.Anything, "user:alice", mock.Anything, time.Hour).Return(&domain.LoginFailures{Count: 6}, nil)
+	attempts.On("RecordFailure", mock.Anything, "ip:203.0.113.1", mock.Anything, time.Hour).Return(&domain.LoginFailures{Count: 4}, nil)
+	attempts.On("ReleaseFailure", mock.Anything, "user:alice").Return(nil)
+	attempts.On("ReleaseFailure", mock.Anything, "ip:203.0.113.1").Return(nil)
+	throttle := NewLoginThrottle(attempts, testUserPolicy, testIPPolicy)
+
+	var throttled *domain.LoginThrottledError
+	err := throttle.Reserve(context.Background(), "alice", "203.0.113.1")
+	assert.ErrorAs(t, err, &throttled)
+	assert.InDelta(t, float64(2*time.Second), float64(throttled.RetryAfter), float64(time.Second))
+	attempts.AssertExpectations(t)
+}
+
+func TestLoginThrottle_ReserveError(t *testing.T) {
+	attempts := new(mocks.MockLoginAttemptRepository)
+	attempts.On("Get", mock.Anything, "user:alice").Return(nil, errors.New("db down"))
+	throttle := NewLoginThrottle(attempts, testUserPolicy, testIPPolicy)
+
+	err := throttle.Reserve(context.Background(), "alice", "203.0.113.1")
+	assert.EqualError(t, err, "db down")
+}
+
+func TestLoginThrottle_Release(t *testing.T) {
+	attempts := new(mocks.MockLoginAttemptRepository)
+	attempts.On("ReleaseFailure", mock.Anything, "user:alice").Return(nil)
+	attempts.On("ReleaseFailure", mock.Anything, "ip:203.0.113.1").Return(nil)
+	attempts.On("Reset", mock.Anything, "user:alice").Return(nil)
+	throttle := NewLoginThrottle(attempts, testUserPolicy, testIPPolicy)
+
+	assert.NoError(t, throttle.Release(context.Background(), "alice", "203.0.113.1"))
+	// Succeeding clears the username only, so one valid account does not
+	// unblock an IP that is guessing at others
+	assert.NoError(t, throttle.RecordSuccess(context.Background(), "alice"))
+	attempts.AssertExpectations(t)
+}
+
+func TestLoginThrottlePolicy_BlockedUntil(t *testing.T) {
+	last := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		count int
+		want  time.Time
+	}{
+		{0, time.Time{}},
+		{3, time.Time{}},
+		{4, last.Add(time.Second)},
+		{5, last.Add(2 * time.Second)},
+		{9, last.Add(32 * time.Second)},
+		{10, last.Add(15 * time.Minute)},
+		{50, last.Add(15 * time.Minute)},
+	}
+	for _, c := range cases {
+		got := testUserPolicy.BlockedUntil(domain.LoginFailures{Count: c.count, LastFailure: last})
+		assert.Equal(t, c.want, got, "count %d", c.count)
+	}
+
+	// Without a lockout the backoff stays capped
+	assert.Equal(t, last.Add(time.Minute), testIPPolicy.BlockedUntil(domain.LoginFailures{Count: 500, LastFailure: last}))
+}
+
+func TestLoginThrottledError(t *testing.T) {
+	err := error(&domain.LoginThrottledError{RetryAfter: 30 * time.Second})
+	assert.ErrorIs(t, err, domain.ErrTooManyAttempts)
+	assert.Contains(t, err.Error(), "30s")
+}
@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	domain "task-manager/Domain"
+	"time"
+)
+
+type ProjectUseCase struct {
+	projectRepo domain.IProjectRepository
+	taskRepo    domain.ITaskRepository
+	userRepo    domain.IUserRepository
+	auditRepo   domain.IAuditRepository
+	events      domain.IEventPublisher
+}
+
+func NewProjectUseCase(projectRepo domain.IProjectRepository, taskRepo domain.ITaskRepository, userRepo domain.IUserRepository, auditRepo domain.IAuditRepository, events domain.IEventPublisher) domain.IProjectUseCase {
+	return &ProjectUseCase{projectRepo: projectRepo, taskRepo: taskRepo, userRepo: userRepo, auditRepo: auditRepo, events: events}
+}
+
+func (uc *ProjectUseCase) ListProjects(ctx context.Context, requester domain.Claims) ([]domain.Project, error) {
+	if requester.Can(domain.PermProjectsManage) {
+		return uc.projectRepo.GetAll(ctx)
+	}
+	if requester.UserID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+	return uc.projectRepo.GetByMember(ctx, requester.UserID)
+}
+
+func (uc *ProjectUseCase) GetProject(ctx context.Context, id string) (*domain.Project, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.projectRepo.GetByID(ctx, id)
+}
+
+func (uc *ProjectUseCase) CreateProject(ctx context.Context, project domain.Project, creatorID string) (*domain.Project, error) {
+	if creatorID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	project.Name = strings.TrimSpace(project.Name)
+	project.CreatedBy = creatorID
+	project.Members = []domain.ProjectMember{{UserID: creatorID, Role: domain.ProjectOwner, AddedAt: time.Now()}}
+	if err := project.Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.projectRepo.Create(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, domain.AuditProjectCreated, creatorID, created.ID, []domain.FieldChange{
+		{Field: "name", After: created.Name},
+	})
+	return created, nil
+}
+
+func (uc *ProjectUseCase) UpdateProject(ctx context.Context, id string, patch domain.ProjectPatch, actorID string) (*domain.Project, error) {
+	project, err := uc.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *project
+
+	if patch.Name != nil {
+		project.Name = strings.TrimSpace(*patch.Name)
+	}
+	if patch.Description != nil {
+		project.Description = *patch.Description
+	}
+	if err := project.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.projectRepo.Update(ctx, *project)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []domain.FieldChange{}
+	if before.Name != updated.Name {
+		changes = append(changes, domain.FieldChange{Field: "name", Before: before.Name, After: updated.Name})
+	}
+	if before.Description != updated.Description {
+		changes = append(changes, domain.FieldChange{Field: "description", Before: before.Description, After: updated.Description})
+	}
+	uc.audit(ctx, domain.AuditProjectUpdated, actorID, id, changes)
+	return updated, nil
+}
+
+// DeleteProject refuses to delete a project that still holds tasks, so tasks
+// are never left without a project.
+func (uc *ProjectUseCase) DeleteProject(ctx context.Context, id string, actorID string) error {
+	project, err := uc.GetProject(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	page, err := uc.taskRepo.Find(ctx, domain.TaskQuery{ProjectID: id, SortBy: domain.SortByCreatedAt, Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(page.Tasks) > 0 {
+		return domain.ErrProjectNotEmpty
+	}
+
+	if err := uc.projectRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, domain.AuditProjectDeleted, actorID, id, []domain.FieldChange{
+		{Field: "name", Before: project.Name},
+	})
+	publishEvents(ctx, uc.events, actorID, domain.Event{Type: domain.EventProjectDeleted, Project: project})
+	return nil
+}
+
+// SetMember adds an existing user to the project or changes their role. The
+// last owner cannot be demoted.
+func (uc *ProjectUseCase) SetMember(ctx context.Context, id string, userID string, role domain.ProjectRole, actorID string) (*domain.Project, error) {
+	if userID == "" || !role.IsValid() {
+		return nil, domain.ErrInvalidInput
+	}
+	project, err := uc.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	current, isMember := project.RoleOf(userID)
+	if isMember && current == role {
+		return project, nil
+	}
+	if current == domain.ProjectOwner && project.Owners() == 1 {
+		return nil, domain.ErrLastOwner
+	}
+
+	members := slices.Clone(project.Members)
+	if i := slices.IndexFunc(members, func(m domain.ProjectMember) bool { return m.UserID == userID }); i >= 0 {
+		members[i].Role = role
+	} else {
+		members = append(members, domain.ProjectMember{UserID: userID, Role: role, AddedAt: time.Now()})
+	}
+	project.Members = members
+
+	updated, err := uc.projectRepo.Update(ctx, *project)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, domain.AuditMemberSet, actorID, id, []domain.FieldChange{
+		{Field: "members." + userID, Before: string(current), After: string(role)},
+	})
+	publishEvents(ctx, uc.events, actorID, domain.Event{Type: domain.EventProjectMembersChanged, Project: updated})
+	return updated, nil
+}
+
+// RemoveMember takes a user out of the project. The last owner cannot be
+// removed.
+func (uc *ProjectUseCase) RemoveMember(ctx context.Context, id string, userID string, actorID string) (*domain.Project, error) {
+	project, err := uc.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(project.Members, func(m domain.ProjectMember) bool { return m.UserID == userID })
+	if i < 0 {
+		return nil, domain.ErrNotFound
+	}
+	removed := project.Members[i]
+	if removed.Role == domain.ProjectOwner && project.Owners() == 1 {
+		return nil, domain.ErrLastOwner
+	}
+	project.Members = slices.Delete(slices.Clone(project.Members), i, i+1)
+
+	updated, err := uc.projectRepo.Update(ctx, *project)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, domain.AuditMemberRemoved, actorID, id, []domain.FieldChange{
+		{Field: "members." + userID, Before: string(removed.Role)},
+	})
+	publishEvents(ctx, uc.events, actorID, domain.Event{Type: domain.EventProjectMembersChanged, Project: updated})
+	return updated, nil
+}
+
+func (uc *ProjectUseCase) Authorize(ctx context.Context, projectID string, requester domain.Claims, minimum domain.ProjectRole) (domain.ProjectRole, error) {
+	project, err := uc.GetProject(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	// Outsiders are not told whether the project exists
+	role, ok := project.RoleFor(requester)
+	if !ok {
+		return "", domain.ErrNotFound
+	}
+	if !role.Includes(minimum) {
+		return "", domain.ErrForbidden
+	}
+	return role, nil
+}
+
+func (uc *ProjectUseCase) audit(ctx context.Context, action, actorID, projectID string, changes []domain.FieldChange) {
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityProject,
+		EntityID:   projectID,
+		Changes:    changes,
+	})
+}
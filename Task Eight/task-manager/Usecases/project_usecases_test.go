@@ -0,0 +1,202 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProjectUseCaseTestSuite struct {
+	suite.Suite
+	mockProjects *mocks.MockProjectRepository
+	mockTasks    *mocks.MockTaskRepository
+	mockUsers    *mocks.MockUserRepository
+	mockAudit    *mocks.MockAuditRepository
+	mockEvents   *mocks.MockEventPublisher
+	useCase      domain.IProjectUseCase
+	project      domain.Project
+}
+
+func (suite *ProjectUseCaseTestSuite) SetupTest() {
+	suite.mockProjects = new(mocks.MockProjectRepository)
+	suite.mockTasks = new(mocks.MockTaskRepository)
+	suite.mockUsers = new(mocks.MockUserRepository)
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockAudit.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockEvents = new(mocks.MockEventPublisher)
+	suite.mockEvents.On("Publish", mock.Anything, mock.Anything).Maybe()
+	suite.useCase = NewProjectUseCase(suite.mockProjects, suite.mockTasks, suite.mockUsers, suite.mockAudit, suite.mockEvents)
+
+	suite.project = domain.Project{ID: testProjectID, Name: "Launch", Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectOwner},
+		{UserID: "user-2", Role: domain.ProjectViewer},
+	}}
+	suite.mockProjects.On("GetByID", mock.Anything, testProjectID).Return(&suite.project, nil).Maybe()
+	suite.mockProjects.On("GetByID", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound).Maybe()
+}
+
+func (suite *ProjectUseCaseTestSuite) TestCreateProject_CreatorIsOwner() {
+	suite.mockProjects.On("Create", mock.Anything, mock.MatchedBy(func(p domain.Project) bool {
+		return p.Name == "Launch" && p.CreatedBy == "user-1" && len(p.Members) == 1 &&
+			p.Members[0].UserID == "user-1" && p.Members[0].Role == domain.ProjectOwner
+	})).Return(&suite.project, nil)
+
+	_, err := suite.useCase.CreateProject(context.Background(), domain.Project{Name: "  Launch "}, "user-1")
+	assert.NoError(suite.T(), err)
+	suite.mockProjects.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditProjectCreated && e.EntityType == domain.AuditEntityProject && e.EntityID == testProjectID
+	}))
+}
+
+func (suite *ProjectUseCaseTestSuite) TestCreateProject_InvalidName() {
+	for _, name := range []string{"", "   ", strings.Repeat("x", domain.MaxProjectNameLength+1)} {
+		_, err := suite.useCase.CreateProject(context.Background(), domain.Project{Name: name}, "user-1")
+		assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	}
+	suite.mockProjects.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *ProjectUseCaseTestSuite) TestListProjects() {
+	suite.mockProjects.On("GetAll", mock.Anything).Return([]domain.Project{suite.project}, nil)
+	suite.mockProjects.On("GetByMember", mock.Anything, "user-2").Return([]domain.Project{}, nil)
+
+	all, err := suite.useCase.ListProjects(context.Background(), domain.Claims{UserID: "admin-1", Role: domain.RoleAdmin, Permissions: domain.Permissions})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), all, 1)
+	_, err = suite.useCase.ListProjects(context.Background(), domain.Claims{UserID: "user-2", Role: domain.RoleUser})
+	assert.NoError(suite.T(), err)
+	suite.mockProjects.AssertExpectations(suite.T())
+}
+
+func (suite *ProjectUseCaseTestSuite) TestSetMember_AddsUser() {
+	suite.mockUsers.On("GetByID", mock.Anything, "user-3").Return(&domain.User{ID: "user-3"}, nil)
+	suite.mockProjects.On("Update", mock.Anything, mock.MatchedBy(func(p domain.Project) bool {
+		role, ok := p.RoleOf("user-3")
+		return ok && role == domain.ProjectEditor && len(p.Members) == 3
+	})).Return(&suite.project, nil)
+
+	_, err := suite.useCase.SetMember(context.Background(), testProjectID, "user-3", domain.ProjectEditor, "user-1")
+	assert.NoError(suite.T(), err)
+	suite.mockProjects.AssertExpectations(suite.T())
+}
+
+func (suite *ProjectUseCaseTestSuite) TestSetMember_Rejected() {
+	suite.mockUsers.On("GetByID", mock.Anything, "ghost").Return(nil, domain.ErrNotFound)
+	suite.mockUsers.On("GetByID", mock.Anything, "user-1").Return(&domain.User{ID: "user-1"}, nil)
+
+	_, err := suite.useCase.SetMember(context.Background(), testProjectID, "ghost", domain.ProjectViewer, "user-1")
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	_, err = suite.useCase.SetMember(context.Background(), testProjectID, "user-1", "admin", "user-1")
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	_, err = suite.useCase.SetMember(context.Background(), testProjectID, "user-1", domain.ProjectEditor, "user-1")
+	assert.Equal(suite.T(), domain.ErrLastOwner, err)
+	suite.mockProjects.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (suite *ProjectUseCaseTestSuite) TestRemoveMember() {
+	suite.mockProjects.On("Update", mock.Anything, mock.MatchedBy(func(p domain.Project) bool {
+		_, ok := p.RoleOf("user-2")
+		return !ok && len(p.Members) == 1
+	})).Return(&suite.project, nil)
+
+	_, err := suite.useCase.RemoveMember(context.Background(), testProjectID, "user-2", "user-1")
+	assert.NoError(suite.T(), err)
+	_, err = suite.useCase.RemoveMember(context.Background(), testProjectID, "user-1", "user-1")
+	assert.Equal(suite.T(), domain.ErrLastOwner, err)
+	_, err = suite.useCase.RemoveMember(context.Background(), testProjectID, "user-3", "user-1")
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockProjects.AssertNumberOfCalls(suite.T(), "Update", 1)
+}
+
+func (suite *ProjectUseCaseTestSuite) TestRemoveMember_EndsTaskStream() {
+	stream := NewTaskStreamUseCase(suite.mockProjects)
+	useCase := NewProjectUseCase(suite.mockProjects, suite.mockTasks, suite.mockUsers, suite.mockAudit, stream)
+	remaining := domain.Project{ID: testProjectID, Members: suite.project.Members[:1]}
+	suite.mockProjects.On("Update", mock.Anything, mock.Anything).Return(&remaining, nil)
+	viewer := domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	events, err := stream.Subscribe(context.Background(), testProjectID, viewer, "")
+	suite.Require().NoError(err)
+
+	_, err = useCase.RemoveMember(context.Background(), testProjectID, "user-2", "user-1")
+	suite.Require().NoError(err)
+	stream.Publish(context.Background(), domain.Event{Type: domain.EventTaskCreated, Task: &domain.Task{ID: "t1", ProjectID: testProjectID, CreatedBy: "user-2"}})
+
+	_, open := <-events
+	assert.False(suite.T(), open, "removed member still receives events")
+}
+
+func (suite *ProjectUseCaseTestSuite) TestDeleteProject_NotEmpty() {
+	suite.mockTasks.On("Find", mock.Anything, mock.MatchedBy(func(q domain.TaskQuery) bool {
+		return q.ProjectID == testProjectID
+	})).Return(&domain.TaskPage{Tasks: []domain.Task{{ID: "1"}}, Total: 1}, nil)
+
+	err := suite.useCase.DeleteProject(context.Background(), testProjectID, "user-1")
+	assert.Equal(suite.T(), domain.ErrProjectNotEmpty, err)
+	suite.mockProjects.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (suite *ProjectUseCaseTestSuite) TestDeleteProject() {
+	suite.mockTasks.On("Find", mock.Anything, mock.Anything).Return(&domain.TaskPage{Tasks: []domain.Task{}}, nil)
+	suite.mockProjects.On("Delete", mock.Anything, testProjectID).Return(nil)
+
+	err := suite.useCase.DeleteProject(context.Background(), testProjectID, "user-1")
+	assert.NoError(suite.T(), err)
+	suite.mockProjects.AssertExpectations(suite.T())
+}
+
+func (suite *ProjectUseCaseTestSuite) TestAuthorize() {
+	cases := []struct {
+		requester domain.Claims
+		minimum   domain.ProjectRole
+		role      domain.ProjectRole
+		err       error
+	}{
+		{domain.Claims{UserID: "user-1", Role: domain.RoleUser}, domain.ProjectOwner, domain.ProjectOwner, nil},
+		{domain.Claims{UserID: "user-2", Role: domain.RoleUser}, domain.ProjectViewer, domain.ProjectViewer, nil},
+		{domain.Claims{UserID: "user-2", Role: domain.RoleUser}, domain.ProjectEditor, "", domain.ErrForbidden},
+		{domain.Claims{UserID: "user-3", Role: domain.RoleUser}, domain.ProjectViewer, "", domain.ErrNotFound},
+		{domain.Claims{UserID: "admin-1", Role: domain.RoleAdmin, Permissions: domain.Permissions}, domain.ProjectOwner, domain.ProjectOwner, nil},
+	}
+	for _, c := range cases {
+		role, err := suite.useCase.Authorize(context.Background(), testProjectID, c.requester, c.minimum)
+		assert.Equal(suite.T(), c.err, err, c.requester.UserID)
+		assert.Equal(suite.T(), c.role, role, c.requester.UserID)
+	}
+
+	_, err := suite.useCase.Authorize(context.Background(), "missing", domain.Claims{UserID: "admin-1", Role: domain.RoleAdmin, Permissions: domain.Permissions}, domain.ProjectViewer)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+}
+
+func TestProjectRole_Includes(t *testing.T) {
+	assert.True(t, domain.ProjectOwner.Includes(domain.ProjectEditor))
+	assert.True(t, domain.ProjectEditor.Includes(domain.ProjectEditor))
+	assert.False(t, domain.ProjectViewer.Includes(domain.ProjectEditor))
+	assert.False(t, domain.ProjectRole("admin").Includes(domain.ProjectViewer))
+}
+
+func TestProject_Validate(t *testing.T) {
+	valid := domain.Project{Name: "Launch", Members: []domain.ProjectMember{{UserID: "user-1", Role: domain.ProjectOwner}}}
+	assert.NoError(t, valid.Validate())
+
+	// Projects adopted from before projects existed have no members
+	assert.NoError(t, (&domain.Project{Name: domain.UnscopedProjectName}).Validate())
+
+	duplicate := valid
+	duplicate.Members = append(duplicate.Members, domain.ProjectMember{UserID: "user-1", Role: domain.ProjectViewer})
+	badRole := valid
+	badRole.Members = []domain.ProjectMember{{UserID: "user-1", Role: "admin"}}
+	for _, p := range []domain.Project{{}, duplicate, badRole} {
+		assert.Equal(t, domain.ErrInvalidInput, p.Validate())
+	}
+}
+
+func TestProjectUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ProjectUseCaseTestSuite))
+}
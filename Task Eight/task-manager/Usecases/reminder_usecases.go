@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	domain "task-manager/Domain"
+	"time"
+)
+
+type ReminderUseCase struct {
+	taskRepo     domain.ITaskRepository
+	userRepo     domain.IUserRepository
+	reminderRepo domain.IReminderRepository
+	notifiers    []domain.INotifier
+	offsets      []time.Duration
+	window       time.Duration
+}
+
+// NewReminderUseCase creates the reminder use case. A reminder fires offset
+// before a task's due date, or after it for negative offsets, and is sent if
+// it fell due within the last window. The window must be longer than the
+// interval between runs so that no reminder is missed.
+func NewReminderUseCase(taskRepo domain.ITaskRepository, userRepo domain.IUserRepository, reminderRepo domain.IReminderRepository, notifiers []domain.INotifier, offsets []time.Duration, window time.Duration) domain.IReminderUseCase {
+	return &ReminderUseCase{
+		taskRepo:     taskRepo,
+		userRepo:     userRepo,
+		reminderRepo: reminderRepo,
+		notifiers:    notifiers,
+		offsets:      offsets,
+		window:       window,
+	}
+}
+
+// SendDueReminders sends the reminders of open tasks through every notifier.
+// Failed deliveries are logged and retried on the next run while they are
+// still within the window.
+func (uc *ReminderUseCase) SendDueReminders(ctx context.Context, now time.Time) error {
+	for _, offset := range uc.offsets {
+		// The reminder fires at due - offset, so look for due dates that put
+		// that moment inside (now - window, now]
+		query := domain.TaskQuery{
+			DueAfter:  now.Add(offset - uc.window),
+			DueBefore: now.Add(offset),
+			SortBy:    domain.SortByDueDate,
+			Limit:     domain.MaxTaskPageSize,
+		}
+		for {
+			page, err := uc.taskRepo.Find(ctx, query)
+			if err != nil {
+				return err
+			}
+			for i := range page.Tasks {
+				if page.Tasks[i].Status != domain.StatusDone {
+					uc.remind(ctx, &page.Tasks[i], offset, now)
+				}
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+	}
+
+	// Deliveries older than the longest overdue offset can never be
+	// claimed again
+	return uc.reminderRepo.DeleteDueBefore(ctx, now.Add(uc.minOffset()-uc.window))
+}
+
+// remind notifies the task's assignee, or its creator if it is unassigned.
+func (uc *ReminderUseCase) remind(ctx context.Context, task *domain.Task, offset time.Duration, now time.Time) {
+	recipientID := task.AssigneeID
+	if recipientID == "" {
+		recipientID = task.CreatedBy
+	}
+	user, err := uc.userRepo.GetByID(ctx, recipientID)
+	if err != nil {
+		log.Printf("Failed to load recipient %s of reminder for task %s: %v", recipientID, task.ID, err)
+		return
+	}
+	notification := newReminderNotification(task, user, offset)
+
+	for _, notifier := range uc.notifiers {
+		delivery := domain.ReminderDelivery{
+			TaskID:  task.ID,
+			UserID:  user.ID,
+			Channel: notifier.Name(),
+			Offset:  offset,
+			DueDate: task.DueDate,
+			SentAt:  now,
+		}
+		// Claiming first keeps concurrent schedulers from sending twice
+		if err := uc.reminderRepo.Claim(ctx, delivery); err != nil {
+			if !errors.Is(err, domain.ErrDuplicateEntry) {
+				log.Printf("Failed to claim %s reminder for task %s: %v", delivery.Channel, task.ID, err)
+			}
+			continue
+		}
+		if err := notifier.Notify(ctx, notification); err != nil {
+			log.Printf("Failed to send %s reminder for task %s: %v", delivery.Channel, task.ID, err)
+			if err := uc.reminderRepo.Release(ctx, delivery); err != nil {
+				log.Printf("Failed to release %s reminder for task %s: %v", delivery.Channel, task.ID, err)
+			}
+		}
+	}
+}
+
+func (uc *ReminderUseCase) minOffset() time.Duration {
+	var min time.Duration
+	for _, offset := range uc.offsets {
+		if offset < min {
+			min = offset
+		}
+	}
+	return min
+}
+
+func newReminderNotification(task *domain.Task, user *domain.User, offset time.Duration) domain.Notification {
+	var subject string
+	switch {
+	case offset > 0:
+		subject = fmt.Sprintf("Task due in %s: %s", offset, task.Title)
+	case offset == 0:
+		subject = "Task due now: " + task.Title
+	default:
+		subject = fmt.Sprintf("Task overdue by %s: %s", -offset, task.Title)
+	}
+	body := fmt.Sprintf("Hello %s,\n\n%q (task %s) is due %s and is still %s.\n",
+		user.Username, task.Title, task.ID, task.DueDate.UTC().Format(time.RFC1123), task.Status)
+	return domain.Notification{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		TaskID:   task.ID,
+		Subject:  subject,
+		Body:     body,
+	}
+}
@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReminderUseCaseTestSuite struct {
+	suite.Suite
+	mockTaskRepo     *mocks.MockTaskRepository
+	mockUserRepo     *mocks.MockUserRepository
+	mockReminderRepo *mocks.MockReminderRepository
+	mockNotifier     *mocks.MockNotifier
+	useCase          domain.IReminderUseCase
+	now              time.Time
+}
+
+func (suite *ReminderUseCaseTestSuite) SetupTest() {
+	suite.mockTaskRepo = new(mocks.MockTaskRepository)
+	suite.mockUserRepo = new(mocks.MockUserRepository)
+	suite.mockReminderRepo = new(mocks.MockReminderRepository)
+	suite.mockNotifier = new(mocks.MockNotifier)
+	suite.mockNotifier.On("Name").Return("log").Maybe()
+	suite.useCase = NewReminderUseCase(suite.mockTaskRepo, suite.mockUserRepo, suite.mockReminderRepo,
+		[]domain.INotifier{suite.mockNotifier}, []time.Duration{time.Hour}, 10*time.Minute)
+	suite.now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *ReminderUseCaseTestSuite) expectTasks(tasks ...domain.Task) {
+	suite.mockTaskRepo.On("Find", mock.Anything, mock.Anything).Return(&domain.TaskPage{Tasks: tasks}, nil).Once()
+	suite.mockReminderRepo.On("DeleteDueBefore", mock.Anything, mock.Anything).Return(nil).Maybe()
+}
+
+func (suite *ReminderUseCaseTestSuite) TestSendDueReminders_QueriesWindow() {
+	expected := domain.TaskQuery{
+		DueAfter:  suite.now.Add(50 * time.Minute),
+		DueBefore: suite.now.Add(time.Hour),
+		SortBy:    domain.SortByDueDate,
+		Limit:     domain.MaxTaskPageSize,
+	}
+	suite.mockTaskRepo.On("Find", mock.Anything, expected).Return(&domain.TaskPage{}, nil)
+	// Only the deliveries that can no longer be claimed are pruned
+	suite.mockReminderRepo.On("DeleteDueBefore", mock.Anything, suite.now.Add(-10*time.Minute)).Return(nil)
+
+	err := suite.useCase.SendDueReminders(context.Background(), suite.now)
+	assert.NoError(suite.T(), err)
+	suite.mockTaskRepo.AssertExpectations(suite.T())
+	suite.mockReminderRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ReminderUseCaseTestSuite) TestSendDueReminders_NotifiesAssignee() {
+	due := suite.now.Add(55 * time.Minute)
+	suite.expectTasks(domain.Task{ID: "1", Title: "Ship", Status: domain.StatusPending, DueDate: due, AssigneeID: "u1", CreatedBy: "u2"})
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(&domain.User{ID: "u1", Username: "alice", Email: "alice@example.com"}, nil)
+	delivery := domain.ReminderDelivery{TaskID: "1", UserID: "u1", Channel: "log", Offset: time.Hour, DueDate: due, SentAt: suite.now}
+	suite.mockReminderRepo.On("Claim", mock.Anything, delivery).Return(nil)
+	suite.mockNotifier.On("Notify", mock.Anything, mock.MatchedBy(func(n domain.Notification) bool {
+		return n.UserID == "u1" && n.Email == "alice@example.com" && n.TaskID == "1" && n.Subject == "Task due in 1h0m0s: Ship"
+	})).Return(nil)
+
+	err := suite.useCase.SendDueReminders(context.Background(), suite.now)
+	assert.NoError(suite.T(), err)
+	suite.mockNotifier.AssertExpectations(suite.T())
+	suite.mockReminderRepo.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything)
+}
+
+func (suite *ReminderUseCaseTestSuite) TestSendDueReminders_UnassignedNotifiesCreator() {
+	suite.expectTasks(domain.Task{ID: "1", Status: domain.StatusPending, DueDate: suite.now.Add(55 * time.Minute), CreatedBy: "u2"})
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").Return(&domain.User{ID: "u2"}, nil)
+	suite.mockReminderRepo.On("Claim", mock.Anything, mock.Anything).Return(nil)
+	suite.mockNotifier.On("Notify", mock.Anything, mock.MatchedBy(func(n domain.Notification) bool {
+		return n.UserID == "u2"
+	})).Return(nil)
+
+	err := suite.useCase.SendDueReminders(context.Background(), suite.now)
+	assert.NoError(suite.T(), err)
+	suite.mockNotifier.AssertExpectations(suite.T())
+}
+
+func (suite *ReminderUseCaseTestSuite) TestSendDueReminders_SkipsDoneTasks() {
+	suite.expectTasks(domain.Task{ID: "1", Status: domain.StatusDone, DueDate: suite.now.Add(55 * time.Minute), AssigneeID: "u1"})
+
+	err := suite.useCase.SendDueReminders(context.Background(), suite.now)
+	assert.NoError(suite.T(), err)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "GetByID", mock.Anything, mock.Anything)
+	suite.mockNotifier.AssertNotCalled(suite.T(), "Notify", mock.Anything, mock.Anything)
+}
+
+func (suite *ReminderUseCaseTestSuite) TestSendDueReminders_AlreadySent() {
+	suite.expectTasks(domain.Task{ID: "1", Status: domain.StatusPending, DueDate: suite.now.Add(55 * time.Minute), AssigneeID: "u1"})
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(&domain.User{ID: "u1"}, nil)
+	suite.mockReminderRepo.On("Claim", mock.Anything, mock.Anything).Return(domain.ErrDuplicateEntry)
+
+	err := suite.useCase.SendDueReminders(context.Background(), suite.now)
+	assert.NoError(suite.T(), err)
+	suite.mockNotifier.AssertNotCalled(suite.T(), "Notify", mock.Anything, mock.Anything)
+}
+
+func (suite *ReminderUseCaseTestSuite) TestSendDueReminders_FailedDeliveryIsReleased() {
+	suite.expectTasks(domain.Task{ID: "1", Status: domain.StatusPending, DueDate: suite.now.Add(55 * time.Minute), AssigneeID: "u1"})
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(&domain.User{ID: "u1"}, nil)
+	suite.mockReminderRepo.On("Claim", mock.Anything, mock.Anything).Return(nil)
+	suite.mockNotifier.On("Notify", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	suite.mockReminderRepo.On("Release", mock.Anything, mock.MatchedBy(func(d domain.ReminderDelivery) bool {
+		return d.TaskID == "1" && d.Channel == "log"
+	})).Return(nil)
+
+	err := suite.useCase.SendDueReminders(context.Background(), suite.now)
+	assert.NoError(suite.T(), err)
+	suite.mockReminderRepo.AssertExpectations(suite.T())
+}
+
+func (suite *ReminderUseCaseTestSuite) TestSendDueReminders_FindError() {
+	suite.mockTaskRepo.On("Find", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+	err := suite.useCase.SendDueReminders(context.Background(), suite.now)
+	assert.Error(suite.T(), err)
+	suite.mockReminderRepo.AssertNotCalled(suite.T(), "DeleteDueBefore", mock.Anything, mock.Anything)
+}
+
+func TestNewReminderNotification_Subjects(t *testing.T) {
+	task := &domain.Task{ID: "1", Title: "Ship"}
+	user := &domain.User{ID: "u1"}
+	assert.Equal(t, "Task due in 24h0m0s: Ship", newReminderNotification(task, user, 24*time.Hour).Subject)
+	assert.Equal(t, "Task due now: Ship", newReminderNotification(task, user, 0).Subject)
+	assert.Equal(t, "Task overdue by 1h0m0s: Ship", newReminderNotification(task, user, -time.Hour).Subject)
+}
+
+func TestReminderUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(ReminderUseCaseTestSuite))
+}
@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	domain "task-manager/Domain"
+)
+
+type RoleUseCase struct {
+	roleRepo    domain.IRoleRepository
+	userRepo    domain.IUserRepository
+	auditRepo   domain.IAuditRepository
+	authService domain.IAuthService
+	events      domain.IEventPublisher
+}
+
+func NewRoleUseCase(roleRepo domain.IRoleRepository, userRepo domain.IUserRepository, auditRepo domain.IAuditRepository, authService domain.IAuthService, events domain.IEventPublisher) domain.IRoleUseCase {
+	return &RoleUseCase{roleRepo: roleRepo, userRepo: userRepo, auditRepo: auditRepo, authService: authService, events: events}
+}
+
+func (uc *RoleUseCase) ListRoles(ctx context.Context) ([]domain.RoleDefinition, error) {
+	return uc.roleRepo.GetAll(ctx)
+}
+
+func (uc *RoleUseCase) CreateRole(ctx context.Context, role domain.RoleDefinition, actorID string) (*domain.RoleDefinition, error) {
+	role.Name = domain.NormalizeRoleName(string(role.Name))
+	role.Description = strings.TrimSpace(role.Description)
+	if role.Permissions == nil {
+		role.Permissions = []domain.Permission{}
+	}
+	if err := role.Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := uc.roleRepo.Create(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, domain.AuditRoleCreated, actorID, created.Name, []domain.FieldChange{
+		{Field: "permissions", After: joinPermissions(created.Permissions)},
+	})
+	return created, nil
+}
+
+func (uc *RoleUseCase) UpdateRole(ctx context.Context, name domain.Role, patch domain.RoleDefinitionPatch, actorID string) (*domain.RoleDefinition, error) {
+	name = domain.NormalizeRoleName(string(name))
+	// Admins keep every permission, but may be made to use two-factor
+	// authentication
+	if name == domain.RoleAdmin && (patch.RequireMFA == nil || patch.Description != nil || patch.Permissions != nil) {
+		return nil, domain.ErrBuiltinRole
+	}
+
+	role, err := uc.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	before := *role
+	// Validate sorts the permissions, which built-in roles are not stored in
+	before.Permissions = slices.Sorted(slices.Values(role.Permissions))
+
+	if patch.Description != nil {
+		role.Description = strings.TrimSpace(*patch.Description)
+	}
+	if patch.Permissions != nil {
+		role.Permissions = slices.Clone(*patch.Permissions)
+	}
+	if patch.RequireMFA != nil {
+		role.RequireMFA = *patch.RequireMFA
+	}
+	if err := role.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated, err := uc.roleRepo.Update(ctx, *role)
+	if err != nil {
+		return nil, err
+	}
+	uc.authService.InvalidatePermissions(name)
+
+	changes := []domain.FieldChange{}
+	if before.Description != updated.Description {
+		changes = append(changes, domain.FieldChange{Field: "description", Before: before.Description, After: updated.Description})
+	}
+	if !slices.Equal(before.Permissions, updated.Permissions) {
+		changes = append(changes, domain.FieldChange{
+			Field:  "permissions",
+			Before: joinPermissions(before.Permissions),
+			After:  joinPermissions(updated.Permissions),
+		})
+	}
+	if before.RequireMFA != updated.RequireMFA {
+		changes = append(changes, domain.FieldChange{
+			Field:  "require_mfa",
+			Before: strconv.FormatBool(before.RequireMFA),
+			After:  strconv.FormatBool(updated.RequireMFA),
+		})
+	}
+	uc.audit(ctx, domain.AuditRoleUpdated, actorID, name, changes)
+	publishEvents(ctx, uc.events, actorID, domain.Event{Type: domain.EventRoleUpdated, Role: name})
+	return updated, nil
+}
+
+func (uc *RoleUseCase) DeleteRole(ctx context.Context, name domain.Role, actorID string) error {
+	name = domain.NormalizeRoleName(string(name))
+	if name.IsBuiltin() {
+		return domain.ErrBuiltinRole
+	}
+
+	role, err := uc.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	holders, err := uc.userRepo.CountByRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if holders > 0 {
+		return domain.ErrRoleInUse
+	}
+
+	if err := uc.roleRepo.Delete(ctx, name); err != nil {
+		return err
+	}
+	uc.authService.InvalidatePermissions(name)
+
+	uc.audit(ctx, domain.AuditRoleDeleted, actorID, name, []domain.FieldChange{
+		{Field: "permissions", Before: joinPermissions(role.Permissions)},
+	})
+	return nil
+}
+
+func (uc *RoleUseCase) EnsureBuiltinRoles(ctx context.Context) error {
+	for _, builtin := range domain.BuiltinRoles() {
+		existing, err := uc.roleRepo.GetByName(ctx, builtin.Name)
+		if errors.Is(err, domain.ErrNotFound) {
+			if _, err := uc.roleRepo.Create(ctx, builtin); err != nil && !errors.Is(err, domain.ErrDuplicateEntry) {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Admins keep every permission, including ones added by upgrades
+		if builtin.Name == domain.RoleAdmin && !existing.Grants(builtin.Permissions...) {
+			existing.Permissions = builtin.Permissions
+			if _, err := uc.roleRepo.Update(ctx, *existing); err != nil {
+				return err
+			}
+			uc.authService.InvalidatePermissions(builtin.Name)
+		}
+	}
+	return nil
+}
+
+func (uc *RoleUseCase) audit(ctx context.Context, action, actorID string, name domain.Role, changes []domain.FieldChange) {
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityRole,
+		EntityID:   string(name),
+		Changes:    changes,
+	})
+}
+
+func joinPermissions(permissions []domain.Permission) string {
+	names := make([]string, len(permissions))
+	for i, p := range permissions {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ",")
+}
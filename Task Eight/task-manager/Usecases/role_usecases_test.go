@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RoleUseCaseTestSuite struct {
+	suite.Suite
+	mockRoleRepo *mocks.MockRoleRepository
+	mockUserRepo *mocks.MockUserRepository
+	mockAudit    *mocks.MockAuditRepository
+	mockAuthSvc  *mocks.MockAuthService
+	useCase      domain.IRoleUseCase
+	triager      domain.RoleDefinition
+}
+
+func (suite *RoleUseCaseTestSuite) SetupTest() {
+	suite.mockRoleRepo = new(mocks.MockRoleRepository)
+	suite.mockUserRepo = new(mocks.MockUserRepository)
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockAudit.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockAuthSvc = new(mocks.MockAuthService)
+	suite.mockAuthSvc.On("InvalidatePermissions", mock.Anything).Maybe()
+	events := new(mocks.MockEventPublisher)
+	events.On("Publish", mock.Anything, mock.Anything).Maybe()
+	suite.useCase = NewRoleUseCase(suite.mockRoleRepo, suite.mockUserRepo, suite.mockAudit, suite.mockAuthSvc, events)
+
+	suite.triager = domain.RoleDefinition{Name: "triager", Permissions: []domain.Permission{domain.PermTasksUpdate}}
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.Role("triager")).Return(&suite.triager, nil).Maybe()
+}
+
+func (suite *RoleUseCaseTestSuite) TestCreateRole_Normalizes() {
+	suite.mockRoleRepo.On("Create", mock.Anything, mock.MatchedBy(func(r domain.RoleDefinition) bool {
+		return r.Name == "triager" && r.Description == "Sorts tasks" &&
+			assert.ObjectsAreEqual([]domain.Permission{domain.PermTasksDelete, domain.PermTasksUpdate}, r.Permissions)
+	})).Return(&suite.triager, nil)
+
+	_, err := suite.useCase.CreateRole(context.Background(), domain.RoleDefinition{
+		Name:        " Triager ",
+		Description: " Sorts tasks ",
+		Permissions: []domain.Permission{domain.PermTasksUpdate, domain.PermTasksDelete, domain.PermTasksUpdate},
+	}, "admin-1")
+	assert.NoError(suite.T(), err)
+	suite.mockRoleRepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditRoleCreated && e.EntityType == domain.AuditEntityRole && e.EntityID == "triager"
+	}))
+}
+
+func (suite *RoleUseCaseTestSuite) TestCreateRole_Invalid() {
+	cases := []domain.RoleDefinition{
+		{Name: ""},
+		{Name: "has space"},
+		{Name: "triager", Permissions: []domain.Permission{"tasks:explode"}},
+	}
+	for _, role := range cases {
+		_, err := suite.useCase.CreateRole(context.Background(), role, "admin-1")
+		assert.Equal(suite.T(), domain.ErrInvalidInput, err, role.Name)
+	}
+	suite.mockRoleRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *RoleUseCaseTestSuite) TestUpdateRole_InvalidatesCache() {
+	permissions := []domain.Permission{domain.PermTasksUpdate, domain.PermTasksDelete}
+	suite.mockRoleRepo.On("Update", mock.Anything, mock.MatchedBy(func(r domain.RoleDefinition) bool {
+		return r.Name == "triager" && len(r.Permissions) == 2
+	})).Return(&domain.RoleDefinition{Name: "triager", Permissions: permissions}, nil)
+
+	_, err := suite.useCase.UpdateRole(context.Background(), "triager", domain.RoleDefinitionPatch{Permissions: &permissions}, "admin-1")
+	assert.NoError(suite.T(), err)
+	suite.mockAuthSvc.AssertCalled(suite.T(), "InvalidatePermissions", domain.Role("triager"))
+}
+
+func (suite *RoleUseCaseTestSuite) TestUpdateRole_AdminIsImmutable() {
+	_, err := suite.useCase.UpdateRole(context.Background(), domain.RoleAdmin, domain.RoleDefinitionPatch{}, "admin-1")
+	assert.Equal(suite.T(), domain.ErrBuiltinRole, err)
+	suite.mockRoleRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (suite *RoleUseCaseTestSuite) TestUpdateRole_AdminRequireMFA() {
+	admin := domain.RoleDefinition{Name: domain.RoleAdmin, Permissions: domain.Permissions}
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.RoleAdmin).Return(&admin, nil)
+	suite.mockRoleRepo.On("Update", mock.Anything, mock.MatchedBy(func(r domain.RoleDefinition) bool {
+		return r.Name == domain.RoleAdmin && r.RequireMFA && len(r.Permissions) == len(domain.Permissions)
+	})).Return(&domain.RoleDefinition{Name: domain.RoleAdmin, Permissions: slices.Sorted(slices.Values(domain.Permissions)), RequireMFA: true}, nil)
+
+	required := true
+	updated, err := suite.useCase.UpdateRole(context.Background(), domain.RoleAdmin, domain.RoleDefinitionPatch{RequireMFA: &required}, "admin-1")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), updated.RequireMFA)
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditRoleUpdated && len(e.Changes) == 1 &&
+			e.Changes[0] == domain.FieldChange{Field: "require_mfa", Before: "false", After: "true"}
+	}))
+
+	// Anything else about the admin role stays fixed
+	description := "Everything"
+	_, err = suite.useCase.UpdateRole(context.Background(), domain.RoleAdmin, domain.RoleDefinitionPatch{Description: &description, RequireMFA: &required}, "admin-1")
+	assert.Equal(suite.T(), domain.ErrBuiltinRole, err)
+	suite.mockRoleRepo.AssertNumberOfCalls(suite.T(), "Update", 1)
+}
+
+func (suite *RoleUseCaseTestSuite) TestDeleteRole() {
+	suite.mockUserRepo.On("CountByRole", mock.Anything, domain.Role("triager")).Return(int64(0), nil)
+	suite.mockRoleRepo.On("Delete", mock.Anything, domain.Role("triager")).Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.DeleteRole(context.Background(), "triager", "admin-1"))
+	suite.mockRoleRepo.AssertExpectations(suite.T())
+	suite.mockAuthSvc.AssertCalled(suite.T(), "InvalidatePermissions", domain.Role("triager"))
+}
+
+func (suite *RoleUseCaseTestSuite) TestDeleteRole_Refused() {
+	suite.mockUserRepo.On("CountByRole", mock.Anything, domain.Role("triager")).Return(int64(2), nil)
+
+	assert.Equal(suite.T(), domain.ErrRoleInUse, suite.useCase.DeleteRole(context.Background(), "triager", "admin-1"))
+	assert.Equal(suite.T(), domain.ErrBuiltinRole, suite.useCase.DeleteRole(context.Background(), domain.RoleUser, "admin-1"))
+	suite.mockRoleRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (suite *RoleUseCaseTestSuite) TestEnsureBuiltinRoles() {
+	// The stored admin role predates the roles:manage permission
+	stale := domain.RoleDefinition{Name: domain.RoleAdmin, Permissions: []domain.Permission{domain.PermUsersManage}}
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.RoleAdmin).Return(&stale, nil)
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.RoleUser).Return(nil, domain.ErrNotFound)
+	suite.mockRoleRepo.On("Update", mock.Anything, mock.MatchedBy(func(r domain.RoleDefinition) bool {
+		return r.Name == domain.RoleAdmin && len(r.Permissions) == len(domain.Permissions)
+	})).Return(&stale, nil)
+	suite.mockRoleRepo.On("Create", mock.Anything, mock.MatchedBy(func(r domain.RoleDefinition) bool {
+		return r.Name == domain.RoleUser
+	})).Return(&domain.RoleDefinition{Name: domain.RoleUser}, nil)
+
+	assert.NoError(suite.T(), suite.useCase.EnsureBuiltinRoles(context.Background()))
+	suite.mockRoleRepo.AssertExpectations(suite.T())
+}
+
+func TestRoleDefinition_Grants(t *testing.T) {
+	role := domain.RoleDefinition{Permissions: []domain.Permission{domain.PermTasksCreate, domain.PermTasksUpdate}}
+	assert.True(t, role.Grants(domain.PermTasksCreate))
+	assert.True(t, role.Grants())
+	assert.False(t, role.Grants(domain.PermTasksCreate, domain.PermTasksDelete))
+
+	claims := domain.Claims{Permissions: role.Permissions}
+	assert.True(t, claims.Can(domain.PermTasksUpdate))
+	assert.False(t, claims.Can(domain.PermUsersManage))
+}
+
+func TestRoleUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(RoleUseCaseTestSuite))
+}
@@ -0,0 +1,220 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+)
+
+const (
+	// taskStreamHistory is how many recent events are kept for resuming
+	// subscribers.
+	taskStreamHistory = 256
+	// taskStreamBuffer is how far a subscriber may fall behind before it is
+	// dropped. A dropped client reconnects and resumes from its last event.
+	taskStreamBuffer = 64
+)
+
+// TaskStreamUseCase fans task events out to live subscribers. Event IDs are
+// "<epoch>-<sequence>", where the epoch changes on every start, so an ID
+// from before a restart is recognised and answered with a reset.
+type TaskStreamUseCase struct {
+	projectRepo domain.IProjectRepository
+
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	history     []domain.StreamEvent
+	subscribers map[*taskSubscriber]struct{}
+	closed      bool
+}
+
+// taskSubscriber receives the events of the project's tasks that the
+// requester may read. The project is loaded when subscribing and replaced
+// by the one in each membership change.
+type taskSubscriber struct {
+	project   *domain.Project
+	requester domain.Claims
+	events    chan domain.StreamEvent
+}
+
+// receives reports whether the event is sent to the subscriber.
+func (sub *taskSubscriber) receives(event domain.Event) bool {
+	if event.Type == domain.EventStreamReset {
+		return true
+	}
+	return event.Task.ProjectID == sub.project.ID && event.Task.IsVisibleTo(sub.requester, sub.project)
+}
+
+// keeps applies an access event to the subscriber and reports whether the
+// requester may go on reading the project.
+func (sub *taskSubscriber) keeps(event domain.Event) bool {
+	switch event.Type {
+	case domain.EventProjectMembersChanged:
+		if event.Project.ID == sub.project.ID {
+			sub.project = event.Project
+			_, ok := sub.project.RoleFor(sub.requester)
+			return ok
+		}
+	case domain.EventProjectDeleted:
+		return event.Project.ID != sub.project.ID
+	case domain.EventRoleUpdated:
+		// The permissions are frozen in the claims, so make the client
+		// reconnect with fresh ones
+		return event.Role != sub.requester.Role
+	case domain.EventSessionsEnded:
+		if event.User.ID == sub.requester.UserID {
+			return event.TokenID != "" && event.TokenID != sub.requester.TokenID
+		}
+	}
+	return true
+}
+
+func NewTaskStreamUseCase(projectRepo domain.IProjectRepository) domain.ITaskStreamUseCase {
+	return &TaskStreamUseCase{
+		projectRepo: projectRepo,
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		subscribers: make(map[*taskSubscriber]struct{}),
+	}
+}
+
+func (uc *TaskStreamUseCase) Publish(ctx context.Context, event domain.Event) {
+	if slices.Contains(domain.AccessEventTypes, event.Type) {
+		uc.revoke(event)
+		return
+	}
+	if event.Task == nil || !slices.Contains(domain.StreamedEventTypes, event.Type) {
+		return
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.closed {
+		return
+	}
+
+	uc.seq++
+	streamed := domain.StreamEvent{ID: uc.eventID(uc.seq), Event: event}
+	if len(uc.history) == taskStreamHistory {
+		uc.history = uc.history[1:]
+	}
+	uc.history = append(uc.history, streamed)
+
+	for sub := range uc.subscribers {
+		if !sub.receives(event) {
+			continue
+		}
+		select {
+		case sub.events <- streamed:
+		default:
+			// Never block the publishing request on a slow reader
+			uc.remove(sub)
+		}
+	}
+}
+
+func (uc *TaskStreamUseCase) Subscribe(ctx context.Context, projectID string, requester domain.Claims, lastEventID string) (<-chan domain.StreamEvent, error) {
+	project, err := uc.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	var missed []domain.StreamEvent
+	if lastEventID != "" {
+		if missed, err = uc.since(lastEventID); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &taskSubscriber{project: project, requester: requester}
+	sub.events = make(chan domain.StreamEvent, taskStreamBuffer+len(missed))
+	for _, e := range missed {
+		if sub.receives(e.Event) {
+			sub.events <- e
+		}
+	}
+	if uc.closed {
+		close(sub.events)
+		return sub.events, nil
+	}
+
+	uc.subscribers[sub] = struct{}{}
+	go func() {
+		// The stream outliving the access token would outlive its checks
+		var expired <-chan time.Time
+		if !requester.ExpiresAt.IsZero() {
+			timer := time.NewTimer(time.Until(requester.ExpiresAt))
+			defer timer.Stop()
+			expired = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-expired:
+		}
+		uc.mu.Lock()
+		defer uc.mu.Unlock()
+		uc.remove(sub)
+	}()
+	return sub.events, nil
+}
+
+func (uc *TaskStreamUseCase) Close() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	uc.closed = true
+	for sub := range uc.subscribers {
+		uc.remove(sub)
+	}
+}
+
+// revoke ends the subscriptions the access event took access away from.
+func (uc *TaskStreamUseCase) revoke(event domain.Event) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for sub := range uc.subscribers {
+		if !sub.keeps(event) {
+			uc.remove(sub)
+		}
+	}
+}
+
+// since returns the events published after lastEventID, or a single reset
+// event when some of them are no longer in the history. Callers hold the
+// lock.
+func (uc *TaskStreamUseCase) since(lastEventID string) ([]domain.StreamEvent, error) {
+	epoch, seqText, ok := strings.Cut(lastEventID, "-")
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if !ok || err != nil {
+		return nil, fmt.Errorf("%w: malformed Last-Event-ID", domain.ErrInvalidInput)
+	}
+
+	// The history holds the events after oldest-1 up to uc.seq
+	oldest := uc.seq - uint64(len(uc.history)) + 1
+	if epoch != uc.epoch || seq > uc.seq || seq+1 < oldest {
+		reset := domain.Event{Type: domain.EventStreamReset, OccurredAt: time.Now()}
+		return []domain.StreamEvent{{ID: uc.eventID(uc.seq), Event: reset}}, nil
+	}
+	return slices.Clone(uc.history[seq+1-oldest:]), nil
+}
+
+// remove ends a subscription. Callers hold the lock.
+func (uc *TaskStreamUseCase) remove(sub *taskSubscriber) {
+	if _, ok := uc.subscribers[sub]; ok {
+		delete(uc.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (uc *TaskStreamUseCase) eventID(seq uint64) string {
+	return uc.epoch + "-" + strconv.FormatUint(seq, 10)
+}
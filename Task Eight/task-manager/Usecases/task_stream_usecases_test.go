@@ -0,0 +1,265 @@
+package usecases
+
+import (
+	"context"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func taskEvent(eventType, taskID, projectID string) domain.Event {
+	return domain.Event{Type: eventType, Task: &domain.Task{ID: taskID, ProjectID: projectID}}
+}
+
+// streamOwner owns the projects of newTestTaskStream and sees all their tasks.
+var streamOwner = domain.Claims{UserID: "user-1", Role: domain.RoleUser}
+
+// newTestTaskStream returns a stream over two projects owned by user-1, in
+// which user-2 is an editor.
+func newTestTaskStream() domain.ITaskStreamUseCase {
+	projects := new(mocks.MockProjectRepository)
+	for _, id := range []string{testProjectID, "project-2"} {
+		projects.On("GetByID", mock.Anything, id).Return(&domain.Project{ID: id, Members: []domain.ProjectMember{
+			{UserID: "user-1", Role: domain.ProjectOwner},
+			{UserID: "user-2", Role: domain.ProjectEditor},
+		}}, nil).Maybe()
+	}
+	projects.On("GetByID", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound).Maybe()
+	return NewTaskStreamUseCase(projects)
+}
+
+// receive waits briefly for the next event on a stream.
+func receive(t *testing.T, events <-chan domain.StreamEvent) domain.StreamEvent {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		require.True(t, ok, "stream was closed")
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return domain.StreamEvent{}
+	}
+}
+
+// assertClosed checks that a stream ends after the events already queued.
+func assertClosed(t *testing.T, events <-chan domain.StreamEvent) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream was not closed")
+		}
+	}
+}
+
+func TestTaskStream_FiltersByProject(t *testing.T) {
+	stream := newTestTaskStream()
+	ctx := context.Background()
+	other, err := stream.Subscribe(ctx, "project-2", streamOwner, "")
+	require.NoError(t, err)
+	project, err := stream.Subscribe(ctx, testProjectID, streamOwner, "")
+	require.NoError(t, err)
+
+	stream.Publish(ctx, taskEvent(domain.EventTaskCreated, "t1", "project-2"))
+	stream.Publish(ctx, taskEvent(domain.EventTaskStatusChanged, "t1", "project-2"))
+	stream.Publish(ctx, domain.Event{Type: domain.EventUserRegistered, User: &domain.User{ID: "user-3"}})
+	stream.Publish(ctx, taskEvent(domain.EventTaskUpdated, "t2", testProjectID))
+
+	assert.Equal(t, "t1", receive(t, other).Event.Task.ID)
+	assert.Empty(t, other)
+	e := receive(t, project)
+	assert.Equal(t, domain.EventTaskUpdated, e.Event.Type)
+	assert.Equal(t, "t2", e.Event.Task.ID)
+	assert.Empty(t, project)
+}
+
+func TestTaskStream_FiltersByVisibility(t *testing.T) {
+	stream := newTestTaskStream()
+	ctx := context.Background()
+	editor := domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	live, err := stream.Subscribe(ctx, testProjectID, editor, "")
+	require.NoError(t, err)
+
+	hidden := &domain.Task{ID: "t1", ProjectID: testProjectID, CreatedBy: "user-1"}
+	assigned := &domain.Task{ID: "t1", ProjectID: testProjectID, CreatedBy: "user-1", AssigneeID: "user-2"}
+	own := &domain.Task{ID: "t2", ProjectID: testProjectID, CreatedBy: "user-2"}
+	stream.Publish(ctx, domain.Event{Type: domain.EventTaskCreated, Task: hidden})
+	stream.Publish(ctx, domain.Event{Type: domain.EventTaskUpdated, Task: assigned})
+	stream.Publish(ctx, domain.Event{Type: domain.EventTaskCreated, Task: own})
+
+	first := receive(t, live)
+	assert.Equal(t, domain.EventTaskUpdated, first.Event.Type)
+	assert.Equal(t, "t2", receive(t, live).Event.Task.ID)
+	assert.Empty(t, live)
+
+	// Missed events are filtered the same way
+	resumed, err := stream.Subscribe(ctx, testProjectID, editor, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "t2", receive(t, resumed).Event.Task.ID)
+	assert.Empty(t, resumed)
+
+	// Only members can subscribe
+	_, err = stream.Subscribe(ctx, "missing", editor, "")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestTaskStream_ResumesAfterLastEventID(t *testing.T) {
+	stream := newTestTaskStream()
+	ctx := context.Background()
+	first, err := stream.Subscribe(ctx, testProjectID, streamOwner, "")
+	require.NoError(t, err)
+
+	stream.Publish(ctx, taskEvent(domain.EventTaskCreated, "t1", testProjectID))
+	lastSeen := receive(t, first).ID
+	stream.Publish(ctx, domain.Event{Type: domain.EventTaskCreated, Task: &domain.Task{ID: "t2", ProjectID: "project-2", CreatedBy: "user-2"}})
+	stream.Publish(ctx, taskEvent(domain.EventTaskUpdated, "t3", testProjectID))
+	stream.Publish(ctx, taskEvent(domain.EventTaskDeleted, "t1", testProjectID))
+
+	resumed, err := stream.Subscribe(ctx, testProjectID, streamOwner, lastSeen)
+	require.NoError(t, err)
+	e := receive(t, resumed)
+	assert.Equal(t, "t3", e.Event.Task.ID)
+	assert.Equal(t, domain.EventTaskDeleted, receive(t, resumed).Event.Type)
+	assert.Empty(t, resumed)
+
+	// Later events keep arriving on the resumed stream
+	stream.Publish(ctx, taskEvent(domain.EventTaskUpdated, "t4", testProjectID))
+	assert.Equal(t, "t4", receive(t, resumed).Event.Task.ID)
+}
+
+func TestTaskStream_ResetsWhenEventsAreUnknown(t *testing.T) {
+	stream := newTestTaskStream()
+	ctx := context.Background()
+	first, err := stream.Subscribe(ctx, testProjectID, streamOwner, "")
+	require.NoError(t, err)
+	stream.Publish(ctx, taskEvent(domain.EventTaskCreated, "t0", testProjectID))
+	oldest := receive(t, first).ID
+	// One more than the history holds, so the event after oldest is gone
+	for i := 0; i <= taskStreamHistory; i++ {
+		stream.Publish(ctx, taskEvent(domain.EventTaskUpdated, "t1", testProjectID))
+	}
+
+	for name, lastEventID := range map[string]string{
+		"previous run":   "abc-1",
+		"pruned history": oldest,
+		"future event":   oldest[:len(oldest)-1] + "999999",
+	} {
+		resumed, err := stream.Subscribe(ctx, testProjectID, streamOwner, lastEventID)
+		require.NoError(t, err, name)
+		e := receive(t, resumed)
+		assert.Equal(t, domain.EventStreamReset, e.Event.Type, name)
+		assert.Empty(t, resumed, name)
+
+		// Resuming from the reset replays nothing
+		again, err := stream.Subscribe(ctx, testProjectID, streamOwner, e.ID)
+		require.NoError(t, err, name)
+		assert.Empty(t, again, name)
+	}
+}
+
+func TestTaskStream_MalformedLastEventID(t *testing.T) {
+	stream := newTestTaskStream()
+	for _, lastEventID := range []string{"42", "abc-", "abc-x"} {
+		_, err := stream.Subscribe(context.Background(), testProjectID, streamOwner, lastEventID)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, lastEventID)
+	}
+}
+
+func TestTaskStream_DropsSlowSubscribers(t *testing.T) {
+	stream := newTestTaskStream()
+	ctx := context.Background()
+	slow, err := stream.Subscribe(ctx, testProjectID, streamOwner, "")
+	require.NoError(t, err)
+
+	for i := 0; i <= taskStreamBuffer; i++ {
+		stream.Publish(ctx, taskEvent(domain.EventTaskUpdated, "t1", testProjectID))
+	}
+	assert.Len(t, slow, taskStreamBuffer)
+	assertClosed(t, slow)
+}
+
+func TestTaskStream_EndsSubscriptions(t *testing.T) {
+	stream := newTestTaskStream()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled, err := stream.Subscribe(ctx, testProjectID, streamOwner, "")
+	require.NoError(t, err)
+	open, err := stream.Subscribe(context.Background(), testProjectID, streamOwner, "")
+	require.NoError(t, err)
+
+	cancel()
+	assertClosed(t, cancelled)
+
+	stream.Close()
+	assertClosed(t, open)
+	late, err := stream.Subscribe(context.Background(), testProjectID, streamOwner, "")
+	require.NoError(t, err)
+	assertClosed(t, late)
+}
+
+func TestTaskStream_EndsWhenAccessIsLost(t *testing.T) {
+	stream := newTestTaskStream()
+	ctx := context.Background()
+	subscribe := func(requester domain.Claims, projectID string) <-chan domain.StreamEvent {
+		events, err := stream.Subscribe(ctx, projectID, requester, "")
+		require.NoError(t, err)
+		return events
+	}
+	editor := domain.Claims{UserID: "user-2", Role: domain.RoleUser, TokenID: "jti-2"}
+	owner := subscribe(streamOwner, testProjectID)
+	removed := subscribe(editor, testProjectID)
+	kept := subscribe(editor, "project-2")
+
+	// user-2 leaves one project and keeps the other
+	stream.Publish(ctx, domain.Event{Type: domain.EventProjectMembersChanged, Project: &domain.Project{ID: testProjectID, Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectOwner},
+	}}})
+	assertClosed(t, removed)
+	stream.Publish(ctx, taskEvent(domain.EventTaskCreated, "t1", testProjectID))
+	assert.Equal(t, "t1", receive(t, owner).Event.Task.ID)
+
+	// Another user logging out does not matter, the user's own token does
+	stream.Publish(ctx, domain.Event{Type: domain.EventSessionsEnded, User: &domain.User{ID: "user-2"}, TokenID: "jti-other"})
+	stream.Publish(ctx, domain.Event{Type: domain.EventSessionsEnded, User: &domain.User{ID: "user-3"}})
+	stream.Publish(ctx, domain.Event{Type: domain.EventTaskCreated, Task: &domain.Task{ID: "t2", ProjectID: "project-2", CreatedBy: "user-2"}})
+	assert.Equal(t, "t2", receive(t, kept).Event.Task.ID)
+	stream.Publish(ctx, domain.Event{Type: domain.EventSessionsEnded, User: &domain.User{ID: "user-2"}, TokenID: "jti-2"})
+	assertClosed(t, kept)
+
+	stream.Publish(ctx, domain.Event{Type: domain.EventRoleUpdated, Role: domain.RoleUser})
+	assertClosed(t, owner)
+
+	deleted := subscribe(streamOwner, "project-2")
+	stream.Publish(ctx, domain.Event{Type: domain.EventProjectDeleted, Project: &domain.Project{ID: "project-2"}})
+	assertClosed(t, deleted)
+}
+
+func TestTaskStream_EndsWhenTokenExpires(t *testing.T) {
+	stream := newTestTaskStream()
+	requester := streamOwner
+	requester.ExpiresAt = time.Now().Add(50 * time.Millisecond)
+	events, err := stream.Subscribe(context.Background(), testProjectID, requester, "")
+	require.NoError(t, err)
+
+	assertClosed(t, events)
+}
+
+func TestEventFanout(t *testing.T) {
+	first, second := new(mocks.MockEventPublisher), new(mocks.MockEventPublisher)
+	event := taskEvent(domain.EventTaskCreated, "t1", testProjectID)
+	first.On("Publish", mock.Anything, event).Once()
+	second.On("Publish", mock.Anything, event).Once()
+
+	EventFanout{first, second}.Publish(context.Background(), event)
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+}
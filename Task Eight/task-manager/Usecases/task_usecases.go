@@ -1,63 +1,160 @@
 package usecases
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
 	domain "task-manager/Domain"
 	"time"
 )
 
 type TaskUseCase struct {
-	taskRepo domain.ITaskRepository
+	taskRepo    domain.ITaskRepository
+	searcher    domain.ITaskSearcher
+	projectRepo domain.IProjectRepository
+	labelRepo   domain.ILabelRepository
+	commentRepo domain.ICommentRepository
+	blobStore   domain.IBlobStore
+	auditRepo   domain.IAuditRepository
+	events      domain.IEventPublisher
+	transitions domain.StatusTransitions
 }
 
-func NewTaskUseCase(taskRepo domain.ITaskRepository) domain.ITaskUseCase {
-	return &TaskUseCase{taskRepo: taskRepo}
+func NewTaskUseCase(taskRepo domain.ITaskRepository, searcher domain.ITaskSearcher, projectRepo domain.IProjectRepository, labelRepo domain.ILabelRepository, commentRepo domain.ICommentRepository, blobStore domain.IBlobStore, auditRepo domain.IAuditRepository, events domain.IEventPublisher, transitions domain.StatusTransitions) domain.ITaskUseCase {
+	return &TaskUseCase{taskRepo: taskRepo, searcher: searcher, projectRepo: projectRepo, labelRepo: labelRepo, commentRepo: commentRepo, blobStore: blobStore, auditRepo: auditRepo, events: events, transitions: transitions}
 }
 
-func (uc *TaskUseCase) GetAllTasks() ([]domain.Task, error) {
-	tasks, err := uc.taskRepo.GetAll()
+func (uc *TaskUseCase) GetAllTasks(ctx context.Context, projectID string, query domain.TaskQuery, requester domain.Claims) (*domain.TaskPage, error) {
+	if projectID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	visibleTo, err := taskReader(ctx, uc.projectRepo, projectID, requester)
+	if err != nil {
+		return nil, err
+	}
+	query.ProjectID = projectID
+	query.VisibleTo = visibleTo
+
+	page, err := uc.taskRepo.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// SearchTasks runs a full-text search over the project's tasks and
+// highlights the matches.
+func (uc *TaskUseCase) SearchTasks(ctx context.Context, projectID string, query domain.TaskSearchQuery, requester domain.Claims) (*domain.TaskSearchPage, error) {
+	if projectID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	visibleTo, err := taskReader(ctx, uc.projectRepo, projectID, requester)
 	if err != nil {
 		return nil, err
 	}
-	return tasks, nil
+	query.ProjectID = projectID
+	query.VisibleTo = visibleTo
+
+	page, err := uc.searcher.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Hits {
+		hit := &page.Hits[i]
+		hit.TitleHighlight = query.Highlight(hit.Task.Title, 0)
+		hit.Snippet = query.Highlight(hit.Task.Description, domain.SearchSnippetLength)
+	}
+	return page, nil
 }
 
-func (uc *TaskUseCase) GetTaskByID(id string) (*domain.Task, error) {
+func (uc *TaskUseCase) GetTaskByID(ctx context.Context, projectID string, id string, requester domain.Claims) (*domain.Task, error) {
 	if id == "" {
 		return nil, domain.ErrInvalidInput
 	}
 
-	task, err := uc.taskRepo.GetByID(id)
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
 	if err != nil {
-		return nil, domain.ErrNotFound
+		return nil, err
 	}
+
+	children, err := uc.taskRepo.GetChildren(ctx, projectID, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress := domain.ComputeProgress(task, children)
+	task.Progress = &progress
+
 	return task, nil
 }
 
-func (uc *TaskUseCase) CreateTask(task domain.Task) (*domain.Task, error) {
+func (uc *TaskUseCase) CreateTask(ctx context.Context, projectID string, task domain.Task, requester domain.Claims) (*domain.Task, error) {
+	if projectID == "" || requester.UserID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
 	// Validate task
 	if err := task.Validate(); err != nil {
 		return nil, err
 	}
 
+	// A recurring task starts its series as the first occurrence
+	if task.Recurrence != nil {
+		if task.ParentID != "" {
+			return nil, domain.ErrInvalidInput
+		}
+		if err := startSeries(&task, task.Recurrence.Rule); err != nil {
+			return nil, err
+		}
+	}
+
+	// Make sure the assignee, if any, is a member of the project
+	if task.AssigneeID != "" {
+		if err := uc.checkMember(ctx, projectID, task.AssigneeID); err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+	}
+
+	// Subtasks can only be added to an existing, unfinished parent of the
+	// same project that the requester may read
+	if task.ParentID != "" {
+		parent, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, task.ParentID, requester)
+		if err != nil || parent.Status == domain.StatusDone {
+			return nil, domain.ErrInvalidInput
+		}
+	}
+
 	// Set default status if not provided
 	if task.Status == "" {
-		task.Status = "pending"
+		task.Status = domain.StatusPending
 	}
 
-	// Set timestamps
+	// Record the project, ownership and timestamps
 	now := time.Now()
+	task.ProjectID = projectID
+	task.CreatedBy = requester.UserID
 	task.CreatedAt = now
 	task.UpdatedAt = now
 
-	createdTask, err := uc.taskRepo.Create(task)
+	createdTask, err := uc.taskRepo.Create(ctx, task)
 	if err != nil {
 		return nil, err
 	}
 
+	uc.record(ctx, domain.AuditTaskCreated, requester.UserID, createdTask.ID, nil, createdTask)
 	return createdTask, nil
 }
 
-func (uc *TaskUseCase) UpdateTask(id string, task domain.Task) (*domain.Task, error) {
+func (uc *TaskUseCase) UpdateTask(ctx context.Context, projectID string, id string, task domain.Task, requester domain.Claims) (*domain.Task, error) {
 	if id == "" {
 		return nil, domain.ErrInvalidInput
 	}
@@ -68,33 +165,617 @@ func (uc *TaskUseCase) UpdateTask(id string, task domain.Task) (*domain.Task, er
 	}
 
 	// Check if task exists
-	existingTask, err := uc.taskRepo.GetByID(id)
+	existingTask, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
 	if err != nil {
-		return nil, domain.ErrNotFound
+		return nil, err
+	}
+
+	// A version given by the caller must still be current
+	if task.Version != 0 && task.Version != existingTask.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	task.Version = existingTask.Version
+
+	// Keep the current status when none is given, otherwise follow the workflow
+	if task.Status == "" {
+		task.Status = existingTask.Status
+	} else if task.Status != existingTask.Status {
+		if !uc.transitions.Allows(existingTask.Status, task.Status) {
+			return nil, domain.ErrInvalidTransition
+		}
+		if err := uc.checkCompletion(ctx, projectID, id, task.Status); err != nil {
+			return nil, err
+		}
 	}
 
-	// Preserve original creation time
+	// Preserve original creation time, ownership and structure
 	task.CreatedAt = existingTask.CreatedAt
+	task.CreatedBy = existingTask.CreatedBy
+	task.AssigneeID = existingTask.AssigneeID
+	task.ProjectID = existingTask.ProjectID
+	task.ParentID = existingTask.ParentID
+	task.Checklist = existingTask.Checklist
+	task.Labels = existingTask.Labels
+	task.Attachments = existingTask.Attachments
+	task.Recurrence = existingTask.Recurrence
 	task.UpdatedAt = time.Now()
+	next := continueSeries(&task)
+
+	updatedTask, err := uc.taskRepo.Update(ctx, projectID, id, task)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, domain.AuditTaskUpdated, requester.UserID, id, existingTask, updatedTask)
+	uc.createOccurrence(ctx, next, requester.UserID)
+	return updatedTask, nil
+}
+
+// PatchTask applies a partial update. A non-zero version must match the
+// task's current version.
+func (uc *TaskUseCase) PatchTask(ctx context.Context, projectID string, id string, patch domain.TaskPatch, version int64, requester domain.Claims) (*domain.Task, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != task.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	before := *task
+
+	// Only the fields being changed are validated, so an overdue task can
+	// still be edited without moving its due date
+	if patch.Title != nil {
+		if *patch.Title == "" {
+			return nil, domain.ErrInvalidInput
+		}
+		task.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		task.Description = *patch.Description
+	}
+	if patch.DueDate != nil {
+		if patch.DueDate.Before(time.Now()) {
+			return nil, domain.ErrInvalidInput
+		}
+		task.DueDate = *patch.DueDate
+	}
+	if patch.Status != nil && *patch.Status != task.Status {
+		if !patch.Status.IsValid() {
+			return nil, domain.ErrInvalidStatus
+		}
+		if !uc.transitions.Allows(task.Status, *patch.Status) {
+			return nil, domain.ErrInvalidTransition
+		}
+		if err := uc.checkCompletion(ctx, projectID, id, *patch.Status); err != nil {
+			return nil, err
+		}
+		task.Status = *patch.Status
+	}
+
+	task.UpdatedAt = time.Now()
+	next := continueSeries(task)
+	updatedTask, err := uc.taskRepo.Update(ctx, projectID, id, *task)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, domain.AuditTaskUpdated, requester.UserID, id, &before, updatedTask)
+	uc.createOccurrence(ctx, next, requester.UserID)
+	return updatedTask, nil
+}
+
+// UpdateSeries edits a series through its latest occurrence. Title,
+// description and due date change on that occurrence and on every one
+// generated after it; a new due date also moves the schedule. A plain task
+// becomes the first occurrence of a series when given a rule.
+func (uc *TaskUseCase) UpdateSeries(ctx context.Context, projectID string, id string, patch domain.SeriesPatch, version int64, requester domain.Claims) (*domain.Task, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != task.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	before := *task
+
+	// A completed occurrence has either ended its series or handed it on
+	if task.Status == domain.StatusDone {
+		return nil, fmt.Errorf("%w: completed occurrences cannot change their series", domain.ErrInvalidInput)
+	}
+	if task.Recurrence == nil && (patch.Rule == nil || *patch.Rule == "" || task.ParentID != "") {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if patch.Title != nil {
+		if *patch.Title == "" {
+			return nil, domain.ErrInvalidInput
+		}
+		task.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		task.Description = *patch.Description
+	}
+	if patch.DueDate != nil {
+		if patch.DueDate.Before(time.Now()) {
+			return nil, domain.ErrInvalidInput
+		}
+		task.DueDate = *patch.DueDate
+	}
 
-	updatedTask, err := uc.taskRepo.Update(id, task)
+	switch {
+	case patch.Rule != nil && *patch.Rule == "":
+		task.Recurrence = nil
+	case task.Recurrence == nil:
+		if err := startSeries(task, *patch.Rule); err != nil {
+			return nil, err
+		}
+	default:
+		// Copy, so the diff against before sees the change
+		recurrence := *task.Recurrence
+		if patch.Rule != nil {
+			if recurrence.Rule, err = domain.NormalizeRecurrenceRule(*patch.Rule); err != nil {
+				return nil, err
+			}
+		}
+		if patch.Title != nil {
+			recurrence.Title = task.Title
+		}
+		if patch.Description != nil {
+			recurrence.Description = task.Description
+		}
+		if patch.DueDate != nil {
+			recurrence.ScheduledAt = task.DueDate
+		}
+		task.Recurrence = &recurrence
+	}
+
+	task.UpdatedAt = time.Now()
+	updatedTask, err := uc.taskRepo.Update(ctx, projectID, id, *task)
 	if err != nil {
 		return nil, err
 	}
 
+	uc.record(ctx, domain.AuditTaskUpdated, requester.UserID, id, &before, updatedTask)
 	return updatedTask, nil
 }
 
-func (uc *TaskUseCase) DeleteTask(id string) error {
+func (uc *TaskUseCase) DeleteTask(ctx context.Context, projectID string, id string, requester domain.Claims) error {
 	if id == "" {
 		return domain.ErrInvalidInput
 	}
 
 	// Check if task exists
-	_, err := uc.taskRepo.GetByID(id)
+	existingTask, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
 	if err != nil {
+		return err
+	}
+
+	// Deleting a parent would leave its subtasks dangling
+	children, err := uc.taskRepo.GetChildren(ctx, projectID, id)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return domain.ErrHasSubtasks
+	}
+
+	if err := uc.taskRepo.Delete(ctx, projectID, id); err != nil {
+		return err
+	}
+
+	// The task is gone, so leftover comments and attachment content are
+	// unreachable; only log failures
+	if err := uc.commentRepo.DeleteByTask(ctx, id); err != nil {
+		log.Printf("Failed to delete comments of task %s: %v", id, err)
+	}
+	for _, a := range existingTask.Attachments {
+		if err := uc.blobStore.Delete(ctx, domain.AttachmentKey(id, a.ID)); err != nil {
+			log.Printf("Failed to delete attachment %s of task %s: %v", a.ID, id, err)
+		}
+	}
+
+	uc.record(ctx, domain.AuditTaskDeleted, requester.UserID, id, existingTask, nil)
+	return nil
+}
+
+// AssignTask sets the task's assignee, who must be a member of the project.
+// An empty assigneeID unassigns the task.
+func (uc *TaskUseCase) AssignTask(ctx context.Context, projectID string, id string, assigneeID string, requester domain.Claims) (*domain.Task, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	// Check if task exists
+	existingTask, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if assignee is a member
+	if assigneeID != "" {
+		if err := uc.checkMember(ctx, projectID, assigneeID); err != nil {
+			return nil, domain.ErrNotFound
+		}
+	}
+
+	task, err := uc.taskRepo.Assign(ctx, projectID, id, assigneeID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, domain.AuditTaskAssigned, requester.UserID, id, existingTask, task)
+	return task, nil
+}
+
+// TransitionTask moves a task to a new status along the configured workflow.
+func (uc *TaskUseCase) TransitionTask(ctx context.Context, projectID string, id string, status domain.TaskStatus, requester domain.Claims) (*domain.Task, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	if !status.IsValid() {
+		return nil, domain.ErrInvalidStatus
+	}
+
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	if !uc.transitions.Allows(task.Status, status) {
+		return nil, domain.ErrInvalidTransition
+	}
+	if err := uc.checkCompletion(ctx, projectID, id, status); err != nil {
+		return nil, err
+	}
+
+	before := *task
+	task.Status = status
+	task.UpdatedAt = time.Now()
+	next := continueSeries(task)
+	updatedTask, err := uc.taskRepo.Update(ctx, projectID, id, *task)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, domain.AuditTaskTransitioned, requester.UserID, id, &before, updatedTask)
+	uc.createOccurrence(ctx, next, requester.UserID)
+	return updatedTask, nil
+}
+
+// GetSubtasks returns the direct children of a task that the requester may
+// read.
+func (uc *TaskUseCase) GetSubtasks(ctx context.Context, projectID string, id string, requester domain.Claims) ([]domain.Task, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	_, project, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := uc.taskRepo.GetChildren(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	visible := []domain.Task{}
+	for _, child := range children {
+		if child.IsVisibleTo(requester, project) {
+			visible = append(visible, child)
+		}
+	}
+	return visible, nil
+}
+
+// AddChecklistItem appends an open item to the task's checklist.
+func (uc *TaskUseCase) AddChecklistItem(ctx context.Context, projectID string, id string, text string, requester domain.Claims) (*domain.Task, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	return uc.editChecklist(ctx, projectID, id, requester, func(items []domain.ChecklistItem) ([]domain.ChecklistItem, error) {
+		if len(items) >= domain.MaxChecklistItems {
+			return nil, domain.ErrInvalidInput
+		}
+		itemID, err := newItemID()
+		if err != nil {
+			return nil, err
+		}
+		return append(items, domain.ChecklistItem{ID: itemID, Text: text}), nil
+	})
+}
+
+// UpdateChecklistItem renames or ticks a single checklist item.
+func (uc *TaskUseCase) UpdateChecklistItem(ctx context.Context, projectID string, id string, itemID string, patch domain.ChecklistItemPatch, requester domain.Claims) (*domain.Task, error) {
+	if patch.Text != nil && strings.TrimSpace(*patch.Text) == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	return uc.editChecklist(ctx, projectID, id, requester, func(items []domain.ChecklistItem) ([]domain.ChecklistItem, error) {
+		i := slices.IndexFunc(items, func(item domain.ChecklistItem) bool { return item.ID == itemID })
+		if i < 0 {
+			return nil, domain.ErrNotFound
+		}
+		if patch.Text != nil {
+			items[i].Text = strings.TrimSpace(*patch.Text)
+		}
+		if patch.Done != nil {
+			items[i].Done = *patch.Done
+		}
+		return items, nil
+	})
+}
+
+// ReorderChecklist rearranges the checklist. itemIDs must list every item
+// exactly once.
+func (uc *TaskUseCase) ReorderChecklist(ctx context.Context, projectID string, id string, itemIDs []string, requester domain.Claims) (*domain.Task, error) {
+	return uc.editChecklist(ctx, projectID, id, requester, func(items []domain.ChecklistItem) ([]domain.ChecklistItem, error) {
+		if len(itemIDs) != len(items) {
+			return nil, domain.ErrInvalidInput
+		}
+		byID := make(map[string]domain.ChecklistItem, len(items))
+		for _, item := range items {
+			byID[item.ID] = item
+		}
+		reordered := make([]domain.ChecklistItem, 0, len(items))
+		for _, itemID := range itemIDs {
+			item, ok := byID[itemID]
+			if !ok {
+				return nil, domain.ErrInvalidInput
+			}
+			delete(byID, itemID)
+			reordered = append(reordered, item)
+		}
+		return reordered, nil
+	})
+}
+
+// RemoveChecklistItem deletes a single checklist item.
+func (uc *TaskUseCase) RemoveChecklistItem(ctx context.Context, projectID string, id string, itemID string, requester domain.Claims) (*domain.Task, error) {
+	return uc.editChecklist(ctx, projectID, id, requester, func(items []domain.ChecklistItem) ([]domain.ChecklistItem, error) {
+		i := slices.IndexFunc(items, func(item domain.ChecklistItem) bool { return item.ID == itemID })
+		if i < 0 {
+			return nil, domain.ErrNotFound
+		}
+		return slices.Delete(items, i, i+1), nil
+	})
+}
+
+// editChecklist applies edit to a copy of the task's checklist and stores the
+// result.
+func (uc *TaskUseCase) editChecklist(ctx context.Context, projectID string, id string, requester domain.Claims, edit func([]domain.ChecklistItem) ([]domain.ChecklistItem, error)) (*domain.Task, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *task
+	items, err := edit(slices.Clone(task.Checklist))
+	if err != nil {
+		return nil, err
+	}
+	task.Checklist = items
+	task.UpdatedAt = time.Now()
+	updatedTask, err := uc.taskRepo.Update(ctx, projectID, id, *task)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, domain.AuditTaskUpdated, requester.UserID, id, &before, updatedTask)
+	return updatedTask, nil
+}
+
+// AttachLabel adds a defined label to the task. Attaching a label the task
+// already carries is a no-op.
+func (uc *TaskUseCase) AttachLabel(ctx context.Context, projectID string, id string, label string, requester domain.Claims) (*domain.Task, error) {
+	label = domain.NormalizeLabelName(label)
+	if id == "" || !domain.IsValidLabelName(label) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.labelRepo.GetByName(ctx, label); err != nil {
+		return nil, err
+	}
+	if slices.Contains(task.Labels, label) {
+		return task, nil
+	}
+	if len(task.Labels) >= domain.MaxTaskLabels {
+		return nil, domain.ErrInvalidInput
+	}
+
+	before := *task
+	task.Labels = append(slices.Clone(task.Labels), label)
+	return uc.updateLabels(ctx, projectID, id, &before, task, requester.UserID)
+}
+
+// DetachLabel removes a label from the task. Detaching a label the task does
+// not carry is a no-op.
+func (uc *TaskUseCase) DetachLabel(ctx context.Context, projectID string, id string, label string, requester domain.Claims) (*domain.Task, error) {
+	label = domain.NormalizeLabelName(label)
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	task, _, err := visibleTask(ctx, uc.taskRepo, uc.projectRepo, projectID, id, requester)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.Index(task.Labels, label)
+	if i < 0 {
+		return task, nil
+	}
+
+	before := *task
+	task.Labels = slices.Delete(slices.Clone(task.Labels), i, i+1)
+	return uc.updateLabels(ctx, projectID, id, &before, task, requester.UserID)
+}
+
+func (uc *TaskUseCase) updateLabels(ctx context.Context, projectID string, id string, before, task *domain.Task, actorID string) (*domain.Task, error) {
+	task.UpdatedAt = time.Now()
+	updatedTask, err := uc.taskRepo.Update(ctx, projectID, id, *task)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, domain.AuditTaskUpdated, actorID, id, before, updatedTask)
+	return updatedTask, nil
+}
+
+// checkCompletion refuses to mark a task done while any of its subtasks is open.
+func (uc *TaskUseCase) checkCompletion(ctx context.Context, projectID string, id string, status domain.TaskStatus) error {
+	if status != domain.StatusDone {
+		return nil
+	}
+	children, err := uc.taskRepo.GetChildren(ctx, projectID, id)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if child.Status != domain.StatusDone {
+			return domain.ErrOpenSubtasks
+		}
+	}
+	return nil
+}
+
+// checkMember fails with ErrNotFound unless the user is a member of the
+// project.
+func (uc *TaskUseCase) checkMember(ctx context.Context, projectID string, userID string) error {
+	project, err := uc.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if _, ok := project.RoleOf(userID); !ok {
 		return domain.ErrNotFound
 	}
+	return nil
+}
+
+// taskReader returns the user ID whose tasks the requester is restricted to
+// in the project, or "" when they read every task.
+func taskReader(ctx context.Context, projectRepo domain.IProjectRepository, projectID string, requester domain.Claims) (string, error) {
+	project, err := projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	if project.CanReadAllTasks(requester) {
+		return "", nil
+	}
+	return requester.UserID, nil
+}
+
+// visibleTask loads a task of the project that the requester may read,
+// along with the project. A task they may not read is reported as missing,
+// like a task of another project.
+func visibleTask(ctx context.Context, taskRepo domain.ITaskRepository, projectRepo domain.IProjectRepository, projectID string, id string, requester domain.Claims) (*domain.Task, *domain.Project, error) {
+	project, err := projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	task, err := taskRepo.GetByID(ctx, projectID, id)
+	if err != nil || !task.IsVisibleTo(requester, project) {
+		return nil, nil, domain.ErrNotFound
+	}
+	return task, project, nil
+}
+
+// startSeries makes task the first occurrence of a series following rule.
+func startSeries(task *domain.Task, rule string) error {
+	rule, err := domain.NormalizeRecurrenceRule(rule)
+	if err != nil {
+		return err
+	}
+	if task.DueDate.IsZero() {
+		return domain.ErrInvalidInput
+	}
+	seriesID, err := newItemID()
+	if err != nil {
+		return err
+	}
+	task.Recurrence = &domain.Recurrence{
+		Rule:        rule,
+		SeriesID:    seriesID,
+		Index:       1,
+		ScheduledAt: task.DueDate,
+		Title:       task.Title,
+		Description: task.Description,
+	}
+	return nil
+}
+
+// continueSeries is called before storing a task. When the task is a
+// recurring occurrence being completed for the first time, it marks the
+// task as continued and returns the next occurrence to create, if any.
+func continueSeries(task *domain.Task) *domain.Task {
+	if task.Status != domain.StatusDone || task.Recurrence == nil || task.Recurrence.Continued {
+		return nil
+	}
+	recurrence := *task.Recurrence
+	recurrence.Continued = true
+	task.Recurrence = &recurrence
+
+	next, ok := task.NextOccurrence(time.Now())
+	if !ok {
+		return nil
+	}
+	return next
+}
+
+// createOccurrence stores the next occurrence of a series. The previous one
+// is already completed at that point, so a failure is only logged.
+func (uc *TaskUseCase) createOccurrence(ctx context.Context, next *domain.Task, actorID string) {
+	if next == nil {
+		return
+	}
+	now := time.Now()
+	next.Status = domain.StatusPending
+	next.CreatedAt = now
+	next.UpdatedAt = now
+
+	created, err := uc.taskRepo.Create(ctx, *next)
+	if err != nil {
+		log.Printf("Failed to create occurrence %d of series %s: %v", next.Recurrence.Index, next.Recurrence.SeriesID, err)
+		return
+	}
+	uc.record(ctx, domain.AuditTaskCreated, actorID, created.ID, nil, created)
+}
+
+// newItemID returns a random ID for a checklist item or attachment.
+func newItemID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
 
-	return uc.taskRepo.Delete(id)
+// record audits a stored task mutation and publishes the events describing
+// it.
+func (uc *TaskUseCase) record(ctx context.Context, action, actorID, taskID string, before, after *domain.Task) {
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityTask,
+		EntityID:   taskID,
+		Changes:    domain.DiffTasks(before, after),
+	})
+	publishEvents(ctx, uc.events, actorID, domain.TaskEvents(before, after)...)
 }
@@ -1,7 +1,9 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"slices"
 	domain "task-manager/Domain"
 	"task-manager/Repositories/mocks"
 	"testing"
@@ -14,19 +16,50 @@ import (
 
 type TaskUseCaseTestSuite struct {
 	suite.Suite
-	mockRepo  *mocks.MockTaskRepository
-	useCase   domain.ITaskUseCase
-	dummyTask domain.Task
+	mockRepo      *mocks.MockTaskRepository
+	mockSearcher  *mocks.MockTaskSearcher
+	mockProjects  *mocks.MockProjectRepository
+	mockLabelRepo *mocks.MockLabelRepository
+	mockComments  *mocks.MockCommentRepository
+	mockBlobs     *mocks.MockBlobStore
+	mockAudit     *mocks.MockAuditRepository
+	mockEvents    *mocks.MockEventPublisher
+	useCase       domain.ITaskUseCase
+	dummyTask     domain.Task
+	admin         domain.Claims
+	owner         domain.Claims
+	project       domain.Project
 }
 
+const testProjectID = "project-1"
+
 func (suite *TaskUseCaseTestSuite) SetupTest() {
 	suite.mockRepo = new(mocks.MockTaskRepository)
-	suite.useCase = NewTaskUseCase(suite.mockRepo)
+	suite.mockSearcher = new(mocks.MockTaskSearcher)
+	suite.mockProjects = new(mocks.MockProjectRepository)
+	suite.mockLabelRepo = new(mocks.MockLabelRepository)
+	suite.mockComments = new(mocks.MockCommentRepository)
+	suite.mockBlobs = new(mocks.MockBlobStore)
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockAudit.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockEvents = new(mocks.MockEventPublisher)
+	suite.mockEvents.On("Publish", mock.Anything, mock.Anything).Maybe()
+	suite.useCase = NewTaskUseCase(suite.mockRepo, suite.mockSearcher, suite.mockProjects, suite.mockLabelRepo, suite.mockComments, suite.mockBlobs, suite.mockAudit, suite.mockEvents, domain.DefaultStatusTransitions())
+	suite.admin = domain.Claims{UserID: "admin-1", Username: "admin", Role: domain.RoleAdmin, Permissions: domain.Permissions}
+	suite.owner = domain.Claims{UserID: "user-1", Username: "owner", Role: domain.RoleUser}
+	suite.project = domain.Project{ID: testProjectID, Name: "Launch", Members: []domain.ProjectMember{
+		{UserID: "user-1", Role: domain.ProjectOwner},
+		{UserID: "user-2", Role: domain.ProjectEditor},
+	}}
+	suite.mockProjects.On("GetByID", mock.Anything, testProjectID).Return(&suite.project, nil).Maybe()
+	suite.mockProjects.On("GetByID", mock.Anything, "project-2").Return(&domain.Project{ID: "project-2", Name: "Other"}, nil).Maybe()
 	suite.dummyTask = domain.Task{
 		ID:          "1",
 		Title:       "Test Task",
 		Description: "A task for testing",
 		Status:      "pending",
+		CreatedBy:   "user-1",
+		ProjectID:   testProjectID,
 		DueDate:     time.Now().Add(24 * time.Hour),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -34,8 +67,8 @@ func (suite *TaskUseCaseTestSuite) SetupTest() {
 }
 
 func (suite *TaskUseCaseTestSuite) TestCreateTask_Success() {
-	suite.mockRepo.On("Create", mock.AnythingOfType("domain.Task")).Return(&suite.dummyTask, nil)
-	_, err := suite.useCase.CreateTask(suite.dummyTask)
+	suite.mockRepo.On("Create", mock.Anything, mock.AnythingOfType("domain.Task")).Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, suite.dummyTask, suite.admin)
 	assert.NoError(suite.T(), err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
@@ -43,7 +76,7 @@ func (suite *TaskUseCaseTestSuite) TestCreateTask_Success() {
 func (suite *TaskUseCaseTestSuite) TestCreateTask_ValidationError_EmptyTitle() {
 	invalidTask := suite.dummyTask
 	invalidTask.Title = ""
-	_, err := suite.useCase.CreateTask(invalidTask)
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, invalidTask, suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
@@ -51,21 +84,22 @@ func (suite *TaskUseCaseTestSuite) TestCreateTask_ValidationError_EmptyTitle() {
 func (suite *TaskUseCaseTestSuite) TestCreateTask_ValidationError_PastDueDate() {
 	invalidTask := suite.dummyTask
 	invalidTask.DueDate = time.Now().Add(-24 * time.Hour)
-	_, err := suite.useCase.CreateTask(invalidTask)
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, invalidTask, suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
 
 func (suite *TaskUseCaseTestSuite) TestCreateTask_RepositoryError() {
-	suite.mockRepo.On("Create", mock.AnythingOfType("domain.Task")).Return(nil, errors.New("database error"))
-	_, err := suite.useCase.CreateTask(suite.dummyTask)
+	suite.mockRepo.On("Create", mock.Anything, mock.AnythingOfType("domain.Task")).Return(nil, errors.New("database error"))
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, suite.dummyTask, suite.admin)
 	assert.Error(suite.T(), err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
 func (suite *TaskUseCaseTestSuite) TestGetTaskByID_Success() {
-	suite.mockRepo.On("GetByID", "1").Return(&suite.dummyTask, nil)
-	task, err := suite.useCase.GetTaskByID("1")
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	task, err := suite.useCase.GetTaskByID(context.Background(), testProjectID, "1", suite.admin)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), task)
 	assert.Equal(suite.T(), "1", task.ID)
@@ -73,47 +107,71 @@ func (suite *TaskUseCaseTestSuite) TestGetTaskByID_Success() {
 }
 
 func (suite *TaskUseCaseTestSuite) TestGetTaskByID_EmptyID() {
-	_, err := suite.useCase.GetTaskByID("")
+	_, err := suite.useCase.GetTaskByID(context.Background(), testProjectID, "", suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
 
 func (suite *TaskUseCaseTestSuite) TestGetTaskByID_NotFound() {
-	suite.mockRepo.On("GetByID", "2").Return(nil, errors.New("not found"))
-	_, err := suite.useCase.GetTaskByID("2")
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "2").Return(nil, errors.New("not found"))
+	_, err := suite.useCase.GetTaskByID(context.Background(), testProjectID, "2", suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrNotFound, err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
 func (suite *TaskUseCaseTestSuite) TestGetAllTasks_Success() {
-	tasks := []domain.Task{suite.dummyTask}
-	suite.mockRepo.On("GetAll").Return(tasks, nil)
-	retrievedTasks, err := suite.useCase.GetAllTasks()
+	page := &domain.TaskPage{Tasks: []domain.Task{suite.dummyTask}, Total: 1}
+	suite.mockRepo.On("Find", mock.Anything, mock.MatchedBy(func(q domain.TaskQuery) bool {
+		return q.ProjectID == testProjectID && q.Limit == domain.DefaultTaskPageSize && q.SortBy == domain.SortByCreatedAt
+	})).Return(page, nil)
+	retrieved, err := suite.useCase.GetAllTasks(context.Background(), testProjectID, domain.TaskQuery{}, suite.admin)
 	assert.NoError(suite.T(), err)
-	assert.Len(suite.T(), retrievedTasks, 1)
+	assert.Len(suite.T(), retrieved.Tasks, 1)
+	assert.Equal(suite.T(), int64(1), retrieved.Total)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
 func (suite *TaskUseCaseTestSuite) TestGetAllTasks_RepositoryError() {
-	suite.mockRepo.On("GetAll").Return(nil, errors.New("database error"))
-	_, err := suite.useCase.GetAllTasks()
+	suite.mockRepo.On("Find", mock.Anything, mock.AnythingOfType("domain.TaskQuery")).Return(nil, errors.New("database error"))
+	_, err := suite.useCase.GetAllTasks(context.Background(), testProjectID, domain.TaskQuery{}, suite.admin)
 	assert.Error(suite.T(), err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *TaskUseCaseTestSuite) TestGetAllTasks_InvalidSortField() {
+	_, err := suite.useCase.GetAllTasks(context.Background(), testProjectID, domain.TaskQuery{SortBy: "password"}, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Find", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetAllTasks_InvalidDueRange() {
+	query := domain.TaskQuery{DueAfter: time.Now(), DueBefore: time.Now().Add(-time.Hour)}
+	_, err := suite.useCase.GetAllTasks(context.Background(), testProjectID, query, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetAllTasks_LimitIsCapped() {
+	suite.mockRepo.On("Find", mock.Anything, mock.MatchedBy(func(q domain.TaskQuery) bool {
+		return q.Limit == domain.MaxTaskPageSize
+	})).Return(&domain.TaskPage{}, nil)
+	_, err := suite.useCase.GetAllTasks(context.Background(), testProjectID, domain.TaskQuery{Limit: 5000}, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
 func (suite *TaskUseCaseTestSuite) TestUpdateTask_Success() {
 	updatedTask := suite.dummyTask
-	updatedTask.Status = "completed"
-	suite.mockRepo.On("GetByID", "1").Return(&suite.dummyTask, nil)
-	suite.mockRepo.On("Update", "1", mock.AnythingOfType("domain.Task")).Return(&updatedTask, nil)
-	_, err := suite.useCase.UpdateTask("1", updatedTask)
+	updatedTask.Status = domain.StatusInProgress
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(&updatedTask, nil)
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", updatedTask, suite.admin)
 	assert.NoError(suite.T(), err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
 func (suite *TaskUseCaseTestSuite) TestUpdateTask_EmptyID() {
-	_, err := suite.useCase.UpdateTask("", suite.dummyTask)
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "", suite.dummyTask, suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
@@ -121,41 +179,850 @@ func (suite *TaskUseCaseTestSuite) TestUpdateTask_EmptyID() {
 func (suite *TaskUseCaseTestSuite) TestUpdateTask_ValidationError() {
 	invalidTask := suite.dummyTask
 	invalidTask.Title = ""
-	_, err := suite.useCase.UpdateTask("1", invalidTask)
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", invalidTask, suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
 
 func (suite *TaskUseCaseTestSuite) TestUpdateTask_NotFound() {
-	suite.mockRepo.On("GetByID", "2").Return(nil, errors.New("not found"))
-	_, err := suite.useCase.UpdateTask("2", suite.dummyTask)
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "2").Return(nil, errors.New("not found"))
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "2", suite.dummyTask, suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrNotFound, err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
 func (suite *TaskUseCaseTestSuite) TestDeleteTask_Success() {
-	suite.mockRepo.On("GetByID", "1").Return(&suite.dummyTask, nil)
-	suite.mockRepo.On("Delete", "1").Return(nil)
-	err := suite.useCase.DeleteTask("1")
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	suite.mockRepo.On("Delete", mock.Anything, testProjectID, "1").Return(nil)
+	suite.mockComments.On("DeleteByTask", mock.Anything, "1").Return(nil)
+	err := suite.useCase.DeleteTask(context.Background(), testProjectID, "1", suite.admin)
 	assert.NoError(suite.T(), err)
 	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockComments.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestDeleteTask_PublishesDeletedTask() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	suite.mockRepo.On("Delete", mock.Anything, testProjectID, "1").Return(nil)
+	suite.mockComments.On("DeleteByTask", mock.Anything, "1").Return(nil)
+
+	err := suite.useCase.DeleteTask(context.Background(), testProjectID, "1", suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockEvents.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventTaskDeleted && e.Task.Title == "Test Task"
+	}))
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_FailureIsNotPublished() {
+	suite.mockRepo.On("Create", mock.Anything, mock.AnythingOfType("domain.Task")).Return(nil, errors.New("database error"))
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, suite.dummyTask, suite.admin)
+	assert.Error(suite.T(), err)
+	suite.mockEvents.AssertNotCalled(suite.T(), "Publish", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestDeleteTask_RemovesAttachments() {
+	task := suite.dummyTask
+	task.Attachments = []domain.Attachment{{ID: "a1"}, {ID: "a2"}}
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	suite.mockRepo.On("Delete", mock.Anything, testProjectID, "1").Return(nil)
+	suite.mockComments.On("DeleteByTask", mock.Anything, "1").Return(nil)
+	suite.mockBlobs.On("Delete", mock.Anything, "1/a1").Return(nil)
+	suite.mockBlobs.On("Delete", mock.Anything, "1/a2").Return(nil)
+
+	err := suite.useCase.DeleteTask(context.Background(), testProjectID, "1", suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockBlobs.AssertExpectations(suite.T())
 }
 
 func (suite *TaskUseCaseTestSuite) TestDeleteTask_EmptyID() {
-	err := suite.useCase.DeleteTask("")
+	err := suite.useCase.DeleteTask(context.Background(), testProjectID, "", suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
 
 func (suite *TaskUseCaseTestSuite) TestDeleteTask_NotFound() {
-	suite.mockRepo.On("GetByID", "2").Return(nil, errors.New("not found"))
-	err := suite.useCase.DeleteTask("2")
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "2").Return(nil, errors.New("not found"))
+	err := suite.useCase.DeleteTask(context.Background(), testProjectID, "2", suite.admin)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrNotFound, err)
 	suite.mockRepo.AssertExpectations(suite.T())
 }
 
+func (suite *TaskUseCaseTestSuite) TestCreateTask_RecordsCreator() {
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t domain.Task) bool {
+		return t.CreatedBy == "admin-1"
+	})).Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, suite.dummyTask, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_UnknownAssignee() {
+	task := suite.dummyTask
+	task.AssigneeID = "ghost"
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, task, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_SetsProject() {
+	task := suite.dummyTask
+	task.ProjectID = "other-project"
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t domain.Task) bool {
+		return t.ProjectID == testProjectID
+	})).Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, task, suite.owner)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetTaskByID_OtherProject() {
+	suite.mockRepo.On("GetByID", mock.Anything, "project-2", "1").Return(nil, domain.ErrNotFound)
+	_, err := suite.useCase.GetTaskByID(context.Background(), "project-2", "1", suite.admin)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetAllTasks_CannotLeaveProject() {
+	suite.mockRepo.On("Find", mock.Anything, mock.MatchedBy(func(q domain.TaskQuery) bool {
+		return q.ProjectID == testProjectID
+	})).Return(&domain.TaskPage{}, nil)
+	_, err := suite.useCase.GetAllTasks(context.Background(), testProjectID, domain.TaskQuery{ProjectID: "project-2"}, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetAllTasks_Visibility() {
+	editor := domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	reader := domain.Claims{UserID: "user-9", Role: "auditor", Permissions: []domain.Permission{domain.PermTasksReadAll}}
+	cases := map[string]struct {
+		requester domain.Claims
+		visibleTo string
+	}{
+		"editor":         {editor, "user-2"},
+		"owner":          {suite.owner, ""},
+		"admin":          {suite.admin, ""},
+		"tasks:read_all": {reader, ""},
+	}
+	for name, c := range cases {
+		suite.Run(name, func() {
+			suite.mockRepo.On("Find", mock.Anything, mock.MatchedBy(func(q domain.TaskQuery) bool {
+				return q.VisibleTo == c.visibleTo
+			})).Return(&domain.TaskPage{}, nil).Once()
+			suite.mockSearcher.On("Search", mock.Anything, mock.MatchedBy(func(q domain.TaskSearchQuery) bool {
+				return q.VisibleTo == c.visibleTo
+			})).Return(&domain.TaskSearchPage{}, nil).Once()
+
+			_, err := suite.useCase.GetAllTasks(context.Background(), testProjectID, domain.TaskQuery{VisibleTo: "user-1"}, c.requester)
+			assert.NoError(suite.T(), err)
+			_, err = suite.useCase.SearchTasks(context.Background(), testProjectID, domain.TaskSearchQuery{Text: "report"}, c.requester)
+			assert.NoError(suite.T(), err)
+		})
+	}
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockSearcher.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestHiddenTask_NotFound() {
+	// The task is created by user-1 and not assigned, so the editor user-2
+	// cannot see it
+	editor := domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+
+	_, err := suite.useCase.GetTaskByID(context.Background(), testProjectID, "1", editor)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	_, err = suite.useCase.GetSubtasks(context.Background(), testProjectID, "1", editor)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	title := "Renamed"
+	_, err = suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{Title: &title}, 0, editor)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	_, err = suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusInProgress, editor)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	_, err = suite.useCase.AddChecklistItem(context.Background(), testProjectID, "1", "Review", editor)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	err = suite.useCase.DeleteTask(context.Background(), testProjectID, "1", editor)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	child := suite.dummyTask
+	child.ParentID = "1"
+	_, err = suite.useCase.CreateTask(context.Background(), testProjectID, child, editor)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetChildren", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestAssignedTask_Visible() {
+	editor := domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	task := suite.dummyTask
+	task.AssigneeID = "user-2"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{{ID: "2", CreatedBy: "user-1"}}, nil)
+
+	got, err := suite.useCase.GetTaskByID(context.Background(), testProjectID, "1", editor)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", got.ID)
+}
+
+func (suite *TaskUseCaseTestSuite) TestSearchTasks_HighlightsMatches() {
+	task := suite.dummyTask
+	task.Title = "Fix login <form>"
+	task.Description = "The login form rejects valid passwords"
+	suite.mockSearcher.On("Search", mock.Anything, mock.MatchedBy(func(q domain.TaskSearchQuery) bool {
+		return q.ProjectID == testProjectID && slices.Equal(q.Terms, []string{"login"}) && q.Limit == domain.DefaultTaskSearchPageSize
+	})).Return(&domain.TaskSearchPage{Hits: []domain.TaskSearchHit{{Task: task, Score: 2}}}, nil)
+
+	page, err := suite.useCase.SearchTasks(context.Background(), testProjectID, domain.TaskSearchQuery{Text: "Login", ProjectID: "project-2"}, suite.admin)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), page.Hits, 1)
+	assert.Equal(suite.T(), "Fix <mark>login</mark> &lt;form&gt;", page.Hits[0].TitleHighlight)
+	assert.Equal(suite.T(), "The <mark>login</mark> form rejects valid passwords", page.Hits[0].Snippet)
+	suite.mockSearcher.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestSearchTasks_Cursor() {
+	suite.mockSearcher.On("Search", mock.Anything, mock.MatchedBy(func(q domain.TaskSearchQuery) bool {
+		return q.ProjectID == testProjectID && q.Offset == 40
+	})).Return(&domain.TaskSearchPage{}, nil)
+	_, err := suite.useCase.SearchTasks(context.Background(), testProjectID, domain.TaskSearchQuery{Text: "report", Cursor: "40"}, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockSearcher.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestSearchTasks_InvalidQuery() {
+	for _, query := range []domain.TaskSearchQuery{{Text: "  "}, {Text: "a*"}, {Text: "report", Cursor: "next"}} {
+		_, err := suite.useCase.SearchTasks(context.Background(), testProjectID, query, suite.admin)
+		assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput, query.Text)
+	}
+	suite.mockSearcher.AssertNotCalled(suite.T(), "Search", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestAssignTask_Success() {
+	assigned := suite.dummyTask
+	assigned.AssigneeID = "user-2"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Assign", mock.Anything, testProjectID, "1", "user-2").Return(&assigned, nil)
+	task, err := suite.useCase.AssignTask(context.Background(), testProjectID, "1", "user-2", suite.admin)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "user-2", task.AssigneeID)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestAssignTask_AssigneeNotMember() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.AssignTask(context.Background(), testProjectID, "1", "user-3", suite.admin)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Assign", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUnassignTask_Success() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Assign", mock.Anything, testProjectID, "1", "").Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.AssignTask(context.Background(), testProjectID, "1", "", suite.admin)
+	assert.NoError(suite.T(), err)
+	// The project is only loaded to check visibility, not membership
+	suite.mockProjects.AssertNumberOfCalls(suite.T(), "GetByID", 1)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_InvalidStatus() {
+	task := suite.dummyTask
+	task.Status = "Done"
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, task, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidStatus, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateTask_IllegalTransition() {
+	task := suite.dummyTask
+	task.Status = domain.StatusDone
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", task, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidTransition, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateTask_KeepsStatusWhenOmitted() {
+	task := suite.dummyTask
+	task.Status = ""
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Status == domain.StatusPending
+	})).Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", task, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_Success() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Status == domain.StatusInProgress && t.Title == suite.dummyTask.Title
+	})).Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusInProgress, suite.owner)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_PublishesStatusChange() {
+	current := suite.dummyTask
+	updated := suite.dummyTask
+	updated.Status = domain.StatusInProgress
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&current, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(&updated, nil)
+
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusInProgress, suite.owner)
+	assert.NoError(suite.T(), err)
+	suite.mockEvents.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventTaskUpdated && e.Task.ID == "1" && e.ActorID == "user-1" && e.ID != ""
+	}))
+	suite.mockEvents.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventTaskStatusChanged && e.PreviousStatus == domain.StatusPending &&
+			e.Task.Status == domain.StatusInProgress
+	}))
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_Reopen() {
+	done := suite.dummyTask
+	done.Status = domain.StatusDone
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&done, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(&done, nil)
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusInProgress, suite.admin)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_IllegalTransition() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusDone, suite.owner)
+	assert.Equal(suite.T(), domain.ErrInvalidTransition, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_InvalidStatus() {
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", "complete", suite.owner)
+	assert.Equal(suite.T(), domain.ErrInvalidStatus, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateTask_StaleVersion() {
+	current := suite.dummyTask
+	current.Version = 3
+	task := suite.dummyTask
+	task.Version = 2
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&current, nil)
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", task, suite.admin)
+	assert.Equal(suite.T(), domain.ErrVersionConflict, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestPatchTask_OnlyChangesGivenFields() {
+	current := suite.dummyTask
+	current.Version = 2
+	title := "Renamed"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&current, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Title == "Renamed" && t.Description == current.Description &&
+			t.DueDate.Equal(current.DueDate) && t.Version == 2
+	})).Return(&current, nil)
+	_, err := suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{Title: &title}, 2, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestPatchTask_OverdueTaskCanBeRenamed() {
+	overdue := suite.dummyTask
+	overdue.DueDate = time.Now().Add(-time.Hour)
+	title := "Renamed"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&overdue, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(&overdue, nil)
+	_, err := suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{Title: &title}, 0, suite.admin)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TaskUseCaseTestSuite) TestPatchTask_VersionConflict() {
+	current := suite.dummyTask
+	current.Version = 5
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&current, nil)
+	_, err := suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{}, 4, suite.admin)
+	assert.Equal(suite.T(), domain.ErrVersionConflict, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestPatchTask_InvalidFields() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+
+	empty := ""
+	_, err := suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{Title: &empty}, 0, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+
+	past := time.Now().Add(-time.Hour)
+	_, err = suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{DueDate: &past}, 0, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+
+	done := domain.StatusDone
+	_, err = suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{Status: &done}, 0, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidTransition, err)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateTask_RecordsAuditDiff() {
+	updated := suite.dummyTask
+	updated.Title = "New title"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(&updated, nil)
+
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", updated, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditTaskUpdated && e.ActorID == "admin-1" && e.EntityID == "1" &&
+			len(e.Changes) == 1 && e.Changes[0] == domain.FieldChange{Field: "title", Before: "Test Task", After: "New title"}
+	}))
+}
+
+func (suite *TaskUseCaseTestSuite) TestDeleteTask_RecordsAudit() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	suite.mockRepo.On("Delete", mock.Anything, testProjectID, "1").Return(nil)
+	suite.mockComments.On("DeleteByTask", mock.Anything, "1").Return(errors.New("connection reset"))
+
+	// Failing to clean up comments does not undo the deletion
+	err := suite.useCase.DeleteTask(context.Background(), testProjectID, "1", suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditTaskDeleted && e.ActorID == "admin-1" && len(e.Changes) > 0 && e.Changes[0].After == ""
+	}))
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateTask_FailureIsNotAudited() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(nil, domain.ErrVersionConflict)
+
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", suite.dummyTask, suite.admin)
+	assert.Equal(suite.T(), domain.ErrVersionConflict, err)
+	suite.mockAudit.AssertNotCalled(suite.T(), "Record", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetTaskByID_ReportsProgress() {
+	task := suite.dummyTask
+	task.Checklist = []domain.ChecklistItem{{ID: "a", Text: "Draft", Done: true}, {ID: "b", Text: "Review"}}
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{
+		{ID: "2", Status: domain.StatusDone},
+		{ID: "3", Status: domain.StatusInProgress},
+	}, nil)
+
+	got, err := suite.useCase.GetTaskByID(context.Background(), testProjectID, "1", suite.admin)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &domain.TaskProgress{Completed: 2, Total: 4, Percent: 50}, got.Progress)
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_Subtask() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t domain.Task) bool {
+		return t.ParentID == "1"
+	})).Return(&suite.dummyTask, nil)
+
+	child := suite.dummyTask
+	child.ID = ""
+	child.ParentID = "1"
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, child, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_SubtaskOfDoneParent() {
+	done := suite.dummyTask
+	done.Status = domain.StatusDone
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&done, nil)
+
+	child := suite.dummyTask
+	child.ParentID = "1"
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, child, suite.admin)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_BlockedByOpenSubtasks() {
+	review := suite.dummyTask
+	review.Status = domain.StatusReview
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&review, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{
+		{ID: "2", Status: domain.StatusDone},
+		{ID: "3", Status: domain.StatusPending},
+	}, nil)
+
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusDone, suite.admin)
+	assert.Equal(suite.T(), domain.ErrOpenSubtasks, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestPatchTask_CompletesWhenSubtasksDone() {
+	review := suite.dummyTask
+	review.Status = domain.StatusReview
+	done := domain.StatusDone
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&review, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{{ID: "2", Status: domain.StatusDone}}, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Status == domain.StatusDone
+	})).Return(&review, nil)
+
+	_, err := suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{Status: &done}, 0, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestDeleteTask_WithSubtasks() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{{ID: "2"}}, nil)
+
+	err := suite.useCase.DeleteTask(context.Background(), testProjectID, "1", suite.admin)
+	assert.Equal(suite.T(), domain.ErrHasSubtasks, err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetSubtasks() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{
+		{ID: "2", CreatedBy: "user-1"},
+		{ID: "3", CreatedBy: "admin-1"},
+	}, nil)
+
+	children, err := suite.useCase.GetSubtasks(context.Background(), testProjectID, "1", suite.admin)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), children, 2)
+	assert.Equal(suite.T(), "2", children[0].ID)
+}
+
+func (suite *TaskUseCaseTestSuite) TestGetSubtasks_OnlyVisible() {
+	editor := domain.Claims{UserID: "user-2", Role: domain.RoleUser}
+	parent := suite.dummyTask
+	parent.AssigneeID = "user-2"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&parent, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{
+		{ID: "2", CreatedBy: "user-1"},
+		{ID: "3", CreatedBy: "user-2"},
+		{ID: "4", CreatedBy: "user-1", AssigneeID: "user-2"},
+	}, nil)
+
+	children, err := suite.useCase.GetSubtasks(context.Background(), testProjectID, "1", editor)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), children, 2)
+	assert.Equal(suite.T(), "3", children[0].ID)
+	assert.Equal(suite.T(), "4", children[1].ID)
+}
+
+func (suite *TaskUseCaseTestSuite) TestAddChecklistItem() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return len(t.Checklist) == 1 && t.Checklist[0].Text == "Write docs" && t.Checklist[0].ID != "" && !t.Checklist[0].Done
+	})).Return(&suite.dummyTask, nil)
+
+	_, err := suite.useCase.AddChecklistItem(context.Background(), testProjectID, "1", "  Write docs ", suite.owner)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditTaskUpdated && e.ActorID == "user-1" && e.Changes[0].Field == "checklist"
+	}))
+}
+
+func (suite *TaskUseCaseTestSuite) TestAddChecklistItem_EmptyText() {
+	_, err := suite.useCase.AddChecklistItem(context.Background(), testProjectID, "1", "  ", suite.owner)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateChecklistItem_Tick() {
+	task := suite.dummyTask
+	items := []domain.ChecklistItem{{ID: "a", Text: "Draft"}, {ID: "b", Text: "Review"}}
+	task.Checklist = items
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Checklist[1].Done && !t.Checklist[0].Done
+	})).Return(&task, nil)
+
+	done := true
+	_, err := suite.useCase.UpdateChecklistItem(context.Background(), testProjectID, "1", "b", domain.ChecklistItemPatch{Done: &done}, suite.owner)
+	assert.NoError(suite.T(), err)
+	// The loaded checklist must not be modified in place, the audit diff relies on it
+	assert.False(suite.T(), items[1].Done)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateChecklistItem_UnknownItem() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	done := true
+	_, err := suite.useCase.UpdateChecklistItem(context.Background(), testProjectID, "1", "x", domain.ChecklistItemPatch{Done: &done}, suite.owner)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+}
+
+func (suite *TaskUseCaseTestSuite) TestReorderChecklist() {
+	task := suite.dummyTask
+	task.Checklist = []domain.ChecklistItem{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Checklist[0].ID == "c" && t.Checklist[1].ID == "a" && t.Checklist[2].ID == "b"
+	})).Return(&task, nil)
+
+	_, err := suite.useCase.ReorderChecklist(context.Background(), testProjectID, "1", []string{"c", "a", "b"}, suite.owner)
+	assert.NoError(suite.T(), err)
+
+	for _, ids := range [][]string{{"a", "b"}, {"a", "a", "b"}, {"a", "b", "x"}} {
+		_, err := suite.useCase.ReorderChecklist(context.Background(), testProjectID, "1", ids, suite.owner)
+		assert.Equal(suite.T(), domain.ErrInvalidInput, err, ids)
+	}
+}
+
+func (suite *TaskUseCaseTestSuite) TestAttachLabel() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockLabelRepo.On("GetByName", mock.Anything, "customer-x").Return(&domain.Label{Name: "customer-x", Color: "#ff0000"}, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return len(t.Labels) == 1 && t.Labels[0] == "customer-x"
+	})).Return(&suite.dummyTask, nil)
+
+	_, err := suite.useCase.AttachLabel(context.Background(), testProjectID, "1", " Customer-X ", suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditTaskUpdated && e.Changes[0] == domain.FieldChange{Field: "labels", After: "customer-x"}
+	}))
+}
+
+func (suite *TaskUseCaseTestSuite) TestAttachLabel_AlreadyAttached() {
+	task := suite.dummyTask
+	task.Labels = []string{"bug"}
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockLabelRepo.On("GetByName", mock.Anything, "bug").Return(&domain.Label{Name: "bug"}, nil)
+
+	got, err := suite.useCase.AttachLabel(context.Background(), testProjectID, "1", "bug", suite.admin)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"bug"}, got.Labels)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestAttachLabel_UnknownLabel() {
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&suite.dummyTask, nil)
+	suite.mockLabelRepo.On("GetByName", mock.Anything, "ops").Return(nil, domain.ErrNotFound)
+
+	_, err := suite.useCase.AttachLabel(context.Background(), testProjectID, "1", "ops", suite.admin)
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+}
+
+func (suite *TaskUseCaseTestSuite) TestDetachLabel() {
+	task := suite.dummyTask
+	task.Labels = []string{"bug", "ops"}
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return len(t.Labels) == 1 && t.Labels[0] == "ops"
+	})).Return(&task, nil)
+
+	_, err := suite.useCase.DetachLabel(context.Background(), testProjectID, "1", "bug", suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+// recurringTask returns an occurrence of a weekly series in review, one step
+// away from done.
+func (suite *TaskUseCaseTestSuite) recurringTask() domain.Task {
+	task := suite.dummyTask
+	task.Status = domain.StatusReview
+	task.Title = "Water plants (moved)"
+	task.AssigneeID = "user-2"
+	task.Labels = []string{"chores"}
+	task.Checklist = []domain.ChecklistItem{{ID: "a", Text: "Ferns", Done: true}}
+	task.Recurrence = &domain.Recurrence{
+		Rule:        "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3",
+		SeriesID:    "s1",
+		Index:       1,
+		ScheduledAt: time.Date(2099, 1, 5, 9, 0, 0, 0, time.UTC), // a Monday
+		Title:       "Water plants",
+	}
+	return task
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_StartsSeries() {
+	task := suite.dummyTask
+	task.Recurrence = &domain.Recurrence{Rule: "rrule:freq=weekly;byday=mo;interval=1"}
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t domain.Task) bool {
+		r := t.Recurrence
+		return r != nil && r.Rule == "FREQ=WEEKLY;BYDAY=MO" && r.SeriesID != "" && r.Index == 1 &&
+			r.ScheduledAt.Equal(task.DueDate) && r.Title == task.Title
+	})).Return(&task, nil)
+
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, task, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestCreateTask_InvalidRecurrence() {
+	task := suite.dummyTask
+	task.Recurrence = &domain.Recurrence{Rule: "FREQ=HOURLY"}
+	_, err := suite.useCase.CreateTask(context.Background(), testProjectID, task, suite.admin)
+	assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput)
+
+	task.Recurrence = &domain.Recurrence{Rule: "FREQ=DAILY"}
+	task.ParentID = "parent"
+	_, err = suite.useCase.CreateTask(context.Background(), testProjectID, task, suite.admin)
+	assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_CompletingOccurrenceCreatesNext() {
+	task := suite.recurringTask()
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Status == domain.StatusDone && t.Recurrence.Continued
+	})).Return(&task, nil)
+	suite.mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t domain.Task) bool {
+		thursday := time.Date(2099, 1, 8, 9, 0, 0, 0, time.UTC)
+		return t.Title == "Water plants" && t.Status == domain.StatusPending && t.DueDate.Equal(thursday) &&
+			t.AssigneeID == "user-2" && !t.Checklist[0].Done &&
+			t.Recurrence.Index == 2 && t.Recurrence.SeriesID == "s1" && !t.Recurrence.Continued
+	})).Return(&domain.Task{ID: "2"}, nil)
+
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusDone, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestTransitionTask_CompletingAgainDoesNotRepeat() {
+	task := suite.recurringTask()
+	task.Recurrence.Continued = true
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(&task, nil)
+
+	_, err := suite.useCase.TransitionTask(context.Background(), testProjectID, "1", domain.StatusDone, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestPatchTask_LastOccurrenceEndsSeries() {
+	task := suite.recurringTask()
+	task.Recurrence.Index = 3
+	done := domain.StatusDone
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("GetChildren", mock.Anything, testProjectID, "1").Return([]domain.Task{}, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.AnythingOfType("domain.Task")).Return(&task, nil)
+
+	_, err := suite.useCase.PatchTask(context.Background(), testProjectID, "1", domain.TaskPatch{Status: &done}, 0, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateTask_OnlyChangesOccurrence() {
+	existing := suite.recurringTask()
+	task := suite.dummyTask
+	task.Status = ""
+	task.Title = "Water plants twice"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&existing, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Title == "Water plants twice" && t.Recurrence.Title == "Water plants"
+	})).Return(&existing, nil)
+
+	_, err := suite.useCase.UpdateTask(context.Background(), testProjectID, "1", task, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateSeries() {
+	task := suite.recurringTask()
+	title, rule := "Water all plants", "FREQ=WEEKLY;INTERVAL=2"
+	due := time.Date(2099, 1, 6, 9, 0, 0, 0, time.UTC)
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&task, nil)
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		r := t.Recurrence
+		return t.Title == title && t.DueDate.Equal(due) &&
+			r.Title == title && r.Rule == rule && r.ScheduledAt.Equal(due) && r.SeriesID == "s1"
+	})).Return(&task, nil)
+
+	_, err := suite.useCase.UpdateSeries(context.Background(), testProjectID, "1", domain.SeriesPatch{Title: &title, DueDate: &due, Rule: &rule}, 0, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateSeries_StartAndEnd() {
+	plain := suite.dummyTask
+	rule := "FREQ=DAILY"
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&plain, nil).Once()
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Recurrence != nil && t.Recurrence.Index == 1 && t.Recurrence.Rule == rule
+	})).Return(&plain, nil).Once()
+	_, err := suite.useCase.UpdateSeries(context.Background(), testProjectID, "1", domain.SeriesPatch{Rule: &rule}, 0, suite.admin)
+	assert.NoError(suite.T(), err)
+
+	recurring := suite.recurringTask()
+	none := ""
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&recurring, nil).Once()
+	suite.mockRepo.On("Update", mock.Anything, testProjectID, "1", mock.MatchedBy(func(t domain.Task) bool {
+		return t.Recurrence == nil
+	})).Return(&recurring, nil).Once()
+	_, err = suite.useCase.UpdateSeries(context.Background(), testProjectID, "1", domain.SeriesPatch{Rule: &none}, 0, suite.admin)
+	assert.NoError(suite.T(), err)
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TaskUseCaseTestSuite) TestUpdateSeries_Rejected() {
+	title := "Renamed"
+	plain := suite.dummyTask
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "plain").Return(&plain, nil)
+	_, err := suite.useCase.UpdateSeries(context.Background(), testProjectID, "plain", domain.SeriesPatch{Title: &title}, 0, suite.admin)
+	assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput)
+
+	done := suite.recurringTask()
+	done.Status = domain.StatusDone
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "done").Return(&done, nil)
+	_, err = suite.useCase.UpdateSeries(context.Background(), testProjectID, "done", domain.SeriesPatch{Title: &title}, 0, suite.admin)
+	assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput)
+
+	bad := "FREQ=WEEKLY;BYDAY=XX"
+	recurring := suite.recurringTask()
+	suite.mockRepo.On("GetByID", mock.Anything, testProjectID, "1").Return(&recurring, nil)
+	_, err = suite.useCase.UpdateSeries(context.Background(), testProjectID, "1", domain.SeriesPatch{Rule: &bad}, 0, suite.admin)
+	assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestComputeProgress(t *testing.T) {
+	assert.Equal(t, domain.TaskProgress{}, domain.ComputeProgress(&domain.Task{Status: domain.StatusPending}, nil))
+	assert.Equal(t, domain.TaskProgress{Percent: 100}, domain.ComputeProgress(&domain.Task{Status: domain.StatusDone}, nil))
+
+	task := &domain.Task{Checklist: []domain.ChecklistItem{{Done: true}, {}}}
+	children := []domain.Task{{Status: domain.StatusReview}}
+	assert.Equal(t, domain.TaskProgress{Completed: 1, Total: 3, Percent: 33}, domain.ComputeProgress(task, children))
+}
+
+func TestDiffTasks(t *testing.T) {
+	due := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	before := &domain.Task{Title: "A", Status: domain.StatusPending, DueDate: due}
+	after := &domain.Task{Title: "A", Status: domain.StatusDone, DueDate: due, AssigneeID: "user-2"}
+
+	assert.Equal(t, []domain.FieldChange{
+		{Field: "status", Before: "pending", After: "done"},
+		{Field: "assignee_id", Before: "", After: "user-2"},
+	}, domain.DiffTasks(before, after))
+
+	created := domain.DiffTasks(nil, before)
+	assert.Contains(t, created, domain.FieldChange{Field: "due_date", After: "2030-01-02T03:04:05Z"})
+	assert.Empty(t, domain.DiffTasks(before, before))
+}
+
+func TestParseStatusTransitions(t *testing.T) {
+	g, err := domain.ParseStatusTransitions("pending:in_progress,done; in_progress:pending")
+	assert.NoError(t, err)
+	assert.True(t, g.Allows(domain.StatusPending, domain.StatusDone))
+	assert.True(t, g.Allows(domain.StatusInProgress, domain.StatusPending))
+	assert.False(t, g.Allows(domain.StatusDone, domain.StatusPending))
+
+	_, err = domain.ParseStatusTransitions("pending:finished")
+	assert.ErrorIs(t, err, domain.ErrInvalidStatus)
+
+	_, err = domain.ParseStatusTransitions("pending")
+	assert.Error(t, err)
+}
+
 func TestTaskUseCaseTestSuite(t *testing.T) {
 	suite.Run(t, new(TaskUseCaseTestSuite))
 }
@@ -0,0 +1,338 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	domain "task-manager/Domain"
+	"time"
+)
+
+func (uc *UserUseCase) CompleteMFALogin(ctx context.Context, challenge, code, clientIP string) (*domain.LoginResult, error) {
+	if challenge == "" || code == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	hash := uc.authService.HashToken(challenge)
+	user, err := uc.challengedUser(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Two-factor authentication was turned off, or the user deleted, since
+	// the challenge was issued
+	enrollment, err := uc.mfaRepo.Get(ctx, user.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Codes are throttled like passwords, against the same counts
+	if err := uc.throttle.Reserve(ctx, user.Username, clientIP); err != nil {
+		return nil, err
+	}
+	ok, err := uc.verifyCode(ctx, enrollment, code)
+	if err != nil {
+		// The code was never judged, so it must not count as wrong
+		uc.releaseLoginAttempt(ctx, user.Username, clientIP)
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+	uc.releaseLoginAttempt(ctx, user.Username, clientIP)
+
+	// Whoever deletes the challenge first completes the login
+	if err := uc.tokenRepo.DeleteMFAChallenge(ctx, hash); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	uc.clearLoginFailures(ctx, user.Username)
+
+	result := &domain.LoginResult{}
+	if !enrollment.Confirmed {
+		if result.RecoveryCodes, err = uc.confirmEnrollment(ctx, enrollment); err != nil {
+			return nil, err
+		}
+	}
+	if result.Tokens, err = uc.issueTokens(ctx, user); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (uc *UserUseCase) StartMFALoginEnrollment(ctx context.Context, challenge string) (*domain.MFASetup, error) {
+	if challenge == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	user, err := uc.challengedUser(ctx, uc.authService.HashToken(challenge))
+	if err != nil {
+		return nil, err
+	}
+	return uc.startEnrollment(ctx, user)
+}
+
+func (uc *UserUseCase) StartMFAEnrollment(ctx context.Context, userID string) (*domain.MFASetup, error) {
+	if userID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.startEnrollment(ctx, user)
+}
+
+func (uc *UserUseCase) ConfirmMFAEnrollment(ctx context.Context, userID string, code string) ([]string, error) {
+	if userID == "" || code == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	enrollment, err := uc.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.Confirmed {
+		return nil, domain.ErrMFAEnabled
+	}
+
+	ok, err := uc.verifyCode(ctx, enrollment, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+	return uc.confirmEnrollment(ctx, enrollment)
+}
+
+func (uc *UserUseCase) DisableMFA(ctx context.Context, userID string, password string) error {
+	if userID == "" || password == "" {
+		return domain.ErrInvalidInput
+	}
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !uc.passwordService.Check(password, user.Password) {
+		return domain.ErrInvalidCredentials
+	}
+	enrollment, err := uc.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := uc.roleRequiresMFA(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrMFARequired
+	}
+
+	if err := uc.mfaRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+	if enrollment.Confirmed {
+		uc.auditMFA(ctx, domain.AuditMFADisabled, userID, userID)
+	}
+	return nil
+}
+
+// ResetMFA lets the user log in with their password alone again, or enroll
+// anew if their role requires two-factor authentication.
+func (uc *UserUseCase) ResetMFA(ctx context.Context, id string, actorID string) error {
+	if id == "" || actorID == "" {
+		return domain.ErrInvalidInput
+	}
+	if _, err := uc.manageableUser(ctx, id, actorID); err != nil {
+		return err
+	}
+	enrollment, err := uc.mfaRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.mfaRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if enrollment.Confirmed {
+		uc.auditMFA(ctx, domain.AuditMFADisabled, actorID, id)
+	}
+	return nil
+}
+
+// loginMFA tells whether the user has to pass a second factor to log in, and
+// whether they have to enroll first because their role requires it.
+func (uc *UserUseCase) loginMFA(ctx context.Context, user *domain.User) (needsMFA bool, enroll bool, err error) {
+	enrollment, err := uc.mfaRepo.Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return false, false, err
+	}
+	if err == nil && enrollment.Confirmed {
+		return true, false, nil
+	}
+
+	required, err := uc.roleRequiresMFA(ctx, user.Role)
+	return required, required, err
+}
+
+// roleRequiresMFA reports whether holders of the role must use two-factor
+// authentication. Roles that no longer exist require nothing.
+func (uc *UserUseCase) roleRequiresMFA(ctx context.Context, role domain.Role) (bool, error) {
+	definition, err := uc.roleRepo.GetByName(ctx, role)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return definition.RequireMFA, nil
+}
+
+// startMFAChallenge hands out the pending token that CompleteMFALogin
+// exchanges for the tokens.
+func (uc *UserUseCase) startMFAChallenge(ctx context.Context, user *domain.User, enroll bool) (*domain.LoginResult, error) {
+	token, err := uc.authService.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	challenge := domain.MFAChallenge{
+		Hash:      uc.authService.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(uc.challengeTTL),
+		CreatedAt: now,
+	}
+	if err := uc.tokenRepo.CreateMFAChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{MFAPending: token, MFAExpiresAt: challenge.ExpiresAt, MFAEnrollmentRequired: enroll}, nil
+}
+
+// challengedUser returns the user a pending login belongs to. Unknown and
+// expired challenges, and those of users disabled since, are unauthorized.
+func (uc *UserUseCase) challengedUser(ctx context.Context, hash string) (*domain.User, error) {
+	challenge, err := uc.tokenRepo.GetMFAChallenge(ctx, hash)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil || user.Disabled {
+		return nil, domain.ErrUnauthorized
+	}
+	return user, nil
+}
+
+// startEnrollment stores a new secret for the user, replacing one that was
+// never confirmed.
+func (uc *UserUseCase) startEnrollment(ctx context.Context, user *domain.User) (*domain.MFASetup, error) {
+	existing, err := uc.mfaRepo.Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil && existing.Confirmed {
+		return nil, domain.ErrMFAEnabled
+	}
+
+	secret, err := uc.totpService.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = uc.mfaRepo.Save(ctx, domain.MFAEnrollment{UserID: user.ID, Secret: secret, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.MFASetup{Secret: secret, URI: uc.totpService.URI(secret, user.Username)}, nil
+}
+
+// confirmEnrollment turns the enrolment on and returns its recovery codes,
+// of which only the hashes are kept.
+func (uc *UserUseCase) confirmEnrollment(ctx context.Context, enrollment *domain.MFAEnrollment) ([]string, error) {
+	codes, err := uc.totpService.GenerateRecoveryCodes(domain.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = uc.authService.HashToken(domain.NormalizeRecoveryCode(code))
+	}
+
+	confirmed := *enrollment
+	confirmed.Confirmed = true
+	confirmed.RecoveryCodes = hashes
+	if err := uc.mfaRepo.Save(ctx, confirmed); err != nil {
+		return nil, err
+	}
+	uc.auditMFA(ctx, domain.AuditMFAEnabled, enrollment.UserID, enrollment.UserID)
+	return codes, nil
+}
+
+// verifyCode checks a TOTP or recovery code and uses it up. A TOTP code is
+// not accepted twice, even within the steps it is valid for.
+func (uc *UserUseCase) verifyCode(ctx context.Context, enrollment *domain.MFAEnrollment, code string) (bool, error) {
+	code = domain.NormalizeRecoveryCode(code)
+	if domain.IsTOTPCode(code) {
+		step, ok := uc.totpService.Validate(enrollment.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		err := uc.mfaRepo.UseStep(ctx, enrollment.UserID, step)
+		if errors.Is(err, domain.ErrDuplicateEntry) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		enrollment.LastStep = step
+		return true, nil
+	}
+
+	err := uc.mfaRepo.UseRecoveryCode(ctx, enrollment.UserID, uc.authService.HashToken(code))
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	log.Printf("User %s used a recovery code, %d left", enrollment.UserID, len(enrollment.RecoveryCodes)-1)
+	return true, nil
+}
+
+// releaseLoginAttempt takes back the failure counted for an attempt that
+// passed. Failing to do so only leaves the user throttled a little longer.
+func (uc *UserUseCase) releaseLoginAttempt(ctx context.Context, username, clientIP string) {
+	if err := uc.throttle.Release(ctx, username, clientIP); err != nil {
+		log.Printf("Failed to release the login attempt of %q: %v", username, err)
+	}
+}
+
+// clearLoginFailures forgets the failed logins of a user who got in. Failing
+// to do so only leaves them throttled a little longer.
+func (uc *UserUseCase) clearLoginFailures(ctx context.Context, username string) {
+	if err := uc.throttle.RecordSuccess(ctx, username); err != nil {
+		log.Printf("Failed to clear login failures of %q: %v", username, err)
+	}
+}
+
+// auditMFA records two-factor authentication being turned on or off. The
+// secret and recovery codes are never recorded.
+func (uc *UserUseCase) auditMFA(ctx context.Context, action, actorID, userID string) {
+	enabled := action == domain.AuditMFAEnabled
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   userID,
+		Changes: []domain.FieldChange{
+			{Field: "mfa_enabled", Before: strconv.FormatBool(!enabled), After: strconv.FormatBool(enabled)},
+		},
+	})
+}
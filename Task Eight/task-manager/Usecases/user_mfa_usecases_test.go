@@ -0,0 +1,259 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	domain "task-manager/Domain"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mfaUser is enrolled in two-factor authentication, unlike dummyUser.
+var mfaUser = domain.User{ID: "mfa-1", Username: "mfauser", Password: "hashedpassword", Role: domain.RoleUser}
+
+// expectChallenge makes "challenge" a pending login of mfaUser.
+func (suite *UserUseCaseTestSuite) expectChallenge() {
+	suite.mockAuthSvc.On("HashToken", "challenge").Return("challenge-hash")
+	suite.mockTokenRepo.On("GetMFAChallenge", mock.Anything, "challenge-hash").Return(&domain.MFAChallenge{Hash: "challenge-hash", UserID: "mfa-1", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "mfa-1").Return(&mfaUser, nil)
+}
+
+// expectTokens lets mfaUser be issued a token pair.
+func (suite *UserUseCaseTestSuite) expectTokens() {
+	suite.mockAuthSvc.On("GenerateToken", &mfaUser).Return("jwt-token", nil)
+	suite.mockAuthSvc.On("GenerateOpaqueToken").Return("refresh-token", nil)
+	suite.mockAuthSvc.On("HashToken", "refresh-token").Return("refresh-hash")
+	suite.mockTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+}
+
+func (suite *UserUseCaseTestSuite) TestLogin_MFAChallenge() {
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "mfauser").Return(&mfaUser, nil)
+	suite.mockPasswordSvc.On("Check", "password123", "hashedpassword").Return(true)
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Confirmed: true}, nil)
+	suite.mockAuthSvc.On("GenerateOpaqueToken").Return("challenge", nil)
+	suite.mockAuthSvc.On("HashToken", "challenge").Return("challenge-hash")
+	suite.mockTokenRepo.On("CreateMFAChallenge", mock.Anything, mock.MatchedBy(func(c domain.MFAChallenge) bool {
+		return c.Hash == "challenge-hash" && c.UserID == "mfa-1" && c.ExpiresAt.Sub(c.CreatedAt) == 5*time.Minute
+	})).Return(nil)
+
+	result, err := suite.useCase.Login(context.Background(), "mfauser", "password123", testClientIP)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), result.Tokens)
+	assert.Equal(suite.T(), "challenge", result.MFAPending)
+	assert.False(suite.T(), result.MFAEnrollmentRequired)
+	// Failures are kept until the code is passed too
+	suite.mockThrottle.AssertCalled(suite.T(), "Release", mock.Anything, "mfauser", testClientIP)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "RecordSuccess", mock.Anything, mock.Anything)
+	suite.mockAuthSvc.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything)
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestLogin_MFAEnrollmentRequired() {
+	secured := mfaUser
+	secured.Role = "secured"
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "mfauser").Return(&secured, nil)
+	suite.mockPasswordSvc.On("Check", "password123", "hashedpassword").Return(true)
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(nil, domain.ErrNotFound)
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.Role("secured")).Return(&domain.RoleDefinition{Name: "secured", RequireMFA: true}, nil)
+	suite.mockAuthSvc.On("GenerateOpaqueToken").Return("challenge", nil)
+	suite.mockAuthSvc.On("HashToken", "challenge").Return("challenge-hash")
+	suite.mockTokenRepo.On("CreateMFAChallenge", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := suite.useCase.Login(context.Background(), "mfauser", "password123", testClientIP)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), result.Tokens)
+	assert.True(suite.T(), result.MFAEnrollmentRequired)
+}
+
+func (suite *UserUseCaseTestSuite) TestCompleteMFALogin_Success() {
+	suite.expectChallenge()
+	suite.expectTokens()
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Secret: "SECRET", Confirmed: true}, nil)
+	suite.mockTOTP.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(42), true)
+	suite.mockMFARepo.On("UseStep", mock.Anything, "mfa-1", int64(42)).Return(nil)
+	suite.mockTokenRepo.On("DeleteMFAChallenge", mock.Anything, "challenge-hash").Return(nil)
+
+	result, err := suite.useCase.CompleteMFALogin(context.Background(), "challenge", "123 456", testClientIP)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jwt-token", result.Tokens.AccessToken)
+	assert.Empty(suite.T(), result.RecoveryCodes)
+	suite.mockThrottle.AssertCalled(suite.T(), "RecordSuccess", mock.Anything, "mfauser")
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	suite.mockMFARepo.AssertNotCalled(suite.T(), "Save", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestCompleteMFALogin_InvalidCode() {
+	suite.expectChallenge()
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Secret: "SECRET", Confirmed: true}, nil)
+	suite.mockTOTP.On("Validate", "SECRET", "000000", mock.Anything).Return(int64(0), false)
+	suite.mockTOTP.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(42), true)
+	// The code was already used in this step
+	suite.mockMFARepo.On("UseStep", mock.Anything, "mfa-1", int64(42)).Return(domain.ErrDuplicateEntry)
+
+	for _, code := range []string{"000000", "123456"} {
+		_, err := suite.useCase.CompleteMFALogin(context.Background(), "challenge", code, testClientIP)
+		assert.Equal(suite.T(), domain.ErrInvalidMFACode, err, code)
+	}
+	suite.mockThrottle.AssertNumberOfCalls(suite.T(), "Reserve", 2)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "RecordSuccess", mock.Anything, mock.Anything)
+	suite.mockTokenRepo.AssertNotCalled(suite.T(), "DeleteMFAChallenge", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestCompleteMFALogin_RecoveryCode() {
+	suite.expectChallenge()
+	suite.expectTokens()
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Secret: "SECRET", Confirmed: true, RecoveryCodes: []string{"code-hash"}}, nil)
+	suite.mockAuthSvc.On("HashToken", "abcdefghij").Return("code-hash")
+	suite.mockMFARepo.On("UseRecoveryCode", mock.Anything, "mfa-1", "code-hash").Return(nil)
+	suite.mockTokenRepo.On("DeleteMFAChallenge", mock.Anything, "challenge-hash").Return(nil)
+
+	result, err := suite.useCase.CompleteMFALogin(context.Background(), "challenge", "ABCDE-FGHIJ", testClientIP)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "jwt-token", result.Tokens.AccessToken)
+	suite.mockMFARepo.AssertExpectations(suite.T())
+	suite.mockTOTP.AssertNotCalled(suite.T(), "Validate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestCompleteMFALogin_ConfirmsEnrollment() {
+	suite.expectChallenge()
+	suite.expectTokens()
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Secret: "SECRET"}, nil)
+	suite.mockTOTP.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(42), true)
+	suite.mockMFARepo.On("UseStep", mock.Anything, "mfa-1", int64(42)).Return(nil)
+	suite.mockTokenRepo.On("DeleteMFAChallenge", mock.Anything, "challenge-hash").Return(nil)
+	suite.mockTOTP.On("GenerateRecoveryCodes", domain.RecoveryCodeCount).Return([]string{"abcde-fghij"}, nil)
+	suite.mockAuthSvc.On("HashToken", "abcdefghij").Return("code-hash")
+	suite.mockMFARepo.On("Save", mock.Anything, mock.MatchedBy(func(e domain.MFAEnrollment) bool {
+		return e.Confirmed && e.LastStep == 42 && len(e.RecoveryCodes) == 1 && e.RecoveryCodes[0] == "code-hash"
+	})).Return(nil)
+
+	result, err := suite.useCase.CompleteMFALogin(context.Background(), "challenge", "123456", testClientIP)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"abcde-fghij"}, result.RecoveryCodes)
+	assert.NotNil(suite.T(), result.Tokens)
+	suite.mockMFARepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditMFAEnabled && e.EntityID == "mfa-1"
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestCompleteMFALogin_UnknownChallenge() {
+	suite.mockAuthSvc.On("HashToken", "stale").Return("stale-hash")
+	suite.mockTokenRepo.On("GetMFAChallenge", mock.Anything, "stale-hash").Return(nil, domain.ErrNotFound)
+
+	_, err := suite.useCase.CompleteMFALogin(context.Background(), "stale", "123456", testClientIP)
+	assert.Equal(suite.T(), domain.ErrUnauthorized, err)
+	_, err = suite.useCase.CompleteMFALogin(context.Background(), "", "123456", testClientIP)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockMFARepo.AssertNotCalled(suite.T(), "Get", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestCompleteMFALogin_EnrollmentGone() {
+	suite.expectChallenge()
+	// Two-factor authentication was disabled after the password was checked
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(nil, domain.ErrNotFound)
+
+	_, err := suite.useCase.CompleteMFALogin(context.Background(), "challenge", "123456", testClientIP)
+	assert.Equal(suite.T(), domain.ErrUnauthorized, err)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "Reserve", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestCompleteMFALogin_VerifyError() {
+	suite.expectChallenge()
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Secret: "SECRET", Confirmed: true}, nil)
+	suite.mockTOTP.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(42), true)
+	suite.mockMFARepo.On("UseStep", mock.Anything, "mfa-1", int64(42)).Return(errors.New("db down"))
+
+	_, err := suite.useCase.CompleteMFALogin(context.Background(), "challenge", "123456", testClientIP)
+	assert.EqualError(suite.T(), err, "db down")
+	// The reserved attempt is handed back
+	suite.mockThrottle.AssertCalled(suite.T(), "Release", mock.Anything, "mfauser", testClientIP)
+}
+
+func (suite *UserUseCaseTestSuite) TestStartMFAEnrollment() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockTOTP.On("GenerateSecret").Return("SECRET", nil)
+	suite.mockTOTP.On("URI", "SECRET", "testuser").Return("otpauth://totp/x")
+	suite.mockMFARepo.On("Save", mock.Anything, mock.MatchedBy(func(e domain.MFAEnrollment) bool {
+		return e.UserID == "1" && e.Secret == "SECRET" && !e.Confirmed
+	})).Return(nil)
+
+	setup, err := suite.useCase.StartMFAEnrollment(context.Background(), "1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &domain.MFASetup{Secret: "SECRET", URI: "otpauth://totp/x"}, setup)
+	suite.mockMFARepo.AssertExpectations(suite.T())
+
+	// A confirmed enrolment has to be disabled before starting over
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Confirmed: true}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "mfa-1").Return(&mfaUser, nil)
+	_, err = suite.useCase.StartMFAEnrollment(context.Background(), "mfa-1")
+	assert.Equal(suite.T(), domain.ErrMFAEnabled, err)
+}
+
+func (suite *UserUseCaseTestSuite) TestConfirmMFAEnrollment() {
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Secret: "SECRET"}, nil)
+	suite.mockTOTP.On("Validate", "SECRET", "000000", mock.Anything).Return(int64(0), false)
+	suite.mockTOTP.On("Validate", "SECRET", "123456", mock.Anything).Return(int64(42), true)
+	suite.mockMFARepo.On("UseStep", mock.Anything, "mfa-1", int64(42)).Return(nil)
+	suite.mockTOTP.On("GenerateRecoveryCodes", domain.RecoveryCodeCount).Return([]string{"abcde-fghij", "klmno-pqrst"}, nil)
+	suite.mockAuthSvc.On("HashToken", "abcdefghij").Return("hash-1")
+	suite.mockAuthSvc.On("HashToken", "klmnopqrst").Return("hash-2")
+	suite.mockMFARepo.On("Save", mock.Anything, mock.MatchedBy(func(e domain.MFAEnrollment) bool {
+		return e.Confirmed && assert.ObjectsAreEqual([]string{"hash-1", "hash-2"}, e.RecoveryCodes)
+	})).Return(nil)
+
+	_, err := suite.useCase.ConfirmMFAEnrollment(context.Background(), "mfa-1", "000000")
+	assert.Equal(suite.T(), domain.ErrInvalidMFACode, err)
+
+	codes, err := suite.useCase.ConfirmMFAEnrollment(context.Background(), "mfa-1", "123456")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"abcde-fghij", "klmno-pqrst"}, codes)
+	suite.mockMFARepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestDisableMFA() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "mfa-1").Return(&mfaUser, nil)
+	suite.mockPasswordSvc.On("Check", "password123", "hashedpassword").Return(true)
+	suite.mockPasswordSvc.On("Check", "wrong", "hashedpassword").Return(false)
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Confirmed: true}, nil)
+
+	assert.Equal(suite.T(), domain.ErrInvalidCredentials, suite.useCase.DisableMFA(context.Background(), "mfa-1", "wrong"))
+	suite.mockMFARepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+
+	assert.NoError(suite.T(), suite.useCase.DisableMFA(context.Background(), "mfa-1", "password123"))
+	suite.mockMFARepo.AssertCalled(suite.T(), "Delete", mock.Anything, "mfa-1")
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditMFADisabled && e.ActorID == "mfa-1" && e.EntityID == "mfa-1"
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestDisableMFA_RequiredByRole() {
+	secured := mfaUser
+	secured.Role = "secured"
+	suite.mockUserRepo.On("GetByID", mock.Anything, "mfa-1").Return(&secured, nil)
+	suite.mockPasswordSvc.On("Check", "password123", "hashedpassword").Return(true)
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Confirmed: true}, nil)
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.Role("secured")).Return(&domain.RoleDefinition{Name: "secured", RequireMFA: true}, nil)
+
+	assert.Equal(suite.T(), domain.ErrMFARequired, suite.useCase.DisableMFA(context.Background(), "mfa-1", "password123"))
+	suite.mockMFARepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestResetMFA() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&domain.User{ID: "2", Role: domain.RoleAdmin}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "mfa-1").Return(&mfaUser, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockMFARepo.On("Get", mock.Anything, "mfa-1").Return(&domain.MFAEnrollment{UserID: "mfa-1", Confirmed: true}, nil)
+
+	assert.NoError(suite.T(), suite.useCase.ResetMFA(context.Background(), "mfa-1", "2"))
+	suite.mockMFARepo.AssertCalled(suite.T(), "Delete", mock.Anything, "mfa-1")
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditMFADisabled && e.ActorID == "2" && e.EntityID == "mfa-1"
+	}))
+
+	// Nothing to reset
+	assert.Equal(suite.T(), domain.ErrNotFound, suite.useCase.ResetMFA(context.Background(), "1", "2"))
+}
@@ -1,31 +1,75 @@
 package usecases
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	domain "task-manager/Domain"
+	"time"
 )
 
 type UserUseCase struct {
 	userRepo        domain.IUserRepository
+	roleRepo        domain.IRoleRepository
+	tokenRepo       domain.ITokenRepository
+	mfaRepo         domain.IMFARepository
+	projectRepo     domain.IProjectRepository
+	auditRepo       domain.IAuditRepository
+	events          domain.IEventPublisher
 	passwordService domain.IPasswordService
 	authService     domain.IAuthService
+	totpService     domain.ITOTPService
+	throttle        domain.ILoginThrottle
+	mailer          domain.IMailer
+	refreshTTL      time.Duration
+	// challengeTTL is how long a login may wait for its second factor.
+	challengeTTL time.Duration
+	resetTTL     time.Duration
+	// resetURL is the page users open to pick a new password. Reset mails
+	// link to it with the token in the query string, or carry the bare token
+	// if it is empty.
+	resetURL string
+
+	// adminMu serializes the last-admin check with the write it guards, so
+	// two admins removing each other at once cannot both pass it.
+	adminMu sync.Mutex
 }
 
-func NewUserUseCase(userRepo domain.IUserRepository, passwordService domain.IPasswordService, authService domain.IAuthService) domain.IUserUseCase {
+func NewUserUseCase(userRepo domain.IUserRepository, roleRepo domain.IRoleRepository, tokenRepo domain.ITokenRepository, mfaRepo domain.IMFARepository, projectRepo domain.IProjectRepository, auditRepo domain.IAuditRepository, events domain.IEventPublisher, passwordService domain.IPasswordService, authService domain.IAuthService, totpService domain.ITOTPService, throttle domain.ILoginThrottle, mailer domain.IMailer, refreshTTL time.Duration, challengeTTL time.Duration, resetTTL time.Duration, resetURL string) domain.IUserUseCase {
 	return &UserUseCase{
 		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		tokenRepo:       tokenRepo,
+		mfaRepo:         mfaRepo,
+		projectRepo:     projectRepo,
+		auditRepo:       auditRepo,
+		events:          events,
 		passwordService: passwordService,
 		authService:     authService,
+		totpService:     totpService,
+		throttle:        throttle,
+		mailer:          mailer,
+		refreshTTL:      refreshTTL,
+		challengeTTL:    challengeTTL,
+		resetTTL:        resetTTL,
+		resetURL:        resetURL,
 	}
 }
 
-func (uc *UserUseCase) Register(user domain.User) (*domain.User, error) {
+func (uc *UserUseCase) Register(ctx context.Context, user domain.User) (*domain.User, error) {
 	// Validate user input
 	if err := user.Validate(); err != nil {
 		return nil, err
 	}
 
 	// Check if user already exists
-	exists, err := uc.userRepo.Exists(user.Username)
+	exists, err := uc.userRepo.Exists(ctx, user.Username)
 	if err != nil {
 		return nil, err
 	}
@@ -48,55 +92,279 @@ func (uc *UserUseCase) Register(user domain.User) (*domain.User, error) {
 	user.Password = hashedPassword
 
 	// Create user
-	createdUser, err := uc.userRepo.Create(user)
+	createdUser, err := uc.userRepo.Create(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
+	// Users register themselves, so they are their own actor
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    createdUser.ID,
+		Action:     domain.AuditUserRegistered,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   createdUser.ID,
+		Changes: []domain.FieldChange{
+			{Field: "username", After: createdUser.Username},
+			{Field: "role", After: string(createdUser.Role)},
+		},
+	})
+	publishEvents(ctx, uc.events, createdUser.ID, domain.Event{Type: domain.EventUserRegistered, User: createdUser})
 	return createdUser, nil
 }
 
-func (uc *UserUseCase) Login(username, password string) (string, error) {
+func (uc *UserUseCase) Login(ctx context.Context, username, password, clientIP string) (*domain.LoginResult, error) {
 	if username == "" || password == "" {
-		return "", domain.ErrInvalidInput
+		return nil, domain.ErrInvalidInput
+	}
+
+	// Count the attempt as failed before the password is checked, so
+	// guessing on while blocked tells nothing
+	if err := uc.throttle.Reserve(ctx, username, clientIP); err != nil {
+		return nil, err
+	}
+
+	// Get user by username and check password. Unknown usernames count as
+	// failures too, so they behave like wrong passwords.
+	user, err := uc.userRepo.GetByUsername(ctx, username)
+	if err != nil || !uc.passwordService.Check(password, user.Password) {
+		return nil, domain.ErrInvalidCredentials
+	}
+	uc.releaseLoginAttempt(ctx, username, clientIP)
+
+	needsMFA, enroll, err := uc.loginMFA(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	// With a second factor the failures are only cleared once it is passed,
+	// or a known password would reset the count of wrong codes
+	if !needsMFA {
+		uc.clearLoginFailures(ctx, username)
+	}
+
+	// Only tell who knows the password that the account is disabled
+	if user.Disabled {
+		return nil, domain.ErrUserDisabled
+	}
+
+	if needsMFA {
+		return uc.startMFAChallenge(ctx, user, enroll)
+	}
+	tokens, err := uc.issueTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Tokens: tokens}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is rotated out; presenting it a second time ends all of the user's sessions.
+func (uc *UserUseCase) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, domain.ErrInvalidInput
 	}
 
-	// Get user by username
-	user, err := uc.userRepo.GetByUsername(username)
+	hash := uc.authService.HashToken(refreshToken)
+	stored, err := uc.tokenRepo.GetRefreshToken(ctx, hash)
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrUnauthorized
 	}
 
-	// Check password
-	if !uc.passwordService.Check(password, user.Password) {
-		return "", domain.ErrInvalidCredentials
+	// A rotated token is being replayed, assume it was stolen
+	if stored.Revoked {
+		if err := uc.tokenRepo.DeleteUserRefreshTokens(ctx, stored.UserID); err != nil {
+			return nil, err
+		}
+		if err := uc.endSessions(ctx, stored.UserID, stored.UserID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrUnauthorized
 	}
 
-	// Generate token
-	token, err := uc.authService.GenerateToken(user)
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, domain.ErrUnauthorized
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil || user.Disabled {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// A concurrent refresh with the same token got there first
+	err = uc.tokenRepo.RevokeRefreshToken(ctx, hash)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrUnauthorized
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return token, nil
+	return uc.issueTokens(ctx, user)
 }
 
-func (uc *UserUseCase) PromoteUser(username string, promoterID string) error {
-	if username == "" || promoterID == "" {
+// Logout revokes the caller's current access token and, if given, the refresh token of the session.
+func (uc *UserUseCase) Logout(ctx context.Context, requester domain.Claims, refreshToken string) error {
+	if requester.UserID == "" || requester.TokenID == "" {
 		return domain.ErrInvalidInput
 	}
 
-	// Check if promoter exists and is admin
-	promoter, err := uc.userRepo.GetByID(promoterID)
+	if refreshToken != "" {
+		hash := uc.authService.HashToken(refreshToken)
+		stored, err := uc.tokenRepo.GetRefreshToken(ctx, hash)
+		if err == nil {
+			if stored.UserID != requester.UserID {
+				return domain.ErrForbidden
+			}
+			if err := uc.tokenRepo.DeleteRefreshToken(ctx, hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := uc.tokenRepo.RevokeAccessToken(ctx, requester.TokenID, requester.ExpiresAt); err != nil {
+		return err
+	}
+	publishEvents(ctx, uc.events, requester.UserID, domain.Event{
+		Type:    domain.EventSessionsEnded,
+		User:    &domain.User{ID: requester.UserID},
+		TokenID: requester.TokenID,
+	})
+	return nil
+}
+
+func (uc *UserUseCase) ChangePassword(ctx context.Context, userID string, oldPassword, newPassword, clientIP string) (*domain.TokenPair, error) {
+	if userID == "" || oldPassword == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := domain.ValidatePassword(newPassword); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return domain.ErrNotFound
+		return nil, err
+	}
+	// The old password is guessed at under the same throttle as logins, or
+	// a stolen access token would allow unlimited tries
+	if err := uc.throttle.Reserve(ctx, user.Username, clientIP); err != nil {
+		return nil, err
+	}
+	if !uc.passwordService.Check(oldPassword, user.Password) {
+		return nil, domain.ErrInvalidCredentials
 	}
-	if promoter.Role != domain.RoleAdmin {
-		return domain.ErrForbidden
+	uc.releaseLoginAttempt(ctx, user.Username, clientIP)
+
+	if err := uc.setPassword(ctx, user.ID, newPassword); err != nil {
+		return nil, err
+	}
+
+	// Other sessions end like after a reset, and the caller continues with
+	// tokens carrying the new token version
+	if err := uc.tokenRepo.DeleteUserRefreshTokens(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if err := uc.endSessions(ctx, user.ID, user.ID); err != nil {
+		return nil, err
+	}
+	uc.auditPassword(ctx, domain.AuditPasswordChanged, user.ID)
+	user, err = uc.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.issueTokens(ctx, user)
+}
+
+func (uc *UserUseCase) RequestPasswordReset(ctx context.Context, username string) error {
+	if username == "" {
+		return domain.ErrInvalidInput
+	}
+
+	// Unknown, disabled and unreachable users are skipped without a word
+	user, err := uc.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.Disabled || user.Email == "" {
+		return nil
+	}
+
+	token, err := uc.authService.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	// Only the latest token is valid
+	if err := uc.tokenRepo.DeleteUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return err
+	}
+	now := time.Now()
+	err = uc.tokenRepo.CreatePasswordResetToken(ctx, domain.PasswordResetToken{
+		Hash:      uc.authService.HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(uc.resetTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.Send(ctx, domain.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    uc.resetMailBody(user, token),
+	})
+}
+
+func (uc *UserUseCase) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	if token == "" {
+		return domain.ErrInvalidInput
+	}
+	// Check the password first so a typo does not use up the token
+	if err := domain.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	stored, err := uc.tokenRepo.ConsumePasswordResetToken(ctx, uc.authService.HashToken(token))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	user, err := uc.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil || user.Disabled {
+		return domain.ErrInvalidResetToken
+	}
+
+	if err := uc.setPassword(ctx, user.ID, newPassword); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password must not stay signed in
+	if err := uc.tokenRepo.DeleteUserRefreshTokens(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := uc.endSessions(ctx, user.ID, user.ID); err != nil {
+		return err
+	}
+	uc.auditPassword(ctx, domain.AuditPasswordReset, user.ID)
+	return nil
+}
+
+func (uc *UserUseCase) PromoteUser(ctx context.Context, username string, promoterID string) error {
+	if username == "" || promoterID == "" {
+		return domain.ErrInvalidInput
+	}
+
+	// Check if promoter exists and may hand out the admin role
+	if _, err := uc.grantableRole(ctx, promoterID, domain.RoleAdmin); err != nil {
+		return err
 	}
 
 	// Check if user to be promoted exists
-	userToPromote, err := uc.userRepo.GetByUsername(username)
+	userToPromote, err := uc.userRepo.GetByUsername(ctx, username)
 	if err != nil {
 		return domain.ErrNotFound
 	}
@@ -106,5 +374,364 @@ func (uc *UserUseCase) PromoteUser(username string, promoterID string) error {
 		return domain.ErrInvalidInput
 	}
 
-	return uc.userRepo.Promote(username)
+	if err := uc.userRepo.Promote(ctx, username); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    promoterID,
+		Action:     domain.AuditUserPromoted,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   userToPromote.ID,
+		Changes: []domain.FieldChange{
+			{Field: "role", Before: string(userToPromote.Role), After: string(domain.RoleAdmin)},
+		},
+	})
+	promoted := *userToPromote
+	promoted.Role = domain.RoleAdmin
+	publishEvents(ctx, uc.events, promoterID, domain.Event{Type: domain.EventUserPromoted, User: &promoted})
+
+	// Force the new role into effect by invalidating outstanding access tokens
+	return uc.endSessions(ctx, userToPromote.ID, promoterID)
+}
+
+// AssignRole gives a user another role. The actor's own role must grant every
+// permission of the new role, so nobody can hand out more than they hold.
+func (uc *UserUseCase) AssignRole(ctx context.Context, userID string, role domain.Role, actorID string) (*domain.User, error) {
+	role = domain.NormalizeRoleName(string(role))
+	if userID == "" || role == "" || actorID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if _, err := uc.grantableRole(ctx, actorID, role); err != nil {
+		return nil, err
+	}
+	user, err := uc.manageableUser(ctx, userID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	return uc.changeRole(ctx, user, role, domain.AuditUserRoleChanged, actorID)
+}
+
+func (uc *UserUseCase) ListUsers(ctx context.Context, query domain.UserQuery) (*domain.UserPage, error) {
+	if query.Role != "" {
+		query.Role = domain.NormalizeRoleName(string(query.Role))
+	}
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	return uc.userRepo.Find(ctx, query)
+}
+
+func (uc *UserUseCase) GetUser(ctx context.Context, id string) (*domain.User, error) {
+	if id == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.userRepo.GetByID(ctx, id)
+}
+
+func (uc *UserUseCase) DemoteUser(ctx context.Context, id string, actorID string) (*domain.User, error) {
+	if id == "" || actorID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	user, err := uc.manageableUser(ctx, id, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prevent demoting users who already hold the default role
+	if user.Role == domain.RoleUser {
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.changeRole(ctx, user, domain.RoleUser, domain.AuditUserDemoted, actorID)
+}
+
+func (uc *UserUseCase) SetUserDisabled(ctx context.Context, id string, disabled bool, actorID string) (*domain.User, error) {
+	if id == "" || actorID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	uc.adminMu.Lock()
+	defer uc.adminMu.Unlock()
+	user, err := uc.manageableUser(ctx, id, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled == disabled {
+		return user, nil
+	}
+	if disabled {
+		if err := uc.ensureNotLastAdmin(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.userRepo.SetDisabled(ctx, id, disabled); err != nil {
+		return nil, err
+	}
+
+	action := domain.AuditUserEnabled
+	if disabled {
+		action = domain.AuditUserDisabled
+		// End every session at once rather than when the tokens expire
+		if err := uc.tokenRepo.DeleteUserRefreshTokens(ctx, id); err != nil {
+			return nil, err
+		}
+		if err := uc.endSessions(ctx, id, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   id,
+		Changes: []domain.FieldChange{
+			{Field: "disabled", Before: strconv.FormatBool(user.Disabled), After: strconv.FormatBool(disabled)},
+		},
+	})
+	updated := *user
+	updated.Disabled = disabled
+	return &updated, nil
+}
+
+func (uc *UserUseCase) DeleteUser(ctx context.Context, id string, actorID string) error {
+	if id == "" || actorID == "" {
+		return domain.ErrInvalidInput
+	}
+	uc.adminMu.Lock()
+	defer uc.adminMu.Unlock()
+	user, err := uc.manageableUser(ctx, id, actorID)
+	if err != nil {
+		return err
+	}
+	if err := uc.ensureNotLastAdmin(ctx, user); err != nil {
+		return err
+	}
+
+	// Projects must keep an owner, so one has to be appointed first
+	projects, err := uc.projectRepo.GetByMember(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		if role, _ := project.RoleOf(id); role == domain.ProjectOwner && project.Owners() == 1 {
+			return domain.ErrLastOwner
+		}
+	}
+
+	if err := uc.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := uc.tokenRepo.DeleteUserRefreshTokens(ctx, id); err != nil {
+		return err
+	}
+	if err := uc.mfaRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	publishEvents(ctx, uc.events, actorID, domain.Event{Type: domain.EventSessionsEnded, User: &domain.User{ID: id}})
+
+	for _, project := range projects {
+		project.Members = slices.DeleteFunc(slices.Clone(project.Members), func(m domain.ProjectMember) bool { return m.UserID == id })
+		updated, err := uc.projectRepo.Update(ctx, project)
+		if err != nil {
+			return err
+		}
+		publishEvents(ctx, uc.events, actorID, domain.Event{Type: domain.EventProjectMembersChanged, Project: updated})
+	}
+
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     domain.AuditUserDeleted,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   id,
+		Changes: []domain.FieldChange{
+			{Field: "username", Before: user.Username},
+			{Field: "role", Before: string(user.Role)},
+		},
+	})
+	return nil
+}
+
+// endSessions makes the user's access tokens stop validating and closes the
+// task streams opened with them.
+func (uc *UserUseCase) endSessions(ctx context.Context, userID, actorID string) error {
+	if err := uc.userRepo.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	publishEvents(ctx, uc.events, actorID, domain.Event{Type: domain.EventSessionsEnded, User: &domain.User{ID: userID}})
+	return nil
+}
+
+// setPassword stores the hash of a new password. Outstanding reset tokens are
+// dropped, since they were meant to replace the old password.
+func (uc *UserUseCase) setPassword(ctx context.Context, userID string, password string) error {
+	hash, err := uc.passwordService.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err := uc.userRepo.SetPassword(ctx, userID, hash); err != nil {
+		return err
+	}
+	if err := uc.tokenRepo.DeleteUserPasswordResetTokens(ctx, userID); err != nil {
+		log.Printf("Failed to delete password reset tokens of user %s: %v", userID, err)
+	}
+	return nil
+}
+
+// auditPassword records a password change. Users act on their own account,
+// and the password itself is never recorded.
+func (uc *UserUseCase) auditPassword(ctx context.Context, action, userID string) {
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    userID,
+		Action:     action,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   userID,
+		Changes:    []domain.FieldChange{{Field: "password"}},
+	})
+}
+
+func (uc *UserUseCase) resetMailBody(user *domain.User, token string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\n", user.Username)
+	b.WriteString("Someone asked to reset the password of your account. ")
+	if uc.resetURL != "" {
+		fmt.Fprintf(&b, "Open this link to choose a new one:\n\n%s\n\n", resetLink(uc.resetURL, token))
+	} else {
+		fmt.Fprintf(&b, "Use this token to choose a new one:\n\n%s\n\n", token)
+	}
+	fmt.Fprintf(&b, "It can be used once and expires in %s. ", uc.resetTTL)
+	b.WriteString("If you did not ask for this, you can ignore this email.\n")
+	return b.String()
+}
+
+// resetLink adds the token to the query string of the reset page.
+func resetLink(page string, token string) string {
+	u, err := url.Parse(page)
+	if err != nil {
+		return page + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// changeRole stores the user's new role and signs them out, so the role takes
+// effect at once.
+func (uc *UserUseCase) changeRole(ctx context.Context, user *domain.User, role domain.Role, action, actorID string) (*domain.User, error) {
+	uc.adminMu.Lock()
+	defer uc.adminMu.Unlock()
+	if err := uc.ensureNotLastAdmin(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := uc.userRepo.SetRole(ctx, user.ID, role); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, uc.auditRepo, domain.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: domain.AuditEntityUser,
+		EntityID:   user.ID,
+		Changes: []domain.FieldChange{
+			{Field: "role", Before: string(user.Role), After: string(role)},
+		},
+	})
+
+	// Force the new role into effect by invalidating outstanding access tokens
+	if err := uc.endSessions(ctx, user.ID, actorID); err != nil {
+		return nil, err
+	}
+	updated := *user
+	updated.Role = role
+	return &updated, nil
+}
+
+// manageableUser loads the user and makes sure the actor's role grants every
+// permission the user's role does, so nobody can act on someone above them.
+func (uc *UserUseCase) manageableUser(ctx context.Context, id string, actorID string) (*domain.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+	if _, err := uc.grantableRole(ctx, actorID, user.Role); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ensureNotLastAdmin refuses to take away the admin role or account of the
+// only active admin, which would leave nobody able to manage the system.
+// Callers hold adminMu until the change is stored.
+func (uc *UserUseCase) ensureNotLastAdmin(ctx context.Context, user *domain.User) error {
+	if user.Role != domain.RoleAdmin || user.Disabled {
+		return nil
+	}
+	active := false
+	page, err := uc.userRepo.Find(ctx, domain.UserQuery{Role: domain.RoleAdmin, Disabled: &active, Limit: 2})
+	if err != nil {
+		return err
+	}
+	if len(page.Users) < 2 {
+		return domain.ErrLastAdmin
+	}
+	return nil
+}
+
+// grantableRole loads the role and makes sure the actor's role grants all of
+// its permissions.
+func (uc *UserUseCase) grantableRole(ctx context.Context, actorID string, role domain.Role) (*domain.RoleDefinition, error) {
+	actor, err := uc.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	target, err := uc.roleRepo.GetByName(ctx, role)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrInvalidInput
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	own, err := uc.roleRepo.GetByName(ctx, actor.Role)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrForbidden
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !own.Grants(target.Permissions...) {
+		return nil, domain.ErrForbidden
+	}
+	return target, nil
+}
+
+func (uc *UserUseCase) issueTokens(ctx context.Context, user *domain.User) (*domain.TokenPair, error) {
+	accessToken, err := uc.authService.GenerateToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := uc.authService.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = uc.tokenRepo.CreateRefreshToken(ctx, domain.RefreshToken{
+		Hash:      uc.authService.HashToken(refreshToken),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(uc.refreshTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
 }
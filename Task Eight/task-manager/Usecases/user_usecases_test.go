@@ -1,30 +1,66 @@
 package usecases
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"strings"
+	"sync"
 	domain "task-manager/Domain"
+	repositories "task-manager/Repositories"
 	"task-manager/Repositories/mocks"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
+const testClientIP = "203.0.113.1"
+
 type UserUseCaseTestSuite struct {
 	suite.Suite
 	mockUserRepo    *mocks.MockUserRepository
+	mockRoleRepo    *mocks.MockRoleRepository
+	mockTokenRepo   *mocks.MockTokenRepository
+	mockMFARepo     *mocks.MockMFARepository
+	mockProjectRepo *mocks.MockProjectRepository
+	mockAudit       *mocks.MockAuditRepository
+	mockEvents      *mocks.MockEventPublisher
 	mockPasswordSvc *mocks.MockPasswordService
 	mockAuthSvc     *mocks.MockAuthService
+	mockTOTP        *mocks.MockTOTPService
+	mockThrottle    *mocks.MockLoginThrottle
+	mockMailer      *mocks.MockMailer
 	useCase         domain.IUserUseCase
 	dummyUser       domain.User
 }
 
 func (suite *UserUseCaseTestSuite) SetupTest() {
 	suite.mockUserRepo = new(mocks.MockUserRepository)
+	suite.mockRoleRepo = new(mocks.MockRoleRepository)
+	for _, role := range domain.BuiltinRoles() {
+		suite.mockRoleRepo.On("GetByName", mock.Anything, role.Name).Return(&role, nil).Maybe()
+	}
 	suite.mockPasswordSvc = new(mocks.MockPasswordService)
 	suite.mockAuthSvc = new(mocks.MockAuthService)
-	suite.useCase = NewUserUseCase(suite.mockUserRepo, suite.mockPasswordSvc, suite.mockAuthSvc)
+	suite.mockTokenRepo = new(mocks.MockTokenRepository)
+	suite.mockMFARepo = new(mocks.MockMFARepository)
+	suite.mockMFARepo.On("Get", mock.Anything, "1").Return(nil, domain.ErrNotFound).Maybe()
+	suite.mockMFARepo.On("Delete", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockTOTP = new(mocks.MockTOTPService)
+	suite.mockProjectRepo = new(mocks.MockProjectRepository)
+	suite.mockAudit = new(mocks.MockAuditRepository)
+	suite.mockAudit.On("Record", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockEvents = new(mocks.MockEventPublisher)
+	suite.mockEvents.On("Publish", mock.Anything, mock.Anything).Maybe()
+	suite.mockThrottle = new(mocks.MockLoginThrottle)
+	suite.mockThrottle.On("Reserve", mock.Anything, mock.Anything, testClientIP).Return(nil).Maybe()
+	suite.mockThrottle.On("Release", mock.Anything, mock.Anything, testClientIP).Return(nil).Maybe()
+	suite.mockThrottle.On("RecordSuccess", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.mockMailer = new(mocks.MockMailer)
+	suite.useCase = NewUserUseCase(suite.mockUserRepo, suite.mockRoleRepo, suite.mockTokenRepo, suite.mockMFARepo, suite.mockProjectRepo, suite.mockAudit, suite.mockEvents, suite.mockPasswordSvc, suite.mockAuthSvc, suite.mockTOTP, suite.mockThrottle, suite.mockMailer, time.Hour, 5*time.Minute, 30*time.Minute, "https://tasks.example.com/reset")
 	suite.dummyUser = domain.User{
 		ID:       "1",
 		Username: "testuser",
@@ -34,16 +70,16 @@ func (suite *UserUseCaseTestSuite) SetupTest() {
 }
 
 func (suite *UserUseCaseTestSuite) TestRegister_Success() {
-	suite.mockUserRepo.On("Exists", "testuser").Return(false, nil)
+	suite.mockUserRepo.On("Exists", mock.Anything, "testuser").Return(false, nil)
 	suite.mockPasswordSvc.On("Hash", "password123").Return("hashedpassword", nil)
-	suite.mockUserRepo.On("Create", mock.AnythingOfType("domain.User")).Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("domain.User")).Return(&suite.dummyUser, nil)
 
 	user := domain.User{
 		Username: "testuser",
 		Password: "password123",
 	}
 
-	result, err := suite.useCase.Register(user)
+	result, err := suite.useCase.Register(context.Background(), user)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
 	assert.Equal(suite.T(), "testuser", result.Username)
@@ -51,13 +87,27 @@ func (suite *UserUseCaseTestSuite) TestRegister_Success() {
 	suite.mockPasswordSvc.AssertExpectations(suite.T())
 }
 
+func (suite *UserUseCaseTestSuite) TestRegister_PublishesEvent() {
+	suite.mockUserRepo.On("Exists", mock.Anything, "testuser").Return(false, nil)
+	suite.mockPasswordSvc.On("Hash", "password123").Return("hashedpassword", nil)
+	suite.mockUserRepo.On("Create", mock.Anything, mock.AnythingOfType("domain.User")).Return(&suite.dummyUser, nil)
+
+	_, err := suite.useCase.Register(context.Background(), domain.User{Username: "testuser", Password: "password123"})
+	assert.NoError(suite.T(), err)
+	suite.mockEvents.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		// The password hash never leaves the service
+		payload, _ := json.Marshal(e)
+		return e.Type == domain.EventUserRegistered && e.User.ID == "1" && !strings.Contains(string(payload), "hashedpassword")
+	}))
+}
+
 func (suite *UserUseCaseTestSuite) TestRegister_ValidationError_ShortUsername() {
 	user := domain.User{
 		Username: "ab",
 		Password: "password123",
 	}
 
-	_, err := suite.useCase.Register(user)
+	_, err := suite.useCase.Register(context.Background(), user)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
@@ -68,27 +118,40 @@ func (suite *UserUseCaseTestSuite) TestRegister_ValidationError_ShortPassword()
 		Password: "123",
 	}
 
-	_, err := suite.useCase.Register(user)
+	_, err := suite.useCase.Register(context.Background(), user)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
 
+func (suite *UserUseCaseTestSuite) TestRegister_ValidationError_InvalidEmail() {
+	for _, email := range []string{"not-an-email", "Alice <alice@example.com>"} {
+		user := domain.User{
+			Username: "testuser",
+			Password: "password123",
+			Email:    email,
+		}
+
+		_, err := suite.useCase.Register(context.Background(), user)
+		assert.Equal(suite.T(), domain.ErrInvalidInput, err, email)
+	}
+}
+
 func (suite *UserUseCaseTestSuite) TestRegister_UserAlreadyExists() {
-	suite.mockUserRepo.On("Exists", "testuser").Return(true, nil)
+	suite.mockUserRepo.On("Exists", mock.Anything, "testuser").Return(true, nil)
 
 	user := domain.User{
 		Username: "testuser",
 		Password: "password123",
 	}
 
-	_, err := suite.useCase.Register(user)
+	_, err := suite.useCase.Register(context.Background(), user)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrDuplicateEntry, err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
 }
 
 func (suite *UserUseCaseTestSuite) TestRegister_PasswordHashError() {
-	suite.mockUserRepo.On("Exists", "testuser").Return(false, nil)
+	suite.mockUserRepo.On("Exists", mock.Anything, "testuser").Return(false, nil)
 	suite.mockPasswordSvc.On("Hash", "password123").Return("", errors.New("hash error"))
 
 	user := domain.User{
@@ -96,61 +159,132 @@ func (suite *UserUseCaseTestSuite) TestRegister_PasswordHashError() {
 		Password: "password123",
 	}
 
-	_, err := suite.useCase.Register(user)
+	_, err := suite.useCase.Register(context.Background(), user)
 	assert.Error(suite.T(), err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
 	suite.mockPasswordSvc.AssertExpectations(suite.T())
 }
 
 func (suite *UserUseCaseTestSuite) TestLogin_Success() {
-	suite.mockUserRepo.On("GetByUsername", "testuser").Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&suite.dummyUser, nil)
 	suite.mockPasswordSvc.On("Check", "password123", mock.AnythingOfType("string")).Return(true)
 	suite.mockAuthSvc.On("GenerateToken", &suite.dummyUser).Return("jwt-token", nil)
+	suite.mockAuthSvc.On("GenerateOpaqueToken").Return("refresh-token", nil)
+	suite.mockAuthSvc.On("HashToken", "refresh-token").Return("refresh-hash")
+	suite.mockTokenRepo.On("CreateRefreshToken", mock.Anything, mock.MatchedBy(func(t domain.RefreshToken) bool {
+		return t.Hash == "refresh-hash" && t.UserID == "1" && t.ExpiresAt.After(time.Now())
+	})).Return(nil)
 
-	token, err := suite.useCase.Login("testuser", "password123")
+	result, err := suite.useCase.Login(context.Background(), "testuser", "password123", testClientIP)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "jwt-token", token)
+	assert.Equal(suite.T(), "jwt-token", result.Tokens.AccessToken)
+	assert.Equal(suite.T(), "refresh-token", result.Tokens.RefreshToken)
+	assert.Empty(suite.T(), result.MFAPending)
+	suite.mockThrottle.AssertCalled(suite.T(), "Release", mock.Anything, "testuser", testClientIP)
+	suite.mockThrottle.AssertCalled(suite.T(), "RecordSuccess", mock.Anything, "testuser")
 	suite.mockUserRepo.AssertExpectations(suite.T())
 	suite.mockPasswordSvc.AssertExpectations(suite.T())
 	suite.mockAuthSvc.AssertExpectations(suite.T())
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestLogin_Disabled() {
+	disabled := suite.dummyUser
+	disabled.Disabled = true
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&disabled, nil)
+	suite.mockPasswordSvc.On("Check", "password123", mock.AnythingOfType("string")).Return(true)
+
+	_, err := suite.useCase.Login(context.Background(), "testuser", "password123", testClientIP)
+	assert.Equal(suite.T(), domain.ErrUserDisabled, err)
+	suite.mockAuthSvc.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything)
 }
 
 func (suite *UserUseCaseTestSuite) TestLogin_EmptyCredentials() {
-	_, err := suite.useCase.Login("", "password123")
+	_, err := suite.useCase.Login(context.Background(), "", "password123", testClientIP)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 
-	_, err = suite.useCase.Login("testuser", "")
+	_, err = suite.useCase.Login(context.Background(), "testuser", "", testClientIP)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
 
 func (suite *UserUseCaseTestSuite) TestLogin_UserNotFound() {
-	suite.mockUserRepo.On("GetByUsername", "nonexistent").Return(nil, errors.New("not found"))
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "nonexistent").Return(nil, errors.New("not found"))
 
-	_, err := suite.useCase.Login("nonexistent", "password123")
+	_, err := suite.useCase.Login(context.Background(), "nonexistent", "password123", testClientIP)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidCredentials, err)
+	suite.mockThrottle.AssertCalled(suite.T(), "Reserve", mock.Anything, "nonexistent", testClientIP)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything)
 	suite.mockUserRepo.AssertExpectations(suite.T())
 }
 
 func (suite *UserUseCaseTestSuite) TestLogin_InvalidPassword() {
-	suite.mockUserRepo.On("GetByUsername", "testuser").Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&suite.dummyUser, nil)
 	suite.mockPasswordSvc.On("Check", "wrongpassword", mock.AnythingOfType("string")).Return(false)
 
-	_, err := suite.useCase.Login("testuser", "wrongpassword")
+	_, err := suite.useCase.Login(context.Background(), "testuser", "wrongpassword", testClientIP)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidCredentials, err)
+	suite.mockThrottle.AssertCalled(suite.T(), "Reserve", mock.Anything, "testuser", testClientIP)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "RecordSuccess", mock.Anything, mock.Anything)
 	suite.mockUserRepo.AssertExpectations(suite.T())
 	suite.mockPasswordSvc.AssertExpectations(suite.T())
 }
 
+func (suite *UserUseCaseTestSuite) TestLogin_Throttled() {
+	suite.mockThrottle.On("Reserve", mock.Anything, "testuser", "198.51.100.7").Return(&domain.LoginThrottledError{RetryAfter: time.Minute})
+
+	_, err := suite.useCase.Login(context.Background(), "testuser", "password123", "198.51.100.7")
+	assert.ErrorIs(suite.T(), err, domain.ErrTooManyAttempts)
+	var throttled *domain.LoginThrottledError
+	assert.ErrorAs(suite.T(), err, &throttled)
+	assert.Equal(suite.T(), time.Minute, throttled.RetryAfter)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "GetByUsername", mock.Anything, mock.Anything)
+	suite.mockPasswordSvc.AssertNotCalled(suite.T(), "Check", mock.Anything, mock.Anything)
+}
+
+// Bad logins fired at once must not all get past the throttle by reading the
+// count before any of them has failed.
+func (suite *UserUseCaseTestSuite) TestLogin_ParallelFailuresThrottled() {
+	throttle := NewLoginThrottle(repositories.NewMemoryLoginAttemptRepository(), testUserPolicy, testIPPolicy)
+	useCase := NewUserUseCase(suite.mockUserRepo, suite.mockRoleRepo, suite.mockTokenRepo, suite.mockMFARepo, suite.mockProjectRepo, suite.mockAudit, suite.mockEvents, suite.mockPasswordSvc, suite.mockAuthSvc, suite.mockTOTP, throttle, suite.mockMailer, time.Hour, 5*time.Minute, 30*time.Minute, "https://tasks.example.com/reset")
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&suite.dummyUser, nil)
+	suite.mockPasswordSvc.On("Check", "wrongpassword", mock.AnythingOfType("string")).Return(false)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[error]int)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := useCase.Login(context.Background(), "testuser", "wrongpassword", testClientIP)
+			if errors.Is(err, domain.ErrTooManyAttempts) {
+				err = domain.ErrTooManyAttempts
+			}
+			mu.Lock()
+			results[err]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Only the attempts the policy lets through without a delay were checked
+	allowed := testUserPolicy.FreeFailures + 1
+	assert.Equal(suite.T(), allowed, results[domain.ErrInvalidCredentials])
+	assert.Equal(suite.T(), 20-allowed, results[domain.ErrTooManyAttempts])
+	suite.mockPasswordSvc.AssertNumberOfCalls(suite.T(), "Check", allowed)
+}
+
 func (suite *UserUseCaseTestSuite) TestLogin_TokenGenerationError() {
-	suite.mockUserRepo.On("GetByUsername", "testuser").Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&suite.dummyUser, nil)
 	suite.mockPasswordSvc.On("Check", "password123", mock.AnythingOfType("string")).Return(true)
 	suite.mockAuthSvc.On("GenerateToken", &suite.dummyUser).Return("", errors.New("token error"))
 
-	_, err := suite.useCase.Login("testuser", "password123")
+	_, err := suite.useCase.Login(context.Background(), "testuser", "password123", testClientIP)
 	assert.Error(suite.T(), err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
 	suite.mockPasswordSvc.AssertExpectations(suite.T())
@@ -169,29 +303,37 @@ func (suite *UserUseCaseTestSuite) TestPromoteUser_Success() {
 		Role:     domain.RoleUser,
 	}
 
-	suite.mockUserRepo.On("GetByID", "2").Return(&promoter, nil)
-	suite.mockUserRepo.On("GetByUsername", "testuser").Return(&userToPromote, nil)
-	suite.mockUserRepo.On("Promote", "testuser").Return(nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&promoter, nil)
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&userToPromote, nil)
+	suite.mockUserRepo.On("Promote", mock.Anything, "testuser").Return(nil)
+	suite.mockUserRepo.On("IncrementTokenVersion", mock.Anything, "1").Return(nil)
 
-	err := suite.useCase.PromoteUser("testuser", "2")
+	err := suite.useCase.PromoteUser(context.Background(), "testuser", "2")
 	assert.NoError(suite.T(), err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserPromoted && e.ActorID == "2" && e.EntityID == "1" &&
+			e.Changes[0] == domain.FieldChange{Field: "role", Before: "user", After: "admin"}
+	}))
+	suite.mockEvents.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventUserPromoted && e.ActorID == "2" && e.User.Role == domain.RoleAdmin
+	}))
 }
 
 func (suite *UserUseCaseTestSuite) TestPromoteUser_EmptyInputs() {
-	err := suite.useCase.PromoteUser("", "2")
+	err := suite.useCase.PromoteUser(context.Background(), "", "2")
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 
-	err = suite.useCase.PromoteUser("testuser", "")
+	err = suite.useCase.PromoteUser(context.Background(), "testuser", "")
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 }
 
 func (suite *UserUseCaseTestSuite) TestPromoteUser_PromoterNotFound() {
-	suite.mockUserRepo.On("GetByID", "2").Return(nil, errors.New("not found"))
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(nil, errors.New("not found"))
 
-	err := suite.useCase.PromoteUser("testuser", "2")
+	err := suite.useCase.PromoteUser(context.Background(), "testuser", "2")
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrNotFound, err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
@@ -204,9 +346,9 @@ func (suite *UserUseCaseTestSuite) TestPromoteUser_PromoterNotAdmin() {
 		Role:     domain.RoleUser,
 	}
 
-	suite.mockUserRepo.On("GetByID", "2").Return(&promoter, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&promoter, nil)
 
-	err := suite.useCase.PromoteUser("testuser", "2")
+	err := suite.useCase.PromoteUser(context.Background(), "testuser", "2")
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrForbidden, err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
@@ -219,10 +361,10 @@ func (suite *UserUseCaseTestSuite) TestPromoteUser_UserToPromoteNotFound() {
 		Role:     domain.RoleAdmin,
 	}
 
-	suite.mockUserRepo.On("GetByID", "2").Return(&promoter, nil)
-	suite.mockUserRepo.On("GetByUsername", "nonexistent").Return(nil, errors.New("not found"))
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&promoter, nil)
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "nonexistent").Return(nil, errors.New("not found"))
 
-	err := suite.useCase.PromoteUser("nonexistent", "2")
+	err := suite.useCase.PromoteUser(context.Background(), "nonexistent", "2")
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrNotFound, err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
@@ -240,15 +382,405 @@ func (suite *UserUseCaseTestSuite) TestPromoteUser_UserAlreadyAdmin() {
 		Role:     domain.RoleAdmin,
 	}
 
-	suite.mockUserRepo.On("GetByID", "2").Return(&promoter, nil)
-	suite.mockUserRepo.On("GetByUsername", "testuser").Return(&userToPromote, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&promoter, nil)
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&userToPromote, nil)
 
-	err := suite.useCase.PromoteUser("testuser", "2")
+	err := suite.useCase.PromoteUser(context.Background(), "testuser", "2")
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
 	suite.mockUserRepo.AssertExpectations(suite.T())
 }
 
+func (suite *UserUseCaseTestSuite) TestAssignRole_Success() {
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.Role("triager")).
+		Return(&domain.RoleDefinition{Name: "triager", Permissions: []domain.Permission{domain.PermTasksUpdate}}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&domain.User{ID: "2", Role: domain.RoleUser}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("SetRole", mock.Anything, "1", domain.Role("triager")).Return(nil)
+	suite.mockUserRepo.On("IncrementTokenVersion", mock.Anything, "1").Return(nil)
+
+	user, err := suite.useCase.AssignRole(context.Background(), "1", " Triager ", "2")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), domain.Role("triager"), user.Role)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserRoleChanged && e.ActorID == "2" && e.EntityID == "1" &&
+			e.Changes[0] == domain.FieldChange{Field: "role", Before: "user", After: "triager"}
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestAssignRole_Rejected() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&domain.User{ID: "2", Role: domain.RoleUser}, nil)
+	suite.mockRoleRepo.On("GetByName", mock.Anything, domain.Role("ghost")).Return(nil, domain.ErrNotFound)
+
+	// A user cannot hand out admin, which grants more than they hold
+	_, err := suite.useCase.AssignRole(context.Background(), "1", domain.RoleAdmin, "2")
+	assert.Equal(suite.T(), domain.ErrForbidden, err)
+	_, err = suite.useCase.AssignRole(context.Background(), "1", "ghost", "2")
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	_, err = suite.useCase.AssignRole(context.Background(), "", domain.RoleUser, "2")
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "SetRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// activeAdmins makes the repository report the given active admins.
+func (suite *UserUseCaseTestSuite) activeAdmins(admins ...domain.User) {
+	suite.mockUserRepo.On("Find", mock.Anything, mock.MatchedBy(func(q domain.UserQuery) bool {
+		return q.Role == domain.RoleAdmin && q.Disabled != nil && !*q.Disabled
+	})).Return(&domain.UserPage{Users: admins}, nil)
+}
+
+func (suite *UserUseCaseTestSuite) TestDemoteUser() {
+	admin := domain.User{ID: "2", Username: "admin", Role: domain.RoleAdmin}
+	other := domain.User{ID: "3", Username: "other", Role: domain.RoleAdmin}
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&admin, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "3").Return(&other, nil)
+	suite.activeAdmins(admin, other)
+	suite.mockUserRepo.On("SetRole", mock.Anything, "3", domain.RoleUser).Return(nil)
+	suite.mockUserRepo.On("IncrementTokenVersion", mock.Anything, "3").Return(nil)
+
+	user, err := suite.useCase.DemoteUser(context.Background(), "3", "2")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), domain.RoleUser, user.Role)
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserDemoted && e.EntityID == "3"
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestLastAdminIsProtected() {
+	admin := domain.User{ID: "2", Username: "admin", Role: domain.RoleAdmin}
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&admin, nil)
+	suite.activeAdmins(admin)
+
+	_, err := suite.useCase.DemoteUser(context.Background(), "2", "2")
+	assert.Equal(suite.T(), domain.ErrLastAdmin, err)
+	_, err = suite.useCase.AssignRole(context.Background(), "2", domain.RoleUser, "2")
+	assert.Equal(suite.T(), domain.ErrLastAdmin, err)
+	_, err = suite.useCase.SetUserDisabled(context.Background(), "2", true, "2")
+	assert.Equal(suite.T(), domain.ErrLastAdmin, err)
+	assert.Equal(suite.T(), domain.ErrLastAdmin, suite.useCase.DeleteUser(context.Background(), "2", "2"))
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "SetRole", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "SetDisabled", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestManagingHigherRoleIsForbidden() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&domain.User{ID: "2", Role: domain.RoleAdmin}, nil)
+
+	_, err := suite.useCase.SetUserDisabled(context.Background(), "2", true, "1")
+	assert.Equal(suite.T(), domain.ErrForbidden, err)
+	assert.Equal(suite.T(), domain.ErrForbidden, suite.useCase.DeleteUser(context.Background(), "2", "1"))
+}
+
+func (suite *UserUseCaseTestSuite) TestSetUserDisabled_EndsSessions() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&domain.User{ID: "2", Role: domain.RoleAdmin}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("SetDisabled", mock.Anything, "1", true).Return(nil)
+	suite.mockTokenRepo.On("DeleteUserRefreshTokens", mock.Anything, "1").Return(nil)
+	suite.mockUserRepo.On("IncrementTokenVersion", mock.Anything, "1").Return(nil)
+
+	user, err := suite.useCase.SetUserDisabled(context.Background(), "1", true, "2")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), user.Disabled)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditUserDisabled && e.EntityID == "1"
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestDeleteUser_RemovesMemberships() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&domain.User{ID: "2", Role: domain.RoleAdmin}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockUserRepo.On("Delete", mock.Anything, "1").Return(nil)
+	suite.mockTokenRepo.On("DeleteUserRefreshTokens", mock.Anything, "1").Return(nil)
+	project := domain.Project{ID: "p1", Members: []domain.ProjectMember{
+		{UserID: "1", Role: domain.ProjectEditor},
+		{UserID: "2", Role: domain.ProjectOwner},
+	}}
+	suite.mockProjectRepo.On("GetByMember", mock.Anything, "1").Return([]domain.Project{project}, nil)
+	suite.mockProjectRepo.On("Update", mock.Anything, mock.MatchedBy(func(p domain.Project) bool {
+		_, ok := p.RoleOf("1")
+		return p.ID == "p1" && !ok && len(p.Members) == 1
+	})).Return(&project, nil)
+
+	assert.NoError(suite.T(), suite.useCase.DeleteUser(context.Background(), "1", "2"))
+	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockProjectRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestDeleteUser_LastOwner() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "2").Return(&domain.User{ID: "2", Role: domain.RoleAdmin}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	project := domain.Project{ID: "p1", Members: []domain.ProjectMember{
+		{UserID: "1", Role: domain.ProjectOwner},
+		{UserID: "2", Role: domain.ProjectEditor},
+	}}
+	suite.mockProjectRepo.On("GetByMember", mock.Anything, "1").Return([]domain.Project{project}, nil)
+
+	assert.Equal(suite.T(), domain.ErrLastOwner, suite.useCase.DeleteUser(context.Background(), "1", "2"))
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+	suite.mockProjectRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+// Two admins disabling each other at once must not both get past the check
+// and leave no active admin behind.
+func (suite *UserUseCaseTestSuite) TestSetUserDisabled_ParallelLastAdmin() {
+	userRepo := repositories.NewMemoryUserRepository()
+	useCase := NewUserUseCase(userRepo, suite.mockRoleRepo, suite.mockTokenRepo, suite.mockMFARepo, suite.mockProjectRepo, suite.mockAudit, suite.mockEvents, suite.mockPasswordSvc, suite.mockAuthSvc, suite.mockTOTP, suite.mockThrottle, suite.mockMailer, time.Hour, 5*time.Minute, 30*time.Minute, "https://tasks.example.com/reset")
+	suite.mockTokenRepo.On("DeleteUserRefreshTokens", mock.Anything, mock.Anything).Return(nil)
+	first, err := userRepo.Create(context.Background(), domain.User{Username: "first", Role: domain.RoleAdmin})
+	suite.Require().NoError(err)
+	second, err := userRepo.Create(context.Background(), domain.User{Username: "second", Role: domain.RoleAdmin})
+	suite.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, pair := range [][2]string{{first.ID, second.ID}, {second.ID, first.ID}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = useCase.SetUserDisabled(context.Background(), pair[0], true, pair[1])
+		}()
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(suite.T(), []error{nil, domain.ErrLastAdmin}, errs)
+	active := false
+	page, err := userRepo.Find(context.Background(), domain.UserQuery{Role: domain.RoleAdmin, Disabled: &active, Limit: 2})
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), page.Users, 1)
+}
+
+func (suite *UserUseCaseTestSuite) TestListUsers_Normalizes() {
+	suite.mockUserRepo.On("Find", mock.Anything, domain.UserQuery{Role: "triager", Limit: domain.MaxUserPageSize}).
+		Return(&domain.UserPage{Users: []domain.User{}}, nil)
+
+	_, err := suite.useCase.ListUsers(context.Background(), domain.UserQuery{Role: " Triager", Limit: 1000})
+	assert.NoError(suite.T(), err)
+	_, err = suite.useCase.ListUsers(context.Background(), domain.UserQuery{Limit: -1})
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestChangePassword() {
+	bumped := suite.dummyUser
+	bumped.TokenVersion = 1
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil).Times(2)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&bumped, nil).Once()
+	suite.mockPasswordSvc.On("Check", "wrong", "hashedpassword").Return(false)
+	suite.mockPasswordSvc.On("Check", "password123", "hashedpassword").Return(true)
+	suite.mockPasswordSvc.On("Hash", "newpassword").Return("newhash", nil)
+	suite.mockUserRepo.On("SetPassword", mock.Anything, "1", "newhash").Return(nil)
+	suite.mockTokenRepo.On("DeleteUserPasswordResetTokens", mock.Anything, "1").Return(nil)
+	suite.mockTokenRepo.On("DeleteUserRefreshTokens", mock.Anything, "1").Return(nil)
+	suite.mockUserRepo.On("IncrementTokenVersion", mock.Anything, "1").Return(nil)
+	suite.mockAuthSvc.On("GenerateToken", &bumped).Return("jwt-token", nil)
+	suite.mockAuthSvc.On("GenerateOpaqueToken").Return("refresh-token", nil)
+	suite.mockAuthSvc.On("HashToken", "refresh-token").Return("refresh-hash")
+	suite.mockTokenRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := suite.useCase.ChangePassword(context.Background(), "1", "wrong", "newpassword", testClientIP)
+	assert.Equal(suite.T(), domain.ErrInvalidCredentials, err)
+	suite.mockThrottle.AssertCalled(suite.T(), "Reserve", mock.Anything, "testuser", testClientIP)
+	suite.mockThrottle.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything)
+	_, err = suite.useCase.ChangePassword(context.Background(), "1", "password123", "short", testClientIP)
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "SetPassword", mock.Anything, mock.Anything, mock.Anything)
+
+	tokens, err := suite.useCase.ChangePassword(context.Background(), "1", "password123", "newpassword", testClientIP)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &domain.TokenPair{AccessToken: "jwt-token", RefreshToken: "refresh-token"}, tokens)
+	suite.mockThrottle.AssertCalled(suite.T(), "Release", mock.Anything, "testuser", testClientIP)
+	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	suite.mockAuthSvc.AssertExpectations(suite.T())
+	suite.mockEvents.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventSessionsEnded && e.User.ID == "1" && e.TokenID == ""
+	}))
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditPasswordChanged && e.EntityID == "1"
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestChangePassword_Throttled() {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockThrottle.On("Reserve", mock.Anything, "testuser", "198.51.100.7").Return(&domain.LoginThrottledError{RetryAfter: time.Minute})
+
+	_, err := suite.useCase.ChangePassword(context.Background(), "1", "password123", "newpassword", "198.51.100.7")
+	assert.ErrorIs(suite.T(), err, domain.ErrTooManyAttempts)
+	suite.mockPasswordSvc.AssertNotCalled(suite.T(), "Check", mock.Anything, mock.Anything)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "SetPassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestRequestPasswordReset_MailsHashedToken() {
+	user := suite.dummyUser
+	user.Email = "test@example.com"
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&user, nil)
+	suite.mockAuthSvc.On("GenerateOpaqueToken").Return("reset/token+1", nil)
+	suite.mockAuthSvc.On("HashToken", "reset/token+1").Return("resethash")
+	suite.mockTokenRepo.On("DeleteUserPasswordResetTokens", mock.Anything, "1").Return(nil)
+	suite.mockTokenRepo.On("CreatePasswordResetToken", mock.Anything, mock.MatchedBy(func(t domain.PasswordResetToken) bool {
+		ttl := t.ExpiresAt.Sub(t.CreatedAt)
+		return t.Hash == "resethash" && t.UserID == "1" && ttl == 30*time.Minute
+	})).Return(nil)
+	suite.mockMailer.On("Send", mock.Anything, mock.MatchedBy(func(m domain.Mail) bool {
+		return m.To == "test@example.com" &&
+			strings.Contains(m.Body, "https://tasks.example.com/reset?token=reset%2Ftoken%2B1") &&
+			strings.Contains(m.Body, "30m0s")
+	})).Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.RequestPasswordReset(context.Background(), "testuser"))
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	suite.mockMailer.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestRequestPasswordReset_UnknownUser() {
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "ghost").Return(nil, domain.ErrNotFound)
+	// Without an address there is nowhere to send the token
+	suite.mockUserRepo.On("GetByUsername", mock.Anything, "testuser").Return(&suite.dummyUser, nil)
+
+	assert.NoError(suite.T(), suite.useCase.RequestPasswordReset(context.Background(), "ghost"))
+	assert.NoError(suite.T(), suite.useCase.RequestPasswordReset(context.Background(), "testuser"))
+	suite.mockTokenRepo.AssertNotCalled(suite.T(), "CreatePasswordResetToken", mock.Anything, mock.Anything)
+	suite.mockMailer.AssertNotCalled(suite.T(), "Send", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestResetPassword_EndsSessions() {
+	suite.mockAuthSvc.On("HashToken", "token").Return("resethash")
+	suite.mockTokenRepo.On("ConsumePasswordResetToken", mock.Anything, "resethash").
+		Return(&domain.PasswordResetToken{Hash: "resethash", UserID: "1"}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockPasswordSvc.On("Hash", "newpassword").Return("newhash", nil)
+	suite.mockUserRepo.On("SetPassword", mock.Anything, "1", "newhash").Return(nil)
+	suite.mockTokenRepo.On("DeleteUserPasswordResetTokens", mock.Anything, "1").Return(nil)
+	suite.mockTokenRepo.On("DeleteUserRefreshTokens", mock.Anything, "1").Return(nil)
+	suite.mockUserRepo.On("IncrementTokenVersion", mock.Anything, "1").Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.ResetPassword(context.Background(), "token", "newpassword"))
+	suite.mockUserRepo.AssertExpectations(suite.T())
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	suite.mockAudit.AssertCalled(suite.T(), "Record", mock.Anything, mock.MatchedBy(func(e domain.AuditEntry) bool {
+		return e.Action == domain.AuditPasswordReset && e.EntityID == "1"
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestResetPassword_Rejected() {
+	suite.mockAuthSvc.On("HashToken", "used").Return("usedhash")
+	suite.mockTokenRepo.On("ConsumePasswordResetToken", mock.Anything, "usedhash").Return(nil, domain.ErrNotFound)
+
+	err := suite.useCase.ResetPassword(context.Background(), "used", "newpassword")
+	assert.Equal(suite.T(), domain.ErrInvalidResetToken, err)
+
+	// A password that would be refused leaves the token unused
+	err = suite.useCase.ResetPassword(context.Background(), "token", "short")
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+	suite.mockTokenRepo.AssertNumberOfCalls(suite.T(), "ConsumePasswordResetToken", 1)
+	suite.mockUserRepo.AssertNotCalled(suite.T(), "SetPassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestRefresh_Success() {
+	stored := domain.RefreshToken{Hash: "old-hash", UserID: "1", ExpiresAt: time.Now().Add(time.Hour)}
+	suite.mockAuthSvc.On("HashToken", "old-token").Return("old-hash")
+	suite.mockTokenRepo.On("GetRefreshToken", mock.Anything, "old-hash").Return(&stored, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	suite.mockTokenRepo.On("RevokeRefreshToken", mock.Anything, "old-hash").Return(nil)
+	suite.mockAuthSvc.On("GenerateToken", &suite.dummyUser).Return("new-jwt", nil)
+	suite.mockAuthSvc.On("GenerateOpaqueToken").Return("new-token", nil)
+	suite.mockAuthSvc.On("HashToken", "new-token").Return("new-hash")
+	suite.mockTokenRepo.On("CreateRefreshToken", mock.Anything, mock.AnythingOfType("domain.RefreshToken")).Return(nil)
+
+	tokens, err := suite.useCase.Refresh(context.Background(), "old-token")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new-jwt", tokens.AccessToken)
+	assert.Equal(suite.T(), "new-token", tokens.RefreshToken)
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	suite.mockAuthSvc.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestRefresh_UnknownToken() {
+	suite.mockAuthSvc.On("HashToken", "bogus").Return("bogus-hash")
+	suite.mockTokenRepo.On("GetRefreshToken", mock.Anything, "bogus-hash").Return(nil, domain.ErrNotFound)
+
+	_, err := suite.useCase.Refresh(context.Background(), "bogus")
+	assert.Equal(suite.T(), domain.ErrUnauthorized, err)
+}
+
+func (suite *UserUseCaseTestSuite) TestRefresh_Expired() {
+	stored := domain.RefreshToken{Hash: "old-hash", UserID: "1", ExpiresAt: time.Now().Add(-time.Minute)}
+	suite.mockAuthSvc.On("HashToken", "old-token").Return("old-hash")
+	suite.mockTokenRepo.On("GetRefreshToken", mock.Anything, "old-hash").Return(&stored, nil)
+
+	_, err := suite.useCase.Refresh(context.Background(), "old-token")
+	assert.Equal(suite.T(), domain.ErrUnauthorized, err)
+	suite.mockTokenRepo.AssertNotCalled(suite.T(), "RevokeRefreshToken", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestRefresh_LostRace() {
+	stored := domain.RefreshToken{Hash: "old-hash", UserID: "1", ExpiresAt: time.Now().Add(time.Hour)}
+	suite.mockAuthSvc.On("HashToken", "old-token").Return("old-hash")
+	suite.mockTokenRepo.On("GetRefreshToken", mock.Anything, "old-hash").Return(&stored, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "1").Return(&suite.dummyUser, nil)
+	// Another refresh revoked the token after it was read
+	suite.mockTokenRepo.On("RevokeRefreshToken", mock.Anything, "old-hash").Return(domain.ErrNotFound)
+
+	_, err := suite.useCase.Refresh(context.Background(), "old-token")
+	assert.Equal(suite.T(), domain.ErrUnauthorized, err)
+	suite.mockTokenRepo.AssertNotCalled(suite.T(), "CreateRefreshToken", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestRefresh_ReuseRevokesAllSessions() {
+	stored := domain.RefreshToken{Hash: "old-hash", UserID: "1", ExpiresAt: time.Now().Add(time.Hour), Revoked: true}
+	suite.mockAuthSvc.On("HashToken", "old-token").Return("old-hash")
+	suite.mockTokenRepo.On("GetRefreshToken", mock.Anything, "old-hash").Return(&stored, nil)
+	suite.mockTokenRepo.On("DeleteUserRefreshTokens", mock.Anything, "1").Return(nil)
+	suite.mockUserRepo.On("IncrementTokenVersion", mock.Anything, "1").Return(nil)
+
+	_, err := suite.useCase.Refresh(context.Background(), "old-token")
+	assert.Equal(suite.T(), domain.ErrUnauthorized, err)
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	suite.mockUserRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserUseCaseTestSuite) TestLogout_Success() {
+	expiresAt := time.Now().Add(10 * time.Minute)
+	requester := domain.Claims{UserID: "1", TokenID: "jti-1", ExpiresAt: expiresAt}
+	stored := domain.RefreshToken{Hash: "refresh-hash", UserID: "1"}
+	suite.mockAuthSvc.On("HashToken", "refresh-token").Return("refresh-hash")
+	suite.mockTokenRepo.On("GetRefreshToken", mock.Anything, "refresh-hash").Return(&stored, nil)
+	suite.mockTokenRepo.On("DeleteRefreshToken", mock.Anything, "refresh-hash").Return(nil)
+	suite.mockTokenRepo.On("RevokeAccessToken", mock.Anything, "jti-1", expiresAt).Return(nil)
+
+	err := suite.useCase.Logout(context.Background(), requester, "refresh-token")
+	assert.NoError(suite.T(), err)
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+	// Task streams opened with the token are closed too
+	suite.mockEvents.AssertCalled(suite.T(), "Publish", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		return e.Type == domain.EventSessionsEnded && e.User.ID == "1" && e.TokenID == "jti-1"
+	}))
+}
+
+func (suite *UserUseCaseTestSuite) TestLogout_OtherUsersRefreshToken() {
+	requester := domain.Claims{UserID: "1", TokenID: "jti-1"}
+	stored := domain.RefreshToken{Hash: "refresh-hash", UserID: "2"}
+	suite.mockAuthSvc.On("HashToken", "refresh-token").Return("refresh-hash")
+	suite.mockTokenRepo.On("GetRefreshToken", mock.Anything, "refresh-hash").Return(&stored, nil)
+
+	err := suite.useCase.Logout(context.Background(), requester, "refresh-token")
+	assert.Equal(suite.T(), domain.ErrForbidden, err)
+	suite.mockTokenRepo.AssertNotCalled(suite.T(), "DeleteRefreshToken", mock.Anything, mock.Anything)
+}
+
+func (suite *UserUseCaseTestSuite) TestLogout_AccessTokenOnly() {
+	requester := domain.Claims{UserID: "1", TokenID: "jti-1"}
+	suite.mockTokenRepo.On("RevokeAccessToken", mock.Anything, "jti-1", time.Time{}).Return(nil)
+
+	err := suite.useCase.Logout(context.Background(), requester, "")
+	assert.NoError(suite.T(), err)
+	suite.mockTokenRepo.AssertExpectations(suite.T())
+}
+
 func TestUserUseCaseTestSuite(t *testing.T) {
 	suite.Run(t, new(UserUseCaseTestSuite))
 }
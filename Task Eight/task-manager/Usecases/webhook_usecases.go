@@ -0,0 +1,289 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	domain "task-manager/Domain"
+	"time"
+)
+
+const (
+	// webhookBatchSize caps the deliveries attempted by a single run.
+	webhookBatchSize = 100
+	// webhookConcurrency caps the deliveries in flight at once, so one slow
+	// endpoint does not hold up the others.
+	webhookConcurrency = 8
+)
+
+type WebhookUseCase struct {
+	webhookRepo  domain.IWebhookRepository
+	deliveryRepo domain.IWebhookDeliveryRepository
+	sender       domain.IWebhookSender
+	retry        domain.RetryPolicy
+}
+
+func NewWebhookUseCase(webhookRepo domain.IWebhookRepository, deliveryRepo domain.IWebhookDeliveryRepository, sender domain.IWebhookSender, retry domain.RetryPolicy) domain.IWebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		sender:       sender,
+		retry:        retry,
+	}
+}
+
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return uc.webhookRepo.GetAll(ctx)
+}
+
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	return uc.webhookRepo.GetByID(ctx, id)
+}
+
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, webhook domain.Webhook, actorID string) (*domain.Webhook, error) {
+	if webhook.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+	webhook.NormalizeEvents()
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+	webhook.CreatedBy = actorID
+	return uc.webhookRepo.Create(ctx, webhook)
+}
+
+func (uc *WebhookUseCase) UpdateWebhook(ctx context.Context, id string, patch domain.WebhookPatch) (*domain.Webhook, error) {
+	webhook, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.URL != nil {
+		webhook.URL = *patch.URL
+	}
+	if patch.Events != nil {
+		webhook.Events = patch.Events
+	}
+	if patch.Active != nil {
+		webhook.Active = *patch.Active
+	}
+	if patch.Secret != nil {
+		webhook.Secret = *patch.Secret
+	}
+	webhook.NormalizeEvents()
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+	return uc.webhookRepo.Update(ctx, *webhook)
+}
+
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id string) error {
+	if err := uc.webhookRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return uc.deliveryRepo.DeleteByWebhook(ctx, id)
+}
+
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, webhookID string, query domain.WebhookDeliveryQuery) (*domain.WebhookDeliveryPage, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+	if _, err := uc.webhookRepo.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	query.WebhookID = webhookID
+	return uc.deliveryRepo.Find(ctx, query)
+}
+
+// ReplayDelivery queues the logged payload again as a new delivery, so the
+// original entry keeps its outcome.
+func (uc *WebhookUseCase) ReplayDelivery(ctx context.Context, webhookID string, deliveryID string) (*domain.WebhookDelivery, error) {
+	webhook, err := uc.webhookRepo.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.Active {
+		return nil, fmt.Errorf("%w: webhook is inactive", domain.ErrInvalidInput)
+	}
+	original, err := uc.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.WebhookID != webhookID {
+		return nil, domain.ErrNotFound
+	}
+
+	return uc.deliveryRepo.Create(ctx, domain.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        domain.DeliveryPending,
+		NextAttemptAt: time.Now(),
+		ReplayOf:      original.ID,
+	})
+}
+
+// Publish queues a delivery for every active webhook subscribed to the
+// event. Like the audit log, it runs after the change has been stored, so
+// storage errors are logged rather than returned.
+func (uc *WebhookUseCase) Publish(ctx context.Context, event domain.Event) {
+	webhooks, err := uc.webhookRepo.GetAll(ctx)
+	if err != nil {
+		log.Printf("Failed to load webhooks for event %s: %v", event.Type, err)
+		return
+	}
+
+	var payload []byte
+	for i := range webhooks {
+		if !webhooks[i].Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				log.Printf("Failed to encode event %s: %v", event.Type, err)
+				return
+			}
+		}
+		_, err := uc.deliveryRepo.Create(ctx, domain.WebhookDelivery{
+			WebhookID:     webhooks[i].ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: event.OccurredAt,
+		})
+		if err != nil {
+			log.Printf("Failed to queue event %s for webhook %s: %v", event.Type, webhooks[i].ID, err)
+		}
+	}
+}
+
+func (uc *WebhookUseCase) DeliverDue(ctx context.Context, now time.Time) error {
+	deliveries, err := uc.deliveryRepo.FindDue(ctx, now, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// Each webhook is loaded once per run
+	webhooks := make(map[string]*domain.Webhook)
+	for _, d := range deliveries {
+		if _, ok := webhooks[d.WebhookID]; ok {
+			continue
+		}
+		webhook, err := uc.webhookRepo.GetByID(ctx, d.WebhookID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		webhooks[d.WebhookID] = webhook
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, webhookConcurrency)
+	for i := range deliveries {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(delivery *domain.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			uc.attempt(ctx, webhooks[delivery.WebhookID], delivery, now)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a retry
+// while attempts remain.
+func (uc *WebhookUseCase) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery, now time.Time) {
+	if webhook == nil || !webhook.Active {
+		// Nothing will ever send it, but it can be replayed once the
+		// webhook is active again
+		delivery.Status = domain.DeliveryFailed
+		delivery.Error = "webhook is inactive or deleted"
+		delivery.NextAttemptAt = time.Time{}
+		uc.saveAttempt(ctx, delivery)
+		return
+	}
+
+	status, err := uc.sender.Send(ctx, webhook, delivery)
+	if ctx.Err() != nil {
+		// Shutting down; the delivery stays due and is sent on the next start
+		return
+	}
+
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.LastAttemptAt = now
+	switch {
+	case err == nil:
+		delivery.Status = domain.DeliverySucceeded
+		delivery.Error = ""
+		delivery.NextAttemptAt = time.Time{}
+	case delivery.Attempts >= uc.retry.MaxAttempts:
+		delivery.Status = domain.DeliveryFailed
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = time.Time{}
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now.Add(uc.retry.Delay(delivery.Attempts))
+	}
+	uc.saveAttempt(ctx, delivery)
+}
+
+func (uc *WebhookUseCase) saveAttempt(ctx context.Context, delivery *domain.WebhookDelivery) {
+	if err := uc.deliveryRepo.Update(ctx, *delivery); err != nil {
+		log.Printf("Failed to record attempt of webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// newWebhookSecret returns a random 32-byte secret, hex encoded.
+func newWebhookSecret() (string, error) {
+	return randomHex(32)
+}
+
+// newEventID returns a random 16-byte event ID, hex encoded. Receivers use
+// it to recognise an event delivered more than once.
+func newEventID() (string, error) {
+	return randomHex(16)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// EventFanout publishes every event to each of its publishers in turn.
+type EventFanout []domain.IEventPublisher
+
+func (f EventFanout) Publish(ctx context.Context, event domain.Event) {
+	for _, publisher := range f {
+		publisher.Publish(ctx, event)
+	}
+}
+
+// publishEvents stamps the events with an ID and time and publishes them.
+func publishEvents(ctx context.Context, publisher domain.IEventPublisher, actorID string, events ...domain.Event) {
+	for _, event := range events {
+		id, err := newEventID()
+		if err != nil {
+			log.Printf("Failed to publish event %s: %v", event.Type, err)
+			continue
+		}
+		event.ID = id
+		event.ActorID = actorID
+		event.OccurredAt = time.Now()
+		publisher.Publish(ctx, event)
+	}
+}
@@ -0,0 +1,270 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	domain "task-manager/Domain"
+	"task-manager/Repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookUseCaseTestSuite struct {
+	suite.Suite
+	mockWebhooks   *mocks.MockWebhookRepository
+	mockDeliveries *mocks.MockWebhookDeliveryRepository
+	mockSender     *mocks.MockWebhookSender
+	useCase        domain.IWebhookUseCase
+	webhook        domain.Webhook
+	delivery       domain.WebhookDelivery
+	now            time.Time
+}
+
+func (suite *WebhookUseCaseTestSuite) SetupTest() {
+	suite.mockWebhooks = new(mocks.MockWebhookRepository)
+	suite.mockDeliveries = new(mocks.MockWebhookDeliveryRepository)
+	suite.mockSender = new(mocks.MockWebhookSender)
+	retry := domain.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour}
+	suite.useCase = NewWebhookUseCase(suite.mockWebhooks, suite.mockDeliveries, suite.mockSender, retry)
+	suite.webhook = domain.Webhook{
+		ID:     "w1",
+		URL:    "https://example.com/hook",
+		Secret: "0123456789abcdef",
+		Events: []string{domain.EventTaskCreated},
+		Active: true,
+	}
+	suite.now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	suite.delivery = domain.WebhookDelivery{
+		ID:            "d1",
+		WebhookID:     "w1",
+		EventID:       "e1",
+		EventType:     domain.EventTaskCreated,
+		Payload:       `{"id":"e1"}`,
+		Status:        domain.DeliveryPending,
+		NextAttemptAt: suite.now,
+	}
+}
+
+func (suite *WebhookUseCaseTestSuite) TestCreateWebhook_GeneratesSecret() {
+	suite.mockWebhooks.On("Create", mock.Anything, mock.MatchedBy(func(w domain.Webhook) bool {
+		return len(w.Secret) == 64 && w.CreatedBy == "admin-1" &&
+			assert.ObjectsAreEqual([]string{domain.EventTaskCreated, domain.EventTaskDeleted}, w.Events)
+	})).Return(&suite.webhook, nil)
+
+	// Events are sorted and de-duplicated
+	webhook := domain.Webhook{
+		URL:    "https://example.com/hook",
+		Events: []string{domain.EventTaskDeleted, domain.EventTaskCreated, domain.EventTaskDeleted},
+		Active: true,
+	}
+	_, err := suite.useCase.CreateWebhook(context.Background(), webhook, "admin-1")
+	assert.NoError(suite.T(), err)
+	suite.mockWebhooks.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestCreateWebhook_Invalid() {
+	for name, webhook := range map[string]domain.Webhook{
+		"relative url":  {URL: "/hook", Events: []string{domain.EventTaskCreated}},
+		"ftp url":       {URL: "ftp://example.com", Events: []string{domain.EventTaskCreated}},
+		"no events":     {URL: "https://example.com"},
+		"unknown event": {URL: "https://example.com", Events: []string{"task.exploded"}},
+		"short secret":  {URL: "https://example.com", Events: []string{domain.EventTaskCreated}, Secret: "short"},
+	} {
+		_, err := suite.useCase.CreateWebhook(context.Background(), webhook, "admin-1")
+		assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput, name)
+	}
+	suite.mockWebhooks.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookUseCaseTestSuite) TestUpdateWebhook_AppliesPatch() {
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	suite.mockWebhooks.On("Update", mock.Anything, mock.MatchedBy(func(w domain.Webhook) bool {
+		return !w.Active && w.URL == suite.webhook.URL && w.Secret == suite.webhook.Secret
+	})).Return(&suite.webhook, nil)
+
+	active := false
+	_, err := suite.useCase.UpdateWebhook(context.Background(), "w1", domain.WebhookPatch{Active: &active})
+	assert.NoError(suite.T(), err)
+	suite.mockWebhooks.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestUpdateWebhook_EmptyEvents() {
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	_, err := suite.useCase.UpdateWebhook(context.Background(), "w1", domain.WebhookPatch{Events: []string{}})
+	assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput)
+	suite.mockWebhooks.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookUseCaseTestSuite) TestDeleteWebhook_RemovesDeliveries() {
+	suite.mockWebhooks.On("Delete", mock.Anything, "w1").Return(nil)
+	suite.mockDeliveries.On("DeleteByWebhook", mock.Anything, "w1").Return(nil)
+	assert.NoError(suite.T(), suite.useCase.DeleteWebhook(context.Background(), "w1"))
+	suite.mockDeliveries.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestPublish_QueuesForSubscribers() {
+	inactive := suite.webhook
+	inactive.ID = "w2"
+	inactive.Active = false
+	other := suite.webhook
+	other.ID = "w3"
+	other.Events = []string{domain.EventUserRegistered}
+	suite.mockWebhooks.On("GetAll", mock.Anything).Return([]domain.Webhook{suite.webhook, inactive, other}, nil)
+	suite.mockDeliveries.On("Create", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		var event domain.Event
+		return d.WebhookID == "w1" && d.EventID == "e1" && d.Status == domain.DeliveryPending &&
+			d.NextAttemptAt.Equal(suite.now) && json.Unmarshal([]byte(d.Payload), &event) == nil && event.Task.ID == "t1"
+	})).Return(&suite.delivery, nil).Once()
+
+	suite.useCase.Publish(context.Background(), domain.Event{
+		ID:         "e1",
+		Type:       domain.EventTaskCreated,
+		OccurredAt: suite.now,
+		Task:       &domain.Task{ID: "t1"},
+	})
+	suite.mockDeliveries.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestDeliverDue_Success() {
+	suite.mockDeliveries.On("FindDue", mock.Anything, suite.now, mock.Anything).Return([]domain.WebhookDelivery{suite.delivery}, nil)
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	suite.mockSender.On("Send", mock.Anything, &suite.webhook, mock.Anything).Return(200, nil)
+	suite.mockDeliveries.On("Update", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.DeliverySucceeded && d.Attempts == 1 && d.ResponseStatus == 200 &&
+			d.NextAttemptAt.IsZero() && d.LastAttemptAt.Equal(suite.now)
+	})).Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.DeliverDue(context.Background(), suite.now))
+	suite.mockDeliveries.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestDeliverDue_RetriesWithBackoff() {
+	suite.delivery.Attempts = 1
+	suite.mockDeliveries.On("FindDue", mock.Anything, suite.now, mock.Anything).Return([]domain.WebhookDelivery{suite.delivery}, nil)
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	suite.mockSender.On("Send", mock.Anything, mock.Anything, mock.Anything).Return(503, errors.New("unexpected response status 503"))
+	suite.mockDeliveries.On("Update", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		// The second failure waits twice the base backoff
+		return d.Status == domain.DeliveryPending && d.Attempts == 2 && d.ResponseStatus == 503 &&
+			d.Error != "" && d.NextAttemptAt.Equal(suite.now.Add(2*time.Minute))
+	})).Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.DeliverDue(context.Background(), suite.now))
+	suite.mockDeliveries.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestDeliverDue_GivesUpAfterMaxAttempts() {
+	suite.delivery.Attempts = 2
+	suite.mockDeliveries.On("FindDue", mock.Anything, suite.now, mock.Anything).Return([]domain.WebhookDelivery{suite.delivery}, nil)
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	suite.mockSender.On("Send", mock.Anything, mock.Anything, mock.Anything).Return(0, errors.New("connection refused"))
+	suite.mockDeliveries.On("Update", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.DeliveryFailed && d.Attempts == 3 && d.Error == "connection refused" && d.NextAttemptAt.IsZero()
+	})).Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.DeliverDue(context.Background(), suite.now))
+	suite.mockDeliveries.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestDeliverDue_DeletedWebhook() {
+	suite.mockDeliveries.On("FindDue", mock.Anything, suite.now, mock.Anything).Return([]domain.WebhookDelivery{suite.delivery}, nil)
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(nil, domain.ErrNotFound)
+	suite.mockDeliveries.On("Update", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.DeliveryFailed && d.Attempts == 0
+	})).Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.DeliverDue(context.Background(), suite.now))
+	suite.mockSender.AssertNotCalled(suite.T(), "Send", mock.Anything, mock.Anything, mock.Anything)
+	suite.mockDeliveries.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestDeliverDue_ShutdownLeavesDeliveryPending() {
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.mockDeliveries.On("FindDue", mock.Anything, suite.now, mock.Anything).Return([]domain.WebhookDelivery{suite.delivery}, nil)
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	suite.mockSender.On("Send", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) { cancel() }).Return(0, context.Canceled)
+
+	assert.NoError(suite.T(), suite.useCase.DeliverDue(ctx, suite.now))
+	suite.mockDeliveries.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookUseCaseTestSuite) TestReplayDelivery_QueuesCopy() {
+	suite.delivery.Status = domain.DeliveryFailed
+	suite.delivery.Attempts = 3
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	suite.mockDeliveries.On("GetByID", mock.Anything, "d1").Return(&suite.delivery, nil)
+	suite.mockDeliveries.On("Create", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.ReplayOf == "d1" && d.Payload == suite.delivery.Payload && d.EventID == "e1" &&
+			d.Status == domain.DeliveryPending && d.Attempts == 0
+	})).Return(&domain.WebhookDelivery{ID: "d2"}, nil)
+
+	replay, err := suite.useCase.ReplayDelivery(context.Background(), "w1", "d1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "d2", replay.ID)
+}
+
+func (suite *WebhookUseCaseTestSuite) TestReplayDelivery_OtherWebhook() {
+	suite.delivery.WebhookID = "w2"
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	suite.mockDeliveries.On("GetByID", mock.Anything, "d1").Return(&suite.delivery, nil)
+
+	_, err := suite.useCase.ReplayDelivery(context.Background(), "w1", "d1")
+	assert.Equal(suite.T(), domain.ErrNotFound, err)
+	suite.mockDeliveries.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookUseCaseTestSuite) TestReplayDelivery_InactiveWebhook() {
+	suite.webhook.Active = false
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+
+	_, err := suite.useCase.ReplayDelivery(context.Background(), "w1", "d1")
+	assert.ErrorIs(suite.T(), err, domain.ErrInvalidInput)
+}
+
+func (suite *WebhookUseCaseTestSuite) TestListDeliveries_ScopesToWebhook() {
+	suite.mockWebhooks.On("GetByID", mock.Anything, "w1").Return(&suite.webhook, nil)
+	expected := domain.WebhookDeliveryQuery{WebhookID: "w1", Status: domain.DeliveryFailed, Limit: domain.DefaultWebhookDeliveryPageSize}
+	suite.mockDeliveries.On("Find", mock.Anything, expected).Return(&domain.WebhookDeliveryPage{}, nil)
+
+	_, err := suite.useCase.ListDeliveries(context.Background(), "w1", domain.WebhookDeliveryQuery{WebhookID: "w9", Status: domain.DeliveryFailed})
+	assert.NoError(suite.T(), err)
+	suite.mockDeliveries.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUseCaseTestSuite) TestListDeliveries_InvalidStatus() {
+	_, err := suite.useCase.ListDeliveries(context.Background(), "w1", domain.WebhookDeliveryQuery{Status: "lost"})
+	assert.Equal(suite.T(), domain.ErrInvalidInput, err)
+}
+
+func TestWebhookUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookUseCaseTestSuite))
+}
+
+func TestTaskEvents(t *testing.T) {
+	before := &domain.Task{ID: "1", Status: domain.StatusPending}
+	after := &domain.Task{ID: "1", Status: domain.StatusPending, Title: "renamed"}
+	done := &domain.Task{ID: "1", Status: domain.StatusDone}
+
+	assert.Empty(t, domain.TaskEvents(nil, nil))
+	assert.Equal(t, []domain.Event{{Type: domain.EventTaskCreated, Task: after}}, domain.TaskEvents(nil, after))
+	assert.Equal(t, []domain.Event{{Type: domain.EventTaskDeleted, Task: before}}, domain.TaskEvents(before, nil))
+	assert.Equal(t, []domain.Event{{Type: domain.EventTaskUpdated, Task: after}}, domain.TaskEvents(before, after))
+	assert.Equal(t, []domain.Event{
+		{Type: domain.EventTaskUpdated, Task: done},
+		{Type: domain.EventTaskStatusChanged, Task: done, PreviousStatus: domain.StatusPending},
+	}, domain.TaskEvents(before, done))
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := domain.RetryPolicy{MaxAttempts: 10, Backoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+	assert.Equal(t, 30*time.Second, policy.Delay(1))
+	assert.Equal(t, time.Minute, policy.Delay(2))
+	assert.Equal(t, 4*time.Minute, policy.Delay(4))
+	assert.Equal(t, 5*time.Minute, policy.Delay(5))
+	assert.Equal(t, 5*time.Minute, policy.Delay(50))
+}
@@ -3,40 +3,240 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server      ServerConfig
+	Storage     StorageConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Passwords   PasswordConfig
+	Login       LoginConfig
+	MFA         MFAConfig
+	Admin       AdminConfig
+	Tasks       TaskConfig
+	Attachments AttachmentConfig
+	Reminders   ReminderConfig
+	SMTP        SMTPConfig
+	Mail        MailConfig
+	Webhooks    WebhookConfig
 }
 
 type ServerConfig struct {
 	Port string
 	Host string
+	// TrustedProxies lists the proxies whose X-Forwarded-For headers are
+	// believed when finding the client IP. None by default, as anyone could
+	// set the header otherwise.
+	TrustedProxies []string
+}
+
+// Supported storage drivers.
+const (
+	StorageMongo  = "mongo"
+	StorageMemory = "memory"
+	StorageSQLite = "sqlite"
+)
+
+type StorageConfig struct {
+	Driver     string
+	SQLitePath string
 }
 
 type DatabaseConfig struct {
-	URI      string
-	Database string
+	URI          string
+	Database     string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	QueryTimeout time.Duration
 }
 
 type JWTConfig struct {
-	Secret string
+	Secret          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// PasswordConfig holds the password reset settings.
+type PasswordConfig struct {
+	// ResetTTL is how long a reset token stays valid.
+	ResetTTL time.Duration
+	// ResetURL is the page reset mails link to, with the token added as the
+	// "token" query parameter. When empty the mail holds the bare token.
+	ResetURL string
+}
+
+// LoginConfig throttles password guessing on /login. After FreeFailures
+// failed attempts a username is blocked for Backoff, doubling with each
+// further failure up to MaxBackoff, and after LockoutAfter failures for
+// LockoutDuration. Failures are forgotten Window after the last one. Client
+// IPs, which many users may share, get the same policy with their own
+// thresholds.
+type LoginConfig struct {
+	FreeFailures    int
+	Backoff         time.Duration
+	MaxBackoff      time.Duration
+	LockoutAfter    int
+	LockoutDuration time.Duration
+	Window          time.Duration
+	IPFreeFailures  int
+	IPLockoutAfter  int
+}
+
+// MFAConfig holds the two-factor authentication settings.
+type MFAConfig struct {
+	// Issuer names the service in authenticator apps.
+	Issuer string
+	// ChallengeTTL is how long a password-checked login may wait for its
+	// second factor.
+	ChallengeTTL time.Duration
+}
+
+// AdminConfig optionally seeds an admin account at startup, which is the only
+// way to get one when running without a persistent database.
+type AdminConfig struct {
+	Username string
+	Password string
+}
+
+// TaskConfig holds task workflow settings.
+type TaskConfig struct {
+	// StatusTransitions overrides the default status workflow, written as
+	// "from:to,to;from:to". Empty means the default.
+	StatusTransitions string
+}
+
+// AttachmentConfig holds the settings of task attachments.
+type AttachmentConfig struct {
+	// Dir is where the local blob store keeps attachment content.
+	Dir string
+	// MaxSize is the largest accepted upload in bytes.
+	MaxSize int64
+}
+
+// ReminderConfig holds the due-date reminder settings.
+type ReminderConfig struct {
+	Enabled bool
+	// Offsets are how long before the due date reminders fire; negative
+	// offsets fire after it.
+	Offsets []time.Duration
+	// Interval is the time between scheduler runs, Window how late a
+	// reminder may still be sent.
+	Interval time.Duration
+	Window   time.Duration
+	// Channels names the notifiers to send through: "log" and "smtp".
+	Channels []string
+}
+
+// SMTPConfig is the mail server used by the "smtp" reminder channel and mail
+// driver.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// MailConfig selects how account emails, such as password resets, are sent.
+type MailConfig struct {
+	// Driver is "log", "file" or "smtp"; smtp uses the SMTP settings.
+	Driver string
+	// Dir is where the file driver writes messages.
+	Dir string
+}
+
+// WebhookConfig holds the outgoing webhook delivery settings.
+type WebhookConfig struct {
+	// Interval is the time between dispatcher runs.
+	Interval time.Duration
+	// Timeout bounds a single delivery attempt.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery fails; retries
+	// wait Backoff, doubling each time up to MaxBackoff.
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
 }
 
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "localhost"),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			Host:           getEnv("SERVER_HOST", "localhost"),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES", nil),
+		},
+		Storage: StorageConfig{
+			Driver:     getEnv("STORAGE_DRIVER", StorageMongo),
+			SQLitePath: getEnv("SQLITE_PATH", "task_manager.db"),
 		},
 		Database: DatabaseConfig{
-			URI:      getEnv("MONGO_URI", "mongodb://localhost:27017"),
-			Database: getEnv("MONGO_DATABASE", "task_manager_db"),
+			URI:          getEnv("MONGO_URI", "mongodb://localhost:27017"),
+			Database:     getEnv("MONGO_DATABASE", "task_manager_db"),
+			ReadTimeout:  getEnvAsDuration("DB_READ_TIMEOUT", 5*time.Second),
+			WriteTimeout: getEnvAsDuration("DB_WRITE_TIMEOUT", 5*time.Second),
+			QueryTimeout: getEnvAsDuration("DB_QUERY_TIMEOUT", 10*time.Second),
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "your-very-secret-key"),
+			Secret:          getEnv("JWT_SECRET", "your-very-secret-key"),
+			AccessTokenTTL:  getEnvAsDuration("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvAsDuration("JWT_REFRESH_TTL", 7*24*time.Hour),
+		},
+		Passwords: PasswordConfig{
+			ResetTTL: getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+			ResetURL: getEnv("PASSWORD_RESET_URL", ""),
+		},
+		Login: LoginConfig{
+			FreeFailures:    getEnvAsInt("LOGIN_FREE_FAILURES", 5),
+			Backoff:         getEnvAsDuration("LOGIN_BACKOFF", time.Second),
+			MaxBackoff:      getEnvAsDuration("LOGIN_MAX_BACKOFF", time.Minute),
+			LockoutAfter:    getEnvAsInt("LOGIN_LOCKOUT_AFTER", 10),
+			LockoutDuration: getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			Window:          getEnvAsDuration("LOGIN_FAILURE_WINDOW", time.Hour),
+			IPFreeFailures:  getEnvAsInt("LOGIN_IP_FREE_FAILURES", 20),
+			IPLockoutAfter:  getEnvAsInt("LOGIN_IP_LOCKOUT_AFTER", 100),
+		},
+		MFA: MFAConfig{
+			Issuer:       getEnv("MFA_ISSUER", "Task Manager"),
+			ChallengeTTL: getEnvAsDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		},
+		Admin: AdminConfig{
+			Username: getEnv("ADMIN_USERNAME", ""),
+			Password: getEnv("ADMIN_PASSWORD", ""),
+		},
+		Tasks: TaskConfig{
+			StatusTransitions: getEnv("TASK_STATUS_TRANSITIONS", ""),
+		},
+		Attachments: AttachmentConfig{
+			Dir:     getEnv("ATTACHMENTS_DIR", "attachments"),
+			MaxSize: int64(getEnvAsInt("ATTACHMENTS_MAX_SIZE", 10<<20)),
+		},
+		Reminders: ReminderConfig{
+			Enabled:  getEnvAsBool("REMINDERS_ENABLED", true),
+			Offsets:  getEnvAsDurations("REMINDER_OFFSETS", []time.Duration{24 * time.Hour, time.Hour, 0}),
+			Interval: getEnvAsDuration("REMINDER_INTERVAL", time.Minute),
+			Window:   getEnvAsDuration("REMINDER_WINDOW", time.Hour),
+			Channels: getEnvAsList("REMINDER_CHANNELS", []string{"log"}),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", "localhost"),
+			Port:     getEnvAsInt("SMTP_PORT", 25),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "task-manager@localhost"),
+		},
+		Mail: MailConfig{
+			Driver: getEnv("MAIL_DRIVER", "log"),
+			Dir:    getEnv("MAIL_DIR", "mail"),
+		},
+		Webhooks: WebhookConfig{
+			Interval:    getEnvAsDuration("WEBHOOK_INTERVAL", 5*time.Second),
+			Timeout:     getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts: getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			Backoff:     getEnvAsDuration("WEBHOOK_BACKOFF", 30*time.Second),
+			MaxBackoff:  getEnvAsDuration("WEBHOOK_MAX_BACKOFF", time.Hour),
 		},
 	}
 }
@@ -56,3 +256,53 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma-separated value, dropping empty items.
+func getEnvAsList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvAsDurations parses a comma-separated list of durations. The default
+// is used if any item is malformed.
+func getEnvAsDurations(key string, defaultValue []time.Duration) []time.Duration {
+	var durations []time.Duration
+	for _, item := range getEnvAsList(key, nil) {
+		d, err := time.ParseDuration(item)
+		if err != nil {
+			return defaultValue
+		}
+		durations = append(durations, d)
+	}
+	if durations == nil {
+		return defaultValue
+	}
+	return durations
+}